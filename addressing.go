@@ -0,0 +1,223 @@
+package mpd
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+var ErrConvertSegmentTemplate = errors.New("cannot convert SegmentTemplate")
+
+// ConvertToSegmentTimeline replaces SegmentTemplate@duration in the Period at the index by SegmentTimelines that list
+// the same segments over the Period duration, or up to SegmentTemplate@endNumber in a Period without a known end.
+// If mediaTime is true, $Number$ in SegmentTemplate@media is replaced by $Time$, keeping its format tag; the
+// segments must be available under both names. The MPD is not modified if an error is returned.
+func (m *MPD) ConvertToSegmentTimeline(index int, mediaTime bool) error {
+	return m.convertSegmentTemplates(index, func(window *segmentWindow, effective *SegmentTemplate) (*SegmentTemplate, error) {
+		if effective.SegmentTimeline != nil || effective.Duration == 0 {
+			return nil, nil
+		}
+
+		segments, err := window.segments(&effective.MultipleSegmentBase, -1)
+		if err != nil {
+			return nil, errors.Join(ErrConvertSegmentTemplate, err)
+		}
+
+		converted := *effective
+		converted.Duration = 0
+		converted.SegmentTimeline = &SegmentTimeline{}
+		timeline := newTimelineBuilder(converted.SegmentTimeline)
+
+		for _, segment := range segments {
+			timeline.append(segment.Time, segment.Duration)
+		}
+
+		if mediaTime {
+			converted.Media = replaceTemplateIdentifier(converted.Media, "Number", "Time")
+		}
+
+		return &converted, nil
+	})
+}
+
+// ConvertToSegmentDuration replaces the SegmentTimelines of SegmentTemplates in the Period at the index by
+// SegmentTemplate@duration. The segments must be regular: each segment has to start within tolerance of its start
+// on the grid of @duration from the PresentationTimeOffset, and only the last segment may be shorter. @duration is
+// the average segment duration. SegmentTemplate@endNumber is set if the segments end before the Period.
+//
+// $Time$ in SegmentTemplate@media is replaced by $Number$, keeping its format tag, as $Time$ requires a
+// SegmentTimeline; the segments must be available under both names. The MPD is not modified if an error is
+// returned.
+func (m *MPD) ConvertToSegmentDuration(index int, tolerance time.Duration) error {
+	return m.convertSegmentTemplates(index, func(window *segmentWindow, effective *SegmentTemplate) (*SegmentTemplate, error) {
+		if effective.SegmentTimeline == nil {
+			return nil, nil
+		}
+
+		segments, err := window.segments(&effective.MultipleSegmentBase, -1)
+		if err != nil {
+			return nil, errors.Join(ErrConvertSegmentTemplate, err)
+		}
+
+		if len(segments) == 0 {
+			return nil, fmt.Errorf("%w: SegmentTimeline without segments in Period", ErrConvertSegmentTemplate)
+		}
+
+		first, last := &segments[0], &segments[len(segments)-1]
+		offset, timescale := effective.PresentationTimeOffset, first.Timescale
+		firstNumber := uint64(maxUint(effective.StartNumber, 1))
+
+		duration := last.Duration
+		if count := last.Number - firstNumber; count > 0 && last.Time > offset {
+			duration = (last.Time - offset + count/2) / count
+		}
+
+		deviation := durationTicks(tolerance, timescale)
+
+		for i := range segments {
+			segment := &segments[i]
+			start := offset + (segment.Number-firstNumber)*duration
+
+			if absDifference(segment.Time, start) > deviation || segment.Duration > duration+deviation || i+1 < len(segments) && duration > segment.Duration+deviation {
+				return nil, fmt.Errorf("%w: segment %d at %d with duration %d does not match @duration %d", ErrConvertSegmentTemplate, segment.Number, segment.Time, segment.Duration, duration)
+			}
+		}
+
+		converted := *effective
+		converted.SegmentTimeline = nil
+		converted.Duration = uint(duration)
+		converted.Media = replaceTemplateIdentifier(converted.Media, "Time", "Number")
+
+		if window.periodEnd > 0 {
+			if count := (durationTicks(window.periodEnd, timescale) + duration - 1) / duration; last.Number+1 < firstNumber+count {
+				converted.EndNumber = uint(last.Number)
+			}
+		}
+
+		return &converted, nil
+	})
+}
+
+// convertSegmentTemplates converts the SegmentTemplates of all levels of the Period at the index. Convert returns the
+// converted effective SegmentTemplate of a level, or nil if it needs no conversion. A level only receives the
+// converted addressing if it has its own or cannot inherit it.
+func (m *MPD) convertSegmentTemplates(index int, convert func(window *segmentWindow, effective *SegmentTemplate) (*SegmentTemplate, error)) error {
+	if index < 0 || index >= len(m.Period) {
+		return fmt.Errorf("%w: no period %d", ErrConvertSegmentTemplate, index)
+	}
+
+	duration, err := m.PeriodDuration(index)
+	if err != nil {
+		return errors.Join(ErrConvertSegmentTemplate, err)
+	}
+
+	// Segments are generated relative to the start of the Period.
+	window := &segmentWindow{periodEnd: duration, end: duration}
+
+	var apply []func()
+
+	type chains struct {
+		original, converted []*SegmentTemplate
+	}
+
+	convertLevel := func(parent chains, template *SegmentTemplate) (chains, error) {
+		if template == nil {
+			return parent, nil
+		}
+
+		converted := *template
+		level := chains{
+			original:  append(parent.original[:len(parent.original):len(parent.original)], template),
+			converted: append(parent.converted[:len(parent.converted):len(parent.converted)], &converted),
+		}
+
+		target, err := convert(window, inherit(level.original))
+		if err != nil {
+			return chains{}, err
+		}
+
+		if target == nil {
+			return level, nil
+		}
+
+		inherited := inherit(level.converted)
+
+		if template.Duration != 0 || inherited.Duration != target.Duration {
+			converted.Duration = target.Duration
+		}
+
+		if template.SegmentTimeline != nil || !reflect.DeepEqual(inherited.SegmentTimeline, target.SegmentTimeline) {
+			converted.SegmentTimeline = target.SegmentTimeline
+		}
+
+		if template.EndNumber != 0 || inherited.EndNumber != target.EndNumber {
+			converted.EndNumber = target.EndNumber
+		}
+
+		if template.Media != "" || inherited.Media != target.Media {
+			converted.Media = target.Media
+		}
+
+		if result := inherit(level.converted); result.Duration != target.Duration || result.EndNumber != target.EndNumber || !reflect.DeepEqual(result.SegmentTimeline, target.SegmentTimeline) {
+			return chains{}, fmt.Errorf("%w: period %d: converted SegmentTemplate overridden by inheritance", ErrConvertSegmentTemplate, index)
+		}
+
+		apply = append(apply, func() {
+			template.Duration = converted.Duration
+			template.SegmentTimeline = converted.SegmentTimeline
+			template.EndNumber = converted.EndNumber
+			template.Media = converted.Media
+		})
+
+		return level, nil
+	}
+
+	period := &m.Period[index]
+
+	periodChains, err := convertLevel(chains{}, period.SegmentTemplate)
+	if err != nil {
+		return err
+	}
+
+	for i := range period.AdaptationSet {
+		adaptationSet := &period.AdaptationSet[i]
+
+		adaptationSetChains, err := convertLevel(periodChains, adaptationSet.SegmentTemplate)
+		if err != nil {
+			return err
+		}
+
+		for j := range adaptationSet.Representation {
+			if _, err := convertLevel(adaptationSetChains, adaptationSet.Representation[j].SegmentTemplate); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, f := range apply {
+		f()
+	}
+
+	return nil
+}
+
+// replaceTemplateIdentifier replaces the identifier from by to in a SegmentTemplate, keeping format tags.
+func replaceTemplateIdentifier(template, from, to string) string {
+	return templateIdentifierPattern.ReplaceAllStringFunc(template, func(identifier string) string {
+		match := templateIdentifierPattern.FindStringSubmatch(identifier)
+		if match[1] != from {
+			return identifier
+		}
+
+		return "$" + to + match[2] + "$"
+	})
+}
+
+func absDifference(a, b uint64) uint64 {
+	if a > b {
+		return a - b
+	}
+
+	return b - a
+}
@@ -0,0 +1,225 @@
+package mpd_test
+
+import (
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"go.eigsys.de/go-mpd"
+	"testing"
+	"time"
+)
+
+func allSegments(t *testing.T, m *mpd.MPD) [][]mpd.Segment {
+	t.Helper()
+
+	var segments [][]mpd.Segment
+
+	for i := range m.Period {
+		period := &m.Period[i]
+
+		for j := range period.AdaptationSet {
+			adaptationSet := &period.AdaptationSet[j]
+
+			for k := range adaptationSet.Representation {
+				representationSegments, err := m.Segments(period, adaptationSet, &adaptationSet.Representation[k], nil)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				segments = append(segments, representationSegments)
+			}
+		}
+	}
+
+	return segments
+}
+
+func TestMPD_ConvertToSegmentTimeline(t *testing.T) {
+	testMPD := newAdMPD()
+	adaptationSet := &testMPD.Period[0].AdaptationSet[0]
+	adaptationSet.Representation = append(adaptationSet.Representation, mpd.Representation{
+		ID:              "ad-2",
+		Bandwidth:       2000000,
+		SegmentTemplate: &mpd.SegmentTemplate{MultipleSegmentBase: mpd.MultipleSegmentBase{SegmentBase: mpd.SegmentBase{Timescale: 2}}},
+	})
+
+	wantSegments := allSegments(t, testMPD)
+
+	if err := testMPD.ConvertToSegmentTimeline(0, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantTemplate := &mpd.SegmentTemplate{
+		Media: "$Number$.m4s",
+		MultipleSegmentBase: mpd.MultipleSegmentBase{
+			SegmentBase:     mpd.SegmentBase{Timescale: 1},
+			SegmentTimeline: &mpd.SegmentTimeline{S: []mpd.S{{T: uint64Ptr(0), D: 5, R: 2}}},
+		},
+	}
+	if diff := cmp.Diff(adaptationSet.SegmentTemplate, wantTemplate); diff != "" {
+		t.Errorf("wrong SegmentTemplate: %s", diff)
+	}
+
+	wantTemplate = &mpd.SegmentTemplate{
+		MultipleSegmentBase: mpd.MultipleSegmentBase{
+			SegmentBase:     mpd.SegmentBase{Timescale: 2},
+			SegmentTimeline: &mpd.SegmentTimeline{S: []mpd.S{{T: uint64Ptr(0), D: 5, R: 5}}},
+		},
+	}
+	if diff := cmp.Diff(adaptationSet.Representation[1].SegmentTemplate, wantTemplate); diff != "" {
+		t.Errorf("wrong SegmentTemplate: %s", diff)
+	}
+
+	if diff := cmp.Diff(allSegments(t, testMPD), wantSegments); diff != "" {
+		t.Errorf("wrong segments: %s", diff)
+	}
+
+	if err := testMPD.ConvertToSegmentDuration(0, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(allSegments(t, testMPD), wantSegments); diff != "" {
+		t.Errorf("wrong segments: %s", diff)
+	}
+
+	testMPD = newAdMPD()
+	if err := testMPD.ConvertToSegmentTimeline(0, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if media := testMPD.Period[0].AdaptationSet[0].SegmentTemplate.Media; media != "$Time$.m4s" {
+		t.Errorf("wrong media: %s", media)
+	}
+
+	if err := testMPD.ConvertToSegmentDuration(0, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(testMPD, newAdMPD()); diff != "" {
+		t.Errorf("wrong MPD: %s", diff)
+	}
+}
+
+func TestMPD_ConvertToSegmentDuration(t *testing.T) {
+	testMPD := newContentMPD()
+	video := testMPD.Period[0].AdaptationSet[0].SegmentTemplate
+	video.Media = "video/$Time%08d$.m4s"
+	video.SegmentTimeline.S = []mpd.S{{T: uint64Ptr(0), D: 4004}, {D: 3996}, {D: 4001, R: 4}, {D: 3995}}
+	audio := testMPD.Period[0].AdaptationSet[1].SegmentTemplate
+	audio.SegmentTimeline.S[0].R = 9
+
+	if err := testMPD.ConvertToSegmentDuration(0, 10*time.Millisecond); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantVideo := &mpd.SegmentTemplate{
+		Media:               "video/$Number%08d$.m4s",
+		MultipleSegmentBase: mpd.MultipleSegmentBase{SegmentBase: mpd.SegmentBase{Timescale: 1000}, Duration: 4001},
+	}
+	if diff := cmp.Diff(video, wantVideo); diff != "" {
+		t.Errorf("wrong SegmentTemplate: %s", diff)
+	}
+
+	wantAudio := &mpd.SegmentTemplate{
+		Media:               "audio/$Number$.m4s",
+		MultipleSegmentBase: mpd.MultipleSegmentBase{SegmentBase: mpd.SegmentBase{Timescale: 48000}, Duration: 96000, EndNumber: 10},
+	}
+	if diff := cmp.Diff(audio, wantAudio); diff != "" {
+		t.Errorf("wrong SegmentTemplate: %s", diff)
+	}
+
+	if err := testMPD.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if segments := allSegments(t, testMPD); len(segments[0]) != 8 || len(segments[1]) != 10 {
+		t.Errorf("wrong number of segments: %d %d", len(segments[0]), len(segments[1]))
+	}
+}
+
+func TestMPD_ConvertSegmentTemplate_Errors(t *testing.T) {
+	toDuration := func(tolerance time.Duration) func(*mpd.MPD) error {
+		return func(m *mpd.MPD) error { return m.ConvertToSegmentDuration(0, tolerance) }
+	}
+
+	toTimeline := func(m *mpd.MPD) error { return m.ConvertToSegmentTimeline(0, false) }
+
+	testCases := map[string]struct {
+		mutate  func(*mpd.MPD)
+		convert func(*mpd.MPD) error
+	}{
+		"missing period":          {convert: func(m *mpd.MPD) error { return m.ConvertToSegmentTimeline(1, false) }},
+		"invalid period duration": {mutate: func(m *mpd.MPD) { m.Period[0].Duration = "invalid" }, convert: toDuration(0)},
+		"irregular segments": {
+			mutate: func(m *mpd.MPD) {
+				m.Period[0].AdaptationSet[0].SegmentTemplate.SegmentTimeline.S = []mpd.S{{T: uint64Ptr(0), D: 4000}, {D: 2000}, {D: 4000, R: 5}}
+			},
+			convert: toDuration(time.Second),
+		},
+		"short segment": {
+			mutate: func(m *mpd.MPD) {
+				m.Period[0].AdaptationSet[0].SegmentTemplate.SegmentTimeline.S = []mpd.S{{T: uint64Ptr(0), D: 4000, R: 2}, {D: 2000}, {T: uint64Ptr(16000), D: 4000, R: 3}}
+			},
+			convert: toDuration(time.Second),
+		},
+		"long last segment": {
+			mutate: func(m *mpd.MPD) {
+				m.Period[0].AdaptationSet[0].SegmentTemplate.SegmentTimeline.S = []mpd.S{{T: uint64Ptr(0), D: 4000, R: 6}, {D: 6000}}
+			},
+			convert: toDuration(time.Second),
+		},
+		"no segments in period": {
+			mutate: func(m *mpd.MPD) {
+				m.Period[0].AdaptationSet[0].SegmentTemplate.SegmentTimeline.S = []mpd.S{{T: uint64Ptr(60000), D: 4000}}
+			},
+			convert: toDuration(0),
+		},
+		"open-ended SegmentTimeline": {
+			mutate: func(m *mpd.MPD) {
+				m.Period[0].Duration = ""
+				m.MediaPresentationDuration = ""
+				m.Type = mpd.DynamicPresentationType
+				m.Period[0].AdaptationSet[0].SegmentTemplate.SegmentTimeline.S = []mpd.S{{T: uint64Ptr(0), D: 4000, R: -1}}
+			},
+			convert: toDuration(0),
+		},
+		"@duration without period end": {
+			mutate: func(m *mpd.MPD) {
+				*m = *newAdMPD()
+				m.Type = mpd.DynamicPresentationType
+				m.Period[0].Duration = ""
+			},
+			convert: toTimeline,
+		},
+		"inherited @endNumber": {
+			mutate: func(m *mpd.MPD) {
+				m.Period[0].SegmentTemplate = &mpd.SegmentTemplate{
+					MultipleSegmentBase: mpd.MultipleSegmentBase{
+						SegmentBase:     mpd.SegmentBase{Timescale: 48000},
+						SegmentTimeline: &mpd.SegmentTimeline{S: []mpd.S{{T: uint64Ptr(0), D: 96000, R: 4}}},
+					},
+				}
+			},
+			convert: toDuration(0),
+		},
+	}
+
+	for name, testCase := range testCases {
+		testMPD := newContentMPD()
+		if testCase.mutate != nil {
+			testCase.mutate(testMPD)
+		}
+
+		wantMPD := newContentMPD()
+		if testCase.mutate != nil {
+			testCase.mutate(wantMPD)
+		}
+
+		if err := testCase.convert(testMPD); !errors.Is(err, mpd.ErrConvertSegmentTemplate) {
+			t.Errorf("%s: wrong error: %v", name, err)
+		}
+
+		if diff := cmp.Diff(testMPD, wantMPD); diff != "" {
+			t.Errorf("%s: MPD was modified: %s", name, diff)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package mpd
+
+import (
+	"errors"
+	"math/big"
+)
+
+var ErrVerifySegmentAlignment = errors.New("cannot verify segment alignment")
+
+// SegmentMisalignment is the first segment at which a Representation is not aligned with the first Representation of
+// its AdaptationSet. The Period, AdaptationSet and Representation are given by index.
+type SegmentMisalignment struct {
+	Period         int
+	AdaptationSet  int
+	Representation int
+
+	// Segment is the index of the misaligned segment in the segments of both Representations.
+	Segment int
+
+	// Reference is the segment of the first Representation and Misaligned the segment of the misaligned
+	// Representation. Either is nil if its Representation has fewer segments.
+	Reference  *Segment
+	Misaligned *Segment
+}
+
+// VerifySegmentAlignment checks that the Representations of every AdaptationSet with SegmentAlignment,
+// SubsegmentAlignment or BitstreamSwitching, or in a Period with BitstreamSwitching, have the same number of
+// segments and that their segments start at the same presentation times. The presentation times are compared
+// exactly, also across timescales. Subsegments of SegmentBase are only compared after
+// Representation.ConvertSegmentBaseToSegmentList.
+func (m *MPD) VerifySegmentAlignment() ([]SegmentMisalignment, error) {
+	var misalignments []SegmentMisalignment
+
+	for i := range m.Period {
+		period := &m.Period[i]
+
+		for j := range period.AdaptationSet {
+			adaptationSet := &period.AdaptationSet[j]
+			if !adaptationSet.SegmentAlignment && !adaptationSet.SubsegmentAlignment && !adaptationSet.BitstreamSwitching && !period.BitstreamSwitching {
+				continue
+			}
+
+			var (
+				referenceSegments []Segment
+				reference         []*big.Rat
+			)
+
+			for k := range adaptationSet.Representation {
+				representation := &adaptationSet.Representation[k]
+
+				segments, err := m.Segments(period, adaptationSet, representation, nil)
+				if err != nil {
+					return nil, errors.Join(ErrVerifySegmentAlignment, err)
+				}
+
+				starts := segmentStarts(period, adaptationSet, representation, segments)
+				if k == 0 {
+					referenceSegments, reference = segments, starts
+
+					continue
+				}
+
+				if n := misalignedSegment(reference, starts); n >= 0 {
+					misalignment := SegmentMisalignment{Period: i, AdaptationSet: j, Representation: k, Segment: n}
+
+					if n < len(referenceSegments) {
+						misalignment.Reference = &referenceSegments[n]
+					}
+
+					if n < len(segments) {
+						misalignment.Misaligned = &segments[n]
+					}
+
+					misalignments = append(misalignments, misalignment)
+				}
+			}
+		}
+	}
+
+	return misalignments, nil
+}
+
+// segmentStarts returns the exact start times of the segments relative to the start of the Period in seconds.
+func segmentStarts(period *Period, adaptationSet *AdaptationSet, representation *Representation, segments []Segment) []*big.Rat {
+	offset := mediaTimeOffset(period, adaptationSet, representation)
+	starts := make([]*big.Rat, len(segments))
+
+	for i := range segments {
+		starts[i] = new(big.Rat).Sub(ticksToSeconds(segments[i].Time, segments[i].Timescale), offset)
+	}
+
+	return starts
+}
+
+// misalignedSegment returns the index of the first start time that differs, or -1 if all are equal.
+func misalignedSegment(reference, starts []*big.Rat) int {
+	for i := range reference {
+		if i == len(starts) || reference[i].Cmp(starts[i]) != 0 {
+			return i
+		}
+	}
+
+	if len(starts) > len(reference) {
+		return len(reference)
+	}
+
+	return -1
+}
@@ -0,0 +1,105 @@
+package mpd_test
+
+import (
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"go.eigsys.de/go-mpd"
+	"testing"
+)
+
+func newAlignmentMPD() *mpd.MPD {
+	testMPD := newContentMPD()
+
+	video := &testMPD.Period[0].AdaptationSet[0]
+	video.SegmentAlignment = true
+	video.Representation = append(video.Representation,
+		mpd.Representation{ID: "v2", Bandwidth: 2000000, SegmentTemplate: newAlignmentTemplate(mpd.S{T: uint64Ptr(0), D: 360000, R: 6}, mpd.S{D: 180000})},
+		mpd.Representation{ID: "v3", Bandwidth: 3000000, SegmentTemplate: newAlignmentTemplate(mpd.S{T: uint64Ptr(0), D: 360000, R: 1}, mpd.S{D: 360001}, mpd.S{D: 359999, R: 3}, mpd.S{D: 180000})},
+		mpd.Representation{ID: "v4", Bandwidth: 4000000, SegmentTemplate: newAlignmentTemplate(mpd.S{T: uint64Ptr(0), D: 360000, R: 6})},
+		mpd.Representation{ID: "v5", Bandwidth: 5000000, SegmentTemplate: newAlignmentTemplate(mpd.S{T: uint64Ptr(0), D: 360000, R: 6}, mpd.S{D: 90000, R: 1})},
+	)
+
+	audio := &testMPD.Period[0].AdaptationSet[1]
+	audio.Representation = append(audio.Representation, mpd.Representation{ID: "a2", Bandwidth: 64000, SegmentTemplate: &mpd.SegmentTemplate{
+		MultipleSegmentBase: mpd.MultipleSegmentBase{
+			SegmentBase:     mpd.SegmentBase{Timescale: 96000},
+			SegmentTimeline: &mpd.SegmentTimeline{S: []mpd.S{{T: uint64Ptr(0), D: 192000, R: 14}}},
+		},
+	}})
+
+	return testMPD
+}
+
+func newAlignmentTemplate(s ...mpd.S) *mpd.SegmentTemplate {
+	return &mpd.SegmentTemplate{
+		MultipleSegmentBase: mpd.MultipleSegmentBase{
+			SegmentBase:     mpd.SegmentBase{Timescale: 90000},
+			SegmentTimeline: &mpd.SegmentTimeline{S: s},
+		},
+	}
+}
+
+func TestMPD_VerifySegmentAlignment(t *testing.T) {
+	testMPD := newAlignmentMPD()
+
+	misalignments, err := testMPD.VerifySegmentAlignment()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantMisalignments := []struct {
+		representation, segment int
+		reference, misaligned   bool
+	}{
+		{representation: 2, segment: 3, reference: true, misaligned: true},
+		{representation: 3, segment: 7, reference: true, misaligned: false},
+		{representation: 4, segment: 8, reference: false, misaligned: true},
+	}
+
+	if len(misalignments) != len(wantMisalignments) {
+		t.Fatalf("wrong misalignments: %+v", misalignments)
+	}
+
+	for i, want := range wantMisalignments {
+		misalignment := misalignments[i]
+		if misalignment.Period != 0 || misalignment.AdaptationSet != 0 || misalignment.Representation != want.representation || misalignment.Segment != want.segment ||
+			(misalignment.Reference != nil) != want.reference || (misalignment.Misaligned != nil) != want.misaligned {
+			t.Errorf("wrong misalignment: %+v", misalignment)
+		}
+	}
+
+	wantSegment := &mpd.Segment{
+		URL: "video/4.m4s", Number: 4, Time: 1080001, Duration: 359999, Timescale: 90000,
+		PresentationTime: 12000011111, PresentationDuration: 3999988889,
+	}
+	if diff := cmp.Diff(misalignments[0].Misaligned, wantSegment); diff != "" {
+		t.Errorf("wrong segment: %s", diff)
+	}
+
+	testMPD.Period[0].BitstreamSwitching = true
+	testMPD.Period[0].AdaptationSet[0].SegmentAlignment = false
+
+	misalignments, err = testMPD.VerifySegmentAlignment()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(misalignments) != 3 {
+		t.Errorf("wrong misalignments: %+v", misalignments)
+	}
+
+	testMPD.Period[0].AdaptationSet[1].Representation[1].SegmentTemplate.Timescale = 0
+
+	if misalignments, err := testMPD.VerifySegmentAlignment(); err != nil || len(misalignments) != 4 || misalignments[3].AdaptationSet != 1 {
+		t.Errorf("unexpected result: %+v %v", misalignments, err)
+	}
+}
+
+func TestMPD_VerifySegmentAlignment_Errors(t *testing.T) {
+	testMPD := newAlignmentMPD()
+	testMPD.Period[0].AdaptationSet[0].SegmentTemplate.Media = ""
+
+	if _, err := testMPD.VerifySegmentAlignment(); !errors.Is(err, mpd.ErrVerifySegmentAlignment) {
+		t.Errorf("wrong error: %v", err)
+	}
+}
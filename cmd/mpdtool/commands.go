@@ -0,0 +1,343 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go.eigsys.de/go-mpd"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// readMPD reads an MPD in XML or JSON from the named file or stdin.
+func readMPD(env *environment, name string) (*mpd.MPD, error) {
+	data, err := readFile(env, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeMPD(data)
+}
+
+// decodeMPD decodes an MPD in XML or JSON.
+func decodeMPD(data []byte) (*mpd.MPD, error) {
+	if isJSON(data) {
+		return mpd.ReadJSON(io.NopCloser(bytes.NewReader(data)))
+	}
+
+	return mpd.Read(io.NopCloser(bytes.NewReader(data)))
+}
+
+func isJSON(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n\uFEFF"), []byte("{"))
+}
+
+func displayName(name string) string {
+	if name == "" || name == "-" {
+		return "<stdin>"
+	}
+
+	return name
+}
+
+// runValidate prints the violations of every manifest and exits with exitFailure if any is invalid.
+func runValidate(env *environment, args []string) (int, error) {
+	names, err := parseFlags(newFlagSet(env, "validate"), args, -1)
+	if err != nil {
+		return exitError, err
+	}
+
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+
+	code := exitOK
+
+	for _, name := range names {
+		manifest, err := readMPD(env, name)
+		if err != nil {
+			fmt.Fprintf(env.stderr, "%s: %v\n", displayName(name), err)
+			code = exitError
+
+			continue
+		}
+
+		err = manifest.Validate()
+		if err == nil {
+			continue
+		}
+
+		for _, line := range strings.Split(err.Error(), "\n") {
+			fmt.Fprintf(env.stdout, "%s: %s\n", displayName(name), strings.TrimPrefix(line, mpd.ErrInvalidMPD.Error()+": "))
+		}
+
+		if code == exitOK {
+			code = exitFailure
+		}
+	}
+
+	return code, nil
+}
+
+// runFmt prints the manifest in the canonical form of the package, or writes it back to the file. SegmentTimelines
+// and durations are normalised with MPD.NormalizeSegmentTimelines and MPD.NormalizeDurations.
+func runFmt(env *environment, args []string) (int, error) {
+	flags := newFlagSet(env, "fmt")
+	asJSON := flags.Bool("json", false, "print JSON instead of XML")
+	write := flags.Bool("w", false, "write the result to the file instead of stdout")
+
+	names, err := parseFlags(flags, args, 1)
+	if err != nil {
+		return exitError, err
+	}
+
+	name := ""
+	if len(names) > 0 {
+		name = names[0]
+	}
+
+	if *write && (name == "" || name == "-") {
+		return exitError, fmt.Errorf("%w: -w requires a file", errUsage)
+	}
+
+	manifest, err := readMPD(env, name)
+	if err != nil {
+		return exitError, err
+	}
+
+	manifest.NormalizeSegmentTimelines()
+	manifest.NormalizeDurations()
+
+	output, err := marshalMPD(manifest, *asJSON)
+	if err != nil {
+		return exitError, err
+	}
+
+	if !*write {
+		_, err := env.stdout.Write(output)
+		return exitOK, err
+	}
+
+	info, err := os.Stat(name)
+	if err != nil {
+		return exitError, err
+	}
+
+	return exitOK, os.WriteFile(name, output, info.Mode().Perm())
+}
+
+func marshalMPD(manifest *mpd.MPD, asJSON bool) ([]byte, error) {
+	var (
+		output []byte
+		err    error
+	)
+
+	if asJSON {
+		output, err = manifest.JSON()
+	} else {
+		output, err = manifest.Bytes()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return append(output, '\n'), nil
+}
+
+// runSegments prints the initialization and media segments of every Representation as tab-separated values.
+// Times are in seconds relative to the start of the media presentation.
+func runSegments(env *environment, args []string) (int, error) {
+	flags := newFlagSet(env, "segments")
+	start := flags.Duration("start", 0, "list segments from this presentation time")
+	end := flags.Duration("end", 0, "list segments up to this presentation time, required for live manifests")
+	periodID := flags.String("period", "", "only list segments of the Period with this ID")
+	representationID := flags.String("representation", "", "only list segments of Representations with this ID")
+
+	names, err := parseFlags(flags, args, 1)
+	if err != nil {
+		return exitError, err
+	}
+
+	manifest, err := readMPD(env, strings.Join(names, ""))
+	if err != nil {
+		return exitError, err
+	}
+
+	filter := &mpd.SegmentFilter{Start: *start, End: *end}
+
+	fmt.Fprintln(env.stdout, "period\tadaptationSet\trepresentation\tnumber\ttime\tduration\tstart\tend\turl\trange")
+
+	for i := range manifest.Period {
+		period := &manifest.Period[i]
+		if *periodID != "" && period.ID != *periodID {
+			continue
+		}
+
+		for j := range period.AdaptationSet {
+			adaptationSet := &period.AdaptationSet[j]
+
+			for k := range adaptationSet.Representation {
+				representation := &adaptationSet.Representation[k]
+				if *representationID != "" && representation.ID != *representationID {
+					continue
+				}
+
+				prefix := fmt.Sprintf("%s\t%s\t%s", orIndex(period.ID, i), orIndex(adaptationSetID(adaptationSet), j), representation.ID)
+
+				initialization, err := manifest.InitializationSegment(period, adaptationSet, representation)
+				if err != nil {
+					return exitError, fmt.Errorf("period %s: representation %s: %w", orIndex(period.ID, i), representation.ID, err)
+				}
+
+				if initialization != nil {
+					fmt.Fprintf(env.stdout, "%s\tinit\t\t\t\t\t%s\t%s\n", prefix, initialization.URL, initialization.Range)
+				}
+
+				segments, err := manifest.Segments(period, adaptationSet, representation, filter)
+				if err != nil {
+					return exitError, fmt.Errorf("period %s: representation %s: %w", orIndex(period.ID, i), representation.ID, err)
+				}
+
+				for _, segment := range segments {
+					fmt.Fprintf(env.stdout, "%s\t%d\t%d\t%d\t%s\t%s\t%s\t%s\n", prefix, segment.Number, segment.Time, segment.Duration,
+						seconds(segment.PresentationTime), seconds(segment.PresentationTime+segment.PresentationDuration),
+						segment.URL, segment.Range)
+				}
+			}
+		}
+	}
+
+	return exitOK, nil
+}
+
+func orIndex(id string, index int) string {
+	if id == "" {
+		return "#" + strconv.Itoa(index)
+	}
+
+	return id
+}
+
+func adaptationSetID(adaptationSet *mpd.AdaptationSet) string {
+	if adaptationSet.ID == 0 {
+		return ""
+	}
+
+	return strconv.FormatUint(uint64(adaptationSet.ID), 10)
+}
+
+func seconds(duration time.Duration) string {
+	return strconv.FormatFloat(duration.Seconds(), 'f', -1, 64)
+}
+
+// runInfo prints a summary of the manifest.
+func runInfo(env *environment, args []string) (int, error) {
+	names, err := parseFlags(newFlagSet(env, "info"), args, 1)
+	if err != nil {
+		return exitError, err
+	}
+
+	manifest, err := readMPD(env, strings.Join(names, ""))
+	if err != nil {
+		return exitError, err
+	}
+
+	w := tabwriter.NewWriter(env.stdout, 0, 4, 2, ' ', 0)
+
+	presentationType := manifest.Type
+	if presentationType == "" {
+		presentationType = mpd.StaticPresentationType
+	}
+
+	fmt.Fprintf(w, "Type:\t%s\n", presentationType)
+	fmt.Fprintf(w, "Profiles:\t%s\n", manifest.Profiles)
+
+	for _, attribute := range []struct{ name, value string }{
+		{"Duration", manifest.MediaPresentationDuration},
+		{"Availability start", manifest.AvailabilityStartTime},
+		{"Publish time", manifest.PublishTime},
+		{"Minimum update period", manifest.MinimumUpdatePeriod},
+		{"Time shift buffer", manifest.TimeShiftBufferDepth},
+		{"Minimum buffer", manifest.MinBufferTime},
+	} {
+		if attribute.value != "" {
+			fmt.Fprintf(w, "%s:\t%s\n", attribute.name, attribute.value)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return exitError, err
+	}
+
+	for i := range manifest.Period {
+		period := &manifest.Period[i]
+
+		fmt.Fprintf(env.stdout, "\nPeriod %s:%s\n", orIndex(period.ID, i), periodTiming(manifest, i))
+
+		for j := range period.AdaptationSet {
+			printAdaptationSet(env.stdout, &period.AdaptationSet[j], j)
+		}
+	}
+
+	return exitOK, nil
+}
+
+func periodTiming(manifest *mpd.MPD, index int) string {
+	var timing string
+
+	if start, err := manifest.PeriodStart(index); err == nil {
+		timing += " start " + start.String()
+	}
+
+	if duration, err := manifest.PeriodDuration(index); err == nil && duration > 0 {
+		timing += ", duration " + duration.String()
+	}
+
+	return timing
+}
+
+func printAdaptationSet(out io.Writer, adaptationSet *mpd.AdaptationSet, index int) {
+	description := []string{string(adaptationSet.ContentType), string(adaptationSet.MIMEType)}
+	if adaptationSet.Lang != "" {
+		description = append(description, "lang="+adaptationSet.Lang)
+	}
+
+	if len(adaptationSet.ContentProtection) > 0 {
+		description = append(description, "protected")
+	}
+
+	fmt.Fprintf(out, "  AdaptationSet %s: %s\n", orIndex(adaptationSetID(adaptationSet), index), strings.Join(strings.Fields(strings.Join(description, " ")), " "))
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+
+	for i := range adaptationSet.Representation {
+		representation := &adaptationSet.Representation[i]
+
+		codecs := representation.Codecs
+		if codecs == "" {
+			codecs = adaptationSet.Codecs
+		}
+
+		var resolution, frameRate string
+
+		if representation.Width > 0 || representation.Height > 0 {
+			resolution = fmt.Sprintf("%dx%d", representation.Width, representation.Height)
+		}
+
+		if frameRate = string(representation.FrameRate); frameRate == "" {
+			frameRate = string(adaptationSet.FrameRate)
+		}
+
+		if frameRate != "" {
+			frameRate += " fps"
+		}
+
+		fmt.Fprintf(w, "    %s\t%d bps\t%s\t%s\t%s\n", representation.ID, representation.Bandwidth, resolution, frameRate, codecs)
+	}
+
+	_ = w.Flush()
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go.eigsys.de/go-mpd"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	mpdFormat    = "mpd"
+	jsonFormat   = "json"
+	hlsFormat    = "hls"
+	smoothFormat = "smooth"
+)
+
+// runConvert converts a manifest to an MPD in XML or JSON, or to HLS playlists.
+// HLS playlists are read from and written to local files only: the media playlists of a multivariant playlist are
+// resolved relative to its directory, and converted playlists are written to the output directory.
+func runConvert(env *environment, args []string) (int, error) {
+	flags := newFlagSet(env, "convert")
+	from := flags.String("from", "", "input format: mpd, json, hls or smooth (default: detected)")
+	to := flags.String("to", "", "output format: mpd, json or hls")
+	output := flags.String("o", "", "output directory for hls")
+	multivariant := flags.String("multivariant", "main.m3u8", "file name of the HLS multivariant playlist")
+	now := flags.String("now", "", "wall clock time to convert a dynamic MPD to HLS at, e.g. 2024-01-01T00:00:00Z (default: current time)")
+
+	names, err := parseFlags(flags, args, 1)
+	if err != nil {
+		return exitError, err
+	}
+
+	name := strings.Join(names, "")
+
+	switch {
+	case *to != mpdFormat && *to != jsonFormat && *to != hlsFormat:
+		return exitError, fmt.Errorf("%w: unsupported output format %q", errUsage, *to)
+	case *to == hlsFormat && *output == "":
+		return exitError, fmt.Errorf("%w: -o is required for hls", errUsage)
+	}
+
+	data, err := readFile(env, name)
+	if err != nil {
+		return exitError, err
+	}
+
+	if *from == "" {
+		*from = detectFormat(data)
+	}
+
+	manifest, err := importManifest(*from, data, name)
+	if err != nil {
+		return exitError, err
+	}
+
+	if *to != hlsFormat {
+		output, err := marshalMPD(manifest, *to == jsonFormat)
+		if err != nil {
+			return exitError, err
+		}
+
+		_, err = env.stdout.Write(output)
+
+		return exitOK, err
+	}
+
+	options := &mpd.HLSOptions{Now: time.Now()}
+	if *now != "" {
+		if options.Now, err = mpd.ParseDateTime(*now); err != nil {
+			return exitError, fmt.Errorf("%w: invalid -now: %v", errUsage, err)
+		}
+	}
+
+	playlists, err := manifest.ConvertToHLS(options)
+	if err != nil {
+		return exitError, err
+	}
+
+	if err := os.MkdirAll(*output, 0o755); err != nil {
+		return exitError, err
+	}
+
+	if err := os.WriteFile(filepath.Join(*output, *multivariant), playlists.Multivariant.Bytes(), 0o644); err != nil {
+		return exitError, err
+	}
+
+	for uri, playlist := range playlists.Media {
+		path, err := localPath(*output, uri)
+		if err != nil {
+			return exitError, err
+		}
+
+		if err := os.WriteFile(path, playlist.Bytes(), 0o644); err != nil {
+			return exitError, err
+		}
+	}
+
+	return exitOK, nil
+}
+
+// localPath returns the path of the playlist URI in dir. It fails if the URI is absolute or leaves dir.
+func localPath(dir, uri string) (string, error) {
+	path := filepath.FromSlash(uri)
+	if !filepath.IsLocal(path) {
+		return "", fmt.Errorf("playlist %q outside of %s", uri, dir)
+	}
+
+	return filepath.Join(dir, path), nil
+}
+
+func detectFormat(data []byte) string {
+	trimmed := bytes.TrimLeft(data, " \t\r\n\uFEFF")
+
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return jsonFormat
+	case bytes.HasPrefix(trimmed, []byte("#EXTM3U")):
+		return hlsFormat
+	case bytes.Contains(trimmed, []byte("<SmoothStreamingMedia")) || bytes.HasPrefix(trimmed, []byte{0xFF, 0xFE}) || bytes.HasPrefix(trimmed, []byte{0xFE, 0xFF}):
+		return smoothFormat
+	default:
+		return mpdFormat
+	}
+}
+
+func importManifest(format string, data []byte, name string) (*mpd.MPD, error) {
+	switch format {
+	case mpdFormat, jsonFormat:
+		if isJSON(data) != (format == jsonFormat) {
+			return nil, fmt.Errorf("input is not %s", format)
+		}
+
+		return decodeMPD(data)
+	case hlsFormat:
+		return importHLS(data, name)
+	case smoothFormat:
+		manifest, err := mpd.ParseSmoothStreamingMedia(data)
+		if err != nil {
+			return nil, err
+		}
+
+		return mpd.ConvertFromSmoothStreaming(manifest)
+	default:
+		return nil, fmt.Errorf("%w: unsupported input format %q", errUsage, format)
+	}
+}
+
+// importHLS reads the media playlists referenced by the multivariant playlist from the directory of the named file,
+// or the working directory for stdin.
+func importHLS(data []byte, name string) (*mpd.MPD, error) {
+	multivariant, err := mpd.ParseHLSMultivariantPlaylist(data)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := "."
+	if name != "" && name != "-" {
+		dir = filepath.Dir(name)
+	}
+
+	playlists := &mpd.HLSPlaylists{Multivariant: multivariant, Media: map[string]*mpd.HLSMediaPlaylist{}}
+
+	var uris []string
+
+	for _, variant := range multivariant.Variant {
+		uris = append(uris, variant.URI)
+	}
+
+	for _, media := range multivariant.Media {
+		if media.URI != "" {
+			uris = append(uris, media.URI)
+		}
+	}
+
+	for _, uri := range uris {
+		if _, ok := playlists.Media[uri]; ok {
+			continue
+		}
+
+		if parsed, err := url.Parse(uri); err != nil || parsed.IsAbs() || parsed.Host != "" {
+			return nil, fmt.Errorf("cannot read media playlist %q: only local files are supported", uri)
+		}
+
+		path, err := localPath(dir, uri)
+		if err != nil {
+			return nil, err
+		}
+
+		mediaData, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if playlists.Media[uri], err = mpd.ParseHLSMediaPlaylist(mediaData); err != nil {
+			return nil, fmt.Errorf("%s: %w", uri, err)
+		}
+	}
+
+	return mpd.ConvertFromHLS(playlists, nil)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// runDiff compares two manifests on the level of the MPD model, so that formatting, attribute order, namespace
+// prefixes and omitted default values do not matter. It prints one line per difference and exits with exitFailure
+// if the manifests differ.
+func runDiff(env *environment, args []string) (int, error) {
+	names, err := parseFlags(newFlagSet(env, "diff"), args, 2)
+	if err != nil {
+		return exitError, err
+	}
+
+	if len(names) != 2 {
+		return exitError, fmt.Errorf("%w: two files required", errUsage)
+	}
+
+	var trees [2]any
+
+	for i, name := range names {
+		manifest, err := readMPD(env, name)
+		if err != nil {
+			return exitError, fmt.Errorf("%s: %w", displayName(name), err)
+		}
+
+		data, err := manifest.JSON()
+		if err != nil {
+			return exitError, err
+		}
+
+		if err := json.Unmarshal(data, &trees[i]); err != nil {
+			return exitError, err
+		}
+	}
+
+	differences := diffTrees("", trees[0], trees[1], nil)
+	for _, difference := range differences {
+		fmt.Fprintln(env.stdout, difference)
+	}
+
+	if len(differences) > 0 {
+		return exitFailure, nil
+	}
+
+	return exitOK, nil
+}
+
+// diffTrees appends the differences between two decoded JSON values to differences, with removed values prefixed
+// by "-", added values by "+" and changed values by "~".
+func diffTrees(path string, a, b any, differences []string) []string {
+	switch {
+	case a == nil && b == nil:
+		return differences
+	case a == nil:
+		return append(differences, fmt.Sprintf("+ %s: %s", path, encodeValue(b)))
+	case b == nil:
+		return append(differences, fmt.Sprintf("- %s: %s", path, encodeValue(a)))
+	}
+
+	switch a := a.(type) {
+	case map[string]any:
+		if b, ok := b.(map[string]any); ok {
+			keys := make([]string, 0, len(a)+len(b))
+			for key := range a {
+				keys = append(keys, key)
+			}
+
+			for key := range b {
+				if _, ok := a[key]; !ok {
+					keys = append(keys, key)
+				}
+			}
+
+			sort.Strings(keys)
+
+			for _, key := range keys {
+				differences = diffTrees(joinPath(path, key), a[key], b[key], differences)
+			}
+
+			return differences
+		}
+	case []any:
+		if b, ok := b.([]any); ok {
+			for i := 0; i < len(a) || i < len(b); i++ {
+				var elementA, elementB any
+				if i < len(a) {
+					elementA = a[i]
+				}
+
+				if i < len(b) {
+					elementB = b[i]
+				}
+
+				differences = diffTrees(path+"["+strconv.Itoa(i)+"]", elementA, elementB, differences)
+			}
+
+			return differences
+		}
+	}
+
+	if reflect.DeepEqual(a, b) {
+		return differences
+	}
+
+	return append(differences, fmt.Sprintf("~ %s: %s -> %s", path, encodeValue(a), encodeValue(b)))
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+func encodeValue(value any) string {
+	data, _ := json.Marshal(value)
+	return string(data)
+}
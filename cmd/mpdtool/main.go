@@ -0,0 +1,136 @@
+// Command mpdtool validates, formats, compares, inspects and converts MPEG-DASH manifests.
+//
+// Usage:
+//
+//	mpdtool <command> [flags] [file ...]
+//
+// The commands are:
+//
+//	validate  check manifests for conformance, exits with 1 if any is invalid
+//	fmt       normalise and pretty-print a manifest
+//	diff      compare two manifests semantically, exits with 1 if they differ
+//	segments  list the segments of every Representation
+//	info      summarise Periods, AdaptationSets and Representations
+//	convert   convert between MPD, JSON, HLS and Smooth Streaming
+//
+// Manifests are read from the given files, or from stdin if the file is omitted or "-". MPDs may be XML or JSON.
+// Errors exit with 2.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitError   = 2
+)
+
+var errUsage = errors.New("invalid usage")
+
+type command struct {
+	name        string
+	usage       string
+	description string
+	run         func(env *environment, args []string) (int, error)
+}
+
+var commands = []command{
+	{"validate", "[file ...]", "check manifests for conformance", runValidate},
+	{"fmt", "[-json] [-w] [file]", "normalise and pretty-print a manifest", runFmt},
+	{"diff", "file1 file2", "compare two manifests semantically", runDiff},
+	{"segments", "[-start duration] [-end duration] [-period id] [-representation id] [file]", "list the segments of every Representation", runSegments},
+	{"info", "[file]", "summarise Periods, AdaptationSets and Representations", runInfo},
+	{"convert", "[-from format] -to format [-o dir] [file]", "convert between mpd, json, hls and smooth", runConvert},
+}
+
+// environment holds the standard streams, so that commands can be run in tests.
+type environment struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], &environment{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr}))
+}
+
+func run(args []string, env *environment) int {
+	if len(args) == 0 {
+		printUsage(env.stderr)
+		return exitError
+	}
+
+	for _, command := range commands {
+		if command.name != args[0] {
+			continue
+		}
+
+		code, err := command.run(env, args[1:])
+		if errors.Is(err, errUsage) {
+			fmt.Fprintf(env.stderr, "%v\nusage: mpdtool %s %s\n", err, command.name, command.usage)
+			return exitError
+		}
+
+		if err != nil {
+			fmt.Fprintf(env.stderr, "mpdtool %s: %v\n", command.name, err)
+			return exitError
+		}
+
+		return code
+	}
+
+	if args[0] == "help" || args[0] == "-h" || args[0] == "-help" {
+		printUsage(env.stdout)
+		return exitOK
+	}
+
+	fmt.Fprintf(env.stderr, "mpdtool: unknown command %q\n", args[0])
+	printUsage(env.stderr)
+
+	return exitError
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: mpdtool <command> [flags] [file ...]")
+	fmt.Fprintln(w)
+
+	for _, command := range commands {
+		fmt.Fprintf(w, "  %-9s %s\n", command.name, command.description)
+	}
+}
+
+// newFlagSet returns a flag set that reports errors instead of exiting.
+func newFlagSet(env *environment, name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(env.stderr)
+
+	return flags
+}
+
+// parseFlags parses the flags and returns the positional arguments, of which there must be at most max.
+func parseFlags(flags *flag.FlagSet, args []string, max int) ([]string, error) {
+	if err := flags.Parse(args); err != nil {
+		return nil, errors.Join(errUsage, err)
+	}
+
+	if max >= 0 && flags.NArg() > max {
+		return nil, fmt.Errorf("%w: too many arguments", errUsage)
+	}
+
+	return flags.Args(), nil
+}
+
+// readFile reads the named file, or stdin if the name is empty or "-".
+func readFile(env *environment, name string) ([]byte, error) {
+	if name == "" || name == "-" {
+		return io.ReadAll(env.stdin)
+	}
+
+	return os.ReadFile(name)
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"bytes"
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const fixtures = "../../testdata/zencoder"
+
+func runTool(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+
+	code := run(args, &environment{stdin: strings.NewReader(stdin), stdout: &stdout, stderr: &stderr})
+
+	return code, stdout.String(), stderr.String()
+}
+
+func mustReadFixture(name string) string {
+	data, err := os.ReadFile(filepath.Join(fixtures, name))
+	if err != nil {
+		panic(err)
+	}
+
+	return string(data)
+}
+
+func TestRun_Usage(t *testing.T) {
+	testCases := map[string]struct {
+		args     []string
+		wantCode int
+	}{
+		"no command":      {args: nil, wantCode: exitError},
+		"unknown command": {args: []string{"lint"}, wantCode: exitError},
+		"help":            {args: []string{"help"}, wantCode: exitOK},
+		"unknown flag":    {args: []string{"fmt", "-x"}, wantCode: exitError},
+		"too many files":  {args: []string{"info", "a.mpd", "b.mpd"}, wantCode: exitError},
+		"missing file":    {args: []string{"info", "missing.mpd"}, wantCode: exitError},
+	}
+
+	for name, testCase := range testCases {
+		if code, _, _ := runTool("", testCase.args...); code != testCase.wantCode {
+			t.Errorf("%s: wrong exit code: %d", name, code)
+		}
+	}
+}
+
+func TestRun_Validate(t *testing.T) {
+	code, stdout, stderr := runTool("", "validate", filepath.Join(fixtures, "segment_list.mpd"), filepath.Join(fixtures, "newperiod.mpd"))
+	if code != exitFailure || stderr != "" {
+		t.Fatalf("unexpected result: %d %s", code, stderr)
+	}
+
+	name := filepath.Join(fixtures, "newperiod.mpd")
+	wantStdout := name + ": Period 0: AdaptationSet 0: no Representation\n" +
+		name + ": Period 0: AdaptationSet 1: duplicate @id 1\n" +
+		name + ": Period 0: AdaptationSet 1: no Representation\n" +
+		name + ": Period 1: cannot determine period timing: period 1 lacks start and previous period lacks duration\n" +
+		name + ": Period 1: AdaptationSet 0: no Representation\n" +
+		name + ": Period 1: AdaptationSet 1: duplicate @id 2\n" +
+		name + ": Period 1: AdaptationSet 1: no Representation\n"
+
+	if diff := cmp.Diff(stdout, wantStdout); diff != "" {
+		t.Errorf("wrong output: %s", diff)
+	}
+
+	if code, stdout, _ := runTool(mustReadFixture("segment_list.mpd"), "validate"); code != exitOK || stdout != "" {
+		t.Errorf("unexpected result: %d %s", code, stdout)
+	}
+
+	if code, _, stderr := runTool("", "validate", filepath.Join(fixtures, "invalid.mpd")); code != exitError || !strings.HasPrefix(stderr, filepath.Join(fixtures, "invalid.mpd")+": cannot unmarshal MPD") {
+		t.Errorf("unexpected result: %d %s", code, stderr)
+	}
+}
+
+func TestRun_Fmt(t *testing.T) {
+	input := mustReadFixture("segment_timeline.mpd")
+
+	code, formatted, _ := runTool(input, "fmt")
+	if code != exitOK {
+		t.Fatalf("wrong exit code: %d", code)
+	}
+
+	if !strings.Contains(formatted, `mediaPresentationDuration="PT1M5.063S" minBufferTime="PT2S"`) {
+		t.Errorf("durations not normalised: %s", formatted)
+	}
+
+	expanded := strings.Replace(input, `<S d="479232" r="2"></S>`, `<S t="231424" d="479232"></S><S d="479232" r="1"></S>`, 1)
+	if code, normalized, _ := runTool(expanded, "fmt"); code != exitOK || normalized != formatted {
+		t.Errorf("unexpected result: %d %s", code, cmp.Diff(normalized, formatted))
+	}
+
+	code, asJSON, _ := runTool(input, "fmt", "-json")
+	if code != exitOK || !strings.HasPrefix(asJSON, "{\n  \"period\": [") {
+		t.Fatalf("unexpected result: %d %s", code, asJSON)
+	}
+
+	if code, fromJSON, _ := runTool(asJSON, "fmt"); code != exitOK || fromJSON != formatted {
+		t.Errorf("unexpected result: %d %s", code, cmp.Diff(fromJSON, formatted))
+	}
+
+	name := filepath.Join(t.TempDir(), "manifest.mpd")
+	if err := os.WriteFile(name, []byte(input), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if code, _, stderr := runTool("", "fmt", "-w", name); code != exitOK {
+		t.Fatalf("unexpected result: %d %s", code, stderr)
+	}
+
+	if written, err := os.ReadFile(name); err != nil || string(written) != formatted {
+		t.Errorf("wrong file: %v %s", err, cmp.Diff(string(written), formatted))
+	}
+
+	if code, _, _ := runTool(input, "fmt", "-w"); code != exitError {
+		t.Errorf("wrong exit code: %d", code)
+	}
+}
+
+func TestRun_Diff(t *testing.T) {
+	name := filepath.Join(fixtures, "segment_list.mpd")
+	changed := strings.NewReplacer(`bandwidth="255000"`, `bandwidth="128000"`, `lang="English"`, `lang="en"`, ` segmentAlignment="true"`, "").
+		Replace(mustReadFixture("segment_list.mpd"))
+
+	code, stdout, _ := runTool(changed, "diff", name, "-")
+	if code != exitFailure {
+		t.Fatalf("wrong exit code: %d", code)
+	}
+
+	wantStdout := `~ period[0].adaptationSet[0].lang: "English" -> "en"
+~ period[0].adaptationSet[0].representation[0].bandwidth: 255000 -> 128000
+- period[0].adaptationSet[0].segmentAlignment: true
+- period[0].adaptationSet[1].segmentAlignment: true
+`
+	if diff := cmp.Diff(stdout, wantStdout); diff != "" {
+		t.Errorf("wrong output: %s", diff)
+	}
+
+	_, asJSON, _ := runTool("", "convert", "-to", "json", name)
+	if code, stdout, _ := runTool(asJSON, "diff", "-", name); code != exitOK || stdout != "" {
+		t.Errorf("unexpected result: %d %s", code, stdout)
+	}
+
+	if code, _, _ := runTool("", "diff", name); code != exitError {
+		t.Errorf("wrong exit code: %d", code)
+	}
+
+	if code, _, _ := runTool("", "diff", name, filepath.Join(fixtures, "invalid.mpd")); code != exitError {
+		t.Errorf("wrong exit code: %d", code)
+	}
+}
+
+func TestRun_Segments(t *testing.T) {
+	code, stdout, _ := runTool("", "segments", "-end", "3s", "-representation", "video_1", filepath.Join(fixtures, "segment_list.mpd"))
+	if code != exitOK {
+		t.Fatalf("wrong exit code: %d", code)
+	}
+
+	base := "http://localhost:8002/dash/b4324d65-ad06-4735-9535-5cd4af84ebb6/f2ad47b2-5362-46e6-ad1d-dff7b10f00b8/"
+	wantStdout := "period\tadaptationSet\trepresentation\tnumber\ttime\tduration\tstart\tend\turl\trange\n" +
+		"#0\t2\tvideo_1\tinit\t\t\t\t\t" + base + "init.m4f\t\n" +
+		"#0\t2\tvideo_1\t1\t0\t225120\t0\t7.504\t" + base + "segment0.m4f\t\n"
+
+	if diff := cmp.Diff(stdout, wantStdout); diff != "" {
+		t.Errorf("wrong output: %s", diff)
+	}
+
+	code, stdout, _ = runTool("", "segments", "-period", "1", filepath.Join(fixtures, "segment_timeline_multi_period.mpd"))
+	if code != exitOK || strings.Contains(stdout, "\n0\t") || !strings.Contains(stdout, "\n1\t") {
+		t.Errorf("unexpected result: %d %s", code, stdout)
+	}
+
+	live := `<MPD profiles="urn:mpeg:dash:profile:isoff-live:2011" type="dynamic" availabilityStartTime="1970-01-01T00:00:00Z" minBufferTime="PT2S">
+  <Period id="0">
+    <AdaptationSet mimeType="video/mp4">
+      <SegmentTemplate media="$Number$.m4s" duration="2"></SegmentTemplate>
+      <Representation id="v" bandwidth="1000"></Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>`
+
+	if code, _, stderr := runTool(live, "segments"); code != exitError || !strings.Contains(stderr, "cannot resolve segments") {
+		t.Errorf("unexpected result: %d %s", code, stderr)
+	}
+}
+
+func TestRun_Info(t *testing.T) {
+	code, stdout, _ := runTool("", "info", filepath.Join(fixtures, "segment_list.mpd"))
+	if code != exitOK {
+		t.Fatalf("wrong exit code: %d", code)
+	}
+
+	wantStdout := `Type:            static
+Profiles:        urn:mpeg:dash:profile:isoff-live:2011
+Duration:        PT30.016S
+Minimum buffer:  PT2.000S
+
+Period #0: start 0s, duration 30.016s
+  AdaptationSet 1: audio/mp4 lang=English
+    audio_1  255000 bps      mp4a.40.2
+  AdaptationSet 2: video/mp4
+    video_1  4172274 bps  1280x720  30000/1001 fps  avc1.640028
+`
+	if diff := cmp.Diff(stdout, wantStdout); diff != "" {
+		t.Errorf("wrong output: %s", diff)
+	}
+}
+
+func TestRun_Convert(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(fixtures, "live_profile.mpd")
+
+	code, _, stderr := runTool("", "convert", "-to", "hls", "-o", dir, "-multivariant", "index.m3u8", input)
+	if code != exitOK {
+		t.Fatalf("unexpected result: %d %s", code, stderr)
+	}
+
+	for _, name := range []string{"index.m3u8", "audio_800.m3u8", "video_1500.m3u8", "text_subtitle_en.m3u8"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	code, asJSON, stderr := runTool("", "convert", "-to", "json", filepath.Join(dir, "index.m3u8"))
+	if code != exitOK || !strings.HasPrefix(asJSON, "{") {
+		t.Fatalf("unexpected result: %d %s", code, stderr)
+	}
+
+	code, asXML, stderr := runTool(asJSON, "convert", "-to", "mpd")
+	if code != exitOK || !strings.Contains(asXML, `codecs="avc1.4d401f" bandwidth="2847827" id="video_1500"`) {
+		t.Errorf("unexpected result: %d %s %s", code, stderr, asXML)
+	}
+
+	smooth := `<SmoothStreamingMedia MajorVersion="2" MinorVersion="0" Duration="40000000">
+  <StreamIndex Type="audio" Name="audio" Url="QualityLevels({bitrate})/Fragments(audio={start time})">
+    <QualityLevel Bitrate="128000" FourCC="AACL" SamplingRate="48000" Channels="2"></QualityLevel>
+    <c d="20000000" r="2"></c>
+  </StreamIndex>
+</SmoothStreamingMedia>`
+
+	code, asXML, stderr = runTool(smooth, "convert", "-to", "mpd")
+	if code != exitOK || !strings.Contains(asXML, `bandwidth="128000" id="audio_0"`) {
+		t.Errorf("unexpected result: %d %s %s", code, stderr, asXML)
+	}
+
+	remote := "#EXTM3U\n#EXT-X-STREAM-INF:BANDWIDTH=1000\nhttps://example.com/video.m3u8\n"
+
+	testCases := map[string]struct {
+		stdin string
+		args  []string
+	}{
+		"missing output format":   {args: []string{"convert", input}},
+		"missing output dir":      {args: []string{"convert", "-to", "hls", input}},
+		"unknown input format":    {args: []string{"convert", "-from", "ism", "-to", "mpd", input}},
+		"wrong input format":      {args: []string{"convert", "-from", "json", "-to", "mpd", input}},
+		"invalid now":             {args: []string{"convert", "-to", "hls", "-o", dir, "-now", "today", input}},
+		"remote media playlist":   {stdin: remote, args: []string{"convert", "-to", "mpd"}},
+		"missing media playlist":  {stdin: strings.Replace(remote, "https://example.com/", "", 1), args: []string{"convert", "-to", "mpd"}},
+		"invalid Smooth manifest": {stdin: "<SmoothStreamingMedia>", args: []string{"convert", "-to", "mpd"}},
+	}
+
+	for name, testCase := range testCases {
+		if code, _, _ := runTool(testCase.stdin, testCase.args...); code != exitError {
+			t.Errorf("%s: wrong exit code: %d", name, code)
+		}
+	}
+
+	traversal := strings.Replace(remote, "https://example.com/", "../../", 1)
+	if code, _, stderr := runTool(traversal, "convert", "-to", "mpd"); code != exitError || !strings.Contains(stderr, "outside of") {
+		t.Errorf("unexpected result: %d %s", code, stderr)
+	}
+}
+
+func TestLocalPath(t *testing.T) {
+	if path, err := localPath("out", "audio/en.m3u8"); err != nil || path != filepath.Join("out", "audio", "en.m3u8") {
+		t.Errorf("unexpected result: %s %v", path, err)
+	}
+
+	for _, uri := range []string{"../pwn.m3u8", "audio/../../pwn.m3u8", "/tmp/pwn.m3u8", ""} {
+		if _, err := localPath("out", uri); err == nil {
+			t.Errorf("%q: expected error", uri)
+		}
+	}
+}
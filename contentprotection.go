@@ -0,0 +1,348 @@
+package mpd
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+var ErrResolveContentProtection = errors.New("cannot resolve ContentProtection reference")
+
+// NewMP4ProtectionContentProtection creates the ContentProtection signalling common encryption
+// with the given protection scheme, e.g. "cenc" or "cbcs", and the default KID.
+func NewMP4ProtectionContentProtection(scheme string, defaultKID UUID) ContentProtection {
+	contentProtection := ContentProtection{Descriptor: Descriptor{SchemeIDURI: MP4Protection2011SchemeIDURI, Value: scheme}}
+	contentProtection.SetDefaultKID(defaultKID)
+
+	return contentProtection
+}
+
+// NewDRMContentProtection creates the ContentProtection of a DRM system.
+// A PSSH box is added if data or key IDs are given.
+func NewDRMContentProtection(systemID UUID, data []byte, kid ...UUID) ContentProtection {
+	contentProtection := ContentProtection{Descriptor: Descriptor{SchemeIDURI: NewUUIDSchemeIDURI(systemID)}}
+	if len(data) > 0 || len(kid) > 0 {
+		contentProtection.AddPSSH(NewPSSH(systemID, data, kid...))
+	}
+
+	return contentProtection
+}
+
+// NewWidevineContentProtection creates a Widevine ContentProtection with the Widevine PSSH data.
+func NewWidevineContentProtection(data []byte, kid ...UUID) ContentProtection {
+	return NewDRMContentProtection(WidevineSystemID, data, kid...)
+}
+
+// NewPlayReadyContentProtection creates a PlayReady ContentProtection from a PlayReady Object,
+// which is used as PSSH data and for the mspr:pro element.
+func NewPlayReadyContentProtection(playReadyObject []byte, kid ...UUID) ContentProtection {
+	contentProtection := NewDRMContentProtection(PlayReadySystemID, playReadyObject, kid...)
+	if len(playReadyObject) > 0 {
+		contentProtection.MSPro = []string{base64.StdEncoding.EncodeToString(playReadyObject)}
+	}
+
+	return contentProtection
+}
+
+// NewFairPlayContentProtection creates a FairPlay ContentProtection.
+func NewFairPlayContentProtection(data []byte, kid ...UUID) ContentProtection {
+	return NewDRMContentProtection(FairPlaySystemID, data, kid...)
+}
+
+// NewClearKeyContentProtection creates a W3C Clear Key ContentProtection with a PSSH box listing the key IDs.
+func NewClearKeyContentProtection(kid ...UUID) ContentProtection {
+	return NewDRMContentProtection(ClearKeySystemID, nil, kid...)
+}
+
+// DefaultKID parses ContentProtection.CENCDefaultKID.
+func (c *ContentProtection) DefaultKID() (UUID, error) {
+	return ParseUUID(c.CENCDefaultKID)
+}
+
+// SetDefaultKID sets ContentProtection.CENCDefaultKID in the canonical form.
+func (c *ContentProtection) SetDefaultKID(kid UUID) {
+	c.CENCDefaultKID = kid.String()
+}
+
+// PSSH decodes all PSSH boxes of ContentProtection.CENCPSSH.
+func (c *ContentProtection) PSSH() ([]*PSSH, error) {
+	boxes := make([]*PSSH, 0, len(c.CENCPSSH))
+
+	for _, value := range c.CENCPSSH {
+		pssh, err := DecodePSSH(value)
+		if err != nil {
+			return nil, err
+		}
+
+		boxes = append(boxes, pssh)
+	}
+
+	return boxes, nil
+}
+
+// AddPSSH appends a PSSH box to ContentProtection.CENCPSSH.
+func (c *ContentProtection) AddPSSH(pssh *PSSH) {
+	c.CENCPSSH = append(c.CENCPSSH, pssh.Base64())
+}
+
+// PlayReadyObject decodes the first ContentProtection.MSPro element.
+func (c *ContentProtection) PlayReadyObject() (*PlayReadyObject, error) {
+	if len(c.MSPro) == 0 {
+		return nil, ErrParsePlayReadyObject
+	}
+
+	return DecodePlayReadyObject(c.MSPro[0])
+}
+
+// SetPlayReadyObject replaces ContentProtection.MSPro and the data of all PlayReady PSSH boxes
+// with the PlayReady Object, e.g. after changing the LA_URL of its header.
+func (c *ContentProtection) SetPlayReadyObject(object *PlayReadyObject) error {
+	boxes, err := c.PSSH()
+	if err != nil {
+		return err
+	}
+
+	data, err := object.Bytes()
+	if err != nil {
+		return err
+	}
+
+	c.MSPro = []string{base64.StdEncoding.EncodeToString(data)}
+
+	for i, pssh := range boxes {
+		if pssh.SystemID == PlayReadySystemID {
+			pssh.Data = data
+			c.CENCPSSH[i] = pssh.Base64()
+		}
+	}
+
+	return nil
+}
+
+// ResolveContentProtection returns copies of the ContentProtection elements of the Period, with each element
+// referencing another one through ContentProtection.Ref merged with the referenced element.
+// Attributes and children of the referencing element take precedence.
+// Referenced elements are looked up by ContentProtection.RefID in the Period first and then in the MPD.
+func (m *MPD) ResolveContentProtection(period *Period, contentProtection []ContentProtection) ([]ContentProtection, error) {
+	resolved := make([]ContentProtection, 0, len(contentProtection))
+
+	for _, element := range contentProtection {
+		if element.Ref != "" {
+			referenced := m.findContentProtectionRef(period, element.Ref)
+			if referenced == nil {
+				return nil, fmt.Errorf("%w: unknown ref %q", ErrResolveContentProtection, element.Ref)
+			}
+
+			element = element.merge(referenced)
+		}
+
+		resolved = append(resolved, element)
+	}
+
+	return resolved, nil
+}
+
+// EffectiveContentProtection returns the resolved ContentProtection elements that apply to the AdaptationSet,
+// or to the Representation if it is not nil. Representation elements replace AdaptationSet elements
+// with the same scheme.
+func (m *MPD) EffectiveContentProtection(period *Period, adaptationSet *AdaptationSet, representation *Representation) ([]ContentProtection, error) {
+	effective, err := m.ResolveContentProtection(period, adaptationSet.ContentProtection)
+	if err != nil || representation == nil || len(representation.ContentProtection) == 0 {
+		return effective, err
+	}
+
+	overrides, err := m.ResolveContentProtection(period, representation.ContentProtection)
+	if err != nil {
+		return nil, err
+	}
+
+	schemes := map[SchemeIDURI]bool{}
+	for _, element := range overrides {
+		schemes[element.SchemeIDURI] = true
+	}
+
+	inherited := effective[:0]
+	for _, element := range effective {
+		if !schemes[element.SchemeIDURI] {
+			inherited = append(inherited, element)
+		}
+	}
+
+	return append(inherited, overrides...), nil
+}
+
+// DanglingContentProtectionRefs returns the sorted ContentProtection.Ref values that do not resolve.
+func (m *MPD) DanglingContentProtectionRefs() []string {
+	dangling := map[string]bool{}
+
+	for i := range m.Period {
+		period := &m.Period[i]
+
+		walkContentProtection(period, func(contentProtection []ContentProtection) {
+			for _, element := range contentProtection {
+				if element.Ref != "" && m.findContentProtectionRef(period, element.Ref) == nil {
+					dangling[element.Ref] = true
+				}
+			}
+		})
+	}
+
+	refs := make([]string, 0, len(dangling))
+	for ref := range dangling {
+		refs = append(refs, ref)
+	}
+
+	sort.Strings(refs)
+
+	return refs
+}
+
+// DeduplicateContentProtection moves ContentProtection elements that occur more than once in the
+// AdaptationSets and Representations of all Periods to the MPD, and replaces them with references.
+// Existing MPD-level elements with a ContentProtection.RefID are reused if identical.
+func (m *MPD) DeduplicateContentProtection() {
+	var candidates []ContentProtection
+	var counts []int
+
+	for i := range m.Period {
+		walkContentProtection(&m.Period[i], func(contentProtection []ContentProtection) {
+			for _, element := range contentProtection {
+				if element.Ref != "" || element.RefID != "" || reflect.DeepEqual(element, ContentProtection{Descriptor: Descriptor{SchemeIDURI: element.SchemeIDURI}}) {
+					continue
+				}
+
+				index := indexContentProtection(candidates, element)
+				if index < 0 {
+					candidates = append(candidates, element)
+					counts = append(counts, 0)
+					index = len(candidates) - 1
+				}
+
+				counts[index]++
+			}
+		})
+	}
+
+	refIDs := map[string]bool{}
+	for _, element := range m.ContentProtection {
+		refIDs[element.RefID] = true
+	}
+
+	refs := make([]string, len(candidates))
+	for index, candidate := range candidates {
+		if counts[index] < 2 {
+			continue
+		}
+
+		for _, element := range m.ContentProtection {
+			if element.RefID != "" && reflect.DeepEqual(element.withoutRefID(), candidate) {
+				refs[index] = element.RefID
+				break
+			}
+		}
+
+		if refs[index] != "" {
+			continue
+		}
+
+		for n := 1; refs[index] == ""; n++ {
+			if refID := fmt.Sprintf("cp%d", n); !refIDs[refID] {
+				refs[index] = refID
+			}
+		}
+
+		refIDs[refs[index]] = true
+		candidate.RefID = refs[index]
+		m.ContentProtection = append(m.ContentProtection, candidate)
+	}
+
+	for i := range m.Period {
+		walkContentProtection(&m.Period[i], func(contentProtection []ContentProtection) {
+			for j, element := range contentProtection {
+				if element.Ref != "" || element.RefID != "" {
+					continue
+				}
+
+				if index := indexContentProtection(candidates, element); index >= 0 && refs[index] != "" {
+					contentProtection[j] = ContentProtection{Descriptor: Descriptor{SchemeIDURI: element.SchemeIDURI}, Ref: refs[index]}
+				}
+			}
+		})
+	}
+}
+
+func (m *MPD) findContentProtectionRef(period *Period, ref string) *ContentProtection {
+	for _, contentProtection := range [][]ContentProtection{period.ContentProtection, m.ContentProtection} {
+		for i := range contentProtection {
+			if contentProtection[i].RefID == ref {
+				return &contentProtection[i]
+			}
+		}
+	}
+
+	return nil
+}
+
+// walkContentProtection calls fn with the ContentProtection elements of all AdaptationSets and Representations of the
+// Period.
+func walkContentProtection(period *Period, fn func([]ContentProtection)) {
+	for i := range period.AdaptationSet {
+		adaptationSet := &period.AdaptationSet[i]
+		fn(adaptationSet.ContentProtection)
+
+		for j := range adaptationSet.Representation {
+			fn(adaptationSet.Representation[j].ContentProtection)
+		}
+	}
+}
+
+func indexContentProtection(contentProtection []ContentProtection, element ContentProtection) int {
+	for i := range contentProtection {
+		if reflect.DeepEqual(contentProtection[i], element) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (c ContentProtection) withoutRefID() ContentProtection {
+	c.RefID = ""
+	return c
+}
+
+func (c ContentProtection) merge(referenced *ContentProtection) ContentProtection {
+	if c.SchemeIDURI == "" {
+		c.SchemeIDURI = referenced.SchemeIDURI
+	}
+
+	if c.Value == "" {
+		c.Value = referenced.Value
+	}
+
+	if len(c.Items) == 0 {
+		c.Items = referenced.Items
+	}
+
+	if len(c.MSPro) == 0 {
+		c.MSPro = referenced.MSPro
+	}
+
+	if len(c.CENCPSSH) == 0 {
+		c.CENCPSSH = referenced.CENCPSSH
+	}
+
+	if c.CENCDefaultKID == "" {
+		c.CENCDefaultKID = referenced.CENCDefaultKID
+	}
+
+	if c.Robustness == "" {
+		c.Robustness = referenced.Robustness
+	}
+
+	c.Ref = ""
+
+	return c
+}
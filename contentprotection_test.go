@@ -0,0 +1,228 @@
+package mpd_test
+
+import (
+	"bytes"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"go.eigsys.de/go-mpd"
+	"io"
+	"testing"
+)
+
+func TestNewContentProtection(t *testing.T) {
+	kid := mustParseUUID("08e36702-8f33-436c-a5dd-60ffe5571e60")
+
+	type TestCase struct {
+		name                  string
+		contentProtection     mpd.ContentProtection
+		wantContentProtection mpd.ContentProtection
+	}
+
+	testCases := []TestCase{
+		{
+			name:              "mp4protection",
+			contentProtection: mpd.NewMP4ProtectionContentProtection("cbcs", kid),
+			wantContentProtection: mpd.ContentProtection{
+				Descriptor:     mpd.Descriptor{SchemeIDURI: mpd.MP4Protection2011SchemeIDURI, Value: "cbcs"},
+				CENCDefaultKID: "08e36702-8f33-436c-a5dd-60ffe5571e60",
+			},
+		},
+		{
+			name:              "Widevine",
+			contentProtection: mpd.NewWidevineContentProtection([]byte{1, 2}),
+			wantContentProtection: mpd.ContentProtection{
+				Descriptor: mpd.Descriptor{SchemeIDURI: mpd.WidevineSchemeIDURI},
+				CENCPSSH:   []string{mpd.NewPSSH(mpd.WidevineSystemID, []byte{1, 2}).Base64()},
+			},
+		},
+		{
+			name:              "PlayReady",
+			contentProtection: mpd.NewPlayReadyContentProtection([]byte{3, 4}, kid),
+			wantContentProtection: mpd.ContentProtection{
+				Descriptor: mpd.Descriptor{SchemeIDURI: mpd.PlayReadySchemeIDURI},
+				MSPro:      []string{"AwQ="},
+				CENCPSSH:   []string{mpd.NewPSSH(mpd.PlayReadySystemID, []byte{3, 4}, kid).Base64()},
+			},
+		},
+		{
+			name:                  "FairPlay",
+			contentProtection:     mpd.NewFairPlayContentProtection(nil),
+			wantContentProtection: mpd.ContentProtection{Descriptor: mpd.Descriptor{SchemeIDURI: mpd.FairPlaySchemeIDURI}},
+		},
+		{
+			name:              "ClearKey",
+			contentProtection: mpd.NewClearKeyContentProtection(kid),
+			wantContentProtection: mpd.ContentProtection{
+				Descriptor: mpd.Descriptor{SchemeIDURI: mpd.ClearKeySchemeIDURI},
+				CENCPSSH:   []string{mpd.NewPSSH(mpd.ClearKeySystemID, nil, kid).Base64()},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if diff := cmp.Diff(testCase.contentProtection, testCase.wantContentProtection); diff != "" {
+				t.Errorf("wrong ContentProtection: %s", diff)
+			}
+		})
+	}
+}
+
+func TestContentProtection_DefaultKID(t *testing.T) {
+	testMPD, err := mpd.Read(mustOpenFixture("zencoder/live_profile.mpd"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	kid, err := testMPD.Period[0].AdaptationSet[0].ContentProtection[0].DefaultKID()
+	if err != nil || kid != mustParseUUID("08e36702-8f33-436c-a5dd-60ffe5571e60") {
+		t.Errorf("wrong KID: %s", kid)
+	}
+
+	if _, err := testMPD.Period[0].AdaptationSet[0].ContentProtection[1].DefaultKID(); !errors.Is(err, mpd.ErrParseUUID) {
+		t.Errorf("wrong error: %v", err)
+	}
+}
+
+func TestMPD_DeduplicateContentProtection(t *testing.T) {
+	testMPD, err := mpd.Read(mustOpenFixture("zencoder/live_profile.mpd"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	period := &testMPD.Period[0]
+	wantContentProtection := append([]mpd.ContentProtection{}, period.AdaptationSet[1].ContentProtection...)
+
+	testMPD.DeduplicateContentProtection()
+
+	if len(testMPD.ContentProtection) != 3 || testMPD.ContentProtection[2].RefID != "cp3" {
+		t.Fatalf("wrong MPD ContentProtection: %+v", testMPD.ContentProtection)
+	}
+
+	wantReference := mpd.ContentProtection{Descriptor: mpd.Descriptor{SchemeIDURI: mpd.PlayReadySchemeIDURI}, Ref: "cp3"}
+	if diff := cmp.Diff(period.AdaptationSet[1].ContentProtection[2], wantReference); diff != "" {
+		t.Errorf("wrong reference: %s", diff)
+	}
+
+	data, err := testMPD.Bytes()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	testMPD, err = mpd.Read(io.NopCloser(bytes.NewReader(data)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	period = &testMPD.Period[0]
+	for i := range period.AdaptationSet[:2] {
+		contentProtection, err := testMPD.EffectiveContentProtection(period, &period.AdaptationSet[i], nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if diff := cmp.Diff(contentProtection, wantContentProtection); diff != "" {
+			t.Errorf("wrong ContentProtection: %s", diff)
+		}
+	}
+
+	testMPD.DeduplicateContentProtection()
+
+	if len(testMPD.ContentProtection) != 3 {
+		t.Errorf("wrong MPD ContentProtection: %+v", testMPD.ContentProtection)
+	}
+}
+
+func TestMPD_EffectiveContentProtection(t *testing.T) {
+	widevine := mpd.NewWidevineContentProtection([]byte{1})
+	widevine.RefID = "widevine"
+	widevine.Robustness = "SW_SECURE_CRYPTO"
+
+	testMPD := &mpd.MPD{
+		ContentProtection: []mpd.ContentProtection{widevine, {Descriptor: mpd.Descriptor{SchemeIDURI: mpd.FairPlaySchemeIDURI}, RefID: "fairplay"}},
+		Period: []mpd.Period{{
+			ContentProtection: []mpd.ContentProtection{mpd.NewWidevineContentProtection([]byte{2})},
+			AdaptationSet: []mpd.AdaptationSet{{
+				RepresentationBase: mpd.RepresentationBase{ContentProtection: []mpd.ContentProtection{
+					mpd.NewMP4ProtectionContentProtection("cenc", mpd.UUID{}),
+					{Descriptor: mpd.Descriptor{SchemeIDURI: mpd.WidevineSchemeIDURI}, Ref: "widevine", Robustness: "HW_SECURE_ALL"},
+				}},
+				Representation: []mpd.Representation{
+					{RepresentationBase: mpd.RepresentationBase{ContentProtection: []mpd.ContentProtection{
+						{Ref: "fairplay"},
+						{Descriptor: mpd.Descriptor{SchemeIDURI: mpd.WidevineSchemeIDURI}, Ref: "local"},
+					}}},
+					{RepresentationBase: mpd.RepresentationBase{ContentProtection: []mpd.ContentProtection{
+						{Descriptor: mpd.Descriptor{SchemeIDURI: mpd.WidevineSchemeIDURI}, Ref: "missing"},
+					}}},
+				},
+			}},
+		}},
+	}
+
+	testMPD.Period[0].ContentProtection[0].RefID = "local"
+
+	period := &testMPD.Period[0]
+	adaptationSet := &period.AdaptationSet[0]
+
+	contentProtection, err := testMPD.EffectiveContentProtection(period, adaptationSet, &adaptationSet.Representation[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantContentProtection := []mpd.ContentProtection{
+		mpd.NewMP4ProtectionContentProtection("cenc", mpd.UUID{}),
+		{Descriptor: mpd.Descriptor{SchemeIDURI: mpd.FairPlaySchemeIDURI}},
+		mpd.NewWidevineContentProtection([]byte{2}),
+	}
+	if diff := cmp.Diff(contentProtection, wantContentProtection); diff != "" {
+		t.Errorf("wrong ContentProtection: %s", diff)
+	}
+
+	contentProtection, err = testMPD.EffectiveContentProtection(period, adaptationSet, &mpd.Representation{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if contentProtection[1].Robustness != "HW_SECURE_ALL" || len(contentProtection[1].CENCPSSH) != 1 || contentProtection[1].Ref != "" {
+		t.Errorf("wrong ContentProtection: %+v", contentProtection[1])
+	}
+
+	if _, err := testMPD.EffectiveContentProtection(period, adaptationSet, &adaptationSet.Representation[1]); !errors.Is(err, mpd.ErrResolveContentProtection) {
+		t.Errorf("wrong error: %v", err)
+	}
+
+	if _, err := testMPD.EffectiveContentProtection(period, &mpd.AdaptationSet{Representation: adaptationSet.Representation[1:]}, nil); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if refs := testMPD.DanglingContentProtectionRefs(); !cmp.Equal(refs, []string{"missing"}) {
+		t.Errorf("wrong dangling refs: %v", refs)
+	}
+}
+
+func TestMPD_DeduplicateContentProtection_ExistingRefID(t *testing.T) {
+	widevine := mpd.NewWidevineContentProtection([]byte{1})
+	playReady := mpd.NewPlayReadyContentProtection([]byte{2})
+
+	testMPD := &mpd.MPD{
+		ContentProtection: []mpd.ContentProtection{widevine, playReady},
+		Period: []mpd.Period{{AdaptationSet: []mpd.AdaptationSet{
+			{RepresentationBase: mpd.RepresentationBase{ContentProtection: []mpd.ContentProtection{widevine, playReady}}},
+			{RepresentationBase: mpd.RepresentationBase{ContentProtection: []mpd.ContentProtection{widevine, playReady}}},
+		}}},
+	}
+
+	testMPD.ContentProtection[0].RefID = "cp1"
+
+	testMPD.DeduplicateContentProtection()
+
+	refs := []string{testMPD.ContentProtection[2].RefID}
+	for _, contentProtection := range testMPD.Period[0].AdaptationSet[1].ContentProtection {
+		refs = append(refs, contentProtection.Ref)
+	}
+
+	if len(testMPD.ContentProtection) != 3 || !cmp.Equal(refs, []string{"cp2", "cp1", "cp2"}) {
+		t.Errorf("wrong refs: %v", refs)
+	}
+}
@@ -0,0 +1,262 @@
+package mpd
+
+import (
+	"errors"
+	"math/big"
+	"time"
+)
+
+var ErrPeriodContinuity = errors.New("cannot determine period continuity")
+
+// AdaptationSetContinuity is an AdaptationSet whose content continues an AdaptationSet of an earlier Period, so that
+// a player can switch Periods without re-initialising the decoder. The Periods and AdaptationSets are given by index.
+type AdaptationSetContinuity struct {
+	EarlierPeriod        int
+	EarlierAdaptationSet int
+	Period               int
+	AdaptationSet        int
+
+	// Continuous is true for period continuity, where the Period boundary is a segment boundary of all
+	// Representations. It is false for period connectivity, where segments span the boundary and are available in
+	// both Periods.
+	Continuous bool
+}
+
+// PeriodContinuity analyses which AdaptationSets continue an AdaptationSet of an earlier Period.
+//
+// A Period continues the nearest earlier Period with an equal AssetIdentifier, or the directly preceding Period if
+// neither has an AssetIdentifier. AdaptationSets are matched by ID and must have Representations with the same IDs
+// and codecs. The media timelines must continue across the end of the earlier Period: the PresentationTimeOffset of
+// the later Period is the media time at the end of the earlier Period, and the segments of both Periods cover the
+// boundary. Representations whose segments cannot be resolved are not continuous.
+func (m *MPD) PeriodContinuity() ([]AdaptationSetContinuity, error) {
+	var continuities []AdaptationSetContinuity
+
+	for later := 1; later < len(m.Period); later++ {
+		earlier := m.continuedPeriod(later)
+		if earlier < 0 {
+			continue
+		}
+
+		laterStart, err := m.PeriodStart(later)
+		if err != nil {
+			return nil, errors.Join(ErrPeriodContinuity, err)
+		}
+
+		earlierStart, err := m.PeriodStart(earlier)
+		if err != nil {
+			return nil, errors.Join(ErrPeriodContinuity, err)
+		}
+
+		earlierDuration, err := m.PeriodDuration(earlier)
+		if err != nil {
+			return nil, errors.Join(ErrPeriodContinuity, err)
+		}
+
+		if earlierDuration == 0 {
+			continue
+		}
+
+		boundary := periodBoundary{
+			earlier: &m.Period[earlier], later: &m.Period[later],
+			earlierEnd: earlierStart + earlierDuration, earlierDuration: earlierDuration, laterStart: laterStart,
+		}
+
+		for i := range boundary.later.AdaptationSet {
+			adaptationSet := &boundary.later.AdaptationSet[i]
+			if adaptationSet.ID == 0 {
+				continue
+			}
+
+			for j := range boundary.earlier.AdaptationSet {
+				if boundary.earlier.AdaptationSet[j].ID != adaptationSet.ID {
+					continue
+				}
+
+				if connected, continuous := m.adaptationSetContinuity(&boundary, &boundary.earlier.AdaptationSet[j], adaptationSet); connected {
+					continuities = append(continuities, AdaptationSetContinuity{
+						EarlierPeriod: earlier, EarlierAdaptationSet: j, Period: later, AdaptationSet: i, Continuous: continuous,
+					})
+				}
+			}
+		}
+	}
+
+	return continuities, nil
+}
+
+// AnnotatePeriodContinuity replaces the period continuity and connectivity descriptors of all AdaptationSets with
+// the result of PeriodContinuity. AdaptationSets that continue a Period without an ID are not annotated.
+func (m *MPD) AnnotatePeriodContinuity() error {
+	continuities, err := m.PeriodContinuity()
+	if err != nil {
+		return err
+	}
+
+	for i := range m.Period {
+		for j := range m.Period[i].AdaptationSet {
+			adaptationSet := &m.Period[i].AdaptationSet[j]
+			adaptationSet.SupplementalProperty = withoutContinuedPeriod(adaptationSet.SupplementalProperty)
+		}
+	}
+
+	for _, continuity := range continuities {
+		if id := m.Period[continuity.EarlierPeriod].ID; id != "" {
+			m.Period[continuity.Period].AdaptationSet[continuity.AdaptationSet].signalContinuedPeriod(id, continuity.Continuous)
+		}
+	}
+
+	return nil
+}
+
+// ContinuedPeriod returns the ID of the earlier Period that the AdaptationSet signals to continue, and whether it
+// signals period continuity rather than period connectivity. The ID is empty if neither is signalled.
+func (a *AdaptationSet) ContinuedPeriod() (string, bool) {
+	for _, property := range a.SupplementalProperty {
+		switch property.SchemeIDURI {
+		case PeriodContinuitySchemeIDURI:
+			return property.Value, true
+		case PeriodConnectivitySchemeIDURI:
+			return property.Value, false
+		}
+	}
+
+	return "", false
+}
+
+// signalContinuedPeriod replaces the period continuity and connectivity descriptors of the AdaptationSet.
+func (a *AdaptationSet) signalContinuedPeriod(periodID string, continuous bool) {
+	schemeIDURI := PeriodConnectivitySchemeIDURI
+	if continuous {
+		schemeIDURI = PeriodContinuitySchemeIDURI
+	}
+
+	a.SupplementalProperty = append(withoutContinuedPeriod(a.SupplementalProperty), Descriptor{SchemeIDURI: schemeIDURI, Value: periodID})
+}
+
+func withoutContinuedPeriod(properties []Descriptor) []Descriptor {
+	var kept []Descriptor
+
+	for _, property := range properties {
+		if property.SchemeIDURI != PeriodContinuitySchemeIDURI && property.SchemeIDURI != PeriodConnectivitySchemeIDURI {
+			kept = append(kept, property)
+		}
+	}
+
+	return kept
+}
+
+// continuedPeriod returns the index of the Period that the Period at the index continues, or -1.
+func (m *MPD) continuedPeriod(later int) int {
+	assetIdentifier := m.Period[later].AssetIdentifier
+	if assetIdentifier == nil {
+		if m.Period[later-1].AssetIdentifier == nil {
+			return later - 1
+		}
+
+		return -1
+	}
+
+	for earlier := later - 1; earlier >= 0; earlier-- {
+		if other := m.Period[earlier].AssetIdentifier; other != nil && other.SchemeIDURI == assetIdentifier.SchemeIDURI && other.Value == assetIdentifier.Value {
+			return earlier
+		}
+	}
+
+	return -1
+}
+
+// periodBoundary is the end of an earlier Period and the start of a later Period that continues it, relative to the
+// start of the media presentation.
+type periodBoundary struct {
+	earlier, later  *Period
+	earlierEnd      time.Duration
+	earlierDuration time.Duration
+	laterStart      time.Duration
+}
+
+// adaptationSetContinuity reports whether the later AdaptationSet is connected to the earlier one, and whether it is
+// also continuous.
+func (m *MPD) adaptationSetContinuity(boundary *periodBoundary, earlier, later *AdaptationSet) (bool, bool) {
+	if len(earlier.Representation) != len(later.Representation) || len(later.Representation) == 0 {
+		return false, false
+	}
+
+	continuous := true
+
+	for i := range later.Representation {
+		laterRepresentation := &later.Representation[i]
+
+		var earlierRepresentation *Representation
+
+		for j := range earlier.Representation {
+			if earlier.Representation[j].ID == laterRepresentation.ID {
+				earlierRepresentation = &earlier.Representation[j]
+			}
+		}
+
+		if earlierRepresentation == nil || inheritedCodecs(earlier, earlierRepresentation) != inheritedCodecs(later, laterRepresentation) {
+			return false, false
+		}
+
+		connected, representationContinuous := m.representationContinuity(boundary, earlier, earlierRepresentation, later, laterRepresentation)
+		if !connected {
+			return false, false
+		}
+
+		continuous = continuous && representationContinuous
+	}
+
+	return true, continuous
+}
+
+// representationContinuity compares the media timelines of a Representation at the Period boundary in exact
+// fractions of seconds.
+func (m *MPD) representationContinuity(boundary *periodBoundary, earlierAdaptationSet *AdaptationSet, earlier *Representation, laterAdaptationSet *AdaptationSet, later *Representation) (bool, bool) {
+	earlierSegments, err := m.Segments(boundary.earlier, earlierAdaptationSet, earlier, &SegmentFilter{Start: boundary.earlierEnd - time.Nanosecond, End: boundary.earlierEnd})
+	if err != nil || len(earlierSegments) == 0 {
+		return false, false
+	}
+
+	laterSegments, err := m.Segments(boundary.later, laterAdaptationSet, later, &SegmentFilter{Start: boundary.laterStart, End: boundary.laterStart + time.Nanosecond})
+	if err != nil || len(laterSegments) == 0 {
+		return false, false
+	}
+
+	// The media time at the end of the earlier Period has to be the PresentationTimeOffset of the later Period.
+	earlierEnd := new(big.Rat).Add(mediaTimeOffset(boundary.earlier, earlierAdaptationSet, earlier), big.NewRat(int64(boundary.earlierDuration), int64(time.Second)))
+	laterStart := mediaTimeOffset(boundary.later, laterAdaptationSet, later)
+
+	if earlierEnd.Cmp(laterStart) != 0 {
+		return false, false
+	}
+
+	last, first := &earlierSegments[len(earlierSegments)-1], &laterSegments[0]
+	lastEnd := ticksToSeconds(last.Time+last.Duration, last.Timescale)
+	firstStart := ticksToSeconds(first.Time, first.Timescale)
+
+	switch lastEnd.Cmp(earlierEnd) {
+	case -1:
+		return false, false
+	case 0:
+		return firstStart.Cmp(laterStart) <= 0, firstStart.Cmp(laterStart) == 0
+	default:
+		return firstStart.Cmp(laterStart) < 0, false
+	}
+}
+
+// mediaTimeOffset returns the PresentationTimeOffset of the Representation in seconds.
+func mediaTimeOffset(period *Period, adaptationSet *AdaptationSet, representation *Representation) *big.Rat {
+	segmentBase := effectiveSegmentBase(period, adaptationSet, representation)
+
+	return ticksToSeconds(segmentBase.PresentationTimeOffset, uint64(segmentBase.Timescale))
+}
+
+// ticksToSeconds converts a time in timescale units to seconds.
+func ticksToSeconds(ticks, timescale uint64) *big.Rat {
+	if timescale == 0 {
+		timescale = 1
+	}
+
+	return new(big.Rat).SetFrac(new(big.Int).SetUint64(ticks), new(big.Int).SetUint64(timescale))
+}
@@ -0,0 +1,133 @@
+package mpd_test
+
+import (
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"go.eigsys.de/go-mpd"
+	"testing"
+	"time"
+)
+
+func TestMPD_PeriodContinuity(t *testing.T) {
+	testMPD := newContentMPD()
+	testMPD.Period[0].AssetIdentifier = &mpd.Descriptor{SchemeIDURI: "urn:org:dashif:asset-id:2013", Value: "movie"}
+
+	if _, err := testMPD.InsertPeriod(10*time.Second, newAdMPD(), 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	continuities, err := testMPD.PeriodContinuity()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []mpd.AdaptationSetContinuity{
+		{EarlierPeriod: 0, EarlierAdaptationSet: 0, Period: 2, AdaptationSet: 0, Continuous: false},
+		{EarlierPeriod: 0, EarlierAdaptationSet: 1, Period: 2, AdaptationSet: 1, Continuous: true},
+	}
+	if diff := cmp.Diff(continuities, want); diff != "" {
+		t.Errorf("wrong continuities: %s", diff)
+	}
+
+	wantMPD := newContentMPD()
+	wantMPD.Period[0].AssetIdentifier = testMPD.Period[0].AssetIdentifier
+	_, _ = wantMPD.InsertPeriod(10*time.Second, newAdMPD(), 0)
+
+	resumed := &testMPD.Period[2]
+	resumed.AdaptationSet[0].SupplementalProperty = nil
+	resumed.AdaptationSet[1].SupplementalProperty = []mpd.Descriptor{{SchemeIDURI: mpd.PeriodConnectivitySchemeIDURI, Value: "other"}}
+
+	if err := testMPD.AnnotatePeriodContinuity(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(testMPD, wantMPD); diff != "" {
+		t.Errorf("wrong MPD: %s", diff)
+	}
+
+	for i, want := range []struct {
+		id         string
+		continuous bool
+	}{{id: "main", continuous: false}, {id: "main", continuous: true}} {
+		if id, continuous := resumed.AdaptationSet[i].ContinuedPeriod(); id != want.id || continuous != want.continuous {
+			t.Errorf("wrong continued Period: %s %t", id, continuous)
+		}
+	}
+
+	if id, _ := testMPD.Period[1].AdaptationSet[0].ContinuedPeriod(); id != "" {
+		t.Errorf("wrong continued Period: %s", id)
+	}
+}
+
+func TestMPD_PeriodContinuity_Discontinuous(t *testing.T) {
+	testCases := map[string]func(*mpd.MPD){
+		"different codecs":          func(m *mpd.MPD) { m.Period[1].AdaptationSet[1].Representation[0].Codecs = "ec-3" },
+		"different Representations": func(m *mpd.MPD) { m.Period[1].AdaptationSet[1].Representation[0].ID = "a2" },
+		"additional Representation": func(m *mpd.MPD) {
+			m.Period[1].AdaptationSet[1].Representation = append(m.Period[1].AdaptationSet[1].Representation, mpd.Representation{ID: "a2"})
+		},
+		"different AdaptationSet IDs": func(m *mpd.MPD) { m.Period[1].AdaptationSet[1].ID = 3 },
+		"missing AdaptationSet ID":    func(m *mpd.MPD) { m.Period[0].AdaptationSet[1].ID = 0; m.Period[1].AdaptationSet[1].ID = 0 },
+		"media time gap":              func(m *mpd.MPD) { m.Period[1].AdaptationSet[1].SegmentTemplate.PresentationTimeOffset += 48000 },
+		"missing earlier segment": func(m *mpd.MPD) {
+			m.Period[0].AdaptationSet[1].SegmentTemplate.SegmentTimeline.S[0].R = 3
+		},
+		"missing later segment": func(m *mpd.MPD) {
+			timeline := m.Period[1].AdaptationSet[1].SegmentTemplate.SegmentTimeline
+			*timeline.S[0].T += 96000
+		},
+		"unresolved segments": func(m *mpd.MPD) { m.Period[1].AdaptationSet[1].SegmentTemplate.Media = "" },
+		"later segment after boundary": func(m *mpd.MPD) {
+			m.Period[0].AdaptationSet[1].SegmentTemplate.SegmentTimeline.S = []mpd.S{{T: uint64Ptr(0), D: 576000}}
+		},
+	}
+
+	for name, mutate := range testCases {
+		testMPD := newContentMPD()
+		if err := testMPD.SplitPeriod(0, 10*time.Second); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		mutate(testMPD)
+
+		continuities, err := testMPD.PeriodContinuity()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+
+		if len(continuities) != 1 || continuities[0].AdaptationSet != 0 {
+			t.Errorf("%s: wrong continuities: %+v", name, continuities)
+		}
+	}
+
+	testMPD := newContentMPD()
+	if err := testMPD.SplitPeriod(0, 10*time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	testMPD.Period[1].AssetIdentifier = &mpd.Descriptor{SchemeIDURI: "urn:org:dashif:asset-id:2013", Value: "ad"}
+
+	if continuities, err := testMPD.PeriodContinuity(); err != nil || len(continuities) != 0 {
+		t.Errorf("unexpected result: %+v %v", continuities, err)
+	}
+}
+
+func TestMPD_PeriodContinuity_Errors(t *testing.T) {
+	testMPD := newContentMPD()
+	if err := testMPD.SplitPeriod(0, 10*time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	testMPD.Period[1].Start = "invalid"
+
+	if err := testMPD.AnnotatePeriodContinuity(); !errors.Is(err, mpd.ErrPeriodContinuity) {
+		t.Errorf("wrong error: %v", err)
+	}
+
+	testMPD.Period[1].Start = ""
+	testMPD.Period[0].Duration = ""
+
+	if continuities, err := testMPD.PeriodContinuity(); !errors.Is(err, mpd.ErrPeriodContinuity) || continuities != nil {
+		t.Errorf("unexpected result: %+v %v", continuities, err)
+	}
+}
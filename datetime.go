@@ -0,0 +1,25 @@
+package mpd
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrParseDateTime = errors.New("cannot parse date time")
+
+// ParseDateTime parses an xs:dateTime, e.g. "2023-04-01T12:00:00Z".
+// Values without a time zone are interpreted as UTC.
+func ParseDateTime(value string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999"} {
+		if dateTime, err := time.Parse(layout, value); err == nil {
+			return dateTime, nil
+		}
+	}
+
+	return time.Time{}, ErrParseDateTime
+}
+
+// FormatDateTime formats a time as xs:dateTime in UTC, e.g. "2023-04-01T12:00:00.5Z".
+func FormatDateTime(dateTime time.Time) string {
+	return dateTime.UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,43 @@
+package mpd_test
+
+import (
+	"errors"
+	"go.eigsys.de/go-mpd"
+	"testing"
+	"time"
+)
+
+func TestParseDateTime(t *testing.T) {
+	type TestCase struct {
+		input   string
+		want    time.Time
+		wantErr error
+	}
+
+	testCases := []TestCase{
+		{input: "2023-04-01T12:00:00Z", want: time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)},
+		{input: "2023-04-01T14:00:00.5+02:00", want: time.Date(2023, 4, 1, 12, 0, 0, 500000000, time.UTC)},
+		{input: "2023-04-01T12:00:00", want: time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)},
+		{input: "2023-04-01", wantErr: mpd.ErrParseDateTime},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.input, func(t *testing.T) {
+			dateTime, err := mpd.ParseDateTime(testCase.input)
+			if !errors.Is(err, testCase.wantErr) {
+				t.Errorf("wrong error: %v", err)
+			}
+
+			if !dateTime.Equal(testCase.want) {
+				t.Errorf("wrong date time: %s", dateTime)
+			}
+		})
+	}
+}
+
+func TestFormatDateTime(t *testing.T) {
+	dateTime := time.Date(2023, 4, 1, 14, 0, 0, 500000000, time.FixedZone("", 2*60*60))
+	if formatted := mpd.FormatDateTime(dateTime); formatted != "2023-04-01T12:00:00.5Z" {
+		t.Errorf("wrong date time: %s", formatted)
+	}
+}
@@ -0,0 +1,373 @@
+package mpd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrBuildFromDirectory = errors.New("cannot build MPD from directory")
+
+var templateIdentifierPattern = regexp.MustCompile(`\$(RepresentationID|Number|Time|Bandwidth|SubNumber)?(%0[0-9]+d)?\$`)
+
+// templatePattern converts a SegmentTemplate media or initialization template into a regular expression
+// with named groups for every identifier.
+func templatePattern(template string) (*regexp.Regexp, error) {
+	var builder strings.Builder
+
+	builder.WriteString("^")
+
+	position := 0
+	for _, match := range templateIdentifierPattern.FindAllStringSubmatchIndex(template, -1) {
+		builder.WriteString(regexp.QuoteMeta(template[position:match[0]]))
+		position = match[1]
+
+		if match[2] < 0 {
+			builder.WriteString(`\$`)
+			continue
+		}
+
+		identifier := template[match[2]:match[3]]
+		if strings.Contains(builder.String(), "(?P<"+identifier+">") {
+			builder.WriteString(`.+`)
+			continue
+		}
+
+		if identifier == "RepresentationID" {
+			builder.WriteString(`(?P<RepresentationID>[^/]+)`)
+		} else {
+			builder.WriteString(`(?P<` + identifier + `>[0-9]+)`)
+		}
+	}
+
+	builder.WriteString(regexp.QuoteMeta(template[position:]))
+	builder.WriteString("$")
+
+	return regexp.Compile(builder.String())
+}
+
+// directoryRepresentation collects the files of one Representation found in a directory.
+type directoryRepresentation struct {
+	ID             string
+	Initialization string
+	Media          []directoryMedia
+}
+
+type directoryMedia struct {
+	Name  string
+	Order uint64
+}
+
+// BuildFromDirectory creates a static MPD from the CMAF/fMP4 segments stored in a local directory.
+// The directory layout is described by the initialization and media templates of the given SegmentTemplate,
+// relative to the directory, e.g. "$RepresentationID$/init.mp4" and "$RepresentationID$/$Number$.m4s".
+// Initialization segments are probed for codecs, timescale and media properties, media segments for their exact
+// decode times and durations, and bandwidths are measured from the file sizes.
+// Every track type results in a separate AdaptationSet.
+func BuildFromDirectory(dir string, template SegmentTemplate) (*MPD, error) {
+	found, err := scanDirectory(dir, template)
+	if err != nil {
+		return nil, errors.Join(ErrBuildFromDirectory, err)
+	}
+
+	adaptationSets := map[ContentType]*AdaptationSet{}
+	languages := map[ContentType]map[string]bool{}
+
+	var mediaPresentationDuration, maxSegmentDuration time.Duration
+
+	for _, files := range found {
+		built, err := buildRepresentation(template, files)
+		if err != nil {
+			return nil, errors.Join(ErrBuildFromDirectory, err)
+		}
+
+		if built.Duration > mediaPresentationDuration {
+			mediaPresentationDuration = built.Duration
+		}
+
+		if built.MaxSegmentDuration > maxSegmentDuration {
+			maxSegmentDuration = built.MaxSegmentDuration
+		}
+
+		adaptationSet, ok := adaptationSets[built.ContentType]
+		if !ok {
+			adaptationSet = &AdaptationSet{ContentType: built.ContentType}
+			adaptationSet.MIMEType = built.MIMEType
+			adaptationSets[built.ContentType] = adaptationSet
+			languages[built.ContentType] = map[string]bool{}
+		}
+
+		adaptationSet.Representation = append(adaptationSet.Representation, built.Representation)
+		languages[built.ContentType][built.Language] = true
+	}
+
+	period := Period{ID: "0"}
+
+	for _, contentType := range []ContentType{VideoContentType, AudioContentType, SubtitlesContentType} {
+		adaptationSet, ok := adaptationSets[contentType]
+		if !ok {
+			continue
+		}
+
+		if len(languages[contentType]) == 1 {
+			for language := range languages[contentType] {
+				adaptationSet.Lang = language
+			}
+		}
+
+		adaptationSet.ID = uint(len(period.AdaptationSet) + 1)
+		period.AdaptationSet = append(period.AdaptationSet, *adaptationSet)
+	}
+
+	mpd := New()
+	mpd.Profiles = Live2011Profile
+	mpd.Type = StaticPresentationType
+	mpd.MediaPresentationDuration = FormatDuration(mediaPresentationDuration)
+	mpd.MaxSegmentDuration = FormatDuration(maxSegmentDuration)
+	mpd.MinBufferTime = FormatDuration(maxSegmentDuration)
+	mpd.Period = []Period{period}
+
+	return mpd, nil
+}
+
+// scanDirectory finds all initialization and media segments matching the SegmentTemplate.
+func scanDirectory(dir string, template SegmentTemplate) ([]*directoryRepresentation, error) {
+	initializationPattern, err := templatePattern(template.Initialization)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaPattern, err := templatePattern(template.Media)
+	if err != nil {
+		return nil, err
+	}
+
+	orderGroup := mediaPattern.SubexpIndex("Number")
+	if orderGroup < 0 {
+		orderGroup = mediaPattern.SubexpIndex("Time")
+	}
+
+	if orderGroup < 0 {
+		return nil, fmt.Errorf("media template %q lacks $Number$ or $Time$", template.Media)
+	}
+
+	representations := map[string]*directoryRepresentation{}
+	get := func(id string) *directoryRepresentation {
+		if _, ok := representations[id]; !ok {
+			representations[id] = &directoryRepresentation{ID: id}
+		}
+
+		return representations[id]
+	}
+
+	representationID := func(pattern *regexp.Regexp, matches []string) string {
+		if index := pattern.SubexpIndex("RepresentationID"); index >= 0 {
+			return matches[index]
+		}
+
+		return "0"
+	}
+
+	err = filepath.WalkDir(dir, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		relativeName, err := filepath.Rel(dir, name)
+		if err != nil {
+			return err
+		}
+
+		relativeName = filepath.ToSlash(relativeName)
+
+		if matches := initializationPattern.FindStringSubmatch(relativeName); matches != nil {
+			get(representationID(initializationPattern, matches)).Initialization = name
+		} else if matches := mediaPattern.FindStringSubmatch(relativeName); matches != nil {
+			order, err := strconv.ParseUint(matches[orderGroup], 10, 64)
+			if err != nil {
+				return err
+			}
+
+			representation := get(representationID(mediaPattern, matches))
+			representation.Media = append(representation.Media, directoryMedia{Name: name, Order: order})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(representations))
+	for id, representation := range representations {
+		if representation.Initialization == "" {
+			return nil, fmt.Errorf("missing initialization segment for representation %q", id)
+		}
+
+		if len(representation.Media) == 0 {
+			return nil, fmt.Errorf("missing media segments for representation %q", id)
+		}
+
+		ids = append(ids, id)
+	}
+
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no segments found in %q", dir)
+	}
+
+	sort.Strings(ids)
+
+	sorted := make([]*directoryRepresentation, 0, len(ids))
+	for _, id := range ids {
+		representation := representations[id]
+		sort.Slice(representation.Media, func(i, j int) bool {
+			return representation.Media[i].Order < representation.Media[j].Order
+		})
+
+		sorted = append(sorted, representation)
+	}
+
+	return sorted, nil
+}
+
+// builtRepresentation is a Representation probed from its segments.
+type builtRepresentation struct {
+	Representation     Representation
+	ContentType        ContentType
+	MIMEType           MIMEType
+	Language           string
+	Duration           time.Duration
+	MaxSegmentDuration time.Duration
+}
+
+// buildRepresentation probes the initialization and media segments of a Representation.
+func buildRepresentation(template SegmentTemplate, files *directoryRepresentation) (*builtRepresentation, error) {
+	initialization, err := os.ReadFile(files.Initialization)
+	if err != nil {
+		return nil, err
+	}
+
+	tracks, err := probeInitSegment(initialization)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", files.Initialization, err)
+	}
+
+	t := tracks[0]
+	if t.Timescale == 0 {
+		return nil, fmt.Errorf("%s: %w: zero timescale", files.Initialization, ErrProbeSegment)
+	}
+
+	segmentTemplate := &SegmentTemplate{
+		Initialization: template.Initialization,
+		Media:          template.Media,
+	}
+	segmentTemplate.Timescale = uint(t.Timescale)
+	segmentTemplate.SegmentTimeline = &SegmentTimeline{}
+	timeline := newTimelineBuilder(segmentTemplate.SegmentTimeline)
+
+	if strings.Contains(template.Media, "$Number") {
+		segmentTemplate.StartNumber = uint(files.Media[0].Order)
+	}
+
+	var bandwidth, duration, end, maxSegmentDuration, sampleCount uint64
+
+	for _, media := range files.Media {
+		data, err := os.ReadFile(media.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		f, err := probeMediaSegment(data, t)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", media.Name, err)
+		}
+
+		if f.Duration == 0 {
+			return nil, fmt.Errorf("%s: %w: zero duration", media.Name, ErrProbeSegment)
+		}
+
+		if len(segmentTemplate.SegmentTimeline.S) == 0 {
+			segmentTemplate.PresentationTimeOffset = f.BaseMediaDecodeTime
+		}
+
+		timeline.append(f.BaseMediaDecodeTime, f.Duration)
+
+		if segmentBandwidth := (uint64(len(data))*8*uint64(t.Timescale) + f.Duration - 1) / f.Duration; segmentBandwidth > bandwidth {
+			bandwidth = segmentBandwidth
+		}
+
+		if f.Duration > maxSegmentDuration {
+			maxSegmentDuration = f.Duration
+		}
+
+		if f.BaseMediaDecodeTime+f.Duration > end {
+			end = f.BaseMediaDecodeTime + f.Duration
+		}
+
+		duration += f.Duration
+		sampleCount += f.SampleCount
+	}
+
+	built := &builtRepresentation{
+		Representation: Representation{
+			ID:              files.ID,
+			Bandwidth:       uint(bandwidth),
+			SegmentTemplate: segmentTemplate,
+		},
+		ContentType:        SubtitlesContentType,
+		MIMEType:           ApplicationMP4MIMEType,
+		Language:           t.Language,
+		Duration:           scaledDuration(int64(end-segmentTemplate.PresentationTimeOffset), uint64(t.Timescale)),
+		MaxSegmentDuration: scaledDuration(int64(maxSegmentDuration), uint64(t.Timescale)),
+	}
+
+	representation := &built.Representation
+	representation.Codecs = t.Codecs
+
+	switch t.HandlerType {
+	case "vide":
+		built.ContentType = VideoContentType
+		built.MIMEType = VideoMP4MIMEType
+		representation.Width = t.Width
+		representation.Height = t.Height
+		representation.FrameRate = frameRate(sampleCount*uint64(t.Timescale), duration)
+	case "soun":
+		built.ContentType = AudioContentType
+		built.MIMEType = AudioMP4MIMEType
+		representation.AudioSamplingRate = &AudioSamplingRate{t.SampleRate}
+		representation.AudioChannelConfiguration = []*Descriptor{{
+			SchemeIDURI: AudioChannelConfiguration2011SchemeIDURI,
+			Value:       strconv.FormatUint(uint64(t.ChannelCount), 10),
+		}}
+	}
+
+	return built, nil
+}
+
+// frameRate reduces a frame rate fraction.
+func frameRate(numerator, denominator uint64) FrameRate {
+	if denominator == 0 {
+		return ""
+	}
+
+	a, b := numerator, denominator
+	for b != 0 {
+		a, b = b, a%b
+	}
+
+	numerator /= a
+	denominator /= a
+
+	if denominator == 1 {
+		return FrameRate(strconv.FormatUint(numerator, 10))
+	}
+
+	return FrameRate(fmt.Sprintf("%d/%d", numerator, denominator))
+}
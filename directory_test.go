@@ -0,0 +1,393 @@
+package mpd_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"go.eigsys.de/go-mpd"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func u16(values ...uint16) []byte {
+	var data []byte
+	for _, value := range values {
+		data = binary.BigEndian.AppendUint16(data, value)
+	}
+
+	return data
+}
+
+func u32(values ...uint32) []byte {
+	var data []byte
+	for _, value := range values {
+		data = binary.BigEndian.AppendUint32(data, value)
+	}
+
+	return data
+}
+
+func u64(values ...uint64) []byte {
+	var data []byte
+	for _, value := range values {
+		data = binary.BigEndian.AppendUint64(data, value)
+	}
+
+	return data
+}
+
+func makeFullBox(boxType string, version uint8, flags uint32, payload ...[]byte) []byte {
+	header := []byte{version, byte(flags >> 16), byte(flags >> 8), byte(flags)}
+	return makeBox(boxType, append([][]byte{header}, payload...)...)
+}
+
+func packLanguage(language string) uint16 {
+	if language == "" {
+		return 0
+	}
+
+	return uint16(language[0]-0x60)<<10 | uint16(language[1]-0x60)<<5 | uint16(language[2]-0x60)
+}
+
+func makeInitSegment(handlerType string, timescale uint32, language string, sampleEntry []byte) []byte {
+	tkhd := makeFullBox("tkhd", 0, 3, u32(0, 0, 1, 0, 0), make([]byte, 60))
+	mdhd := makeFullBox("mdhd", 0, 0, u32(0, 0, timescale, 0), u16(packLanguage(language), 0))
+	hdlr := makeFullBox("hdlr", 0, 0, u32(0), []byte(handlerType), make([]byte, 12), []byte{0})
+	stsd := makeFullBox("stsd", 0, 0, u32(1), sampleEntry)
+	trak := makeBox("trak", tkhd, makeBox("mdia", mdhd, hdlr, makeBox("minf", makeBox("stbl", stsd))))
+	trex := makeFullBox("trex", 0, 0, u32(1, 1, 1024, 0, 0))
+
+	return append(makeBox("ftyp", []byte("cmfc"), u32(0)), makeBox("moov", trak, makeBox("mvex", trex))...)
+}
+
+func makeVisualSampleEntry(format string, width, height uint16, children ...[]byte) []byte {
+	return makeBox(format, append([][]byte{make([]byte, 24), u16(width, height), make([]byte, 50)}, children...)...)
+}
+
+func makeAudioSampleEntry(format string, channelCount uint16, sampleRate uint32, children ...[]byte) []byte {
+	return makeBox(format, append([][]byte{make([]byte, 16), u16(channelCount, 16, 0, 0), u32(sampleRate << 16)}, children...)...)
+}
+
+func makeAACConfiguration() []byte {
+	decoderSpecificInfo := []byte{0x05, 0x02, 0x11, 0x90}
+	decoderConfig := append([]byte{0x04, byte(13 + len(decoderSpecificInfo)), 0x40, 0x15}, make([]byte, 11)...)
+	decoderConfig = append(decoderConfig, decoderSpecificInfo...)
+	es := append([]byte{0x03, byte(3 + len(decoderConfig) + 3), 0, 1, 0}, decoderConfig...)
+	es = append(es, 0x06, 0x01, 0x02)
+
+	return makeFullBox("esds", 0, 0, es)
+}
+
+// makeMediaSegment creates a media segment with one movie fragment.
+// Without sample durations, the trex default of 1024 applies to sampleCount samples.
+func makeMediaSegment(baseMediaDecodeTime uint64, sampleCount uint32, sampleDurations ...uint32) []byte {
+	tfhd := makeFullBox("tfhd", 0, 0x020000, u32(1))
+	tfdt := makeFullBox("tfdt", 1, 0, u64(baseMediaDecodeTime))
+
+	trun := makeFullBox("trun", 0, 0x200, u32(sampleCount), make([]byte, 4*sampleCount))
+	if len(sampleDurations) > 0 {
+		trun = makeFullBox("trun", 0, 0x101, u32(uint32(len(sampleDurations)), 0), u32(sampleDurations...))
+	}
+
+	moof := makeBox("moof", makeFullBox("mfhd", 0, 0, u32(1)), makeBox("traf", tfhd, tfdt, trun))
+
+	return append(moof, makeBox("mdat", make([]byte, 1000))...)
+}
+
+func writeFiles(t *testing.T, files map[string][]byte) string {
+	dir := t.TempDir()
+
+	for name, data := range files {
+		name = filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(name), 0o700); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := os.WriteFile(name, data, 0o600); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	return dir
+}
+
+func repeatDuration(duration uint32, count int) []uint32 {
+	durations := make([]uint32, count)
+	for i := range durations {
+		durations[i] = duration
+	}
+
+	return durations
+}
+
+func TestBuildFromDirectory(t *testing.T) {
+	avcC := makeBox("avcC", []byte{1, 0x64, 0, 0x1f, 0xff})
+	videoSegments := [][]byte{
+		makeMediaSegment(0, 0, repeatDuration(3000, 60)...),
+		makeMediaSegment(180000, 0, repeatDuration(3000, 60)...),
+		makeMediaSegment(360000, 0, repeatDuration(3000, 30)...),
+	}
+	audioSegments := [][]byte{
+		makeMediaSegment(0, 94),
+		makeMediaSegment(96256, 94),
+	}
+
+	dir := writeFiles(t, map[string][]byte{
+		"video/init.mp4": makeInitSegment("vide", 90000, "und", makeVisualSampleEntry("avc1", 1280, 720, avcC)),
+		"video/1.m4s":    videoSegments[0],
+		"video/2.m4s":    videoSegments[1],
+		"video/3.m4s":    videoSegments[2],
+		"audio/init.mp4": makeInitSegment("soun", 48000, "eng", makeAudioSampleEntry("mp4a", 2, 48000, makeAACConfiguration())),
+		"audio/1.m4s":    audioSegments[0],
+		"audio/2.m4s":    audioSegments[1],
+		"README.md":      []byte("ignored"),
+	})
+
+	testMPD, err := mpd.BuildFromDirectory(dir, mpd.SegmentTemplate{
+		Initialization: "$RepresentationID$/init.mp4",
+		Media:          "$RepresentationID$/$Number$.m4s",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	videoTemplate := &mpd.SegmentTemplate{
+		Initialization: "$RepresentationID$/init.mp4",
+		Media:          "$RepresentationID$/$Number$.m4s",
+	}
+	videoTemplate.Timescale = 90000
+	videoTemplate.StartNumber = 1
+	videoTemplate.SegmentTimeline = &mpd.SegmentTimeline{S: []mpd.S{{T: uint64Ptr(0), D: 180000, R: 1}, {D: 90000}}}
+
+	audioTemplate := &mpd.SegmentTemplate{
+		Initialization: "$RepresentationID$/init.mp4",
+		Media:          "$RepresentationID$/$Number$.m4s",
+	}
+	audioTemplate.Timescale = 48000
+	audioTemplate.StartNumber = 1
+	audioTemplate.SegmentTimeline = &mpd.SegmentTimeline{S: []mpd.S{{T: uint64Ptr(0), D: 96256, R: 1}}}
+
+	videoRepresentation := mpd.Representation{
+		ID:              "video",
+		Bandwidth:       uint(len(videoSegments[2]) * 8),
+		SegmentTemplate: videoTemplate,
+	}
+	videoRepresentation.Codecs = "avc1.64001F"
+	videoRepresentation.Width = 1280
+	videoRepresentation.Height = 720
+	videoRepresentation.FrameRate = "30"
+
+	audioRepresentation := mpd.Representation{
+		ID:              "audio",
+		Bandwidth:       uint((len(audioSegments[0])*8*48000 + 96255) / 96256),
+		SegmentTemplate: audioTemplate,
+	}
+	audioRepresentation.Codecs = "mp4a.40.2"
+	audioRepresentation.AudioSamplingRate = &mpd.AudioSamplingRate{48000}
+	audioRepresentation.AudioChannelConfiguration = []*mpd.Descriptor{{
+		SchemeIDURI: mpd.AudioChannelConfiguration2011SchemeIDURI,
+		Value:       "2",
+	}}
+
+	videoAdaptationSet := mpd.AdaptationSet{ID: 1, Lang: "und", ContentType: mpd.VideoContentType}
+	videoAdaptationSet.MIMEType = mpd.VideoMP4MIMEType
+	videoAdaptationSet.Representation = []mpd.Representation{videoRepresentation}
+
+	audioAdaptationSet := mpd.AdaptationSet{ID: 2, Lang: "eng", ContentType: mpd.AudioContentType}
+	audioAdaptationSet.MIMEType = mpd.AudioMP4MIMEType
+	audioAdaptationSet.Representation = []mpd.Representation{audioRepresentation}
+
+	wantMPD := mpd.New()
+	wantMPD.Profiles = mpd.Live2011Profile
+	wantMPD.Type = mpd.StaticPresentationType
+	wantMPD.MediaPresentationDuration = "PT5S"
+	wantMPD.MaxSegmentDuration = "PT2.005333333S"
+	wantMPD.MinBufferTime = "PT2.005333333S"
+	wantMPD.Period = []mpd.Period{{
+		ID:            "0",
+		AdaptationSet: []mpd.AdaptationSet{videoAdaptationSet, audioAdaptationSet},
+	}}
+
+	if diff := cmp.Diff(testMPD, wantMPD); diff != "" {
+		t.Errorf("wrong MPD: %s", diff)
+	}
+}
+
+func TestBuildFromDirectory_Codecs(t *testing.T) {
+	type TestCase struct {
+		name        string
+		handlerType string
+		sampleEntry []byte
+		wantCodecs  mpd.Codecs
+	}
+
+	hvcC := makeBox("hvcC", []byte{1, 0x01, 0x60, 0, 0, 0, 0xb0, 0, 0, 0, 0, 0, 93})
+	sinf := makeBox("sinf", makeBox("frma", []byte("avc3")))
+	avcC := makeBox("avcC", []byte{1, 0x42, 0xc0, 0x1e})
+
+	testCases := []TestCase{
+		{name: "avc3", handlerType: "vide", sampleEntry: makeVisualSampleEntry("encv", 640, 360, sinf, avcC), wantCodecs: "avc3.42C01E"},
+		{name: "hvc1", handlerType: "vide", sampleEntry: makeVisualSampleEntry("hvc1", 640, 360, hvcC), wantCodecs: "hvc1.1.6.L93.B0"},
+		{name: "av01", handlerType: "vide", sampleEntry: makeVisualSampleEntry("av01", 640, 360, makeBox("av1C", []byte{0x81, 0x04, 0x40})), wantCodecs: "av01.0.04M.10"},
+		{name: "av01 12 bit", handlerType: "vide", sampleEntry: makeVisualSampleEntry("av01", 640, 360, makeBox("av1C", []byte{0x81, 0x48, 0xe0})), wantCodecs: "av01.2.08H.12"},
+		{name: "vp09", handlerType: "vide", sampleEntry: makeVisualSampleEntry("vp09", 640, 360, makeFullBox("vpcC", 1, 0, []byte{0, 31, 0x80})), wantCodecs: "vp09.00.31.08"},
+		{name: "avc1 without configuration", handlerType: "vide", sampleEntry: makeVisualSampleEntry("avc1", 640, 360), wantCodecs: "avc1"},
+		{name: "ec-3", handlerType: "soun", sampleEntry: makeAudioSampleEntry("ec-3", 6, 48000), wantCodecs: "ec-3"},
+		{name: "opus", handlerType: "soun", sampleEntry: makeAudioSampleEntry("Opus", 2, 48000), wantCodecs: "opus"},
+		{name: "flac", handlerType: "soun", sampleEntry: makeAudioSampleEntry("fLaC", 2, 48000), wantCodecs: "flac"},
+		{name: "mp3", handlerType: "soun", sampleEntry: makeAudioSampleEntry("mp4a", 2, 48000, makeFullBox("esds", 0, 0, []byte{0x03, 0x14, 0, 1, 0x80, 0, 2, 0x04, 0x0d, 0x6b, 0x15}, make([]byte, 11))), wantCodecs: "mp4a.6b"},
+		{name: "mp4a with optional ES fields", handlerType: "soun", sampleEntry: makeAudioSampleEntry("mp4a", 2, 48000, makeFullBox("esds", 0, 0, []byte{0x03, 0x1b, 0, 1, 0x60, 2, 'a', 'b', 0, 3, 0x04, 0x11, 0x40, 0x15}, make([]byte, 11), []byte{0x05, 0x02, 0xf8, 0x20})), wantCodecs: "mp4a.40.33"},
+		{name: "mp4a without esds", handlerType: "soun", sampleEntry: makeAudioSampleEntry("mp4a", 2, 48000), wantCodecs: "mp4a"},
+		{name: "stpp", handlerType: "subt", sampleEntry: makeBox("stpp", make([]byte, 8)), wantCodecs: "stpp"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string][]byte{
+				"init.mp4": makeInitSegment(testCase.handlerType, 1000, "", testCase.sampleEntry),
+				"0.m4s":    makeMediaSegment(0, 2),
+			})
+
+			testMPD, err := mpd.BuildFromDirectory(dir, mpd.SegmentTemplate{Initialization: "init.mp4", Media: "$Time$.m4s"})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if codecs := testMPD.Period[0].AdaptationSet[0].Representation[0].Codecs; codecs != testCase.wantCodecs {
+				t.Errorf("wrong codecs: %s", codecs)
+			}
+		})
+	}
+}
+
+func TestBuildFromDirectory_FragmentVariants(t *testing.T) {
+	tkhd := makeFullBox("tkhd", 1, 3, u64(0, 0), u32(7, 0), u64(0), make([]byte, 60))
+	mdhd := makeFullBox("mdhd", 1, 0, u64(0, 0), u32(1000), u64(0), u16(0x7fff, 0))
+	hdlr := makeFullBox("hdlr", 0, 0, u32(0), []byte("text"), make([]byte, 12), []byte{0})
+	stsd := makeFullBox("stsd", 0, 0, u32(1), makeBox("wvtt", make([]byte, 8)))
+	trak := makeBox("trak", tkhd, makeBox("mdia", mdhd, hdlr, makeBox("minf", makeBox("stbl", stsd))))
+	initSegment := makeBox("moov", trak)
+
+	otherTraf := makeBox("traf", makeFullBox("tfhd", 0, 0, u32(8)))
+	traf := makeBox("traf",
+		makeFullBox("tfhd", 0, 0x0b, u32(7), u64(0), u32(1, 500)),
+		makeFullBox("tfdt", 0, 0, u32(4000)),
+		makeFullBox("trun", 0, 0xe05, u32(2, 0, 0), make([]byte, 24)),
+	)
+	secondTraf := makeBox("traf",
+		makeFullBox("tfhd", 0, 0x08, u32(7, 250)),
+		makeFullBox("tfdt", 0, 0, u32(5000)),
+		makeFullBox("trun", 0, 0, u32(4)),
+	)
+	mediaSegment := append(makeBox("moof", otherTraf, traf), makeBox("moof", secondTraf)...)
+
+	dir := writeFiles(t, map[string][]byte{"init.mp4": initSegment, "4000.m4s": mediaSegment})
+
+	testMPD, err := mpd.BuildFromDirectory(dir, mpd.SegmentTemplate{Initialization: "init.mp4", Media: "$Time$.m4s"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	adaptationSet := testMPD.Period[0].AdaptationSet[0]
+	if adaptationSet.ContentType != mpd.SubtitlesContentType || adaptationSet.MIMEType != mpd.ApplicationMP4MIMEType || adaptationSet.Lang != "" {
+		t.Errorf("wrong AdaptationSet: %+v", adaptationSet)
+	}
+
+	wantTimeline := &mpd.SegmentTimeline{S: []mpd.S{{T: uint64Ptr(4000), D: 2000}}}
+	if diff := cmp.Diff(adaptationSet.Representation[0].SegmentTemplate.SegmentTimeline, wantTimeline); diff != "" {
+		t.Errorf("wrong SegmentTimeline: %s", diff)
+	}
+
+	if codecs := adaptationSet.Representation[0].Codecs; codecs != "wvtt" {
+		t.Errorf("wrong codecs: %s", codecs)
+	}
+
+	// The Period starts at the first segment.
+	if pto := adaptationSet.Representation[0].SegmentTemplate.PresentationTimeOffset; pto != 4000 || testMPD.MediaPresentationDuration != "PT2S" {
+		t.Errorf("wrong timing: %d %s", pto, testMPD.MediaPresentationDuration)
+	}
+
+	// A track run without per-sample fields is not iterated sample by sample.
+	secondTraf = makeBox("traf",
+		makeFullBox("tfhd", 0, 0x08, u32(7, 250)),
+		makeFullBox("tfdt", 0, 0, u32(5000)),
+		makeFullBox("trun", 0, 0, u32(math.MaxUint32)),
+	)
+	mediaSegment = append(makeBox("moof", otherTraf, traf), makeBox("moof", secondTraf)...)
+	dir = writeFiles(t, map[string][]byte{"init.mp4": initSegment, "4000.m4s": mediaSegment})
+
+	if testMPD, err = mpd.BuildFromDirectory(dir, mpd.SegmentTemplate{Initialization: "init.mp4", Media: "$Time$.m4s"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if s := testMPD.Period[0].AdaptationSet[0].Representation[0].SegmentTemplate.SegmentTimeline.S; s[0].D != 1000+250*math.MaxUint32 {
+		t.Errorf("wrong S: %+v", s)
+	}
+}
+
+func TestBuildFromDirectory_Errors(t *testing.T) {
+	initSegment := makeInitSegment("soun", 48000, "", makeAudioSampleEntry("mp4a", 2, 48000))
+
+	type TestCase struct {
+		name     string
+		files    map[string][]byte
+		template mpd.SegmentTemplate
+	}
+
+	template := mpd.SegmentTemplate{Initialization: "$RepresentationID$/init.mp4", Media: "$RepresentationID$/$Number%05d$.m4s"}
+
+	testCases := []TestCase{
+		{name: "empty directory", files: map[string][]byte{}, template: template},
+		{name: "missing initialization segment", files: map[string][]byte{"a/00001.m4s": makeMediaSegment(0, 1)}, template: template},
+		{name: "missing media segments", files: map[string][]byte{"a/init.mp4": initSegment}, template: template},
+		{
+			name:     "missing $Number$ or $Time$",
+			files:    map[string][]byte{"a/init.mp4": initSegment},
+			template: mpd.SegmentTemplate{Initialization: "$RepresentationID$/init.mp4", Media: "$RepresentationID$/media.m4s"},
+		},
+		{
+			name:     "invalid initialization segment",
+			files:    map[string][]byte{"a/init.mp4": []byte("invalid"), "a/00001.m4s": makeMediaSegment(0, 1)},
+			template: template,
+		},
+		{
+			name:     "initialization segment without tracks",
+			files:    map[string][]byte{"a/init.mp4": makeBox("moov"), "a/00001.m4s": makeMediaSegment(0, 1)},
+			template: template,
+		},
+		{
+			name:     "invalid media segment",
+			files:    map[string][]byte{"a/init.mp4": initSegment, "a/00001.m4s": makeBox("moof", makeBox("traf"))},
+			template: template,
+		},
+		{
+			name:     "media segment of another track",
+			files:    map[string][]byte{"a/init.mp4": initSegment, "a/00001.m4s": makeBox("moof", makeBox("traf", makeFullBox("tfhd", 0, 0, u32(2))))},
+			template: template,
+		},
+		{
+			name: "truncated track run",
+			files: map[string][]byte{"a/init.mp4": initSegment, "a/00001.m4s": makeBox("moof", makeBox("traf",
+				makeFullBox("tfhd", 0, 0x020000, u32(1)),
+				makeFullBox("trun", 0, 0x100, u32(math.MaxUint32), make([]byte, 8)),
+			))},
+			template: template,
+		},
+		{
+			name:     "empty media segment",
+			files:    map[string][]byte{"a/init.mp4": initSegment, "a/00001.m4s": makeMediaSegment(0, 0)},
+			template: template,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dir := writeFiles(t, testCase.files)
+			if _, err := mpd.BuildFromDirectory(dir, testCase.template); !errors.Is(err, mpd.ErrBuildFromDirectory) {
+				t.Errorf("wrong error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package mpd
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrParseDuration = errors.New("cannot parse duration")
+
+var durationPattern = regexp.MustCompile(`^(-)?P(?:([0-9]+)Y)?(?:([0-9]+)M)?(?:([0-9.]+)D)?(?:T(?:([0-9.]+)H)?(?:([0-9.]+)M)?(?:([0-9.]+)S)?)?$`)
+
+// ParseDuration parses an xs:duration, e.g. "PT1M30.5S", rounded to nanoseconds.
+// Years and months are only supported if they are zero, e.g. "P0Y0M0DT0H0M10S", since their length is ambiguous.
+func ParseDuration(value string) (time.Duration, error) {
+	seconds, err := parseDurationSeconds(value)
+	if err != nil {
+		return 0, err
+	}
+
+	if durationOutOfRange(seconds) {
+		return 0, fmt.Errorf("%w: %q out of range", ErrParseDuration, value)
+	}
+
+	return roundDuration(seconds), nil
+}
+
+// durationOutOfRange reports whether seconds cannot be represented as time.Duration.
+func durationOutOfRange(seconds *big.Rat) bool {
+	return new(big.Rat).Abs(seconds).Cmp(big.NewRat(math.MaxInt64/int64(time.Second), 1)) > 0
+}
+
+// parseDurationSeconds parses an xs:duration like ParseDuration into exact seconds.
+func parseDurationSeconds(value string) (*big.Rat, error) {
+	matches := durationPattern.FindStringSubmatch(value)
+	if matches == nil || strings.HasSuffix(value, "T") || value == "P" || value == "-P" {
+		return nil, ErrParseDuration
+	}
+
+	if strings.Trim(matches[2]+matches[3], "0") != "" {
+		return nil, fmt.Errorf("%w: years and months in %q", ErrParseDuration, value)
+	}
+
+	units := []int64{int64(24 * time.Hour / time.Second), int64(time.Hour / time.Second), int64(time.Minute / time.Second), 1}
+	seconds := new(big.Rat)
+
+	for i, unit := range units {
+		if matches[i+4] == "" {
+			continue
+		}
+
+		component, ok := new(big.Rat).SetString(matches[i+4])
+		if !ok {
+			return nil, fmt.Errorf("%w: invalid number %q", ErrParseDuration, matches[i+4])
+		}
+
+		seconds.Add(seconds, component.Mul(component, big.NewRat(unit, 1)))
+	}
+
+	if matches[1] != "" {
+		seconds.Neg(seconds)
+	}
+
+	return seconds, nil
+}
+
+// FormatDuration formats a duration as xs:duration using hours, minutes and seconds, e.g. "PT1M30.5S".
+func FormatDuration(duration time.Duration) string {
+	var builder strings.Builder
+
+	if duration < 0 {
+		builder.WriteString("-")
+		duration = -duration
+	}
+
+	builder.WriteString("PT")
+
+	if hours := duration / time.Hour; hours > 0 {
+		builder.WriteString(strconv.FormatInt(int64(hours), 10) + "H")
+		duration -= hours * time.Hour
+	}
+
+	if minutes := duration / time.Minute; minutes > 0 {
+		builder.WriteString(strconv.FormatInt(int64(minutes), 10) + "M")
+		duration -= minutes * time.Minute
+	}
+
+	if duration > 0 || strings.HasSuffix(builder.String(), "PT") {
+		builder.WriteString(strconv.FormatInt(int64(duration/time.Second), 10))
+
+		if fraction := duration % time.Second; fraction > 0 {
+			builder.WriteString("." + strings.TrimRight(fmt.Sprintf("%09d", fraction), "0"))
+		}
+
+		builder.WriteString("S")
+	}
+
+	return builder.String()
+}
+
+// NormalizeDurations rewrites the durations of the MPD, its Periods and the BaseURLs of all levels in the form of
+// FormatDuration, e.g. "PT90S" as "PT1M30S". Durations that are invalid or cannot be represented exactly in
+// nanoseconds are kept.
+func (m *MPD) NormalizeDurations() {
+	normalize := func(values ...*string) {
+		for _, value := range values {
+			if *value == "" {
+				continue
+			}
+
+			seconds, err := parseDurationSeconds(*value)
+			if err != nil {
+				continue
+			}
+
+			if duration, err := ParseDuration(*value); err == nil && big.NewRat(int64(duration), int64(time.Second)).Cmp(seconds) == 0 {
+				*value = FormatDuration(duration)
+			}
+		}
+	}
+
+	normalizeBaseURLs := func(baseURLs []BaseURL) {
+		for i := range baseURLs {
+			normalize(&baseURLs[i].TimeShiftBufferDepth)
+		}
+	}
+
+	normalize(&m.MediaPresentationDuration, &m.MinimumUpdatePeriod, &m.MinBufferTime, &m.TimeShiftBufferDepth,
+		&m.SuggestedPresentationDelay, &m.MaxSegmentDuration, &m.MaxSubsegmentDuration)
+	normalizeBaseURLs(m.BaseURL)
+
+	for i := range m.Period {
+		period := &m.Period[i]
+		normalize(&period.Start, &period.Duration)
+		normalizeBaseURLs(period.BaseURL)
+
+		for j := range period.AdaptationSet {
+			adaptationSet := &period.AdaptationSet[j]
+			normalizeBaseURLs(adaptationSet.BaseURL)
+
+			for k := range adaptationSet.Representation {
+				normalizeBaseURLs(adaptationSet.Representation[k].BaseURL)
+			}
+		}
+	}
+}
+
+// scaledDuration converts a time in timescale units to a duration without overflowing for large values.
+func scaledDuration(value int64, timescale uint64) time.Duration {
+	if timescale == 0 {
+		timescale = 1
+	}
+
+	scale := int64(timescale)
+
+	return time.Duration(value/scale)*time.Second + time.Duration(value%scale)*time.Second/time.Duration(scale)
+}
+
+// rescale converts a time from one timescale to another, rounding to the nearest value.
+// The result saturates at math.MaxUint64.
+func rescale(value, from, to uint64) uint64 {
+	if from == to || from == 0 {
+		return value
+	}
+
+	hi, lo := bits.Mul64(value, to)
+	if hi >= from {
+		return math.MaxUint64
+	}
+
+	quotient, remainder := bits.Div64(hi, lo, from)
+	if remainder >= from-remainder && quotient < math.MaxUint64 {
+		quotient++
+	}
+
+	return quotient
+}
+
+// durationTicks converts a non-negative duration to timescale units, rounding down.
+func durationTicks(duration time.Duration, timescale uint64) uint64 {
+	if timescale == 0 {
+		timescale = 1
+	}
+
+	seconds := uint64(duration / time.Second)
+	fraction := uint64(duration % time.Second)
+
+	return seconds*timescale + fraction*timescale/uint64(time.Second)
+}
+
+// roundDuration converts seconds to a duration, rounding half away from zero to nanoseconds.
+func roundDuration(seconds *big.Rat) time.Duration {
+	nanoseconds := new(big.Rat).Mul(seconds, big.NewRat(int64(time.Second), 1))
+	quotient, remainder := new(big.Int).QuoRem(nanoseconds.Num(), nanoseconds.Denom(), new(big.Int))
+
+	if doubled := new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)); doubled.Cmp(nanoseconds.Denom()) >= 0 {
+		quotient.Add(quotient, big.NewInt(int64(nanoseconds.Sign())))
+	}
+
+	return time.Duration(quotient.Int64())
+}
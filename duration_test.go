@@ -0,0 +1,110 @@
+package mpd_test
+
+import (
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"go.eigsys.de/go-mpd"
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	type TestCase struct {
+		input        string
+		wantDuration time.Duration
+		wantErr      error
+	}
+
+	testCases := []TestCase{
+		{input: "PT2S", wantDuration: 2 * time.Second},
+		{input: "PT1.97S", wantDuration: 1970 * time.Millisecond},
+		{input: "PT6M16S", wantDuration: 6*time.Minute + 16*time.Second},
+		{input: "PT31.421333333S", wantDuration: 31421333333 * time.Nanosecond},
+		{input: "P1DT1H", wantDuration: 25 * time.Hour},
+		{input: "P0D", wantDuration: 0},
+		{input: "-PT5S", wantDuration: -5 * time.Second},
+		{input: "PT0.5H", wantDuration: 30 * time.Minute},
+		{input: "P0Y0M0DT0H0M10S", wantDuration: 10 * time.Second},
+		{input: "PT0.1S", wantDuration: 100 * time.Millisecond},
+		{input: "PT100000000.000000001S", wantDuration: 100000000*time.Second + time.Nanosecond},
+		{input: "PT0.0000000005S", wantDuration: time.Nanosecond},
+		{input: "P0Y1M", wantErr: mpd.ErrParseDuration},
+		{input: "PT10000000000S", wantErr: mpd.ErrParseDuration},
+		{input: "", wantErr: mpd.ErrParseDuration},
+		{input: "P", wantErr: mpd.ErrParseDuration},
+		{input: "PT", wantErr: mpd.ErrParseDuration},
+		{input: "P1Y", wantErr: mpd.ErrParseDuration},
+		{input: "PT1.2.3S", wantErr: mpd.ErrParseDuration},
+		{input: "2S", wantErr: mpd.ErrParseDuration},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.input, func(t *testing.T) {
+			duration, err := mpd.ParseDuration(testCase.input)
+			if !errors.Is(err, testCase.wantErr) {
+				t.Errorf("wrong error: %v", err)
+			}
+
+			if duration != testCase.wantDuration {
+				t.Errorf("wrong duration: %s", duration)
+			}
+		})
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	type TestCase struct {
+		input      time.Duration
+		wantOutput string
+	}
+
+	testCases := []TestCase{
+		{input: 0, wantOutput: "PT0S"},
+		{input: 2 * time.Second, wantOutput: "PT2S"},
+		{input: 65063 * time.Millisecond, wantOutput: "PT1M5.063S"},
+		{input: 2 * time.Hour, wantOutput: "PT2H"},
+		{input: -1500 * time.Millisecond, wantOutput: "-PT1.5S"},
+		{input: 100000000*time.Second + time.Nanosecond, wantOutput: "PT27777H46M40.000000001S"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.wantOutput, func(t *testing.T) {
+			if output := mpd.FormatDuration(testCase.input); output != testCase.wantOutput {
+				t.Errorf("wrong output: %s", output)
+			}
+		})
+	}
+}
+
+func TestMPD_NormalizeDurations(t *testing.T) {
+	testMPD := &mpd.MPD{
+		MediaPresentationDuration: "PT90S",
+		MinBufferTime:             "P0Y0M0DT0H0M2.000S",
+		TimeShiftBufferDepth:      "invalid",
+		MaxSegmentDuration:        "PT0.0000000001S",
+		BaseURL:                   []mpd.BaseURL{{TimeShiftBufferDepth: "PT3600S"}},
+		Period: []mpd.Period{{
+			Start:         "PT0S",
+			Duration:      "P1D",
+			AdaptationSet: []mpd.AdaptationSet{{Representation: []mpd.Representation{{BaseURL: []mpd.BaseURL{{TimeShiftBufferDepth: "PT120.5S"}}}}}},
+		}},
+	}
+
+	testMPD.NormalizeDurations()
+
+	wantMPD := &mpd.MPD{
+		MediaPresentationDuration: "PT1M30S",
+		MinBufferTime:             "PT2S",
+		TimeShiftBufferDepth:      "invalid",
+		MaxSegmentDuration:        "PT0.0000000001S",
+		BaseURL:                   []mpd.BaseURL{{TimeShiftBufferDepth: "PT1H"}},
+		Period: []mpd.Period{{
+			Start:         "PT0S",
+			Duration:      "PT24H",
+			AdaptationSet: []mpd.AdaptationSet{{Representation: []mpd.Representation{{BaseURL: []mpd.BaseURL{{TimeShiftBufferDepth: "PT2M0.5S"}}}}}},
+		}},
+	}
+	if diff := cmp.Diff(testMPD, wantMPD); diff != "" {
+		t.Errorf("wrong MPD: %s", diff)
+	}
+}
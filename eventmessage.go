@@ -0,0 +1,273 @@
+package mpd
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+var (
+	ErrParseEventMessage  = errors.New("cannot parse emsg box")
+	ErrEncodeEventMessage = errors.New("cannot encode emsg box")
+)
+
+// UnknownEventMessageDuration is the EventMessage.EventDuration of events with an unknown duration.
+const UnknownEventMessageDuration uint32 = math.MaxUint32
+
+// EventMessage is an inband event as per the emsg box of ISO/IEC 23009-1.
+type EventMessage struct {
+	Version     uint8
+	SchemeIDURI SchemeIDURI
+	Value       string
+	Timescale   uint32
+
+	// PresentationTimeDelta is relative to the earliest presentation time of the segment and only used in version 0.
+	PresentationTimeDelta uint32
+
+	// PresentationTime is on the media timeline of the track and only used in version 1.
+	PresentationTime uint64
+
+	EventDuration uint32
+	ID            uint32
+	MessageData   []byte
+}
+
+// ParseEventMessage parses a single binary emsg box.
+func ParseEventMessage(data []byte) (*EventMessage, error) {
+	b, err := readBox(data)
+	if err != nil {
+		return nil, errors.Join(ErrParseEventMessage, err)
+	}
+
+	if b.Type != "emsg" || b.Size != len(data) {
+		return nil, ErrParseEventMessage
+	}
+
+	return parseEventMessagePayload(b.Payload)
+}
+
+// ParseEventMessages parses all top-level emsg boxes of a media segment.
+func ParseEventMessages(segment []byte) ([]*EventMessage, error) {
+	boxes, err := findBoxes(segment, "emsg")
+	if err != nil {
+		return nil, errors.Join(ErrParseEventMessage, err)
+	}
+
+	messages := make([]*EventMessage, 0, len(boxes))
+
+	for _, b := range boxes {
+		message, err := parseEventMessagePayload(b.Payload)
+		if err != nil {
+			return nil, err
+		}
+
+		messages = append(messages, message)
+	}
+
+	return messages, nil
+}
+
+func parseEventMessagePayload(payload []byte) (*EventMessage, error) {
+	r := &byteReader{data: payload}
+	version, _ := r.fullBoxHeader()
+	message := &EventMessage{Version: version}
+
+	switch version {
+	case 0:
+		message.SchemeIDURI = SchemeIDURI(r.cstring())
+		message.Value = r.cstring()
+		message.Timescale = r.uint32()
+		message.PresentationTimeDelta = r.uint32()
+		message.EventDuration = r.uint32()
+		message.ID = r.uint32()
+	case 1:
+		message.Timescale = r.uint32()
+		message.PresentationTime = r.uint64()
+		message.EventDuration = r.uint32()
+		message.ID = r.uint32()
+		message.SchemeIDURI = SchemeIDURI(r.cstring())
+		message.Value = r.cstring()
+	default:
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrParseEventMessage, version)
+	}
+
+	if r.err != nil {
+		return nil, errors.Join(ErrParseEventMessage, r.err)
+	}
+
+	message.MessageData = r.data
+
+	return message, nil
+}
+
+// Bytes encodes the emsg box.
+func (e *EventMessage) Bytes() ([]byte, error) {
+	var payload []byte
+
+	switch e.Version {
+	case 0:
+		payload = append(payload, e.SchemeIDURI+"\x00"...)
+		payload = append(payload, e.Value+"\x00"...)
+		payload = binary.BigEndian.AppendUint32(payload, e.Timescale)
+		payload = binary.BigEndian.AppendUint32(payload, e.PresentationTimeDelta)
+		payload = binary.BigEndian.AppendUint32(payload, e.EventDuration)
+		payload = binary.BigEndian.AppendUint32(payload, e.ID)
+	case 1:
+		payload = binary.BigEndian.AppendUint32(payload, e.Timescale)
+		payload = binary.BigEndian.AppendUint64(payload, e.PresentationTime)
+		payload = binary.BigEndian.AppendUint32(payload, e.EventDuration)
+		payload = binary.BigEndian.AppendUint32(payload, e.ID)
+		payload = append(payload, e.SchemeIDURI+"\x00"...)
+		payload = append(payload, e.Value+"\x00"...)
+	default:
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrEncodeEventMessage, e.Version)
+	}
+
+	return appendFullBox(nil, "emsg", e.Version, 0, append(payload, e.MessageData...)), nil
+}
+
+// ConvertToVersion0 converts the message to version 0, with the presentation time relative to segmentTime,
+// the earliest presentation time of the segment carrying the message in EventMessage.Timescale units.
+func (e *EventMessage) ConvertToVersion0(segmentTime uint64) error {
+	if e.Version == 0 {
+		return nil
+	}
+
+	if e.PresentationTime < segmentTime || e.PresentationTime-segmentTime > math.MaxUint32 {
+		return fmt.Errorf("%w: presentation time %d out of range of segment time %d", ErrEncodeEventMessage, e.PresentationTime, segmentTime)
+	}
+
+	e.Version = 0
+	e.PresentationTimeDelta = uint32(e.PresentationTime - segmentTime)
+	e.PresentationTime = 0
+
+	return nil
+}
+
+// ConvertToVersion1 converts the message to version 1, with segmentTime being the earliest presentation time
+// of the segment carrying the message in EventMessage.Timescale units.
+func (e *EventMessage) ConvertToVersion1(segmentTime uint64) {
+	if e.Version == 1 {
+		return
+	}
+
+	e.PresentationTime = e.AbsolutePresentationTime(segmentTime)
+	e.PresentationTimeDelta = 0
+	e.Version = 1
+}
+
+// AbsolutePresentationTime returns the presentation time on the media timeline in EventMessage.Timescale units.
+// segmentTime is the earliest presentation time of the segment carrying the message and only used in version 0.
+func (e *EventMessage) AbsolutePresentationTime(segmentTime uint64) uint64 {
+	if e.Version == 0 {
+		return segmentTime + uint64(e.PresentationTimeDelta)
+	}
+
+	return e.PresentationTime
+}
+
+// Event converts the message to an Event of an EventStream with the timescale and no presentation time offset.
+// The presentation time is moved from presentationTimeOffset, the one of the Representation carrying the message in
+// timescale units, to the start of the Period. segmentTime is the earliest presentation time of the segment in
+// EventMessage.Timescale units and only used in version 0. The message data is stored base64 encoded.
+func (e *EventMessage) Event(timescale uint32, presentationTimeOffset, segmentTime uint64) (Event, error) {
+	presentationTime := rescale(e.AbsolutePresentationTime(segmentTime), uint64(e.Timescale), uint64(timescale))
+	if presentationTime < presentationTimeOffset {
+		return Event{}, fmt.Errorf("%w: message %d before the Period", ErrParseEventMessage, e.ID)
+	}
+
+	event := Event{
+		ID:               strconv.FormatUint(uint64(e.ID), 10),
+		PresentationTime: presentationTime - presentationTimeOffset,
+	}
+
+	if e.EventDuration != UnknownEventMessageDuration {
+		event.Duration = rescale(uint64(e.EventDuration), uint64(e.Timescale), uint64(timescale))
+	}
+
+	if len(e.MessageData) > 0 {
+		event.ContentEncoding = Base64ContentEncoding
+		event.Value = base64.StdEncoding.EncodeToString(e.MessageData)
+	}
+
+	return event, nil
+}
+
+// InbandEventStream returns the InbandEventStream descriptor signalling the message.
+func (e *EventMessage) InbandEventStream() EventStream {
+	return EventStream{SchemeIdURI: e.SchemeIDURI, Value: e.Value}
+}
+
+// AddInbandEventStream adds the InbandEventStream descriptor signalling the message,
+// unless there is one with the same scheme and value. It reports whether the descriptor was added.
+func (r *RepresentationBase) AddInbandEventStream(message *EventMessage) bool {
+	for _, inbandEventStream := range r.InbandEventStream {
+		if inbandEventStream.SchemeIdURI == message.SchemeIDURI && inbandEventStream.Value == message.Value {
+			return false
+		}
+	}
+
+	r.InbandEventStream = append(r.InbandEventStream, message.InbandEventStream())
+
+	return true
+}
+
+// EventMessages converts the events to version 1 messages. Event.ID must be an unsigned 32 bit integer,
+// events without duration get UnknownEventMessageDuration, and base64 content is decoded.
+// The presentation times are moved from the PresentationTimeOffset of the EventStream to presentationTimeOffset,
+// the one of the Representation carrying the messages in EventStream.Timescale units.
+func (s *EventStream) EventMessages(presentationTimeOffset uint64) ([]*EventMessage, error) {
+	timescale := uint32(s.Timescale)
+	if timescale == 0 {
+		timescale = 1
+	}
+
+	messages := make([]*EventMessage, 0, len(s.Event))
+
+	for i := range s.Event {
+		event := &s.Event[i]
+
+		id, err := strconv.ParseUint(event.ID, 10, 32)
+		if err != nil {
+			return nil, errors.Join(ErrEncodeEventMessage, err)
+		}
+
+		if event.PresentationTime+presentationTimeOffset < uint64(s.PresentationTimeOffset) {
+			return nil, fmt.Errorf("%w: event %s before the Period", ErrEncodeEventMessage, event.ID)
+		}
+
+		message := &EventMessage{
+			Version:          1,
+			SchemeIDURI:      s.SchemeIdURI,
+			Value:            s.Value,
+			Timescale:        timescale,
+			PresentationTime: event.PresentationTime + presentationTimeOffset - uint64(s.PresentationTimeOffset),
+			EventDuration:    UnknownEventMessageDuration,
+			ID:               uint32(id),
+			MessageData:      []byte(event.Value),
+		}
+
+		if event.Duration > 0 {
+			if event.Duration >= uint64(UnknownEventMessageDuration) {
+				return nil, fmt.Errorf("%w: duration of event %s too long", ErrEncodeEventMessage, event.ID)
+			}
+
+			message.EventDuration = uint32(event.Duration)
+		}
+
+		if event.ContentEncoding == Base64ContentEncoding {
+			if message.MessageData, err = base64.StdEncoding.DecodeString(event.Value); err != nil {
+				return nil, errors.Join(ErrEncodeEventMessage, err)
+			}
+		} else if event.Value == "" {
+			message.MessageData = []byte(event.MessageData)
+		}
+
+		messages = append(messages, message)
+	}
+
+	return messages, nil
+}
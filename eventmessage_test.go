@@ -0,0 +1,262 @@
+package mpd_test
+
+import (
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"go.eigsys.de/go-mpd"
+	"testing"
+)
+
+func TestParseEventMessage(t *testing.T) {
+	version0 := makeFullBox("emsg", 0, 0, []byte("urn:scte:scte35:2013:bin\x00\x00"), u32(90000, 9000, 0xffffffff, 7), []byte{1, 2})
+	version1 := makeFullBox("emsg", 1, 0, u32(1000), u64(5100), u32(2000, 8), []byte("https://aomedia.org/emsg/ID3\x000\x00"), []byte("ID3"))
+
+	type TestCase struct {
+		name        string
+		data        []byte
+		wantMessage *mpd.EventMessage
+	}
+
+	testCases := []TestCase{
+		{
+			name: "version 0",
+			data: version0,
+			wantMessage: &mpd.EventMessage{
+				SchemeIDURI:           mpd.SCTE35BinSchemeIDURI,
+				Timescale:             90000,
+				PresentationTimeDelta: 9000,
+				EventDuration:         mpd.UnknownEventMessageDuration,
+				ID:                    7,
+				MessageData:           []byte{1, 2},
+			},
+		},
+		{
+			name: "version 1",
+			data: version1,
+			wantMessage: &mpd.EventMessage{
+				Version:          1,
+				SchemeIDURI:      "https://aomedia.org/emsg/ID3",
+				Value:            "0",
+				Timescale:        1000,
+				PresentationTime: 5100,
+				EventDuration:    2000,
+				ID:               8,
+				MessageData:      []byte("ID3"),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			message, err := mpd.ParseEventMessage(testCase.data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(message, testCase.wantMessage); diff != "" {
+				t.Errorf("wrong message: %s", diff)
+			}
+
+			data, err := message.Bytes()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(data, testCase.data); diff != "" {
+				t.Errorf("wrong encoding: %s", diff)
+			}
+		})
+	}
+
+	segment := append(append(makeBox("styp", []byte("msdh")), version0...), version1...)
+	segment = append(segment, makeMediaSegment(0, 1, 1024)...)
+
+	messages, err := mpd.ParseEventMessages(segment)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(messages, []*mpd.EventMessage{testCases[0].wantMessage, testCases[1].wantMessage}); diff != "" {
+		t.Errorf("wrong messages: %s", diff)
+	}
+}
+
+func TestParseEventMessage_Errors(t *testing.T) {
+	testCases := map[string][]byte{
+		"empty":          {},
+		"other box":      makeBox("free"),
+		"trailing data":  append(makeFullBox("emsg", 1, 0, make([]byte, 20), []byte{0, 0}), 0),
+		"version 2":      makeFullBox("emsg", 2, 0),
+		"missing string": makeFullBox("emsg", 0, 0, []byte("urn:example")),
+		"truncated":      makeFullBox("emsg", 1, 0, make([]byte, 10)),
+	}
+
+	for name, data := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := mpd.ParseEventMessage(data); !errors.Is(err, mpd.ErrParseEventMessage) {
+				t.Errorf("wrong error: %v", err)
+			}
+		})
+	}
+
+	if _, err := mpd.ParseEventMessages(makeBox("emsg")); !errors.Is(err, mpd.ErrParseEventMessage) {
+		t.Errorf("wrong error: %v", err)
+	}
+
+	if _, err := mpd.ParseEventMessages([]byte{0, 0}); !errors.Is(err, mpd.ErrParseEventMessage) {
+		t.Errorf("wrong error: %v", err)
+	}
+
+	if _, err := (&mpd.EventMessage{Version: 2}).Bytes(); !errors.Is(err, mpd.ErrEncodeEventMessage) {
+		t.Errorf("wrong error: %v", err)
+	}
+}
+
+func TestEventMessage_ConvertVersion(t *testing.T) {
+	message := &mpd.EventMessage{Version: 1, Timescale: 1000, PresentationTime: 5100}
+
+	if err := message.ConvertToVersion0(6000); !errors.Is(err, mpd.ErrEncodeEventMessage) {
+		t.Errorf("wrong error: %v", err)
+	}
+
+	if err := message.ConvertToVersion0(5000); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(message, &mpd.EventMessage{Timescale: 1000, PresentationTimeDelta: 100}); diff != "" {
+		t.Errorf("wrong message: %s", diff)
+	}
+
+	if err := message.ConvertToVersion0(0); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	message.ConvertToVersion1(6000)
+	message.ConvertToVersion1(0)
+
+	if diff := cmp.Diff(message, &mpd.EventMessage{Version: 1, Timescale: 1000, PresentationTime: 6100}); diff != "" {
+		t.Errorf("wrong message: %s", diff)
+	}
+}
+
+func TestEventMessage_Event(t *testing.T) {
+	message := &mpd.EventMessage{
+		SchemeIDURI:           mpd.SCTE35BinSchemeIDURI,
+		Timescale:             90000,
+		PresentationTimeDelta: 45000,
+		EventDuration:         mpd.UnknownEventMessageDuration,
+		ID:                    7,
+		MessageData:           []byte{1, 2},
+	}
+
+	event, err := message.Event(1000, 0, 900000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantEvent := mpd.Event{ID: "7", PresentationTime: 10500, ContentEncoding: mpd.Base64ContentEncoding, Value: "AQI="}
+	if diff := cmp.Diff(event, wantEvent); diff != "" {
+		t.Errorf("wrong event: %s", diff)
+	}
+
+	message.EventDuration = 270000
+	message.MessageData = nil
+
+	if event, err := message.Event(1000, 0, 0); err != nil || event.Duration != 3000 || event.PresentationTime != 500 || event.Value != "" {
+		t.Errorf("unexpected result: %+v %v", event, err)
+	}
+
+	// The presentation time offset of the Representation is in units of the EventStream timescale.
+	if event, err := message.Event(1000, 200, 900000); err != nil || event.PresentationTime != 10300 {
+		t.Errorf("unexpected result: %+v %v", event, err)
+	}
+
+	if event, err := message.Event(1000, 501, 0); !errors.Is(err, mpd.ErrParseEventMessage) {
+		t.Errorf("unexpected result: %+v %v", event, err)
+	}
+
+	var rescaled []uint64
+	for _, message := range []*mpd.EventMessage{
+		{Version: 1, Timescale: 1, PresentationTime: 1 << 62},
+		{Version: 1, Timescale: 1000, PresentationTime: 1 << 62},
+		{Version: 1, PresentationTime: 1 << 62},
+		{Version: 1, Timescale: 3, PresentationTime: 1},
+		{Version: 1, Timescale: 3, PresentationTime: 1<<64 - 1},
+	} {
+		timescale := uint32(1000)
+		if message.Timescale == 3 {
+			timescale = 2
+		}
+
+		event, err := message.Event(timescale, 0, 0)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		rescaled = append(rescaled, event.PresentationTime)
+	}
+
+	if diff := cmp.Diff(rescaled, []uint64{1<<64 - 1, 1 << 62, 1 << 62, 1, 12297829382473034410}); diff != "" {
+		t.Errorf("wrong rescaled presentation times: %s", diff)
+	}
+
+	var representation mpd.Representation
+	if !representation.AddInbandEventStream(message) || representation.AddInbandEventStream(message) {
+		t.Errorf("wrong result of AddInbandEventStream")
+	}
+
+	if diff := cmp.Diff(representation.InbandEventStream, []mpd.EventStream{{SchemeIdURI: mpd.SCTE35BinSchemeIDURI}}); diff != "" {
+		t.Errorf("wrong InbandEventStream: %s", diff)
+	}
+}
+
+func TestEventStream_EventMessages(t *testing.T) {
+	eventStream := &mpd.EventStream{
+		SchemeIdURI:            "urn:example",
+		Value:                  "1",
+		PresentationTimeOffset: 5,
+		Event: []mpd.Event{
+			{ID: "1", PresentationTime: 10, Duration: 5, Value: "text"},
+			{ID: "2", PresentationTime: 20, ContentEncoding: mpd.Base64ContentEncoding, Value: "AQI="},
+			{ID: "3", PresentationTime: 30, MessageData: "data"},
+		},
+	}
+
+	messages, err := eventStream.EventMessages(100)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantMessages := []*mpd.EventMessage{
+		{Version: 1, SchemeIDURI: "urn:example", Value: "1", Timescale: 1, PresentationTime: 105, EventDuration: 5, ID: 1, MessageData: []byte("text")},
+		{Version: 1, SchemeIDURI: "urn:example", Value: "1", Timescale: 1, PresentationTime: 115, EventDuration: mpd.UnknownEventMessageDuration, ID: 2, MessageData: []byte{1, 2}},
+		{Version: 1, SchemeIDURI: "urn:example", Value: "1", Timescale: 1, PresentationTime: 125, EventDuration: mpd.UnknownEventMessageDuration, ID: 3, MessageData: []byte("data")},
+	}
+	if diff := cmp.Diff(messages, wantMessages); diff != "" {
+		t.Errorf("wrong messages: %s", diff)
+	}
+
+	// Converting the messages back results in events relative to the start of the Period.
+	for i := range messages {
+		event, err := messages[i].Event(1, 100, 0)
+		if err != nil || event.PresentationTime != eventStream.Event[i].PresentationTime-uint64(eventStream.PresentationTimeOffset) {
+			t.Errorf("unexpected result: %+v %v", event, err)
+		}
+	}
+
+	testCases := map[string]mpd.Event{
+		"ID":            {ID: "a"},
+		"duration":      {ID: "1", PresentationTime: 2, Duration: 1 << 32},
+		"base64":        {ID: "1", PresentationTime: 2, ContentEncoding: mpd.Base64ContentEncoding, Value: "%"},
+		"before Period": {ID: "1", PresentationTime: 1},
+	}
+
+	for name, event := range testCases {
+		t.Run(name, func(t *testing.T) {
+			eventStream := &mpd.EventStream{Timescale: 1000, PresentationTimeOffset: 2, Event: []mpd.Event{event}}
+			if _, err := eventStream.EventMessages(0); !errors.Is(err, mpd.ErrEncodeEventMessage) {
+				t.Errorf("wrong error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package mpd
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// TimedEvent is an Event with its timing resolved on the media presentation timeline.
+type TimedEvent struct {
+	Period      *Period
+	EventStream *EventStream
+	Event       *Event
+
+	// PresentationTime is the start of the Event relative to the start of the media presentation.
+	PresentationTime time.Duration
+
+	// Duration is 0 if the Event has no duration.
+	Duration time.Duration
+
+	// WallClockTime is only set in dynamic MPDs.
+	WallClockTime time.Time
+}
+
+// EventFilter selects events. Empty fields match all events.
+type EventFilter struct {
+	SchemeIDURI SchemeIDURI
+	Value       string
+
+	// Start and End select events that overlap the time window, relative to the start of the media presentation.
+	// An End of 0 leaves the window open.
+	Start time.Duration
+	End   time.Duration
+}
+
+// Events returns the events of all EventStreams of all Periods that match the filter, ordered by presentation time.
+// A nil filter matches all events.
+func (m *MPD) Events(filter *EventFilter) ([]TimedEvent, error) {
+	var availabilityStartTime time.Time
+
+	if m.Type == DynamicPresentationType {
+		var err error
+		if availabilityStartTime, err = ParseDateTime(m.AvailabilityStartTime); err != nil {
+			return nil, errors.Join(ErrPeriodTiming, err)
+		}
+	}
+
+	var events []TimedEvent
+
+	for i := range m.Period {
+		period := &m.Period[i]
+		if len(period.EventStream) == 0 {
+			continue
+		}
+
+		periodStart, err := m.PeriodStart(i)
+		if err != nil {
+			return nil, err
+		}
+
+		for j := range period.EventStream {
+			eventStream := &period.EventStream[j]
+			if filter != nil && !filter.matchesStream(eventStream) {
+				continue
+			}
+
+			timescale := uint64(eventStream.Timescale)
+
+			for k := range eventStream.Event {
+				event := &eventStream.Event[k]
+				offset := int64(event.PresentationTime) - int64(eventStream.PresentationTimeOffset)
+				timedEvent := TimedEvent{
+					Period:           period,
+					EventStream:      eventStream,
+					Event:            event,
+					PresentationTime: periodStart + scaledDuration(offset, timescale),
+					Duration:         scaledDuration(int64(event.Duration), timescale),
+				}
+
+				if filter != nil && !filter.matchesTime(&timedEvent) {
+					continue
+				}
+
+				if !availabilityStartTime.IsZero() {
+					timedEvent.WallClockTime = availabilityStartTime.Add(timedEvent.PresentationTime)
+				}
+
+				events = append(events, timedEvent)
+			}
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].PresentationTime < events[j].PresentationTime
+	})
+
+	return events, nil
+}
+
+func (f *EventFilter) matchesStream(eventStream *EventStream) bool {
+	return (f.SchemeIDURI == "" || f.SchemeIDURI == eventStream.SchemeIdURI) &&
+		(f.Value == "" || f.Value == eventStream.Value)
+}
+
+func (f *EventFilter) matchesTime(event *TimedEvent) bool {
+	if f.End != 0 && event.PresentationTime >= f.End {
+		return false
+	}
+
+	if event.Duration == 0 {
+		return event.PresentationTime >= f.Start
+	}
+
+	return event.PresentationTime+event.Duration > f.Start
+}
+
+// EventTracker de-duplicates events across MPD refreshes. Events are the same if scheme, value and
+// Event.ID match. Events without an ID are the same if their presentation time and message match as well.
+// The zero value is ready to use.
+type EventTracker struct {
+	seen map[eventKey]time.Duration
+}
+
+type eventKey struct {
+	schemeIDURI      SchemeIDURI
+	value            string
+	id               string
+	presentationTime time.Duration
+	messageData      string
+	message          string
+}
+
+// Add returns the events that were not added before, in their original order.
+func (t *EventTracker) Add(events []TimedEvent) []TimedEvent {
+	if t.seen == nil {
+		t.seen = map[eventKey]time.Duration{}
+	}
+
+	var added []TimedEvent
+
+	for _, event := range events {
+		key := eventKey{schemeIDURI: event.EventStream.SchemeIdURI, value: event.EventStream.Value, id: event.Event.ID}
+		if key.id == "" {
+			key.presentationTime = event.PresentationTime
+			key.messageData = event.Event.MessageData
+			key.message = event.Event.Value
+		}
+
+		if _, ok := t.seen[key]; ok {
+			continue
+		}
+
+		t.seen[key] = event.PresentationTime + event.Duration
+		added = append(added, event)
+	}
+
+	return added
+}
+
+// Prune forgets events that ended before the presentation time, e.g. the start of the time shift buffer.
+func (t *EventTracker) Prune(before time.Duration) {
+	for key, end := range t.seen {
+		if end < before {
+			delete(t.seen, key)
+		}
+	}
+}
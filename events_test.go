@@ -0,0 +1,138 @@
+package mpd_test
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"go.eigsys.de/go-mpd"
+	"testing"
+	"time"
+)
+
+func eventIDs(events []mpd.TimedEvent) []string {
+	ids := make([]string, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.Event.ID)
+	}
+
+	return ids
+}
+
+func TestMPD_Events_Dynamic(t *testing.T) {
+	testMPD, err := mpd.Read(mustOpenFixture("zencoder/events.mpd"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	events, err := testMPD.Events(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("wrong number of events: %d", len(events))
+	}
+
+	if events[1].PresentationTime != 20*time.Second || events[1].Duration != 5*time.Second {
+		t.Errorf("wrong timing: %s, %s", events[1].PresentationTime, events[1].Duration)
+	}
+
+	if wallClockTime := events[1].WallClockTime; !wallClockTime.Equal(time.Unix(20, 0)) {
+		t.Errorf("wrong wall-clock time: %s", wallClockTime)
+	}
+
+	testMPD.AvailabilityStartTime = "invalid"
+	if _, err := testMPD.Events(nil); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestMPD_Events(t *testing.T) {
+	testMPD := &mpd.MPD{Period: []mpd.Period{
+		{
+			Duration: "PT30S",
+			EventStream: []mpd.EventStream{
+				{SchemeIdURI: "urn:example:a", Value: "1", Timescale: 1000, PresentationTimeOffset: 5000, Event: []mpd.Event{
+					{ID: "a1", PresentationTime: 25000, Duration: 1000},
+					{ID: "a2", PresentationTime: 5000},
+				}},
+				{SchemeIdURI: "urn:example:b", Event: []mpd.Event{{ID: "b1", PresentationTime: 12}}},
+			},
+		},
+		{
+			EventStream: []mpd.EventStream{
+				{SchemeIdURI: "urn:example:a", Value: "2", Timescale: 90000, Event: []mpd.Event{
+					{ID: "a3", PresentationTime: 90000, Duration: 900000},
+				}},
+			},
+		},
+	}}
+
+	type TestCase struct {
+		name    string
+		filter  *mpd.EventFilter
+		wantIDs []string
+	}
+
+	testCases := []TestCase{
+		{name: "all", wantIDs: []string{"a2", "b1", "a1", "a3"}},
+		{name: "scheme", filter: &mpd.EventFilter{SchemeIDURI: "urn:example:a"}, wantIDs: []string{"a2", "a1", "a3"}},
+		{name: "value", filter: &mpd.EventFilter{SchemeIDURI: "urn:example:a", Value: "2"}, wantIDs: []string{"a3"}},
+		{name: "window", filter: &mpd.EventFilter{Start: 12 * time.Second, End: 31 * time.Second}, wantIDs: []string{"b1", "a1"}},
+		{name: "overlap", filter: &mpd.EventFilter{Start: 35 * time.Second}, wantIDs: []string{"a3"}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			events, err := testMPD.Events(testCase.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(eventIDs(events), testCase.wantIDs); diff != "" {
+				t.Errorf("wrong events: %s", diff)
+			}
+		})
+	}
+
+	events, _ := testMPD.Events(nil)
+	if events[3].PresentationTime != 31*time.Second || events[3].Duration != 10*time.Second || !events[3].WallClockTime.IsZero() {
+		t.Errorf("wrong timing: %+v", events[3])
+	}
+
+	testMPD.Period[0].Duration = ""
+	if _, err := testMPD.Events(nil); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestEventTracker(t *testing.T) {
+	stream := &mpd.EventStream{SchemeIdURI: "urn:example:a"}
+	newEvent := func(id string, presentationTime time.Duration, messageData string) mpd.TimedEvent {
+		return mpd.TimedEvent{
+			EventStream:      stream,
+			Event:            &mpd.Event{ID: id, MessageData: messageData},
+			PresentationTime: presentationTime,
+			Duration:         time.Second,
+		}
+	}
+
+	var tracker mpd.EventTracker
+
+	added := tracker.Add([]mpd.TimedEvent{newEvent("1", 0, ""), newEvent("", time.Second, "x"), newEvent("", time.Second, "y")})
+	if len(added) != 3 {
+		t.Errorf("wrong number of events: %d", len(added))
+	}
+
+	refreshed := []mpd.TimedEvent{newEvent("1", 5*time.Second, ""), newEvent("", time.Second, "x"), newEvent("2", 3*time.Second, "")}
+
+	added = tracker.Add(refreshed)
+	if diff := cmp.Diff(eventIDs(added), []string{"2"}); diff != "" {
+		t.Errorf("wrong events: %s", diff)
+	}
+
+	tracker.Prune(1500 * time.Millisecond)
+
+	added = tracker.Add(refreshed)
+	if diff := cmp.Diff(eventIDs(added), []string{"1"}); diff != "" {
+		t.Errorf("wrong events: %s", diff)
+	}
+}
@@ -0,0 +1,370 @@
+package mpd
+
+import (
+	"strconv"
+	"strings"
+)
+
+// RepresentationPredicate reports whether a Representation of an AdaptationSet in a Period is kept by MPD.Filter.
+type RepresentationPredicate func(period *Period, adaptationSet *AdaptationSet, representation *Representation) bool
+
+// AllOf keeps Representations kept by all predicates.
+func AllOf(predicates ...RepresentationPredicate) RepresentationPredicate {
+	return func(period *Period, adaptationSet *AdaptationSet, representation *Representation) bool {
+		for _, predicate := range predicates {
+			if !predicate(period, adaptationSet, representation) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// AnyOf keeps Representations kept by at least one of the predicates.
+func AnyOf(predicates ...RepresentationPredicate) RepresentationPredicate {
+	return func(period *Period, adaptationSet *AdaptationSet, representation *Representation) bool {
+		for _, predicate := range predicates {
+			if predicate(period, adaptationSet, representation) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// Not keeps Representations removed by the predicate.
+func Not(predicate RepresentationPredicate) RepresentationPredicate {
+	return func(period *Period, adaptationSet *AdaptationSet, representation *Representation) bool {
+		return !predicate(period, adaptationSet, representation)
+	}
+}
+
+// MaxResolution keeps Representations that fit into the resolution. Dimensions declared on neither the
+// Representation nor the AdaptationSet fit.
+func MaxResolution(width, height uint) RepresentationPredicate {
+	return func(_ *Period, adaptationSet *AdaptationSet, representation *Representation) bool {
+		return inheritedUint(representation.Width, adaptationSet.Width) <= width &&
+			inheritedUint(representation.Height, adaptationSet.Height) <= height
+	}
+}
+
+// MaxBandwidth keeps Representations with a bandwidth up to the given bits per second.
+func MaxBandwidth(bandwidth uint) RepresentationPredicate {
+	return func(_ *Period, _ *AdaptationSet, representation *Representation) bool {
+		return representation.Bandwidth <= bandwidth
+	}
+}
+
+// CodecPrefixes keeps Representations whose codecs all start with one of the prefixes, e.g. "avc1", "hvc1.2" or
+// "mp4a.40". Representations without codecs are kept.
+func CodecPrefixes(prefixes ...string) RepresentationPredicate {
+	return func(_ *Period, adaptationSet *AdaptationSet, representation *Representation) bool {
+		for _, codec := range strings.Split(string(inheritedCodecs(adaptationSet, representation)), ",") {
+			if codec = strings.TrimSpace(codec); codec != "" && !hasAnyPrefix(codec, prefixes) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// StandardDynamicRange keeps Representations that are not signalled as HDR, either by a PQ or HLG
+// transfer characteristic descriptor or by a Dolby Vision codec.
+func StandardDynamicRange() RepresentationPredicate {
+	return func(_ *Period, adaptationSet *AdaptationSet, representation *Representation) bool {
+		for _, descriptors := range [][]Descriptor{
+			adaptationSet.EssentialProperty, adaptationSet.SupplementalProperty,
+			representation.EssentialProperty, representation.SupplementalProperty,
+		} {
+			for _, descriptor := range descriptors {
+				if descriptor.SchemeIDURI == CICPTransferCharacteristicsSchemeIDURI && (descriptor.Value == "16" || descriptor.Value == "18") {
+					return false
+				}
+			}
+		}
+
+		for _, codec := range strings.Split(string(inheritedCodecs(adaptationSet, representation)), ",") {
+			if hasAnyPrefix(strings.TrimSpace(codec), []string{"dvh1", "dvhe", "dva1", "dvav", "dav1"}) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// Languages keeps Representations of AdaptationSets without a language or with one of the languages.
+// Languages match case-insensitively and by prefix of BCP 47 subtags, so that "en" matches "en-US".
+func Languages(languages ...string) RepresentationPredicate {
+	return func(_ *Period, adaptationSet *AdaptationSet, _ *Representation) bool {
+		if adaptationSet.Lang == "" {
+			return true
+		}
+
+		for _, language := range languages {
+			if strings.EqualFold(adaptationSet.Lang, language) ||
+				len(adaptationSet.Lang) > len(language) && adaptationSet.Lang[len(language)] == '-' && strings.EqualFold(adaptationSet.Lang[:len(language)], language) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// DRMSystems keeps unprotected Representations and protected Representations with a ContentProtection of one of
+// the DRM systems, taking references and AdaptationSet elements into account. Representations whose
+// ContentProtection references do not resolve are removed.
+func (m *MPD) DRMSystems(systemIDs ...UUID) RepresentationPredicate {
+	return func(period *Period, adaptationSet *AdaptationSet, representation *Representation) bool {
+		contentProtection, err := m.EffectiveContentProtection(period, adaptationSet, representation)
+		if err != nil {
+			return false
+		}
+
+		if len(contentProtection) == 0 {
+			return true
+		}
+
+		for _, element := range contentProtection {
+			systemID, err := element.SchemeIDURI.UUID()
+			if err != nil {
+				continue
+			}
+
+			for _, supported := range systemIDs {
+				if systemID == supported {
+					return true
+				}
+			}
+		}
+
+		return false
+	}
+}
+
+// Filter removes the Representations of all Periods not kept by all predicates, together with Representations
+// that depend on removed ones. It then recomputes the present minimum and maximum bandwidth, resolution and frame
+// rate attributes of the affected AdaptationSets, removes AdaptationSets left without Representations, and removes
+// references to removed AdaptationSets and Representations from Subsets, Preselections and associations.
+// Subsets and Preselections whose main component is removed are removed as well.
+func (m *MPD) Filter(predicates ...RepresentationPredicate) {
+	keep := AllOf(predicates...)
+
+	for i := range m.Period {
+		m.Period[i].filter(keep)
+	}
+}
+
+func (p *Period) filter(keep RepresentationPredicate) {
+	removedRepresentations := map[string]bool{}
+
+	for i := range p.AdaptationSet {
+		adaptationSet := &p.AdaptationSet[i]
+
+		for j := range adaptationSet.Representation {
+			if representation := &adaptationSet.Representation[j]; !keep(p, adaptationSet, representation) {
+				removedRepresentations[representation.ID] = true
+			}
+		}
+	}
+
+	if len(removedRepresentations) == 0 {
+		return
+	}
+
+	for dependent := true; dependent; {
+		dependent = false
+
+		for i := range p.AdaptationSet {
+			for _, representation := range p.AdaptationSet[i].Representation {
+				if !removedRepresentations[representation.ID] && containsAny(representation.DependencyId, removedRepresentations) {
+					removedRepresentations[representation.ID] = true
+					dependent = true
+				}
+			}
+		}
+	}
+
+	removedAdaptationSets := map[string]bool{}
+	adaptationSets := p.AdaptationSet[:0]
+
+	for _, adaptationSet := range p.AdaptationSet {
+		count := len(adaptationSet.Representation)
+		representations := adaptationSet.Representation[:0]
+
+		for _, representation := range adaptationSet.Representation {
+			if !removedRepresentations[representation.ID] {
+				representation.AssociationId = removeAll(representation.AssociationId, removedRepresentations)
+				representations = append(representations, representation)
+			}
+		}
+
+		adaptationSet.Representation = representations
+
+		switch {
+		case len(representations) == 0 && count > 0:
+			if adaptationSet.ID != 0 {
+				removedAdaptationSets[strconv.FormatUint(uint64(adaptationSet.ID), 10)] = true
+			}
+
+			continue
+		case len(representations) < count:
+			adaptationSet.updateRanges()
+		}
+
+		adaptationSets = append(adaptationSets, adaptationSet)
+	}
+
+	p.AdaptationSet = adaptationSets
+
+	subsets := p.Subset[:0]
+
+	for _, subset := range p.Subset {
+		contains := subset.Contains[:0]
+
+		for _, id := range subset.Contains {
+			if !removedAdaptationSets[strconv.FormatUint(uint64(id), 10)] {
+				contains = append(contains, id)
+			}
+		}
+
+		if subset.Contains = contains; len(contains) > 0 {
+			subsets = append(subsets, subset)
+		}
+	}
+
+	p.Subset = subsets
+
+	preselections := p.Preselection[:0]
+
+	for _, preselection := range p.Preselection {
+		if len(preselection.PreselectionComponents) > 0 && removedAdaptationSets[preselection.PreselectionComponents[0]] {
+			continue
+		}
+
+		if preselection.PreselectionComponents = removeAll(preselection.PreselectionComponents, removedAdaptationSets); len(preselection.PreselectionComponents) > 0 {
+			preselections = append(preselections, preselection)
+		}
+	}
+
+	p.Preselection = preselections
+}
+
+// updateRanges recomputes the minimum and maximum attributes of the AdaptationSet that are present.
+func (a *AdaptationSet) updateRanges() {
+	var (
+		minBandwidth, maxBandwidth, minWidth, maxWidth, minHeight, maxHeight uint
+		minFrameRate, maxFrameRate                                           FrameRate
+	)
+
+	for i := range a.Representation {
+		representation := &a.Representation[i]
+
+		minBandwidth = minUint(minBandwidth, representation.Bandwidth)
+		maxBandwidth = maxUint(maxBandwidth, representation.Bandwidth)
+		minWidth = minUint(minWidth, representation.Width)
+		maxWidth = maxUint(maxWidth, representation.Width)
+		minHeight = minUint(minHeight, representation.Height)
+		maxHeight = maxUint(maxHeight, representation.Height)
+
+		if frameRate := frameRateValue(representation.FrameRate); frameRate > 0 {
+			if minFrameRate == "" || frameRate < frameRateValue(minFrameRate) {
+				minFrameRate = representation.FrameRate
+			}
+
+			if frameRate > frameRateValue(maxFrameRate) {
+				maxFrameRate = representation.FrameRate
+			}
+		}
+	}
+
+	updateIfPresent(&a.MinBandwidth, minBandwidth)
+	updateIfPresent(&a.MaxBandwidth, maxBandwidth)
+	updateIfPresent(&a.MinWidth, minWidth)
+	updateIfPresent(&a.MaxWidth, maxWidth)
+	updateIfPresent(&a.MinHeight, minHeight)
+	updateIfPresent(&a.MaxHeight, maxHeight)
+	updateIfPresent(&a.MinFrameRate, minFrameRate)
+	updateIfPresent(&a.MaxFrameRate, maxFrameRate)
+}
+
+// updateIfPresent replaces a present attribute with a known value.
+func updateIfPresent[T comparable](attribute *T, value T) {
+	var zero T
+	if *attribute != zero && value != zero {
+		*attribute = value
+	}
+}
+
+// minUint returns the minimum of the non-zero values, or 0 if both are zero.
+func minUint(a, b uint) uint {
+	if a == 0 || b != 0 && b < a {
+		return b
+	}
+
+	return a
+}
+
+func maxUint(a, b uint) uint {
+	if b > a {
+		return b
+	}
+
+	return a
+}
+
+func inheritedUint(value, inherited uint) uint {
+	if value == 0 {
+		return inherited
+	}
+
+	return value
+}
+
+func inheritedCodecs(adaptationSet *AdaptationSet, representation *Representation) Codecs {
+	if representation.Codecs == "" {
+		return adaptationSet.Codecs
+	}
+
+	return representation.Codecs
+}
+
+func hasAnyPrefix(value string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsAny(values []string, set map[string]bool) bool {
+	for _, value := range values {
+		if set[value] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func removeAll(values []string, set map[string]bool) []string {
+	kept := values[:0]
+
+	for _, value := range values {
+		if !set[value] {
+			kept = append(kept, value)
+		}
+	}
+
+	if len(kept) == 0 {
+		return nil
+	}
+
+	return kept
+}
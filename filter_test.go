@@ -0,0 +1,186 @@
+package mpd_test
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"go.eigsys.de/go-mpd"
+	"testing"
+)
+
+func newFilterMPD() *mpd.MPD {
+	widevine := mpd.NewWidevineContentProtection(nil)
+	widevine.RefID = "widevine"
+
+	playReady := mpd.NewPlayReadyContentProtection(nil)
+
+	return &mpd.MPD{
+		ContentProtection: []mpd.ContentProtection{widevine},
+		Period: []mpd.Period{{
+			ID: "0",
+			AdaptationSet: []mpd.AdaptationSet{
+				{
+					ID:           1,
+					ContentType:  mpd.VideoContentType,
+					MinBandwidth: 800000,
+					MaxBandwidth: 20000000,
+					MaxWidth:     3840,
+					MaxHeight:    2160,
+					MaxFrameRate: "60",
+					RepresentationBase: mpd.RepresentationBase{
+						Codecs:            "avc1.64001f",
+						ContentProtection: []mpd.ContentProtection{{Ref: "widevine"}, playReady},
+					},
+					Representation: []mpd.Representation{
+						{ID: "v1", Bandwidth: 800000, RepresentationBase: mpd.RepresentationBase{Width: 640, Height: 360, FrameRate: "25"}},
+						{ID: "v2", Bandwidth: 5000000, RepresentationBase: mpd.RepresentationBase{Width: 1920, Height: 1080, FrameRate: "50"}},
+						{ID: "v3", Bandwidth: 15000000, RepresentationBase: mpd.RepresentationBase{
+							Width: 1920, Height: 1080, FrameRate: "60", Codecs: "hvc1.2.4.L150.B0",
+							SupplementalProperty: []mpd.Descriptor{{SchemeIDURI: mpd.CICPTransferCharacteristicsSchemeIDURI, Value: "16"}},
+						}},
+						{ID: "v4", Bandwidth: 20000000, DependencyId: mpd.StringVector{"v3"}, RepresentationBase: mpd.RepresentationBase{
+							Width: 1920, Height: 1080, FrameRate: "60", Codecs: "hvc1.2.4.L150.B0",
+						}},
+					},
+				},
+				{
+					ID:                 2,
+					ContentType:        mpd.AudioContentType,
+					Lang:               "en-US",
+					RepresentationBase: mpd.RepresentationBase{Codecs: "mp4a.40.2", ContentProtection: []mpd.ContentProtection{{Ref: "widevine"}}},
+					Representation: []mpd.Representation{
+						{ID: "a1", Bandwidth: 128000, AssociationId: mpd.StringVector{"v1", "v4"}},
+						{ID: "a2", Bandwidth: 384000, RepresentationBase: mpd.RepresentationBase{Codecs: "ec-3"}},
+					},
+				},
+				{
+					ID:                 3,
+					ContentType:        mpd.AudioContentType,
+					Lang:               "de",
+					RepresentationBase: mpd.RepresentationBase{Codecs: "mp4a.40.2"},
+					Representation:     []mpd.Representation{{ID: "a3", Bandwidth: 128000}},
+				},
+				{
+					ID:                 4,
+					ContentType:        mpd.SubtitlesContentType,
+					Lang:               "fr",
+					RepresentationBase: mpd.RepresentationBase{ContentProtection: []mpd.ContentProtection{playReady}},
+					Representation:     []mpd.Representation{{ID: "t1", Bandwidth: 1000}},
+				},
+			},
+			Subset: []mpd.Subset{{Contains: mpd.UIntVector{1, 3}}, {Contains: mpd.UIntVector{3}}},
+			Preselection: []mpd.Preselection{
+				{ID: "p1", PreselectionComponents: mpd.StringVector{"3", "2"}},
+				{ID: "p2", PreselectionComponents: mpd.StringVector{"2", "3"}},
+			},
+		}},
+	}
+}
+
+func TestMPD_Filter(t *testing.T) {
+	testMPD := newFilterMPD()
+	testMPD.Filter(
+		mpd.MaxResolution(1920, 1080),
+		mpd.CodecPrefixes("avc1", "hvc1", "mp4a.40"),
+		mpd.StandardDynamicRange(),
+		mpd.Languages("en"),
+		testMPD.DRMSystems(mpd.WidevineSystemID),
+	)
+
+	wantMPD := newFilterMPD()
+	period := &wantMPD.Period[0]
+	video := &period.AdaptationSet[0]
+	video.Representation = video.Representation[:2]
+	video.MaxBandwidth = 5000000
+	video.MaxWidth = 1920
+	video.MaxHeight = 1080
+	video.MaxFrameRate = "50"
+	audio := &period.AdaptationSet[1]
+	audio.Representation = audio.Representation[:1]
+	audio.Representation[0].AssociationId = mpd.StringVector{"v1"}
+	period.AdaptationSet = period.AdaptationSet[:2]
+	period.Subset = []mpd.Subset{{Contains: mpd.UIntVector{1}}}
+	period.Preselection = []mpd.Preselection{{ID: "p2", PreselectionComponents: mpd.StringVector{"2"}}}
+
+	if diff := cmp.Diff(testMPD, wantMPD); diff != "" {
+		t.Errorf("wrong MPD: %s", diff)
+	}
+}
+
+func TestMPD_Filter_Unchanged(t *testing.T) {
+	testMPD := newFilterMPD()
+	testMPD.Filter(mpd.MaxBandwidth(20000000))
+
+	if diff := cmp.Diff(testMPD, newFilterMPD()); diff != "" {
+		t.Errorf("wrong MPD: %s", diff)
+	}
+}
+
+func TestRepresentationPredicate(t *testing.T) {
+	testMPD := newFilterMPD()
+	period := &testMPD.Period[0]
+
+	testCases := map[string]struct {
+		predicate mpd.RepresentationPredicate
+		want      []string
+	}{
+		"AnyOf": {
+			predicate: mpd.AnyOf(mpd.MaxBandwidth(200000), mpd.Not(mpd.MaxResolution(1280, 720))),
+			want:      []string{"v2", "v3", "v4", "a1", "a3", "t1"},
+		},
+		"AllOf without predicates": {
+			predicate: mpd.AllOf(),
+			want:      []string{"v1", "v2", "v3", "v4", "a1", "a2", "a3", "t1"},
+		},
+		"CodecPrefixes": {
+			predicate: mpd.CodecPrefixes("avc1", "ec-3"),
+			want:      []string{"v1", "v2", "a2", "t1"},
+		},
+		"StandardDynamicRange": {
+			predicate: mpd.StandardDynamicRange(),
+			want:      []string{"v1", "v2", "v4", "a1", "a2", "a3", "t1"},
+		},
+		"Languages": {
+			predicate: mpd.Languages("DE", "en-us", "e"),
+			want:      []string{"v1", "v2", "v3", "v4", "a1", "a2", "a3"},
+		},
+		"DRMSystems": {
+			predicate: testMPD.DRMSystems(mpd.PlayReadySystemID),
+			want:      []string{"v1", "v2", "v3", "v4", "a3", "t1"},
+		},
+	}
+
+	for name, testCase := range testCases {
+		var kept []string
+
+		for i := range period.AdaptationSet {
+			adaptationSet := &period.AdaptationSet[i]
+
+			for j := range adaptationSet.Representation {
+				if testCase.predicate(period, adaptationSet, &adaptationSet.Representation[j]) {
+					kept = append(kept, adaptationSet.Representation[j].ID)
+				}
+			}
+		}
+
+		if diff := cmp.Diff(kept, testCase.want); diff != "" {
+			t.Errorf("%s: wrong Representations: %s", name, diff)
+		}
+	}
+}
+
+func TestMPD_Filter_EdgeCases(t *testing.T) {
+	adaptationSet := &mpd.AdaptationSet{RepresentationBase: mpd.RepresentationBase{Codecs: "dvh1.05.06"}}
+	if mpd.StandardDynamicRange()(&mpd.Period{}, adaptationSet, &mpd.Representation{}) {
+		t.Error("Dolby Vision kept")
+	}
+
+	testMPD := &mpd.MPD{Period: []mpd.Period{{AdaptationSet: []mpd.AdaptationSet{{
+		RepresentationBase: mpd.RepresentationBase{ContentProtection: []mpd.ContentProtection{{Ref: "missing"}}},
+		Representation:     []mpd.Representation{{ID: "v1"}},
+	}}}}}
+
+	testMPD.Filter(testMPD.DRMSystems(mpd.WidevineSystemID))
+
+	if len(testMPD.Period[0].AdaptationSet) != 0 {
+		t.Errorf("wrong AdaptationSets: %+v", testMPD.Period[0].AdaptationSet)
+	}
+}
@@ -0,0 +1,504 @@
+package mpd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrProbeSegment = errors.New("cannot probe segment")
+
+// track describes a track of an initialization segment.
+type track struct {
+	ID                    uint32
+	HandlerType           string
+	Timescale             uint32
+	Language              string
+	Codecs                Codecs
+	Width                 uint
+	Height                uint
+	SampleRate            uint
+	ChannelCount          uint
+	DefaultSampleDuration uint32
+}
+
+// fragment summarises all movie fragments of a media segment belonging to one track.
+type fragment struct {
+	BaseMediaDecodeTime uint64
+	Duration            uint64
+	SampleCount         uint64
+}
+
+// probeInitSegment returns the tracks of an initialization segment.
+func probeInitSegment(data []byte) ([]track, error) {
+	traks, err := findBoxes(data, "moov", "trak")
+	if err != nil {
+		return nil, errors.Join(ErrProbeSegment, err)
+	}
+
+	trexes, err := findBoxes(data, "moov", "mvex", "trex")
+	if err != nil {
+		return nil, errors.Join(ErrProbeSegment, err)
+	}
+
+	defaultSampleDurations := map[uint32]uint32{}
+	for _, trex := range trexes {
+		r := &byteReader{data: trex.Payload}
+		r.fullBoxHeader()
+		trackID := r.uint32()
+		_ = r.uint32()
+		defaultSampleDurations[trackID] = r.uint32()
+	}
+
+	tracks := make([]track, 0, len(traks))
+	for _, trak := range traks {
+		t, err := probeTrack(trak.Payload)
+		if err != nil {
+			return nil, errors.Join(ErrProbeSegment, err)
+		}
+
+		t.DefaultSampleDuration = defaultSampleDurations[t.ID]
+		tracks = append(tracks, t)
+	}
+
+	if len(tracks) == 0 {
+		return nil, ErrProbeSegment
+	}
+
+	return tracks, nil
+}
+
+func probeTrack(trak []byte) (track, error) {
+	var t track
+
+	boxes := map[string][]string{
+		"tkhd": {"tkhd"},
+		"mdhd": {"mdia", "mdhd"},
+		"hdlr": {"mdia", "hdlr"},
+		"stsd": {"mdia", "minf", "stbl", "stsd"},
+	}
+
+	payloads := map[string][]byte{}
+	for name, path := range boxes {
+		b, found, err := findBox(trak, path...)
+		if err != nil {
+			return t, err
+		}
+
+		if !found {
+			return t, fmt.Errorf("%w: missing %s box", ErrParseBox, name)
+		}
+
+		payloads[name] = b.Payload
+	}
+
+	tkhd := &byteReader{data: payloads["tkhd"]}
+	if version, _ := tkhd.fullBoxHeader(); version == 1 {
+		tkhd.bytes(16)
+	} else {
+		tkhd.bytes(8)
+	}
+
+	t.ID = tkhd.uint32()
+
+	mdhd := &byteReader{data: payloads["mdhd"]}
+	if version, _ := mdhd.fullBoxHeader(); version == 1 {
+		mdhd.bytes(16)
+		t.Timescale = mdhd.uint32()
+		mdhd.uint64()
+	} else {
+		mdhd.bytes(8)
+		t.Timescale = mdhd.uint32()
+		mdhd.uint32()
+	}
+
+	t.Language = unpackLanguage(mdhd.uint16())
+
+	hdlr := &byteReader{data: payloads["hdlr"]}
+	hdlr.fullBoxHeader()
+	hdlr.uint32()
+	t.HandlerType = string(hdlr.fixed(4))
+
+	stsd := &byteReader{data: payloads["stsd"]}
+	stsd.fullBoxHeader()
+	stsd.uint32()
+
+	for _, r := range []*byteReader{tkhd, mdhd, hdlr, stsd} {
+		if r.err != nil {
+			return t, r.err
+		}
+	}
+
+	entries, err := readBoxes(stsd.data)
+	if err != nil {
+		return t, err
+	}
+
+	if len(entries) == 0 {
+		return t, fmt.Errorf("%w: missing sample entry", ErrParseBox)
+	}
+
+	return t, probeSampleEntry(entries[0], &t)
+}
+
+// unpackLanguage decodes a packed ISO-639-2/T language code.
+func unpackLanguage(packed uint16) string {
+	if packed == 0 || packed == 0x7fff {
+		return ""
+	}
+
+	return string([]byte{
+		byte(packed>>10&0x1f) + 0x60,
+		byte(packed>>5&0x1f) + 0x60,
+		byte(packed&0x1f) + 0x60,
+	})
+}
+
+// probeSampleEntry derives the codecs and media properties from a sample entry.
+func probeSampleEntry(entry box, t *track) error {
+	r := &byteReader{data: entry.Payload}
+	r.bytes(8)
+
+	format := entry.Type
+
+	switch t.HandlerType {
+	case "vide":
+		r.bytes(16)
+		t.Width = uint(r.uint16())
+		t.Height = uint(r.uint16())
+		r.bytes(50)
+	case "soun":
+		r.bytes(8)
+		t.ChannelCount = uint(r.uint16())
+		r.bytes(6)
+		t.SampleRate = uint(r.uint32() >> 16)
+	default:
+		t.Codecs = Codecs(format)
+		return nil
+	}
+
+	if r.err != nil {
+		return r.err
+	}
+
+	children, err := readBoxes(r.data)
+	if err != nil {
+		return err
+	}
+
+	configurations := map[string][]byte{}
+	for _, child := range children {
+		configurations[child.Type] = child.Payload
+	}
+
+	if format == "encv" || format == "enca" {
+		if frma, found, _ := findBox(configurations["sinf"], "frma"); found && len(frma.Payload) == 4 {
+			format = string(frma.Payload)
+		}
+	}
+
+	t.Codecs = codecsFromConfiguration(format, configurations)
+
+	return nil
+}
+
+// codecsFromConfiguration builds an RFC6381 codecs parameter from a sample entry format and its configuration boxes.
+func codecsFromConfiguration(format string, configurations map[string][]byte) Codecs {
+	switch format {
+	case "avc1", "avc2", "avc3", "avc4":
+		if avcC := configurations["avcC"]; len(avcC) >= 4 {
+			return Codecs(fmt.Sprintf("%s.%02X%02X%02X", format, avcC[1], avcC[2], avcC[3]))
+		}
+	case "hvc1", "hev1":
+		if hvcC := configurations["hvcC"]; len(hvcC) >= 13 {
+			return Codecs(format + "." + hevcCodecs(hvcC))
+		}
+	case "av01":
+		if av1C := configurations["av1C"]; len(av1C) >= 3 {
+			return Codecs(av1Codecs(av1C))
+		}
+	case "vp08", "vp09":
+		if vpcC := configurations["vpcC"]; len(vpcC) >= 7 {
+			return Codecs(fmt.Sprintf("%s.%02d.%02d.%02d", format, vpcC[4], vpcC[5], vpcC[6]>>4))
+		}
+	case "mp4a":
+		if codecs, ok := mp4aCodecs(configurations["esds"]); ok {
+			return Codecs(codecs)
+		}
+	case "Opus":
+		return "opus"
+	case "fLaC":
+		return "flac"
+	}
+
+	return Codecs(format)
+}
+
+// hevcCodecs formats the HEVC codecs suffix as per ISO/IEC 14496-15 Annex E.
+func hevcCodecs(hvcC []byte) string {
+	profileSpace := []string{"", "A", "B", "C"}[hvcC[1]>>6]
+	tier := "L"
+
+	if hvcC[1]>>5&1 == 1 {
+		tier = "H"
+	}
+
+	var compatibility uint32
+	for i := 0; i < 32; i++ {
+		if hvcC[2+i/8]>>(7-i%8)&1 == 1 {
+			compatibility |= 1 << i
+		}
+	}
+
+	parts := []string{
+		fmt.Sprintf("%s%d", profileSpace, hvcC[1]&0x1f),
+		fmt.Sprintf("%X", compatibility),
+		fmt.Sprintf("%s%d", tier, hvcC[12]),
+	}
+
+	constraints := hvcC[6:12]
+	for len(constraints) > 0 && constraints[len(constraints)-1] == 0 {
+		constraints = constraints[:len(constraints)-1]
+	}
+
+	for _, constraint := range constraints {
+		parts = append(parts, fmt.Sprintf("%X", constraint))
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// av1Codecs formats the AV1 codecs parameter as per the AV1 ISOBMFF binding.
+func av1Codecs(av1C []byte) string {
+	profile := av1C[1] >> 5
+	level := av1C[1] & 0x1f
+	tier := "M"
+
+	if av1C[2]>>7 == 1 {
+		tier = "H"
+	}
+
+	bitDepth := 8
+	if av1C[2]>>6&1 == 1 {
+		bitDepth = 10
+		if av1C[2]>>5&1 == 1 {
+			bitDepth = 12
+		}
+	}
+
+	return fmt.Sprintf("av01.%d.%02d%s.%02d", profile, level, tier, bitDepth)
+}
+
+// mp4aCodecs derives the codecs parameter from an ES descriptor box.
+func mp4aCodecs(esds []byte) (string, bool) {
+	if len(esds) < 4 {
+		return "", false
+	}
+
+	descriptors := readDescriptors(esds[4:])
+
+	es, ok := descriptors[0x03]
+	if !ok || len(es) < 3 {
+		return "", false
+	}
+
+	flags := es[2]
+	es = es[3:]
+
+	if flags&0x80 != 0 && len(es) >= 2 {
+		es = es[2:]
+	}
+
+	if flags&0x40 != 0 && len(es) >= 1 && len(es) > int(es[0]) {
+		es = es[1+int(es[0]):]
+	}
+
+	if flags&0x20 != 0 && len(es) >= 2 {
+		es = es[2:]
+	}
+
+	decoderConfig, ok := readDescriptors(es)[0x04]
+	if !ok || len(decoderConfig) < 13 {
+		return "", false
+	}
+
+	objectType := decoderConfig[0]
+	if objectType != 0x40 {
+		return fmt.Sprintf("mp4a.%02x", objectType), true
+	}
+
+	decoderSpecificInfo, ok := readDescriptors(decoderConfig[13:])[0x05]
+	if !ok || len(decoderSpecificInfo) < 1 {
+		return "mp4a.40", true
+	}
+
+	return audioSpecificConfigCodecs(decoderSpecificInfo), true
+}
+
+// audioSpecificConfigCodecs derives the codecs parameter from the audio object type of a non-empty
+// AudioSpecificConfig.
+func audioSpecificConfigCodecs(audioSpecificConfig []byte) string {
+	audioObjectType := audioSpecificConfig[0] >> 3
+	if audioObjectType == 31 && len(audioSpecificConfig) >= 2 {
+		audioObjectType = 32 + (audioSpecificConfig[0]&0x07)<<3 + audioSpecificConfig[1]>>5
+	}
+
+	return fmt.Sprintf("mp4a.40.%d", audioObjectType)
+}
+
+// readDescriptors reads consecutive ISO/IEC 14496-1 descriptors and returns their payloads by tag.
+func readDescriptors(data []byte) map[byte][]byte {
+	descriptors := map[byte][]byte{}
+
+	for len(data) >= 2 {
+		tag := data[0]
+		size := 0
+		i := 1
+
+		for ; i < len(data) && i <= 4; i++ {
+			size = size<<7 | int(data[i]&0x7f)
+			if data[i]&0x80 == 0 {
+				i++
+				break
+			}
+		}
+
+		if i+size > len(data) {
+			break
+		}
+
+		descriptors[tag] = data[i : i+size]
+		data = data[i+size:]
+	}
+
+	return descriptors
+}
+
+// probeMediaSegment sums up the movie fragments of a media segment belonging to the given track.
+func probeMediaSegment(data []byte, t track) (fragment, error) {
+	trafs, err := findBoxes(data, "moof", "traf")
+	if err != nil {
+		return fragment{}, errors.Join(ErrProbeSegment, err)
+	}
+
+	var f fragment
+
+	found := false
+
+	for _, traf := range trafs {
+		tfhd, ok, err := findBox(traf.Payload, "tfhd")
+		if err != nil || !ok {
+			return fragment{}, errors.Join(ErrProbeSegment, ErrParseBox, err)
+		}
+
+		r := &byteReader{data: tfhd.Payload}
+		_, flags := r.fullBoxHeader()
+
+		if r.uint32() != t.ID {
+			continue
+		}
+
+		defaultSampleDuration := t.DefaultSampleDuration
+		if flags&0x01 != 0 {
+			r.uint64()
+		}
+
+		if flags&0x02 != 0 {
+			r.uint32()
+		}
+
+		if flags&0x08 != 0 {
+			defaultSampleDuration = r.uint32()
+		}
+
+		if r.err != nil {
+			return fragment{}, errors.Join(ErrProbeSegment, r.err)
+		}
+
+		if tfdt, ok, _ := findBox(traf.Payload, "tfdt"); ok && !found {
+			r := &byteReader{data: tfdt.Payload}
+			if version, _ := r.fullBoxHeader(); version == 1 {
+				f.BaseMediaDecodeTime = r.uint64()
+			} else {
+				f.BaseMediaDecodeTime = uint64(r.uint32())
+			}
+
+			if r.err != nil {
+				return fragment{}, errors.Join(ErrProbeSegment, r.err)
+			}
+		}
+
+		found = true
+
+		truns, err := findBoxes(traf.Payload, "trun")
+		if err != nil {
+			return fragment{}, errors.Join(ErrProbeSegment, err)
+		}
+
+		for _, trun := range truns {
+			duration, count, err := readTrackRun(trun.Payload, defaultSampleDuration)
+			if err != nil {
+				return fragment{}, errors.Join(ErrProbeSegment, err)
+			}
+
+			f.Duration += duration
+			f.SampleCount += count
+		}
+	}
+
+	if !found {
+		return fragment{}, fmt.Errorf("%w: no fragment for track %d", ErrProbeSegment, t.ID)
+	}
+
+	return f, nil
+}
+
+// readTrackRun returns the total duration and the number of samples of a trun box.
+func readTrackRun(payload []byte, defaultSampleDuration uint32) (uint64, uint64, error) {
+	r := &byteReader{data: payload}
+	_, flags := r.fullBoxHeader()
+	sampleCount := r.uint32()
+
+	if flags&0x01 != 0 {
+		r.uint32()
+	}
+
+	if flags&0x04 != 0 {
+		r.uint32()
+	}
+
+	sampleSize := 0
+	for _, flag := range []uint32{0x100, 0x200, 0x400, 0x800} {
+		if flags&flag != 0 {
+			sampleSize += 4
+		}
+	}
+
+	switch {
+	case r.err != nil:
+		return 0, 0, r.err
+	case sampleSize == 0:
+		return uint64(sampleCount) * uint64(defaultSampleDuration), uint64(sampleCount), nil
+	case uint64(sampleCount)*uint64(sampleSize) > uint64(len(r.data)):
+		return 0, 0, ErrParseBox
+	}
+
+	var duration uint64
+
+	for i := uint32(0); i < sampleCount && r.err == nil; i++ {
+		sampleDuration := defaultSampleDuration
+		if flags&0x100 != 0 {
+			sampleDuration = r.uint32()
+		}
+
+		for _, flag := range []uint32{0x200, 0x400, 0x800} {
+			if flags&flag != 0 {
+				r.uint32()
+			}
+		}
+
+		duration += uint64(sampleDuration)
+	}
+
+	return duration, uint64(sampleCount), r.err
+}
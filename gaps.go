@@ -0,0 +1,260 @@
+package mpd
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrAnalyzeTimeline   = errors.New("cannot analyze timeline")
+	ErrPatchTimelineGaps = errors.New("cannot patch timeline gaps")
+)
+
+// TimelineDiscontinuity is a gap or an overlap between consecutive segments of a Representation. The AdaptationSet
+// and Representation are given by index.
+type TimelineDiscontinuity struct {
+	AdaptationSet  int
+	Representation int
+
+	// Time is the end of the earlier segment on the media timeline and Duration the time until the start of the
+	// next segment, both in Timescale units. Duration is negative for an overlap.
+	Time      uint64
+	Duration  int64
+	Timescale uint64
+
+	// PresentationTime is the end of the earlier segment relative to the start of the media presentation.
+	PresentationTime     time.Duration
+	PresentationDuration time.Duration
+}
+
+// TimelineDrift is the difference between the end of the segments of a Representation and the end of the segments
+// of the first Representation of the Period, which accumulates over gaps, overlaps and differing segment durations.
+// The AdaptationSet and Representation are given by index.
+type TimelineDrift struct {
+	AdaptationSet  int
+	Representation int
+
+	// Duration is in Timescale units of the Representation; it is negative if the Representation ends earlier.
+	Duration  int64
+	Timescale uint64
+
+	PresentationDuration time.Duration
+}
+
+// TimelineAnalysis lists the discontinuities and drifts of the Representations of a Period.
+type TimelineAnalysis struct {
+	Discontinuities []TimelineDiscontinuity
+
+	// Drifts only lists Representations that drift.
+	Drifts []TimelineDrift
+}
+
+// AnalyzeTimeline finds the gaps and overlaps between the segments of every Representation in the Period at the
+// index, and the drift of the Representations against each other.
+func (m *MPD) AnalyzeTimeline(index int) (*TimelineAnalysis, error) {
+	if index < 0 || index >= len(m.Period) {
+		return nil, fmt.Errorf("%w: no period %d", ErrAnalyzeTimeline, index)
+	}
+
+	period := &m.Period[index]
+	analysis := &TimelineAnalysis{}
+
+	var (
+		reference time.Duration
+		ends      []TimelineDrift
+	)
+
+	for i := range period.AdaptationSet {
+		adaptationSet := &period.AdaptationSet[i]
+
+		for j := range adaptationSet.Representation {
+			segments, err := m.Segments(period, adaptationSet, &adaptationSet.Representation[j], nil)
+			if err != nil {
+				return nil, errors.Join(ErrAnalyzeTimeline, err)
+			}
+
+			if len(segments) == 0 {
+				continue
+			}
+
+			for k := 1; k < len(segments); k++ {
+				previous, next := &segments[k-1], &segments[k]
+				if end := previous.Time + previous.Duration; end != next.Time {
+					analysis.Discontinuities = append(analysis.Discontinuities, TimelineDiscontinuity{
+						AdaptationSet:        i,
+						Representation:       j,
+						Time:                 end,
+						Duration:             int64(next.Time) - int64(end),
+						Timescale:            next.Timescale,
+						PresentationTime:     previous.PresentationTime + previous.PresentationDuration,
+						PresentationDuration: next.PresentationTime - previous.PresentationTime - previous.PresentationDuration,
+					})
+				}
+			}
+
+			last := &segments[len(segments)-1]
+			end := last.PresentationTime + last.PresentationDuration
+
+			if ends == nil {
+				reference = end
+			}
+
+			ends = append(ends, TimelineDrift{AdaptationSet: i, Representation: j, Timescale: last.Timescale, PresentationDuration: end - reference})
+		}
+	}
+
+	for _, drift := range ends {
+		switch {
+		case drift.PresentationDuration > 0:
+			drift.Duration = int64(durationTicks(drift.PresentationDuration, drift.Timescale))
+		case drift.PresentationDuration < 0:
+			drift.Duration = -int64(durationTicks(-drift.PresentationDuration, drift.Timescale))
+		default:
+			continue
+		}
+
+		analysis.Drifts = append(analysis.Drifts, drift)
+	}
+
+	return analysis, nil
+}
+
+// PatchTimelineGaps closes the gaps of up to maximum between the segments of the SegmentTimelines of
+// SegmentTemplates in the Period at the index and returns the number of closed gaps. Without failover, the segment
+// before a gap is stretched to the start of the next segment. With failover, a segment is inserted into the gap and
+// marked as failover content with FailoverContent; the segment has to be provided, e.g. as a slate, and the media
+// template must address segments by $Time$, as inserting a segment would change the numbers of the following
+// segments. Overlaps and larger gaps are kept. The MPD is not modified if an error is returned.
+func (m *MPD) PatchTimelineGaps(index int, maximum time.Duration, failover bool) (int, error) {
+	if index < 0 || index >= len(m.Period) {
+		return 0, fmt.Errorf("%w: no period %d", ErrPatchTimelineGaps, index)
+	}
+
+	var (
+		apply  []func()
+		closed int
+	)
+
+	patchLevel := func(parent []*SegmentTemplate, template *SegmentTemplate) ([]*SegmentTemplate, error) {
+		if template == nil {
+			return parent, nil
+		}
+
+		chain := append(parent[:len(parent):len(parent)], template)
+		if template.SegmentTimeline == nil {
+			return chain, nil
+		}
+
+		effective := inherit(chain)
+
+		timeline, gaps := template.SegmentTimeline.closeGaps(durationTicks(maximum, uint64(effective.Timescale)), failover)
+		if len(gaps) == 0 {
+			return chain, nil
+		}
+
+		if failover && !strings.Contains(effective.Media, "$Time") {
+			return nil, fmt.Errorf("%w: failover segments in SegmentTemplate without $Time$", ErrPatchTimelineGaps)
+		}
+
+		var failoverContent *FailoverContent
+
+		if failover {
+			failoverContent = &FailoverContent{}
+			if effective.FailoverContent != nil {
+				failoverContent.Valid = effective.FailoverContent.Valid
+				failoverContent.FCS = append(failoverContent.FCS, effective.FailoverContent.FCS...)
+			}
+
+			failoverContent.FCS = append(failoverContent.FCS, gaps...)
+			sort.Slice(failoverContent.FCS, func(i, j int) bool { return failoverContent.FCS[i].T < failoverContent.FCS[j].T })
+		}
+
+		closed += len(gaps)
+		apply = append(apply, func() {
+			template.SegmentTimeline = timeline
+
+			if failoverContent != nil {
+				template.FailoverContent = failoverContent
+			}
+		})
+
+		return chain, nil
+	}
+
+	period := &m.Period[index]
+
+	periodChain, err := patchLevel(nil, period.SegmentTemplate)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range period.AdaptationSet {
+		adaptationSet := &period.AdaptationSet[i]
+
+		adaptationSetChain, err := patchLevel(periodChain, adaptationSet.SegmentTemplate)
+		if err != nil {
+			return 0, err
+		}
+
+		for j := range adaptationSet.Representation {
+			if _, err := patchLevel(adaptationSetChain, adaptationSet.Representation[j].SegmentTemplate); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	for _, f := range apply {
+		f()
+	}
+
+	return closed, nil
+}
+
+// closeGaps returns a normalized copy of the SegmentTimeline with the gaps of up to maximum closed, and the closed
+// gaps. Without failover, the last segment before a gap is stretched; with failover, a segment is inserted.
+// Gaps after open-ended repeats are kept.
+func (t *SegmentTimeline) closeGaps(maximum uint64, failover bool) (*SegmentTimeline, []FCS) {
+	patched := &SegmentTimeline{Items: t.Items}
+
+	var (
+		gaps  []FCS
+		end   uint64
+		known bool
+	)
+
+	for _, s := range t.S {
+		if s.T != nil {
+			if n := len(patched.S); n > 0 && known && *s.T > end && *s.T-end <= maximum {
+				gap := FCS{T: end, D: *s.T - end}
+				gaps = append(gaps, gap)
+
+				switch last := &patched.S[n-1]; {
+				case failover:
+					patched.S = append(patched.S, S{D: gap.D})
+				case last.R == 0:
+					last.D += gap.D
+				default:
+					last.R--
+					patched.S = append(patched.S, S{D: last.D + gap.D, K: last.K})
+				}
+			}
+
+			end, known = *s.T, true
+		}
+
+		patched.S = append(patched.S, s)
+
+		if s.R < 0 {
+			known = false
+		} else {
+			end += s.D * uint64(s.R+1)
+		}
+	}
+
+	patched.Normalize()
+
+	return patched, gaps
+}
@@ -0,0 +1,140 @@
+package mpd_test
+
+import (
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"go.eigsys.de/go-mpd"
+	"testing"
+	"time"
+)
+
+func newGapMPD() *mpd.MPD {
+	testMPD := newContentMPD()
+	testMPD.Period[0].AdaptationSet[0].SegmentTemplate.SegmentTimeline.S = []mpd.S{
+		{T: uint64Ptr(0), D: 4000, R: 2},
+		{T: uint64Ptr(12500), D: 4000, R: 3},
+		{T: uint64Ptr(28000), D: 2000},
+	}
+	testMPD.Period[0].AdaptationSet[1].SegmentTemplate.SegmentTimeline.S[0].R = 13
+
+	return testMPD
+}
+
+func TestMPD_AnalyzeTimeline(t *testing.T) {
+	analysis, err := newGapMPD().AnalyzeTimeline(0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantAnalysis := &mpd.TimelineAnalysis{
+		Discontinuities: []mpd.TimelineDiscontinuity{
+			{
+				AdaptationSet: 0, Representation: 0, Time: 12000, Duration: 500, Timescale: 1000,
+				PresentationTime: 12 * time.Second, PresentationDuration: 500 * time.Millisecond,
+			},
+			{
+				AdaptationSet: 0, Representation: 0, Time: 28500, Duration: -500, Timescale: 1000,
+				PresentationTime: 28500 * time.Millisecond, PresentationDuration: -500 * time.Millisecond,
+			},
+		},
+		Drifts: []mpd.TimelineDrift{
+			{AdaptationSet: 1, Representation: 0, Duration: -96000, Timescale: 48000, PresentationDuration: -2 * time.Second},
+		},
+	}
+	if diff := cmp.Diff(analysis, wantAnalysis); diff != "" {
+		t.Errorf("wrong analysis: %s", diff)
+	}
+
+	if analysis, err := newContentMPD().AnalyzeTimeline(0); err != nil || len(analysis.Discontinuities) != 0 || len(analysis.Drifts) != 0 {
+		t.Errorf("unexpected result: %+v %v", analysis, err)
+	}
+}
+
+func TestMPD_PatchTimelineGaps(t *testing.T) {
+	testMPD := newGapMPD()
+
+	closed, err := testMPD.PatchTimelineGaps(0, 100*time.Millisecond, false)
+	if err != nil || closed != 0 {
+		t.Fatalf("unexpected result: %d %v", closed, err)
+	}
+
+	if diff := cmp.Diff(testMPD, newGapMPD()); diff != "" {
+		t.Errorf("MPD was modified: %s", diff)
+	}
+
+	if closed, err = testMPD.PatchTimelineGaps(0, time.Second, false); err != nil || closed != 1 {
+		t.Fatalf("unexpected result: %d %v", closed, err)
+	}
+
+	wantS := []mpd.S{{T: uint64Ptr(0), D: 4000, R: 1}, {D: 4500}, {D: 4000, R: 3}, {T: uint64Ptr(28000), D: 2000}}
+	if diff := cmp.Diff(testMPD.Period[0].AdaptationSet[0].SegmentTemplate.SegmentTimeline.S, wantS); diff != "" {
+		t.Errorf("wrong SegmentTimeline: %s", diff)
+	}
+
+	analysis, err := testMPD.AnalyzeTimeline(0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(analysis.Discontinuities) != 1 || analysis.Discontinuities[0].Duration != -500 {
+		t.Errorf("wrong discontinuities: %+v", analysis.Discontinuities)
+	}
+
+	testMPD = newGapMPD()
+	template := testMPD.Period[0].AdaptationSet[0].SegmentTemplate
+	template.Media = "video/$Time$.m4s"
+	template.FailoverContent = &mpd.FailoverContent{FCS: []mpd.FCS{{T: 20000, D: 4000}}}
+
+	if closed, err = testMPD.PatchTimelineGaps(0, time.Second, true); err != nil || closed != 1 {
+		t.Fatalf("unexpected result: %d %v", closed, err)
+	}
+
+	wantS = []mpd.S{{T: uint64Ptr(0), D: 4000, R: 2}, {D: 500}, {D: 4000, R: 3}, {T: uint64Ptr(28000), D: 2000}}
+	if diff := cmp.Diff(template.SegmentTimeline.S, wantS); diff != "" {
+		t.Errorf("wrong SegmentTimeline: %s", diff)
+	}
+
+	wantFailoverContent := &mpd.FailoverContent{FCS: []mpd.FCS{{T: 12000, D: 500}, {T: 20000, D: 4000}}}
+	if diff := cmp.Diff(template.FailoverContent, wantFailoverContent); diff != "" {
+		t.Errorf("wrong FailoverContent: %s", diff)
+	}
+}
+
+func TestMPD_TimelineGaps_Errors(t *testing.T) {
+	testMPD := newGapMPD()
+
+	if _, err := testMPD.AnalyzeTimeline(1); !errors.Is(err, mpd.ErrAnalyzeTimeline) {
+		t.Errorf("wrong error: %v", err)
+	}
+
+	if _, err := testMPD.PatchTimelineGaps(1, time.Second, false); !errors.Is(err, mpd.ErrPatchTimelineGaps) {
+		t.Errorf("wrong error: %v", err)
+	}
+
+	if _, err := testMPD.PatchTimelineGaps(0, time.Second, true); !errors.Is(err, mpd.ErrPatchTimelineGaps) {
+		t.Errorf("wrong error: %v", err)
+	}
+
+	testMPD.Period[0].AdaptationSet[1].SegmentTemplate.Media = ""
+
+	if _, err := testMPD.AnalyzeTimeline(0); !errors.Is(err, mpd.ErrAnalyzeTimeline) {
+		t.Errorf("wrong error: %v", err)
+	}
+
+	testMPD = newGapMPD()
+	testMPD.Period[0].AdaptationSet[0].SegmentTemplate.Media = "video/$Time$.m4s"
+	audio := testMPD.Period[0].AdaptationSet[1].SegmentTemplate.SegmentTimeline
+	audio.S = append(audio.S, mpd.S{T: uint64Ptr(1350000), D: 96000})
+
+	if _, err := testMPD.PatchTimelineGaps(0, time.Second, true); !errors.Is(err, mpd.ErrPatchTimelineGaps) {
+		t.Errorf("wrong error: %v", err)
+	}
+
+	wantMPD := newGapMPD()
+	wantMPD.Period[0].AdaptationSet[0].SegmentTemplate.Media = "video/$Time$.m4s"
+	wantMPD.Period[0].AdaptationSet[1].SegmentTemplate.SegmentTimeline.S = audio.S
+
+	if diff := cmp.Diff(testMPD, wantMPD); diff != "" {
+		t.Errorf("MPD was modified: %s", diff)
+	}
+}
@@ -0,0 +1,796 @@
+package mpd
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrParseHLS = errors.New("cannot parse HLS playlist")
+
+type HLSMediaType string
+
+const (
+	AudioHLSMediaType          HLSMediaType = "AUDIO"
+	VideoHLSMediaType          HLSMediaType = "VIDEO"
+	SubtitlesHLSMediaType      HLSMediaType = "SUBTITLES"
+	ClosedCaptionsHLSMediaType HLSMediaType = "CLOSED-CAPTIONS"
+)
+
+type HLSKeyMethod string
+
+const (
+	NoneHLSKeyMethod         HLSKeyMethod = "NONE"
+	AES128HLSKeyMethod       HLSKeyMethod = "AES-128"
+	SampleAESHLSKeyMethod    HLSKeyMethod = "SAMPLE-AES"
+	SampleAESCTRHLSKeyMethod HLSKeyMethod = "SAMPLE-AES-CTR"
+)
+
+type HLSPlaylistType string
+
+const (
+	EventHLSPlaylistType HLSPlaylistType = "EVENT"
+	VODHLSPlaylistType   HLSPlaylistType = "VOD"
+)
+
+// HLSMultivariantPlaylist is an HLS multivariant playlist as per RFC 8216.
+type HLSMultivariantPlaylist struct {
+	Version             int
+	IndependentSegments bool
+	SessionKey          []HLSKey
+	Media               []HLSMedia
+	Variant             []HLSVariant
+}
+
+// HLSMedia is an EXT-X-MEDIA rendition.
+type HLSMedia struct {
+	Type       HLSMediaType
+	URI        string
+	GroupID    string
+	Language   string
+	Name       string
+	Default    bool
+	Autoselect bool
+	Channels   string
+}
+
+// HLSVariant is an EXT-X-STREAM-INF variant stream. Audio and Subtitles are EXT-X-MEDIA group IDs.
+type HLSVariant struct {
+	URI              string
+	Bandwidth        uint64
+	AverageBandwidth uint64
+	Codecs           string
+	Width            uint
+	Height           uint
+	FrameRate        float64
+	Audio            string
+	Subtitles        string
+}
+
+// HLSMediaPlaylist is an HLS media playlist as per RFC 8216.
+type HLSMediaPlaylist struct {
+	Version int
+
+	// TargetDuration is in seconds.
+	TargetDuration        uint64
+	MediaSequence         uint64
+	DiscontinuitySequence uint64
+	PlaylistType          HLSPlaylistType
+	IndependentSegments   bool
+	Segment               []HLSSegment
+	EndList               bool
+}
+
+// HLSSegment is a media segment. Map and Key apply to this and all following segments until they are set again.
+type HLSSegment struct {
+	URI             string
+	Duration        time.Duration
+	ByteRange       *HLSByteRange
+	Discontinuity   bool
+	Key             []HLSKey
+	Map             *HLSMap
+	ProgramDateTime time.Time
+	DateRange       []HLSDateRange
+}
+
+// HLSByteRange is a sub-range of a resource, as per EXT-X-BYTERANGE.
+type HLSByteRange struct {
+	Length uint64
+	Offset uint64
+}
+
+// HLSMap is an EXT-X-MAP media initialization section.
+type HLSMap struct {
+	URI       string
+	ByteRange *HLSByteRange
+}
+
+// HLSKey is an EXT-X-KEY or EXT-X-SESSION-KEY. KeyID is written as the non-standard KEYID attribute,
+// which some DRM systems use to signal the default KID.
+type HLSKey struct {
+	Method            HLSKeyMethod
+	URI               string
+	IV                []byte
+	KeyFormat         string
+	KeyFormatVersions string
+	KeyID             *UUID
+}
+
+// HLSDateRange is an EXT-X-DATERANGE. ClientAttribute names include the X- prefix and are written as quoted strings.
+type HLSDateRange struct {
+	ID              string
+	Class           string
+	StartDate       time.Time
+	EndDate         time.Time
+	Duration        time.Duration
+	PlannedDuration time.Duration
+	ClientAttribute map[string]string
+	SCTE35Cmd       []byte
+	SCTE35Out       []byte
+	SCTE35In        []byte
+	EndOnNext       bool
+}
+
+// Bytes encodes the multivariant playlist.
+func (p *HLSMultivariantPlaylist) Bytes() []byte {
+	var builder strings.Builder
+
+	builder.WriteString("#EXTM3U\n")
+	writeHLSHeader(&builder, p.Version, p.IndependentSegments)
+
+	for i := range p.SessionKey {
+		builder.WriteString("#EXT-X-SESSION-KEY:" + p.SessionKey[i].attributes() + "\n")
+	}
+
+	for _, media := range p.Media {
+		var attributes hlsAttributes
+		attributes.enumerated("TYPE", string(media.Type))
+		attributes.quoted("GROUP-ID", media.GroupID)
+		attributes.quoted("LANGUAGE", media.Language)
+		attributes.quoted("NAME", media.Name)
+		attributes.boolean("DEFAULT", media.Default)
+		attributes.boolean("AUTOSELECT", media.Autoselect)
+		attributes.quoted("CHANNELS", media.Channels)
+		attributes.quoted("URI", media.URI)
+		builder.WriteString("#EXT-X-MEDIA:" + attributes.String() + "\n")
+	}
+
+	for _, variant := range p.Variant {
+		var attributes hlsAttributes
+		attributes.decimal("BANDWIDTH", variant.Bandwidth)
+		attributes.decimal("AVERAGE-BANDWIDTH", variant.AverageBandwidth)
+		attributes.quoted("CODECS", variant.Codecs)
+
+		if variant.Width > 0 && variant.Height > 0 {
+			attributes.enumerated("RESOLUTION", strconv.FormatUint(uint64(variant.Width), 10)+"x"+strconv.FormatUint(uint64(variant.Height), 10))
+		}
+
+		if variant.FrameRate > 0 {
+			attributes.enumerated("FRAME-RATE", strconv.FormatFloat(variant.FrameRate, 'f', 3, 64))
+		}
+
+		attributes.quoted("AUDIO", variant.Audio)
+		attributes.quoted("SUBTITLES", variant.Subtitles)
+		builder.WriteString("#EXT-X-STREAM-INF:" + attributes.String() + "\n" + variant.URI + "\n")
+	}
+
+	return []byte(builder.String())
+}
+
+// Bytes encodes the media playlist.
+func (p *HLSMediaPlaylist) Bytes() []byte {
+	var builder strings.Builder
+
+	builder.WriteString("#EXTM3U\n")
+	writeHLSHeader(&builder, p.Version, p.IndependentSegments)
+	builder.WriteString("#EXT-X-TARGETDURATION:" + strconv.FormatUint(p.TargetDuration, 10) + "\n")
+	builder.WriteString("#EXT-X-MEDIA-SEQUENCE:" + strconv.FormatUint(p.MediaSequence, 10) + "\n")
+
+	if p.DiscontinuitySequence > 0 {
+		builder.WriteString("#EXT-X-DISCONTINUITY-SEQUENCE:" + strconv.FormatUint(p.DiscontinuitySequence, 10) + "\n")
+	}
+
+	if p.PlaylistType != "" {
+		builder.WriteString("#EXT-X-PLAYLIST-TYPE:" + string(p.PlaylistType) + "\n")
+	}
+
+	for i := range p.Segment {
+		segment := &p.Segment[i]
+
+		if segment.Discontinuity {
+			builder.WriteString("#EXT-X-DISCONTINUITY\n")
+		}
+
+		for j := range segment.Key {
+			builder.WriteString("#EXT-X-KEY:" + segment.Key[j].attributes() + "\n")
+		}
+
+		if segment.Map != nil {
+			var attributes hlsAttributes
+			attributes.quoted("URI", segment.Map.URI)
+
+			if segment.Map.ByteRange != nil {
+				attributes.quoted("BYTERANGE", segment.Map.ByteRange.String())
+			}
+
+			builder.WriteString("#EXT-X-MAP:" + attributes.String() + "\n")
+		}
+
+		if !segment.ProgramDateTime.IsZero() {
+			builder.WriteString("#EXT-X-PROGRAM-DATE-TIME:" + FormatDateTime(segment.ProgramDateTime) + "\n")
+		}
+
+		for j := range segment.DateRange {
+			builder.WriteString("#EXT-X-DATERANGE:" + segment.DateRange[j].attributes() + "\n")
+		}
+
+		builder.WriteString("#EXTINF:" + formatHLSSeconds(segment.Duration) + ",\n")
+
+		if segment.ByteRange != nil {
+			builder.WriteString("#EXT-X-BYTERANGE:" + segment.ByteRange.String() + "\n")
+		}
+
+		builder.WriteString(segment.URI + "\n")
+	}
+
+	if p.EndList {
+		builder.WriteString("#EXT-X-ENDLIST\n")
+	}
+
+	return []byte(builder.String())
+}
+
+// ParseHLSMultivariantPlaylist parses a multivariant playlist. Unknown tags are ignored.
+func ParseHLSMultivariantPlaylist(data []byte) (*HLSMultivariantPlaylist, error) {
+	lines, err := hlsLines(data)
+	if err != nil {
+		return nil, err
+	}
+
+	playlist := &HLSMultivariantPlaylist{}
+
+	var variant *HLSVariant
+
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "#") {
+			if variant == nil {
+				return nil, fmt.Errorf("%w: URI %q without EXT-X-STREAM-INF", ErrParseHLS, line)
+			}
+
+			variant.URI = line
+			playlist.Variant = append(playlist.Variant, *variant)
+			variant = nil
+
+			continue
+		}
+
+		name, value, _ := strings.Cut(line, ":")
+
+		switch name {
+		case "#EXT-X-VERSION":
+			playlist.Version, err = strconv.Atoi(value)
+		case "#EXT-X-INDEPENDENT-SEGMENTS":
+			playlist.IndependentSegments = true
+		case "#EXT-X-SESSION-KEY":
+			var key HLSKey
+			key, err = parseHLSKey(value)
+			playlist.SessionKey = append(playlist.SessionKey, key)
+		case "#EXT-X-MEDIA":
+			var media HLSMedia
+			media, err = parseHLSMedia(value)
+			playlist.Media = append(playlist.Media, media)
+		case "#EXT-X-STREAM-INF":
+			variant, err = parseHLSVariant(value)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrParseHLS, name[1:], err)
+		}
+	}
+
+	if variant != nil {
+		return nil, fmt.Errorf("%w: EXT-X-STREAM-INF without URI", ErrParseHLS)
+	}
+
+	return playlist, nil
+}
+
+// ParseHLSMediaPlaylist parses a media playlist. Byte ranges without an offset are resolved to start after the
+// sub-range of the previous segment. Unknown tags are ignored.
+func ParseHLSMediaPlaylist(data []byte) (*HLSMediaPlaylist, error) {
+	lines, err := hlsLines(data)
+	if err != nil {
+		return nil, err
+	}
+
+	playlist := &HLSMediaPlaylist{}
+
+	var (
+		segment                HLSSegment
+		hasDuration, hasOffset bool
+		previousURI            string
+		previousEnd            uint64
+		previousHasByteRange   bool
+	)
+
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "#") {
+			if !hasDuration {
+				return nil, fmt.Errorf("%w: URI %q without EXTINF", ErrParseHLS, line)
+			}
+
+			segment.URI = line
+
+			if segment.ByteRange != nil && !hasOffset {
+				if !previousHasByteRange || previousURI != line {
+					return nil, fmt.Errorf("%w: EXT-X-BYTERANGE of %q without offset", ErrParseHLS, line)
+				}
+
+				segment.ByteRange.Offset = previousEnd
+			}
+
+			previousURI = line
+			previousHasByteRange = segment.ByteRange != nil
+
+			if previousHasByteRange {
+				previousEnd = segment.ByteRange.Offset + segment.ByteRange.Length
+			}
+
+			playlist.Segment = append(playlist.Segment, segment)
+			segment = HLSSegment{}
+			hasDuration = false
+
+			continue
+		}
+
+		name, value, _ := strings.Cut(line, ":")
+
+		switch name {
+		case "#EXT-X-VERSION":
+			playlist.Version, err = strconv.Atoi(value)
+		case "#EXT-X-INDEPENDENT-SEGMENTS":
+			playlist.IndependentSegments = true
+		case "#EXT-X-TARGETDURATION":
+			playlist.TargetDuration, err = strconv.ParseUint(value, 10, 64)
+		case "#EXT-X-MEDIA-SEQUENCE":
+			playlist.MediaSequence, err = strconv.ParseUint(value, 10, 64)
+		case "#EXT-X-DISCONTINUITY-SEQUENCE":
+			playlist.DiscontinuitySequence, err = strconv.ParseUint(value, 10, 64)
+		case "#EXT-X-PLAYLIST-TYPE":
+			playlist.PlaylistType = HLSPlaylistType(value)
+		case "#EXT-X-ENDLIST":
+			playlist.EndList = true
+		case "#EXTINF":
+			duration, _, _ := strings.Cut(value, ",")
+			segment.Duration, err = parseHLSSeconds(duration)
+			hasDuration = true
+		case "#EXT-X-BYTERANGE":
+			segment.ByteRange, hasOffset, err = parseHLSByteRange(value)
+		case "#EXT-X-DISCONTINUITY":
+			segment.Discontinuity = true
+		case "#EXT-X-KEY":
+			var key HLSKey
+			key, err = parseHLSKey(value)
+			segment.Key = append(segment.Key, key)
+		case "#EXT-X-MAP":
+			segment.Map, err = parseHLSMap(value)
+		case "#EXT-X-PROGRAM-DATE-TIME":
+			segment.ProgramDateTime, err = ParseDateTime(value)
+		case "#EXT-X-DATERANGE":
+			var dateRange HLSDateRange
+			dateRange, err = parseHLSDateRange(value)
+			segment.DateRange = append(segment.DateRange, dateRange)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrParseHLS, name[1:], err)
+		}
+	}
+
+	return playlist, nil
+}
+
+// String formats the byte range as <length>@<offset>.
+func (r *HLSByteRange) String() string {
+	return strconv.FormatUint(r.Length, 10) + "@" + strconv.FormatUint(r.Offset, 10)
+}
+
+func (k *HLSKey) attributes() string {
+	var attributes hlsAttributes
+	attributes.enumerated("METHOD", string(k.Method))
+	attributes.quoted("URI", k.URI)
+	attributes.hexadecimal("IV", k.IV)
+
+	if k.KeyID != nil {
+		attributes.hexadecimal("KEYID", k.KeyID[:])
+	}
+
+	attributes.quoted("KEYFORMAT", k.KeyFormat)
+	attributes.quoted("KEYFORMATVERSIONS", k.KeyFormatVersions)
+
+	return attributes.String()
+}
+
+func (d *HLSDateRange) attributes() string {
+	var attributes hlsAttributes
+	attributes.quoted("ID", d.ID)
+	attributes.quoted("CLASS", d.Class)
+	attributes.quoted("START-DATE", FormatDateTime(d.StartDate))
+
+	if !d.EndDate.IsZero() {
+		attributes.quoted("END-DATE", FormatDateTime(d.EndDate))
+	}
+
+	if d.Duration > 0 {
+		attributes.enumerated("DURATION", formatHLSSeconds(d.Duration))
+	}
+
+	if d.PlannedDuration > 0 {
+		attributes.enumerated("PLANNED-DURATION", formatHLSSeconds(d.PlannedDuration))
+	}
+
+	names := make([]string, 0, len(d.ClientAttribute))
+	for name := range d.ClientAttribute {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		attributes.quoted(name, d.ClientAttribute[name])
+	}
+
+	attributes.hexadecimal("SCTE35-CMD", d.SCTE35Cmd)
+	attributes.hexadecimal("SCTE35-OUT", d.SCTE35Out)
+	attributes.hexadecimal("SCTE35-IN", d.SCTE35In)
+	attributes.boolean("END-ON-NEXT", d.EndOnNext)
+
+	return attributes.String()
+}
+
+func parseHLSKey(value string) (HLSKey, error) {
+	list := parseHLSAttributeList(value)
+	key := HLSKey{
+		Method:            HLSKeyMethod(list.values["METHOD"]),
+		URI:               list.values["URI"],
+		IV:                list.hexadecimal("IV"),
+		KeyFormat:         list.values["KEYFORMAT"],
+		KeyFormatVersions: list.values["KEYFORMATVERSIONS"],
+	}
+
+	if keyID := list.hexadecimal("KEYID"); keyID != nil {
+		if len(keyID) != len(UUID{}) {
+			list.fail("KEYID")
+		} else {
+			key.KeyID = &UUID{}
+			copy(key.KeyID[:], keyID)
+		}
+	}
+
+	return key, list.err
+}
+
+func parseHLSMedia(value string) (HLSMedia, error) {
+	list := parseHLSAttributeList(value)
+	media := HLSMedia{
+		Type:       HLSMediaType(list.values["TYPE"]),
+		URI:        list.values["URI"],
+		GroupID:    list.values["GROUP-ID"],
+		Language:   list.values["LANGUAGE"],
+		Name:       list.values["NAME"],
+		Default:    list.boolean("DEFAULT"),
+		Autoselect: list.boolean("AUTOSELECT"),
+		Channels:   list.values["CHANNELS"],
+	}
+
+	return media, list.err
+}
+
+func parseHLSVariant(value string) (*HLSVariant, error) {
+	list := parseHLSAttributeList(value)
+	variant := &HLSVariant{
+		Bandwidth:        list.decimal("BANDWIDTH"),
+		AverageBandwidth: list.decimal("AVERAGE-BANDWIDTH"),
+		Codecs:           list.values["CODECS"],
+		FrameRate:        list.float("FRAME-RATE"),
+		Audio:            list.values["AUDIO"],
+		Subtitles:        list.values["SUBTITLES"],
+	}
+
+	if resolution, ok := list.values["RESOLUTION"]; ok {
+		width, height, _ := strings.Cut(resolution, "x")
+		w, widthErr := strconv.ParseUint(width, 10, 0)
+		h, heightErr := strconv.ParseUint(height, 10, 0)
+
+		if widthErr != nil || heightErr != nil {
+			list.fail("RESOLUTION")
+		}
+
+		variant.Width, variant.Height = uint(w), uint(h)
+	}
+
+	return variant, list.err
+}
+
+func parseHLSMap(value string) (*HLSMap, error) {
+	list := parseHLSAttributeList(value)
+	m := &HLSMap{URI: list.values["URI"]}
+
+	if byteRange, ok := list.values["BYTERANGE"]; ok {
+		var err error
+		if m.ByteRange, _, err = parseHLSByteRange(byteRange); err != nil {
+			list.fail("BYTERANGE")
+		}
+	}
+
+	return m, list.err
+}
+
+func parseHLSDateRange(value string) (HLSDateRange, error) {
+	list := parseHLSAttributeList(value)
+	dateRange := HLSDateRange{
+		ID:              list.values["ID"],
+		Class:           list.values["CLASS"],
+		StartDate:       list.dateTime("START-DATE"),
+		EndDate:         list.dateTime("END-DATE"),
+		Duration:        list.seconds("DURATION"),
+		PlannedDuration: list.seconds("PLANNED-DURATION"),
+		SCTE35Cmd:       list.hexadecimal("SCTE35-CMD"),
+		SCTE35Out:       list.hexadecimal("SCTE35-OUT"),
+		SCTE35In:        list.hexadecimal("SCTE35-IN"),
+		EndOnNext:       list.boolean("END-ON-NEXT"),
+	}
+
+	for name, value := range list.values {
+		if strings.HasPrefix(name, "X-") {
+			if dateRange.ClientAttribute == nil {
+				dateRange.ClientAttribute = map[string]string{}
+			}
+
+			dateRange.ClientAttribute[name] = value
+		}
+	}
+
+	return dateRange, list.err
+}
+
+// parseHLSByteRange parses <length>[@<offset>] and reports whether the offset is present.
+func parseHLSByteRange(value string) (*HLSByteRange, bool, error) {
+	length, offset, hasOffset := strings.Cut(value, "@")
+	byteRange := &HLSByteRange{}
+
+	var err error
+	if byteRange.Length, err = strconv.ParseUint(length, 10, 64); err != nil {
+		return nil, false, err
+	}
+
+	if hasOffset {
+		if byteRange.Offset, err = strconv.ParseUint(offset, 10, 64); err != nil {
+			return nil, false, err
+		}
+	}
+
+	return byteRange, hasOffset, nil
+}
+
+// parseHLSSeconds parses non-negative decimal seconds.
+func parseHLSSeconds(value string) (time.Duration, error) {
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	if seconds < 0 || math.IsInf(seconds, 0) {
+		return 0, fmt.Errorf("invalid seconds %q", value)
+	}
+
+	return time.Duration(math.Round(seconds * float64(time.Second))), nil
+}
+
+// hlsLines checks the EXTM3U header and returns the remaining non-blank lines without comments.
+func hlsLines(data []byte) ([]string, error) {
+	lines := strings.Split(string(data), "\n")
+	if strings.TrimSpace(strings.TrimPrefix(lines[0], "\uFEFF")) != "#EXTM3U" {
+		return nil, fmt.Errorf("%w: missing EXTM3U", ErrParseHLS)
+	}
+
+	result := make([]string, 0, len(lines))
+
+	for _, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "#EXT") {
+			continue
+		}
+
+		result = append(result, line)
+	}
+
+	return result, nil
+}
+
+func writeHLSHeader(builder *strings.Builder, version int, independentSegments bool) {
+	if version > 1 {
+		builder.WriteString("#EXT-X-VERSION:" + strconv.Itoa(version) + "\n")
+	}
+
+	if independentSegments {
+		builder.WriteString("#EXT-X-INDEPENDENT-SEGMENTS\n")
+	}
+}
+
+// formatHLSSeconds formats a duration as decimal seconds without losing precision.
+func formatHLSSeconds(duration time.Duration) string {
+	return strconv.FormatFloat(duration.Seconds(), 'f', -1, 64)
+}
+
+// hlsAttributes builds an attribute list. Empty values are omitted.
+type hlsAttributes []string
+
+func (a *hlsAttributes) enumerated(name, value string) {
+	if value != "" {
+		*a = append(*a, name+"="+value)
+	}
+}
+
+func (a *hlsAttributes) quoted(name, value string) {
+	if value != "" {
+		*a = append(*a, name+`="`+value+`"`)
+	}
+}
+
+func (a *hlsAttributes) decimal(name string, value uint64) {
+	if value > 0 {
+		a.enumerated(name, strconv.FormatUint(value, 10))
+	}
+}
+
+func (a *hlsAttributes) hexadecimal(name string, value []byte) {
+	if len(value) > 0 {
+		a.enumerated(name, "0x"+strings.ToUpper(hex.EncodeToString(value)))
+	}
+}
+
+func (a *hlsAttributes) boolean(name string, value bool) {
+	if value {
+		a.enumerated(name, "YES")
+	}
+}
+
+func (a hlsAttributes) String() string {
+	return strings.Join(a, ",")
+}
+
+// hlsAttributeList is a parsed attribute list. Quoted strings are stored without quotes.
+// The first invalid value is recorded in err.
+type hlsAttributeList struct {
+	values map[string]string
+	err    error
+}
+
+func parseHLSAttributeList(value string) *hlsAttributeList {
+	list := &hlsAttributeList{values: map[string]string{}}
+
+	for value != "" {
+		name, rest, found := strings.Cut(value, "=")
+		if !found || name == "" {
+			list.err = fmt.Errorf("invalid attribute list %q", value)
+			return list
+		}
+
+		end := strings.IndexByte(rest, ',')
+
+		if strings.HasPrefix(rest, `"`) {
+			if end = strings.IndexByte(rest[1:], '"') + 2; end < 2 {
+				list.err = fmt.Errorf("unterminated quoted string in %s", name)
+				return list
+			}
+
+			list.values[name] = rest[1 : end-1]
+		} else {
+			if end < 0 {
+				end = len(rest)
+			}
+
+			list.values[name] = rest[:end]
+		}
+
+		if value = rest[end:]; value != "" && value[0] != ',' {
+			list.err = fmt.Errorf("invalid attribute list %q", rest)
+			return list
+		}
+
+		value = strings.TrimPrefix(value, ",")
+	}
+
+	return list
+}
+
+func (l *hlsAttributeList) fail(name string) {
+	if l.err == nil {
+		l.err = fmt.Errorf("invalid %s %q", name, l.values[name])
+	}
+}
+
+func (l *hlsAttributeList) decimal(name string) uint64 {
+	value, ok := l.values[name]
+	if !ok {
+		return 0
+	}
+
+	decimal, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		l.fail(name)
+	}
+
+	return decimal
+}
+
+func (l *hlsAttributeList) float(name string) float64 {
+	value, ok := l.values[name]
+	if !ok {
+		return 0
+	}
+
+	float, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		l.fail(name)
+	}
+
+	return float
+}
+
+func (l *hlsAttributeList) seconds(name string) time.Duration {
+	value, ok := l.values[name]
+	if !ok {
+		return 0
+	}
+
+	seconds, err := parseHLSSeconds(value)
+	if err != nil {
+		l.fail(name)
+	}
+
+	return seconds
+}
+
+func (l *hlsAttributeList) hexadecimal(name string) []byte {
+	value, ok := l.values[name]
+	if !ok {
+		return nil
+	}
+
+	if len(value) < 2 || value[0] != '0' || value[1] != 'x' && value[1] != 'X' {
+		l.fail(name)
+		return nil
+	}
+
+	decoded, err := hex.DecodeString(value[2:])
+	if err != nil {
+		l.fail(name)
+	}
+
+	return decoded
+}
+
+func (l *hlsAttributeList) dateTime(name string) time.Time {
+	value, ok := l.values[name]
+	if !ok {
+		return time.Time{}
+	}
+
+	dateTime, err := ParseDateTime(value)
+	if err != nil {
+		l.fail(name)
+	}
+
+	return dateTime
+}
+
+func (l *hlsAttributeList) boolean(name string) bool {
+	return l.values[name] == "YES"
+}
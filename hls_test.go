@@ -0,0 +1,295 @@
+package mpd_test
+
+import (
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"go.eigsys.de/go-mpd"
+	"testing"
+	"time"
+)
+
+func TestHLSMultivariantPlaylist_Bytes(t *testing.T) {
+	kid := mustParseUUID("08e36702-8f33-436c-a5dd-60ffe5571e60")
+	playlist := &mpd.HLSMultivariantPlaylist{
+		Version:             6,
+		IndependentSegments: true,
+		SessionKey: []mpd.HLSKey{
+			{Method: mpd.SampleAESHLSKeyMethod, URI: "skd://key", KeyID: &kid, KeyFormat: "com.apple.streamingkeydelivery", KeyFormatVersions: "1"},
+		},
+		Media: []mpd.HLSMedia{
+			{Type: mpd.AudioHLSMediaType, URI: "audio.m3u8", GroupID: "audio", Language: "en", Name: "English", Default: true, Autoselect: true, Channels: "2"},
+			{Type: mpd.SubtitlesHLSMediaType, URI: "subtitles.m3u8", GroupID: "subtitles", Name: "German"},
+		},
+		Variant: []mpd.HLSVariant{
+			{URI: "video.m3u8", Bandwidth: 2000000, AverageBandwidth: 1500000, Codecs: "avc1.640028,mp4a.40.2", Width: 1280, Height: 720, FrameRate: 30000.0 / 1001, Audio: "audio", Subtitles: "subtitles"},
+			{URI: "audio.m3u8", Bandwidth: 128000},
+		},
+	}
+
+	want := `#EXTM3U
+#EXT-X-VERSION:6
+#EXT-X-INDEPENDENT-SEGMENTS
+#EXT-X-SESSION-KEY:METHOD=SAMPLE-AES,URI="skd://key",KEYID=0x08E367028F33436CA5DD60FFE5571E60,KEYFORMAT="com.apple.streamingkeydelivery",KEYFORMATVERSIONS="1"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="audio",LANGUAGE="en",NAME="English",DEFAULT=YES,AUTOSELECT=YES,CHANNELS="2",URI="audio.m3u8"
+#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subtitles",NAME="German",URI="subtitles.m3u8"
+#EXT-X-STREAM-INF:BANDWIDTH=2000000,AVERAGE-BANDWIDTH=1500000,CODECS="avc1.640028,mp4a.40.2",RESOLUTION=1280x720,FRAME-RATE=29.970,AUDIO="audio",SUBTITLES="subtitles"
+video.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=128000
+audio.m3u8
+`
+
+	if diff := cmp.Diff(string(playlist.Bytes()), want); diff != "" {
+		t.Errorf("wrong playlist: %s", diff)
+	}
+}
+
+func TestHLSMediaPlaylist_Bytes(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	playlist := &mpd.HLSMediaPlaylist{
+		Version:               6,
+		TargetDuration:        4,
+		MediaSequence:         100,
+		DiscontinuitySequence: 2,
+		PlaylistType:          mpd.EventHLSPlaylistType,
+		Segment: []mpd.HLSSegment{
+			{
+				URI:             "video.mp4",
+				Duration:        3840 * time.Millisecond,
+				ByteRange:       &mpd.HLSByteRange{Length: 1000, Offset: 800},
+				Key:             []mpd.HLSKey{{Method: mpd.AES128HLSKeyMethod, URI: "key.bin", IV: []byte{0xab, 0xcd}}},
+				Map:             &mpd.HLSMap{URI: "video.mp4", ByteRange: &mpd.HLSByteRange{Length: 800}},
+				ProgramDateTime: start,
+				DateRange: []mpd.HLSDateRange{{
+					ID:              "break-1",
+					Class:           "com.example.ad",
+					StartDate:       start,
+					EndDate:         start.Add(30 * time.Second),
+					Duration:        30 * time.Second,
+					PlannedDuration: 30 * time.Second,
+					ClientAttribute: map[string]string{"X-B": "2", "X-A": "1"},
+					SCTE35Out:       []byte{0xfc, 0x30},
+				}},
+			},
+			{
+				URI:           "next.mp4",
+				Duration:      4 * time.Second,
+				Discontinuity: true,
+				Key:           []mpd.HLSKey{{Method: mpd.NoneHLSKeyMethod}},
+				DateRange: []mpd.HLSDateRange{
+					{ID: "cmd", StartDate: start, SCTE35Cmd: []byte{1}, EndOnNext: true},
+					{ID: "in", StartDate: start, SCTE35In: []byte{2}},
+				},
+			},
+		},
+		EndList: true,
+	}
+
+	want := `#EXTM3U
+#EXT-X-VERSION:6
+#EXT-X-TARGETDURATION:4
+#EXT-X-MEDIA-SEQUENCE:100
+#EXT-X-DISCONTINUITY-SEQUENCE:2
+#EXT-X-PLAYLIST-TYPE:EVENT
+#EXT-X-KEY:METHOD=AES-128,URI="key.bin",IV=0xABCD
+#EXT-X-MAP:URI="video.mp4",BYTERANGE="800@0"
+#EXT-X-PROGRAM-DATE-TIME:2024-01-02T03:04:05Z
+#EXT-X-DATERANGE:ID="break-1",CLASS="com.example.ad",START-DATE="2024-01-02T03:04:05Z",END-DATE="2024-01-02T03:04:35Z",DURATION=30,PLANNED-DURATION=30,X-A="1",X-B="2",SCTE35-OUT=0xFC30
+#EXTINF:3.84,
+#EXT-X-BYTERANGE:1000@800
+video.mp4
+#EXT-X-DISCONTINUITY
+#EXT-X-KEY:METHOD=NONE
+#EXT-X-DATERANGE:ID="cmd",START-DATE="2024-01-02T03:04:05Z",SCTE35-CMD=0x01,END-ON-NEXT=YES
+#EXT-X-DATERANGE:ID="in",START-DATE="2024-01-02T03:04:05Z",SCTE35-IN=0x02
+#EXTINF:4,
+next.mp4
+#EXT-X-ENDLIST
+`
+
+	if diff := cmp.Diff(string(playlist.Bytes()), want); diff != "" {
+		t.Errorf("wrong playlist: %s", diff)
+	}
+}
+
+func TestParseHLSMultivariantPlaylist(t *testing.T) {
+	data := "\uFEFF#EXTM3U\r\n" + `#EXT-X-VERSION:6
+#EXT-X-INDEPENDENT-SEGMENTS
+# comment
+#EXT-X-SESSION-KEY:METHOD=SAMPLE-AES,URI="skd://key",KEYID=0x08E367028F33436CA5DD60FFE5571E60,KEYFORMAT="com.apple.streamingkeydelivery",KEYFORMATVERSIONS="1"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="audio",LANGUAGE="en",NAME="English, US",DEFAULT=YES,AUTOSELECT=YES,CHANNELS="2",URI="audio.m3u8"
+#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subtitles",NAME="German",URI="subtitles.m3u8"
+#EXT-X-STREAM-INF:BANDWIDTH=2000000,AVERAGE-BANDWIDTH=1500000,CODECS="avc1.640028,mp4a.40.2",RESOLUTION=1280x720,FRAME-RATE=29.970,AUDIO="audio",SUBTITLES="subtitles"
+
+video.m3u8
+#EXT-X-UNKNOWN:FOO=BAR
+#EXT-X-STREAM-INF:BANDWIDTH=128000
+audio.m3u8
+`
+
+	playlist, err := mpd.ParseHLSMultivariantPlaylist([]byte(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	kid := mustParseUUID("08e36702-8f33-436c-a5dd-60ffe5571e60")
+	want := &mpd.HLSMultivariantPlaylist{
+		Version:             6,
+		IndependentSegments: true,
+		SessionKey: []mpd.HLSKey{
+			{Method: mpd.SampleAESHLSKeyMethod, URI: "skd://key", KeyID: &kid, KeyFormat: "com.apple.streamingkeydelivery", KeyFormatVersions: "1"},
+		},
+		Media: []mpd.HLSMedia{
+			{Type: mpd.AudioHLSMediaType, URI: "audio.m3u8", GroupID: "audio", Language: "en", Name: "English, US", Default: true, Autoselect: true, Channels: "2"},
+			{Type: mpd.SubtitlesHLSMediaType, URI: "subtitles.m3u8", GroupID: "subtitles", Name: "German"},
+		},
+		Variant: []mpd.HLSVariant{
+			{URI: "video.m3u8", Bandwidth: 2000000, AverageBandwidth: 1500000, Codecs: "avc1.640028,mp4a.40.2", Width: 1280, Height: 720, FrameRate: 29.97, Audio: "audio", Subtitles: "subtitles"},
+			{URI: "audio.m3u8", Bandwidth: 128000},
+		},
+	}
+
+	if diff := cmp.Diff(playlist, want); diff != "" {
+		t.Errorf("wrong playlist: %s", diff)
+	}
+}
+
+func TestParseHLSMediaPlaylist(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	want := &mpd.HLSMediaPlaylist{
+		Version:               6,
+		TargetDuration:        4,
+		MediaSequence:         100,
+		DiscontinuitySequence: 2,
+		PlaylistType:          mpd.EventHLSPlaylistType,
+		IndependentSegments:   true,
+		Segment: []mpd.HLSSegment{
+			{
+				URI:             "video.mp4",
+				Duration:        3840 * time.Millisecond,
+				ByteRange:       &mpd.HLSByteRange{Length: 1000, Offset: 800},
+				Key:             []mpd.HLSKey{{Method: mpd.AES128HLSKeyMethod, URI: "key.bin", IV: []byte{0xab, 0xcd}}},
+				Map:             &mpd.HLSMap{URI: "video.mp4", ByteRange: &mpd.HLSByteRange{Length: 800}},
+				ProgramDateTime: start,
+				DateRange: []mpd.HLSDateRange{{
+					ID:              "break-1",
+					Class:           "com.example.ad",
+					StartDate:       start,
+					EndDate:         start.Add(30 * time.Second),
+					Duration:        30 * time.Second,
+					PlannedDuration: 30 * time.Second,
+					ClientAttribute: map[string]string{"X-B": "2", "X-A": "1"},
+					SCTE35Out:       []byte{0xfc, 0x30},
+				}},
+			},
+			{
+				URI:       "video.mp4",
+				Duration:  4 * time.Second,
+				ByteRange: &mpd.HLSByteRange{Length: 500, Offset: 1800},
+			},
+			{
+				URI:           "next.mp4",
+				Duration:      4 * time.Second,
+				Discontinuity: true,
+				Key:           []mpd.HLSKey{{Method: mpd.NoneHLSKeyMethod}},
+				DateRange: []mpd.HLSDateRange{
+					{ID: "cmd", StartDate: start, SCTE35Cmd: []byte{1}, EndOnNext: true},
+					{ID: "in", StartDate: start, SCTE35In: []byte{2}},
+				},
+			},
+		},
+		EndList: true,
+	}
+
+	data := `#EXTM3U
+#EXT-X-VERSION:6
+#EXT-X-INDEPENDENT-SEGMENTS
+#EXT-X-TARGETDURATION:4
+#EXT-X-MEDIA-SEQUENCE:100
+#EXT-X-DISCONTINUITY-SEQUENCE:2
+#EXT-X-PLAYLIST-TYPE:EVENT
+#EXT-X-KEY:METHOD=AES-128,URI="key.bin",IV=0xABCD
+#EXT-X-MAP:URI="video.mp4",BYTERANGE="800@0"
+#EXT-X-PROGRAM-DATE-TIME:2024-01-02T03:04:05Z
+#EXT-X-DATERANGE:ID="break-1",CLASS="com.example.ad",START-DATE="2024-01-02T03:04:05Z",END-DATE="2024-01-02T03:04:35Z",DURATION=30,PLANNED-DURATION=30,X-A="1",X-B="2",SCTE35-OUT=0xFC30
+#EXTINF:3.84,
+#EXT-X-BYTERANGE:1000@800
+video.mp4
+#EXTINF:4,title
+#EXT-X-BYTERANGE:500
+video.mp4
+#EXT-X-DISCONTINUITY
+#EXT-X-KEY:METHOD=NONE
+#EXT-X-DATERANGE:ID="cmd",START-DATE="2024-01-02T03:04:05Z",SCTE35-CMD=0x01,END-ON-NEXT=YES
+#EXT-X-DATERANGE:ID="in",START-DATE="2024-01-02T03:04:05Z",SCTE35-IN=0x02
+#EXTINF:4,
+next.mp4
+#EXT-X-ENDLIST
+`
+
+	playlist, err := mpd.ParseHLSMediaPlaylist([]byte(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(playlist, want); diff != "" {
+		t.Errorf("wrong playlist: %s", diff)
+	}
+
+	if playlist, err = mpd.ParseHLSMediaPlaylist(want.Bytes()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(playlist, want); diff != "" {
+		t.Errorf("wrong round trip: %s", diff)
+	}
+}
+
+func TestParseHLS_Errors(t *testing.T) {
+	multivariant := map[string]string{
+		"missing header":      "#EXT-X-VERSION:6\n",
+		"invalid version":     "#EXTM3U\n#EXT-X-VERSION:six\n",
+		"URI without tag":     "#EXTM3U\nvideo.m3u8\n",
+		"missing URI":         "#EXTM3U\n#EXT-X-STREAM-INF:BANDWIDTH=1\n",
+		"invalid bandwidth":   "#EXTM3U\n#EXT-X-STREAM-INF:BANDWIDTH=fast\nvideo.m3u8\n",
+		"invalid resolution":  "#EXTM3U\n#EXT-X-STREAM-INF:BANDWIDTH=1,RESOLUTION=HD\nvideo.m3u8\n",
+		"invalid frame rate":  "#EXTM3U\n#EXT-X-STREAM-INF:BANDWIDTH=1,FRAME-RATE=x\nvideo.m3u8\n",
+		"invalid attributes":  "#EXTM3U\n#EXT-X-MEDIA:TYPE\n",
+		"unterminated quote":  "#EXTM3U\n#EXT-X-MEDIA:NAME=\"English\n",
+		"garbage after quote": "#EXTM3U\n#EXT-X-MEDIA:NAME=\"English\"x\n",
+		"invalid KEYID":       "#EXTM3U\n#EXT-X-SESSION-KEY:METHOD=AES-128,KEYID=0x01\n",
+		"invalid IV":          "#EXTM3U\n#EXT-X-SESSION-KEY:METHOD=AES-128,IV=ABCD\n",
+		"invalid hex":         "#EXTM3U\n#EXT-X-SESSION-KEY:METHOD=AES-128,IV=0xZZ\n",
+	}
+
+	for name, data := range multivariant {
+		if _, err := mpd.ParseHLSMultivariantPlaylist([]byte(data)); !errors.Is(err, mpd.ErrParseHLS) {
+			t.Errorf("%s: wrong error: %v", name, err)
+		}
+	}
+
+	media := map[string]string{
+		"missing header":              "",
+		"URI without EXTINF":          "#EXTM3U\nseg.mp4\n",
+		"invalid target duration":     "#EXTM3U\n#EXT-X-TARGETDURATION:x\n",
+		"invalid sequence":            "#EXTM3U\n#EXT-X-MEDIA-SEQUENCE:-1\n",
+		"invalid discontinuity":       "#EXTM3U\n#EXT-X-DISCONTINUITY-SEQUENCE:x\n",
+		"invalid duration":            "#EXTM3U\n#EXTINF:x,\n",
+		"negative duration":           "#EXTM3U\n#EXTINF:-1,\n",
+		"infinite duration":           "#EXTM3U\n#EXTINF:Inf,\n",
+		"invalid byte range":          "#EXTM3U\n#EXT-X-BYTERANGE:x\n",
+		"invalid offset":              "#EXTM3U\n#EXT-X-BYTERANGE:1@x\n",
+		"byte range without offset":   "#EXTM3U\n#EXTINF:1,\n#EXT-X-BYTERANGE:1\nseg.mp4\n",
+		"byte range of other URI":     "#EXTM3U\n#EXTINF:1,\n#EXT-X-BYTERANGE:1@0\na.mp4\n#EXTINF:1,\n#EXT-X-BYTERANGE:1\nb.mp4\n",
+		"invalid key":                 "#EXTM3U\n#EXT-X-KEY:METHOD\n",
+		"invalid map":                 "#EXTM3U\n#EXT-X-MAP:URI=\"init.mp4\",BYTERANGE=\"x\"\n",
+		"invalid date time":           "#EXTM3U\n#EXT-X-PROGRAM-DATE-TIME:yesterday\n",
+		"invalid start date":          "#EXTM3U\n#EXT-X-DATERANGE:ID=\"a\",START-DATE=\"yesterday\"\n",
+		"invalid date range duration": "#EXTM3U\n#EXT-X-DATERANGE:ID=\"a\",DURATION=x\n",
+	}
+
+	for name, data := range media {
+		if _, err := mpd.ParseHLSMediaPlaylist([]byte(data)); !errors.Is(err, mpd.ErrParseHLS) {
+			t.Errorf("%s: wrong error: %v", name, err)
+		}
+	}
+}
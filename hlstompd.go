@@ -0,0 +1,528 @@
+package mpd
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrConvertFromHLS = errors.New("cannot convert HLS to MPD")
+
+// hlsImportTimescale is the timescale of the SegmentTimelines generated from EXTINF durations.
+const hlsImportTimescale = 90000
+
+// HLSImportOptions configure ConvertFromHLS.
+type HLSImportOptions struct {
+	// AvailabilityStartTime anchors a live presentation. It defaults to the EXT-X-PROGRAM-DATE-TIME of the first
+	// segment, which moves with the live window. Pass the value of a previous conversion to keep segment times
+	// stable across playlist refreshes.
+	AvailabilityStartTime time.Time
+}
+
+// ConvertFromHLS converts a multivariant playlist and its media playlists, keyed by their URIs in the multivariant
+// playlist, to an MPD. Variant streams become video Representations, EXT-X-MEDIA audio and subtitles renditions are
+// grouped into AdaptationSets by language, name and codecs, and discontinuities start new Periods. Segments that only
+// differ by a consecutive number are addressed with a SegmentTemplate, all others with a SegmentList whose SegmentURLs
+// carry the EXT-X-BYTERANGE as mediaRange. Both use a SegmentTimeline and EXT-X-MAP as initialization, while a single
+// subtitles file becomes a BaseURL. Playlists without EXT-X-ENDLIST result in a dynamic MPD that is timed by
+// EXT-X-PROGRAM-DATE-TIME. Audio and video must be fragmented MP4; the options may be nil.
+func ConvertFromHLS(playlists *HLSPlaylists, options *HLSImportOptions) (*MPD, error) {
+	if playlists == nil || playlists.Multivariant == nil {
+		return nil, fmt.Errorf("%w: missing multivariant playlist", ErrConvertFromHLS)
+	}
+
+	importer := &hlsImporter{playlists: playlists, trackIndex: map[string]*hlsImportTrack{}, ids: map[string]bool{}}
+	if options != nil {
+		importer.availabilityStartTime = options.AvailabilityStartTime
+	}
+
+	importer.collect()
+
+	if len(importer.tracks) == 0 {
+		return nil, fmt.Errorf("%w: no variant streams or renditions", ErrConvertFromHLS)
+	}
+
+	reference := importer.tracks[0].representations[0]
+	if reference.playlist = playlists.Media[reference.uri]; reference.playlist == nil {
+		return nil, fmt.Errorf("%w: missing media playlist %q", ErrConvertFromHLS, reference.uri)
+	}
+
+	importer.live = !reference.playlist.EndList
+
+	for _, track := range importer.tracks {
+		for _, representation := range track.representations {
+			if representation.playlist = playlists.Media[representation.uri]; representation.playlist == nil {
+				return nil, fmt.Errorf("%w: missing media playlist %q", ErrConvertFromHLS, representation.uri)
+			}
+
+			if err := importer.split(track, representation); err != nil {
+				return nil, errors.Join(ErrConvertFromHLS, err)
+			}
+
+			if len(representation.periods) != len(reference.periods) {
+				return nil, fmt.Errorf("%w: media playlist %q has %d discontinuities instead of %d",
+					ErrConvertFromHLS, representation.uri, len(representation.periods)-1, len(reference.periods)-1)
+			}
+		}
+	}
+
+	return importer.mpd(reference), nil
+}
+
+type hlsImporter struct {
+	playlists             *HLSPlaylists
+	live                  bool
+	availabilityStartTime time.Time
+	tracks                []*hlsImportTrack
+	trackIndex            map[string]*hlsImportTrack
+	ids                   map[string]bool
+}
+
+// hlsImportTrack is an AdaptationSet that is repeated in every Period.
+type hlsImportTrack struct {
+	adaptationSet   AdaptationSet
+	representations []*hlsImportRepresentation
+}
+
+type hlsImportRepresentation struct {
+	representation Representation
+	uri            string
+	playlist       *HLSMediaPlaylist
+	periods        [][]hlsImportSegment
+}
+
+// hlsImportSegment is a media segment with resolved URIs and its offset to the start of the presentation, which is
+// the AvailabilityStartTime for live presentations.
+type hlsImportSegment struct {
+	uri       string
+	byteRange *HLSByteRange
+	mapping   *HLSMap
+	offset    time.Duration
+	duration  time.Duration
+}
+
+// collect creates the tracks and Representations from the multivariant playlist.
+func (c *hlsImporter) collect() {
+	multivariant := c.playlists.Multivariant
+	groupCodecs := map[HLSMediaType]map[string]string{AudioHLSMediaType: {}, SubtitlesHLSMediaType: {}}
+	seen := map[string]bool{}
+
+	for _, variant := range multivariant.Variant {
+		codecs := hlsSplitCodecs(variant.Codecs)
+
+		if _, ok := groupCodecs[AudioHLSMediaType][variant.Audio]; !ok {
+			groupCodecs[AudioHLSMediaType][variant.Audio] = codecs[AudioContentType]
+		}
+
+		if _, ok := groupCodecs[SubtitlesHLSMediaType][variant.Subtitles]; !ok {
+			groupCodecs[SubtitlesHLSMediaType][variant.Subtitles] = codecs[SubtitlesContentType]
+		}
+
+		if seen[variant.URI] {
+			continue
+		}
+
+		seen[variant.URI] = true
+
+		contentType, representation := VideoContentType, Representation{Bandwidth: uint(variant.Bandwidth)}
+		representation.Codecs = Codecs(codecs[VideoContentType])
+
+		if codecs[VideoContentType] == "" && codecs[AudioContentType] != "" {
+			contentType = AudioContentType
+			representation.Codecs = Codecs(codecs[AudioContentType])
+		} else {
+			representation.Width, representation.Height = variant.Width, variant.Height
+			representation.FrameRate = hlsFrameRate(variant.FrameRate)
+		}
+
+		fourCC, _, _ := strings.Cut(string(representation.Codecs), ".")
+		track := c.track(string(contentType)+"\x00"+fourCC, AdaptationSet{ContentType: contentType})
+		c.add(track, variant.URI, representation)
+	}
+
+	for _, mediaType := range []HLSMediaType{AudioHLSMediaType, SubtitlesHLSMediaType} {
+		for _, media := range multivariant.Media {
+			if media.Type != mediaType || media.URI == "" || seen[media.URI] {
+				continue
+			}
+
+			seen[media.URI] = true
+
+			adaptationSet := AdaptationSet{ContentType: AudioContentType, Lang: media.Language}
+			if mediaType == SubtitlesHLSMediaType {
+				adaptationSet.ContentType = SubtitlesContentType
+			}
+
+			if media.Name != "" {
+				adaptationSet.Label = []Label{{Value: media.Name}}
+			}
+
+			codecs := groupCodecs[mediaType][media.GroupID]
+			track := c.track(strings.Join([]string{string(adaptationSet.ContentType), media.Language, media.Name, codecs}, "\x00"), adaptationSet)
+
+			if media.Default && len(track.adaptationSet.Role) == 0 {
+				track.adaptationSet.Role = []Descriptor{{SchemeIDURI: Role2011SchemeIDURI, Value: "main"}}
+			}
+
+			representation := Representation{}
+			representation.Codecs = Codecs(codecs)
+
+			if channels, _, _ := strings.Cut(media.Channels, "/"); channels != "" {
+				representation.AudioChannelConfiguration = []*Descriptor{{SchemeIDURI: AudioChannelConfiguration2011SchemeIDURI, Value: channels}}
+			}
+
+			c.add(track, media.URI, representation)
+		}
+	}
+}
+
+func (c *hlsImporter) track(key string, adaptationSet AdaptationSet) *hlsImportTrack {
+	track, ok := c.trackIndex[key]
+	if !ok {
+		adaptationSet.ID = uint(len(c.tracks) + 1)
+		adaptationSet.SegmentAlignment = true
+
+		if c.playlists.Multivariant.IndependentSegments {
+			adaptationSet.StartWithSAP = 1
+		}
+
+		track = &hlsImportTrack{adaptationSet: adaptationSet}
+		c.trackIndex[key] = track
+		c.tracks = append(c.tracks, track)
+	}
+
+	return track
+}
+
+// add adds a Representation with an ID derived from the media playlist URI.
+func (c *hlsImporter) add(track *hlsImportTrack, uri string, representation Representation) {
+	name := path.Base(strings.SplitN(uri, "?", 2)[0])
+	id := strings.TrimSuffix(name, path.Ext(name))
+
+	for i := 2; id == "" || id == "." || c.ids[id]; i++ {
+		id = strings.TrimSuffix(name, path.Ext(name)) + "-" + strconv.Itoa(i)
+	}
+
+	c.ids[id] = true
+	representation.ID = id
+	track.representations = append(track.representations, &hlsImportRepresentation{representation: representation, uri: uri})
+}
+
+// split resolves the segments of a media playlist and splits them at discontinuities.
+func (c *hlsImporter) split(track *hlsImportTrack, representation *hlsImportRepresentation) error {
+	playlist := representation.playlist
+	if len(playlist.Segment) == 0 {
+		return fmt.Errorf("media playlist %q has no segments", representation.uri)
+	}
+
+	var offset time.Duration
+
+	if c.live {
+		programDateTime, err := hlsFirstProgramDateTime(playlist)
+		if err != nil {
+			return fmt.Errorf("media playlist %q: %w", representation.uri, err)
+		}
+
+		if c.availabilityStartTime.IsZero() {
+			c.availabilityStartTime = programDateTime
+		}
+
+		if offset = programDateTime.Sub(c.availabilityStartTime); offset < 0 {
+			return fmt.Errorf("media playlist %q starts before the AvailabilityStartTime", representation.uri)
+		}
+	}
+
+	var mapping *HLSMap
+
+	for i, segment := range playlist.Segment {
+		if c.live && !segment.ProgramDateTime.IsZero() {
+			offset = segment.ProgramDateTime.Sub(c.availabilityStartTime)
+		}
+
+		if segment.Map != nil {
+			mapping = &HLSMap{URI: resolveURL(representation.uri, segment.Map.URI), ByteRange: segment.Map.ByteRange}
+		}
+
+		if mapping == nil && track.adaptationSet.ContentType != SubtitlesContentType {
+			return fmt.Errorf("media playlist %q has segments without EXT-X-MAP", representation.uri)
+		}
+
+		if i == 0 || segment.Discontinuity {
+			representation.periods = append(representation.periods, nil)
+		}
+
+		last := len(representation.periods) - 1
+		representation.periods[last] = append(representation.periods[last], hlsImportSegment{
+			uri:       resolveURL(representation.uri, segment.URI),
+			byteRange: segment.ByteRange,
+			mapping:   mapping,
+			offset:    offset,
+			duration:  segment.Duration,
+		})
+
+		offset += segment.Duration
+	}
+
+	return nil
+}
+
+// hlsFirstProgramDateTime extrapolates the EXT-X-PROGRAM-DATE-TIME of the first segment.
+func hlsFirstProgramDateTime(playlist *HLSMediaPlaylist) (time.Time, error) {
+	var elapsed time.Duration
+
+	for _, segment := range playlist.Segment {
+		if !segment.ProgramDateTime.IsZero() {
+			return segment.ProgramDateTime.Add(-elapsed), nil
+		}
+
+		elapsed += segment.Duration
+	}
+
+	return time.Time{}, errors.New("live media playlist without EXT-X-PROGRAM-DATE-TIME")
+}
+
+// mpd assembles the MPD. Period boundaries and the presentation timing follow the reference Representation.
+func (c *hlsImporter) mpd(reference *hlsImportRepresentation) *MPD {
+	var maxSegmentDuration time.Duration
+
+	for _, period := range reference.periods {
+		for _, segment := range period {
+			if segment.duration > maxSegmentDuration {
+				maxSegmentDuration = segment.duration
+			}
+		}
+	}
+
+	if targetDuration := time.Duration(reference.playlist.TargetDuration) * time.Second; targetDuration > maxSegmentDuration {
+		maxSegmentDuration = targetDuration
+	}
+
+	mpd := New()
+	mpd.Profiles = Live2011Profile
+	mpd.Type = StaticPresentationType
+	mpd.MinBufferTime = FormatDuration(maxSegmentDuration)
+	mpd.MaxSegmentDuration = FormatDuration(maxSegmentDuration)
+
+	first := reference.periods[0][0]
+	lastPeriod := reference.periods[len(reference.periods)-1]
+	last := lastPeriod[len(lastPeriod)-1]
+
+	if c.live {
+		mpd.Type = DynamicPresentationType
+		mpd.AvailabilityStartTime = FormatDateTime(c.availabilityStartTime)
+		mpd.MinimumUpdatePeriod = FormatDuration(maxSegmentDuration)
+		mpd.TimeShiftBufferDepth = FormatDuration(last.offset + last.duration - first.offset)
+	} else {
+		mpd.MediaPresentationDuration = FormatDuration(last.offset + last.duration)
+	}
+
+	for i, segments := range reference.periods {
+		start := segments[0].offset
+		period := Period{ID: strconv.FormatUint(reference.playlist.DiscontinuitySequence+uint64(i), 10), Start: FormatDuration(start)}
+
+		for _, track := range c.tracks {
+			adaptationSet := track.adaptationSet
+
+			for _, representation := range track.representations {
+				r := representation.representation
+				segments := representation.periods[i]
+				r.MIMEType = hlsMIMEType(adaptationSet.ContentType, segments[0].mapping != nil)
+
+				if len(segments) == 1 && segments[0].mapping == nil && segments[0].byteRange == nil {
+					r.BaseURL = []BaseURL{{Value: segments[0].uri}}
+				} else {
+					r.SegmentTemplate, r.SegmentList = hlsSegmentAddressing(segments, durationTicks(start, hlsImportTimescale))
+				}
+				adaptationSet.Representation = append(adaptationSet.Representation, r)
+			}
+
+			if mimeType := adaptationSet.Representation[0].MIMEType; hlsSameMIMEType(adaptationSet.Representation, mimeType) {
+				adaptationSet.MIMEType = mimeType
+
+				for j := range adaptationSet.Representation {
+					adaptationSet.Representation[j].MIMEType = ""
+				}
+			}
+
+			period.AdaptationSet = append(period.AdaptationSet, adaptationSet)
+		}
+
+		mpd.Period = append(mpd.Period, period)
+	}
+
+	return mpd
+}
+
+func hlsSameMIMEType(representations []Representation, mimeType MIMEType) bool {
+	for _, representation := range representations {
+		if representation.MIMEType != mimeType {
+			return false
+		}
+	}
+
+	return true
+}
+
+func hlsMIMEType(contentType ContentType, fragmented bool) MIMEType {
+	switch {
+	case contentType == VideoContentType:
+		return VideoMP4MIMEType
+	case contentType == AudioContentType:
+		return AudioMP4MIMEType
+	case fragmented:
+		return ApplicationMP4MIMEType
+	default:
+		return TextVTTMIMEType
+	}
+}
+
+// hlsSegmentAddressing creates a SegmentTemplate if the segment URIs only differ by a consecutive number,
+// or a SegmentList otherwise.
+func hlsSegmentAddressing(segments []hlsImportSegment, presentationTimeOffset uint64) (*SegmentTemplate, *SegmentList) {
+	base := MultipleSegmentBase{SegmentTimeline: &SegmentTimeline{}}
+	base.Timescale = hlsImportTimescale
+	base.PresentationTimeOffset = presentationTimeOffset
+	timeline := newTimelineBuilder(base.SegmentTimeline)
+
+	for _, segment := range segments {
+		start := durationTicks(segment.offset, hlsImportTimescale)
+		timeline.append(start, durationTicks(segment.offset+segment.duration, hlsImportTimescale)-start)
+	}
+
+	var initialization string
+
+	if mapping := segments[0].mapping; mapping != nil {
+		initialization = mapping.URI
+
+		if mapping.ByteRange != nil {
+			base.Initialization = &URL{SourceURL: mapping.URI, Range: hlsRange(mapping.ByteRange)}
+			initialization = ""
+		}
+	}
+
+	if media, startNumber, ok := hlsMediaTemplate(segments); ok {
+		base.StartNumber = uint(startNumber)
+		return &SegmentTemplate{MultipleSegmentBase: base, Media: media, Initialization: initialization}, nil
+	}
+
+	if initialization != "" {
+		base.Initialization = &URL{SourceURL: initialization}
+	}
+
+	list := &SegmentList{MultipleSegmentBase: base}
+
+	for _, segment := range segments {
+		segmentURL := SegmentURL{Media: segment.uri}
+		if segment.byteRange != nil {
+			segmentURL.MediaRange = hlsRange(segment.byteRange)
+		}
+
+		list.SegmentURL = append(list.SegmentURL, segmentURL)
+	}
+
+	return nil, list
+}
+
+func hlsRange(byteRange *HLSByteRange) SingleRFC7233Range {
+	return NewSingleRFC7233Range(byteRange.Offset, byteRange.Offset+byteRange.Length-1)
+}
+
+var hlsNumberPattern = regexp.MustCompile(`[0-9]+`)
+
+// hlsMediaTemplate derives a $Number$ media template from a number in the first segment URI, trying the last one first
+// and skipping the file extension. Numbers with leading zeros result in a width format tag. The start number must be
+// positive because a startNumber of 0 cannot be represented.
+func hlsMediaTemplate(segments []hlsImportSegment) (string, uint64, bool) {
+	first := segments[0].uri
+	name := strings.SplitN(first, "?", 2)[0]
+	extension := len(name) - len(path.Ext(name))
+	matches := hlsNumberPattern.FindAllStringIndex(first, -1)
+
+	for i := len(matches) - 1; i >= 0; i-- {
+		if matches[i][0] >= extension && matches[i][0] < len(name) {
+			continue
+		}
+
+		prefix, digits, suffix := first[:matches[i][0]], first[matches[i][0]:matches[i][1]], first[matches[i][1]:]
+
+		startNumber, err := strconv.ParseUint(digits, 10, 64)
+		if err != nil || startNumber == 0 {
+			continue
+		}
+
+		format, identifier := "%d", "$Number$"
+		if len(digits) > 1 && digits[0] == '0' {
+			format, identifier = "%0"+strconv.Itoa(len(digits))+"d", "$Number%0"+strconv.Itoa(len(digits))+"d$"
+		}
+
+		if hlsMatchesTemplate(segments, prefix, format, suffix, startNumber) {
+			escape := strings.NewReplacer("$", "$$")
+			return escape.Replace(prefix) + identifier + escape.Replace(suffix), startNumber, true
+		}
+	}
+
+	return "", 0, false
+}
+
+func hlsMatchesTemplate(segments []hlsImportSegment, prefix, format, suffix string, startNumber uint64) bool {
+	for i, segment := range segments {
+		if segment.byteRange != nil || segment.uri != prefix+fmt.Sprintf(format, startNumber+uint64(i))+suffix {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hlsSplitCodecs splits the CODECS attribute by content type.
+func hlsSplitCodecs(codecs string) map[ContentType]string {
+	split := map[ContentType][]string{}
+
+	for _, codec := range strings.Split(codecs, ",") {
+		if codec = strings.TrimSpace(codec); codec != "" {
+			contentType := hlsCodecContentType(codec)
+			split[contentType] = append(split[contentType], codec)
+		}
+	}
+
+	result := map[ContentType]string{}
+	for contentType, list := range split {
+		result[contentType] = strings.Join(list, ",")
+	}
+
+	return result
+}
+
+func hlsCodecContentType(codec string) ContentType {
+	fourCC, _, _ := strings.Cut(codec, ".")
+
+	switch strings.ToLower(fourCC) {
+	case "mp4a", "ac-3", "ec-3", "ac-4", "opus", "flac", "alac", "mha1", "mhm1", "dtsc", "dtse", "dtsh", "dtsl", "dtsx":
+		return AudioContentType
+	case "wvtt", "stpp":
+		return SubtitlesContentType
+	default:
+		return VideoContentType
+	}
+}
+
+// hlsFrameRate converts a decimal frame rate, e.g. 29.970 to 30000/1001.
+func hlsFrameRate(frameRate float64) FrameRate {
+	const tolerance = 0.001
+
+	switch {
+	case frameRate <= 0:
+		return ""
+	case math.Abs(frameRate-math.Round(frameRate)) < tolerance:
+		return FrameRate(strconv.FormatFloat(math.Round(frameRate), 'f', 0, 64))
+	case math.Abs(math.Round(frameRate*1.001)/1.001-frameRate) < tolerance:
+		return FrameRate(strconv.FormatFloat(math.Round(frameRate*1.001)*1000, 'f', 0, 64) + "/1001")
+	default:
+		return FrameRate(strconv.FormatFloat(math.Round(frameRate*1000), 'f', 0, 64) + "/1000")
+	}
+}
@@ -0,0 +1,306 @@
+package mpd_test
+
+import (
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"go.eigsys.de/go-mpd"
+	"testing"
+	"time"
+)
+
+func mustParseHLSPlaylists(multivariant string, media map[string]string) *mpd.HLSPlaylists {
+	playlists := &mpd.HLSPlaylists{Media: map[string]*mpd.HLSMediaPlaylist{}}
+
+	var err error
+	if playlists.Multivariant, err = mpd.ParseHLSMultivariantPlaylist([]byte(multivariant)); err != nil {
+		panic(err)
+	}
+
+	for uri, data := range media {
+		if playlists.Media[uri], err = mpd.ParseHLSMediaPlaylist([]byte(data)); err != nil {
+			panic(err)
+		}
+	}
+
+	return playlists
+}
+
+func TestConvertFromHLS_RoundTrip(t *testing.T) {
+	testMPD, err := mpd.Read(mustOpenFixture("zencoder/live_profile.mpd"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	exported, err := testMPD.ConvertToHLS(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	media := map[string]string{}
+	for uri, playlist := range exported.Media {
+		media[uri] = string(playlist.Bytes())
+	}
+
+	imported, err := mpd.ConvertFromHLS(mustParseHLSPlaylists(string(exported.Multivariant.Bytes()), media), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if imported.Type != mpd.StaticPresentationType || len(imported.Period) != 1 || len(imported.Period[0].AdaptationSet) != 3 {
+		t.Fatalf("wrong structure: %+v", imported)
+	}
+
+	for i, contentType := range []mpd.ContentType{mpd.VideoContentType, mpd.AudioContentType, mpd.SubtitlesContentType} {
+		if got := imported.Period[0].AdaptationSet[i].ContentType; got != contentType {
+			t.Errorf("wrong content type of AdaptationSet %d: %s", i, got)
+		}
+	}
+
+	video := &imported.Period[0].AdaptationSet[0]
+	if len(video.Representation) != 4 || video.Representation[3].Width != 1280 || video.Representation[3].FrameRate != "30000/1001" {
+		t.Errorf("wrong video Representations: %+v", video.Representation)
+	}
+
+	audio := &imported.Period[0].AdaptationSet[1]
+	if audio.Lang != "en" || audio.Label[0].Value != "en" || audio.Role[0].Value != "main" || audio.Representation[0].Codecs != "mp4a.40.2" {
+		t.Errorf("wrong audio AdaptationSet: %+v", audio)
+	}
+
+	for i, adaptationSet := range testMPD.Period[0].AdaptationSet[:2] {
+		want, err := testMPD.Segments(&testMPD.Period[0], &adaptationSet, &adaptationSet.Representation[0], nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		importedAdaptationSet := &imported.Period[0].AdaptationSet[1-i]
+
+		got, err := imported.Segments(&imported.Period[0], importedAdaptationSet, &importedAdaptationSet.Representation[0], nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(got) != len(want) {
+			t.Fatalf("wrong number of segments: %d instead of %d", len(got), len(want))
+		}
+
+		for j := range want {
+			if got[j].URL != "http://example.com/content/sintel/"+want[j].URL && got[j].URL != want[j].URL || got[j].PresentationTime != want[j].PresentationTime {
+				t.Errorf("wrong segment %d: %+v instead of %+v", j, got[j], want[j])
+			}
+		}
+	}
+}
+
+func TestConvertFromHLS_Live(t *testing.T) {
+	playlists := mustParseHLSPlaylists(`#EXTM3U
+#EXT-X-INDEPENDENT-SEGMENTS
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",LANGUAGE="de",NAME="Deutsch",DEFAULT=YES,CHANNELS="2",URI="audio/de.m3u8"
+#EXT-X-MEDIA:TYPE=CLOSED-CAPTIONS,GROUP-ID="cc",NAME="CC1",INSTREAM-ID="CC1"
+#EXT-X-STREAM-INF:BANDWIDTH=2000000,CODECS="avc1.640028,mp4a.40.2",RESOLUTION=1280x720,FRAME-RATE=25,AUDIO="aac",CLOSED-CAPTIONS="cc"
+video/720p.m3u8
+`, map[string]string{
+		"video/720p.m3u8": `#EXTM3U
+#EXT-X-VERSION:6
+#EXT-X-TARGETDURATION:4
+#EXT-X-MEDIA-SEQUENCE:100
+#EXT-X-DISCONTINUITY-SEQUENCE:3
+#EXT-X-MAP:URI="init.mp4"
+#EXT-X-PROGRAM-DATE-TIME:2024-01-02T03:04:00Z
+#EXTINF:4,
+seg-0100.m4s
+#EXTINF:4,
+seg-0101.m4s
+#EXT-X-DISCONTINUITY
+#EXT-X-MAP:URI="ad/init.mp4"
+#EXT-X-PROGRAM-DATE-TIME:2024-01-02T03:04:08Z
+#EXTINF:2,
+ad/seg-1.m4s
+`,
+		"audio/de.m3u8": `#EXTM3U
+#EXT-X-TARGETDURATION:4
+#EXT-X-MEDIA-SEQUENCE:100
+#EXT-X-DISCONTINUITY-SEQUENCE:3
+#EXT-X-MAP:URI="de.mp4",BYTERANGE="700@0"
+#EXTINF:4,
+#EXT-X-BYTERANGE:1000@700
+de.mp4
+#EXT-X-PROGRAM-DATE-TIME:2024-01-02T03:04:04Z
+#EXTINF:4,
+#EXT-X-BYTERANGE:1100
+de.mp4
+#EXT-X-DISCONTINUITY
+#EXT-X-MAP:URI="ad.mp4",BYTERANGE="600@0"
+#EXTINF:2,
+#EXT-X-BYTERANGE:500@600
+ad.mp4
+`,
+	})
+
+	availabilityStartTime := time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC)
+
+	converted, err := mpd.ConvertFromHLS(playlists, &mpd.HLSImportOptions{AvailabilityStartTime: availabilityStartTime})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := converted.Bytes()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-live:2011" type="dynamic" availabilityStartTime="2024-01-02T03:00:00Z" minimumUpdatePeriod="PT4S" minBufferTime="PT4S" timeShiftBufferDepth="PT10S" maxSegmentDuration="PT4S">
+  <Period id="3" start="PT4M">
+    <AdaptationSet mimeType="video/mp4" startWithSAP="1" id="1" contentType="video" segmentAlignment="true">
+      <Representation width="1280" height="720" frameRate="25" codecs="avc1.640028" bandwidth="2000000" id="720p">
+        <SegmentTemplate timescale="90000" presentationTimeOffset="21600000" startNumber="100" media="video/seg-$Number%04d$.m4s" initialization="video/init.mp4">
+          <SegmentTimeline>
+            <S t="21600000" d="360000" r="1"></S>
+          </SegmentTimeline>
+        </SegmentTemplate>
+      </Representation>
+    </AdaptationSet>
+    <AdaptationSet mimeType="audio/mp4" startWithSAP="1" id="2" lang="de" contentType="audio" segmentAlignment="true">
+      <Label>Deutsch</Label>
+      <Role schemeIdUri="urn:mpeg:dash:role:2011" value="main"></Role>
+      <Representation codecs="mp4a.40.2" bandwidth="0" id="de">
+        <AudioChannelConfiguration schemeIdUri="urn:mpeg:dash:23003:3:audio_channel_configuration:2011" value="2"></AudioChannelConfiguration>
+        <SegmentList timescale="90000" presentationTimeOffset="21600000">
+          <Initialization sourceURL="audio/de.mp4" range="0-699"></Initialization>
+          <SegmentTimeline>
+            <S t="21600000" d="360000" r="1"></S>
+          </SegmentTimeline>
+          <SegmentURL media="audio/de.mp4" mediaRange="700-1699"></SegmentURL>
+          <SegmentURL media="audio/de.mp4" mediaRange="1700-2799"></SegmentURL>
+        </SegmentList>
+      </Representation>
+    </AdaptationSet>
+  </Period>
+  <Period id="4" start="PT4M8S">
+    <AdaptationSet mimeType="video/mp4" startWithSAP="1" id="1" contentType="video" segmentAlignment="true">
+      <Representation width="1280" height="720" frameRate="25" codecs="avc1.640028" bandwidth="2000000" id="720p">
+        <SegmentTemplate timescale="90000" presentationTimeOffset="22320000" startNumber="1" media="video/ad/seg-$Number$.m4s" initialization="video/ad/init.mp4">
+          <SegmentTimeline>
+            <S t="22320000" d="180000"></S>
+          </SegmentTimeline>
+        </SegmentTemplate>
+      </Representation>
+    </AdaptationSet>
+    <AdaptationSet mimeType="audio/mp4" startWithSAP="1" id="2" lang="de" contentType="audio" segmentAlignment="true">
+      <Label>Deutsch</Label>
+      <Role schemeIdUri="urn:mpeg:dash:role:2011" value="main"></Role>
+      <Representation codecs="mp4a.40.2" bandwidth="0" id="de">
+        <AudioChannelConfiguration schemeIdUri="urn:mpeg:dash:23003:3:audio_channel_configuration:2011" value="2"></AudioChannelConfiguration>
+        <SegmentList timescale="90000" presentationTimeOffset="22320000">
+          <Initialization sourceURL="audio/ad.mp4" range="0-599"></Initialization>
+          <SegmentTimeline>
+            <S t="22320000" d="180000"></S>
+          </SegmentTimeline>
+          <SegmentURL media="audio/ad.mp4" mediaRange="600-1099"></SegmentURL>
+        </SegmentList>
+      </Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>`
+
+	if diff := cmp.Diff(string(data), want); diff != "" {
+		t.Errorf("wrong MPD: %s", diff)
+	}
+
+	if converted, err = mpd.ConvertFromHLS(playlists, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if converted.AvailabilityStartTime != "2024-01-02T03:04:00Z" || converted.Period[0].Start != "PT0S" {
+		t.Errorf("wrong default AvailabilityStartTime: %s", converted.AvailabilityStartTime)
+	}
+}
+
+func TestConvertFromHLS_Errors(t *testing.T) {
+	multivariant := "#EXTM3U\n#EXT-X-STREAM-INF:BANDWIDTH=1,CODECS=\"avc1.640028\"\nvideo.m3u8\n#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID=\"a\",NAME=\"a\",URI=\"audio.m3u8\"\n"
+	video := "#EXTM3U\n#EXT-X-MAP:URI=\"init.mp4\"\n#EXTINF:4,\n1.m4s\n#EXT-X-ENDLIST\n"
+
+	testCases := map[string]*mpd.HLSPlaylists{
+		"missing playlists":    nil,
+		"missing multivariant": {},
+		"no variants":          mustParseHLSPlaylists("#EXTM3U\n", nil),
+		"missing reference":    mustParseHLSPlaylists(multivariant, nil),
+		"missing rendition":    mustParseHLSPlaylists(multivariant, map[string]string{"video.m3u8": video}),
+		"no segments": mustParseHLSPlaylists(multivariant, map[string]string{
+			"video.m3u8": video, "audio.m3u8": "#EXTM3U\n#EXT-X-ENDLIST\n",
+		}),
+		"missing map": mustParseHLSPlaylists(multivariant, map[string]string{
+			"video.m3u8": video, "audio.m3u8": "#EXTM3U\n#EXTINF:4,\n1.m4s\n#EXT-X-ENDLIST\n",
+		}),
+		"discontinuity mismatch": mustParseHLSPlaylists(multivariant, map[string]string{
+			"video.m3u8": video, "audio.m3u8": "#EXTM3U\n#EXT-X-MAP:URI=\"init.mp4\"\n#EXTINF:4,\n1.m4s\n#EXT-X-DISCONTINUITY\n#EXTINF:4,\n2.m4s\n#EXT-X-ENDLIST\n",
+		}),
+		"live without program date time": mustParseHLSPlaylists(multivariant, map[string]string{
+			"video.m3u8": "#EXTM3U\n#EXT-X-MAP:URI=\"init.mp4\"\n#EXTINF:4,\n1.m4s\n",
+		}),
+		"live before availability start": mustParseHLSPlaylists(multivariant, map[string]string{
+			"video.m3u8": "#EXTM3U\n#EXT-X-MAP:URI=\"init.mp4\"\n#EXT-X-PROGRAM-DATE-TIME:2024-01-02T03:04:00Z\n#EXTINF:4,\n1.m4s\n",
+			"audio.m3u8": "#EXTM3U\n#EXT-X-MAP:URI=\"init.mp4\"\n#EXT-X-PROGRAM-DATE-TIME:2024-01-02T03:03:00Z\n#EXTINF:4,\n1.m4s\n",
+		}),
+	}
+
+	for name, playlists := range testCases {
+		if _, err := mpd.ConvertFromHLS(playlists, nil); !errors.Is(err, mpd.ErrConvertFromHLS) {
+			t.Errorf("%s: wrong error: %v", name, err)
+		}
+	}
+}
+
+func TestConvertFromHLS_Renditions(t *testing.T) {
+	segments := "#EXTM3U\n#EXT-X-MAP:URI=\"init.mp4\"\n#EXTINF:4,\nseg-1.m4s\n#EXTINF:4,\nseg-3.m4s\n#EXT-X-ENDLIST\n"
+	playlists := mustParseHLSPlaylists(`#EXTM3U
+#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="text",LANGUAGE="fr",NAME="Français",URI="text/fr.m3u8"
+#EXT-X-STREAM-INF:BANDWIDTH=128000,CODECS="mp4a.40.2"
+audio.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=1000000,CODECS="hvc1.1.6.L93.B0,wvtt",RESOLUTION=640x360,FRAME-RATE=23.976,SUBTITLES="text"
+hevc.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=2000000,CODECS="hvc1.1.6.L120.B0,wvtt",RESOLUTION=1280x720,FRAME-RATE=12.5,SUBTITLES="text"
+hevc.m3u8?quality=high
+`, map[string]string{
+		"audio.m3u8":             segments,
+		"hevc.m3u8":              segments,
+		"hevc.m3u8?quality=high": segments,
+		"text/fr.m3u8":           segments,
+	})
+
+	converted, err := mpd.ConvertFromHLS(playlists, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	type Rendition struct {
+		ContentType mpd.ContentType
+		MIMEType    mpd.MIMEType
+		ID          string
+		Codecs      mpd.Codecs
+		FrameRate   mpd.FrameRate
+	}
+
+	var renditions []Rendition
+
+	for _, adaptationSet := range converted.Period[0].AdaptationSet {
+		for _, representation := range adaptationSet.Representation {
+			renditions = append(renditions, Rendition{adaptationSet.ContentType, adaptationSet.MIMEType, representation.ID, representation.Codecs, representation.FrameRate})
+
+			if list := representation.SegmentList; list == nil || len(list.SegmentURL) != 2 || list.Initialization.SourceURL == "" {
+				t.Errorf("wrong SegmentList of %s: %+v", representation.ID, list)
+			}
+		}
+	}
+
+	want := []Rendition{
+		{mpd.AudioContentType, mpd.AudioMP4MIMEType, "audio", "mp4a.40.2", ""},
+		{mpd.VideoContentType, mpd.VideoMP4MIMEType, "hevc", "hvc1.1.6.L93.B0", "24000/1001"},
+		{mpd.VideoContentType, mpd.VideoMP4MIMEType, "hevc-2", "hvc1.1.6.L120.B0", "12500/1000"},
+		{mpd.SubtitlesContentType, mpd.ApplicationMP4MIMEType, "fr", "wvtt", ""},
+	}
+
+	if diff := cmp.Diff(renditions, want); diff != "" {
+		t.Errorf("wrong renditions: %s", diff)
+	}
+}
@@ -0,0 +1,190 @@
+package mpd
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var ErrParseBox = errors.New("cannot parse box")
+
+// box is an ISO/IEC 14496-12 box without its header.
+type box struct {
+	Type    string
+	Payload []byte
+
+	// Size is the total size of the box including its header.
+	Size int
+}
+
+// readBox reads the box at the beginning of data.
+func readBox(data []byte) (box, error) {
+	if len(data) < 8 {
+		return box{}, ErrParseBox
+	}
+
+	size := uint64(binary.BigEndian.Uint32(data))
+	boxType := string(data[4:8])
+	header := uint64(8)
+
+	switch size {
+	case 0:
+		size = uint64(len(data))
+	case 1:
+		if len(data) < 16 {
+			return box{}, ErrParseBox
+		}
+
+		size = binary.BigEndian.Uint64(data[8:])
+		header = 16
+	}
+
+	if boxType == "uuid" {
+		header += 16
+	}
+
+	if size < header || size > uint64(len(data)) {
+		return box{}, ErrParseBox
+	}
+
+	return box{Type: boxType, Payload: data[header:size], Size: int(size)}, nil
+}
+
+// readBoxes reads all consecutive boxes contained in data.
+func readBoxes(data []byte) ([]box, error) {
+	var boxes []box
+
+	for len(data) > 0 {
+		b, err := readBox(data)
+		if err != nil {
+			return nil, err
+		}
+
+		boxes = append(boxes, b)
+		data = data[b.Size:]
+	}
+
+	return boxes, nil
+}
+
+// findBoxes descends into data along the given box types and returns all boxes matching the last type.
+func findBoxes(data []byte, path ...string) ([]box, error) {
+	boxes, err := readBoxes(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []box
+
+	for _, b := range boxes {
+		if b.Type != path[0] {
+			continue
+		}
+
+		if len(path) == 1 {
+			found = append(found, b)
+			continue
+		}
+
+		children, err := findBoxes(b.Payload, path[1:]...)
+		if err != nil {
+			return nil, err
+		}
+
+		found = append(found, children...)
+	}
+
+	return found, nil
+}
+
+// findBox works like findBoxes, but returns the first matching box only.
+func findBox(data []byte, path ...string) (box, bool, error) {
+	boxes, err := findBoxes(data, path...)
+	if err != nil || len(boxes) == 0 {
+		return box{}, false, err
+	}
+
+	return boxes[0], true, nil
+}
+
+// appendBox appends a box with the given payload to dst.
+func appendBox(dst []byte, boxType string, payload []byte) []byte {
+	dst = binary.BigEndian.AppendUint32(dst, uint32(8+len(payload)))
+	dst = append(dst, boxType...)
+
+	return append(dst, payload...)
+}
+
+// appendFullBox works like appendBox, but prepends the version and flags of a full box to the payload.
+func appendFullBox(dst []byte, boxType string, version uint8, flags uint32, payload []byte) []byte {
+	header := []byte{version, byte(flags >> 16), byte(flags >> 8), byte(flags)}
+	return appendBox(dst, boxType, append(header, payload...))
+}
+
+// byteReader reads big-endian fields from a byte slice and remembers the first error.
+type byteReader struct {
+	data []byte
+	err  error
+}
+
+func (r *byteReader) bytes(n int) []byte {
+	if r.err != nil || n < 0 || len(r.data) < n {
+		r.err = ErrParseBox
+		return nil
+	}
+
+	b := r.data[:n]
+	r.data = r.data[n:]
+
+	return b
+}
+
+// fixed works like bytes, but returns zeros instead of nil on error.
+func (r *byteReader) fixed(n int) []byte {
+	if b := r.bytes(n); b != nil {
+		return b
+	}
+
+	return make([]byte, n)
+}
+
+func (r *byteReader) uint8() uint8 {
+	return r.fixed(1)[0]
+}
+
+func (r *byteReader) uint16() uint16 {
+	return binary.BigEndian.Uint16(r.fixed(2))
+}
+
+func (r *byteReader) uint24() uint32 {
+	b := r.fixed(3)
+	return uint32(b[0])<<16 | uint32(b[1])<<8 | uint32(b[2])
+}
+
+func (r *byteReader) uint32() uint32 {
+	return binary.BigEndian.Uint32(r.fixed(4))
+}
+
+func (r *byteReader) uint64() uint64 {
+	return binary.BigEndian.Uint64(r.fixed(8))
+}
+
+// fullBoxHeader reads the version and flags of a full box.
+func (r *byteReader) fullBoxHeader() (uint8, uint32) {
+	return r.uint8(), r.uint24()
+}
+
+// cstring reads a null-terminated UTF-8 string.
+func (r *byteReader) cstring() string {
+	for i, c := range r.data {
+		if c == 0 && r.err == nil {
+			value := string(r.data[:i])
+			r.data = r.data[i+1:]
+
+			return value
+		}
+	}
+
+	r.err = ErrParseBox
+
+	return ""
+}
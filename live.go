@@ -0,0 +1,179 @@
+package mpd
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+var ErrAppendSegment = errors.New("cannot append segment")
+
+// LivePackager maintains the SegmentTimelines of a dynamic MPD at a live origin. Segments are appended as they are
+// packaged, while any number of readers take snapshots of the MPD to serve it. All methods are safe for
+// concurrent use.
+type LivePackager struct {
+	// Now returns the wall-clock time that MPD@publishTime is set to. It defaults to time.Now.
+	Now func() time.Time
+
+	mutex sync.RWMutex
+	mpd   *MPD
+}
+
+// NewLivePackager creates a LivePackager for the MPD. The MPD must not be modified directly afterwards;
+// use LivePackager.Update instead.
+func NewLivePackager(m *MPD) *LivePackager {
+	return &LivePackager{mpd: m}
+}
+
+// Append adds the segment with media time t and duration d in timescale units to the SegmentTimeline of the
+// Representation in the Period with the IDs. The segment is merged into the repeat count of the last S element if it
+// directly follows it with the same duration; after a discontinuity, it starts a new S element with an explicit
+// start time. Segments that end before the time shift buffer of MPD@timeShiftBufferDepth, measured back from the end
+// of the appended segment, are evicted, and StartNumber is incremented accordingly. MPD@publishTime is set to the
+// current time.
+//
+// The SegmentTimeline of the lowest SegmentTemplate of the Representation, AdaptationSet or Period is used, and
+// created if no SegmentTemplate has one. A SegmentTimeline shared by several Representations only needs the segment
+// once; appending the last segment again has no effect.
+func (p *LivePackager) Append(periodID, representationID string, t, d uint64) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if d == 0 {
+		return fmt.Errorf("%w: segment without duration", ErrAppendSegment)
+	}
+
+	period := p.mpd.periodByID(periodID)
+	if period == nil {
+		return fmt.Errorf("%w: no period %q", ErrAppendSegment, periodID)
+	}
+
+	adaptationSet, representation := period.representationByID(representationID)
+	if representation == nil {
+		return fmt.Errorf("%w: no representation %q in period %q", ErrAppendSegment, representationID, periodID)
+	}
+
+	template := liveSegmentTemplate(period, adaptationSet, representation)
+	if template == nil {
+		return fmt.Errorf("%w: representation %q has no SegmentTemplate", ErrAppendSegment, representationID)
+	}
+
+	var timeShiftBufferDepth time.Duration
+
+	if p.mpd.TimeShiftBufferDepth != "" {
+		var err error
+		if timeShiftBufferDepth, err = ParseDuration(p.mpd.TimeShiftBufferDepth); err != nil {
+			return errors.Join(ErrAppendSegment, err)
+		}
+	}
+
+	if template.SegmentTimeline == nil {
+		template.SegmentTimeline = &SegmentTimeline{}
+	}
+
+	timeline := template.SegmentTimeline
+	if n := len(timeline.S); n > 0 && timeline.S[n-1].R < 0 {
+		return fmt.Errorf("%w: SegmentTimeline is open-ended", ErrAppendSegment)
+	}
+
+	end := timeline.end()
+
+	if start, duration, ok := timeline.last(); ok {
+		if start == t && duration == d {
+			return nil
+		}
+
+		if t < end {
+			return fmt.Errorf("%w: segment at %d overlaps the SegmentTimeline ending at %d", ErrAppendSegment, t, end)
+		}
+	}
+
+	timeline.append(end, t, d)
+
+	if timeShiftBufferDepth > 0 {
+		_, _, effective := segmentInformation(period, adaptationSet, representation)
+		timescale := uint64(effective.Timescale)
+
+		if depth := durationTicks(timeShiftBufferDepth, timescale); t+d > depth {
+			trimmed, removed, _ := timeline.trim(t+d-depth, math.MaxUint64)
+			if removed > 0 {
+				template.SegmentTimeline = trimmed
+				template.StartNumber = maxUint(effective.StartNumber, 1) + uint(removed)
+			}
+		}
+	}
+
+	now := time.Now
+	if p.Now != nil {
+		now = p.Now
+	}
+
+	p.mpd.PublishTime = FormatDateTime(now())
+
+	return nil
+}
+
+// Snapshot returns a copy of the MPD that is not affected by later changes.
+func (p *LivePackager) Snapshot() *MPD {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return deepCopy(p.mpd)
+}
+
+// Update calls fn with the MPD for changes other than appending segments, e.g. to start a new Period.
+func (p *LivePackager) Update(fn func(m *MPD) error) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return fn(p.mpd)
+}
+
+// liveSegmentTemplate returns the lowest SegmentTemplate of the Representation with a SegmentTimeline, or the lowest
+// SegmentTemplate if none has one.
+func liveSegmentTemplate(period *Period, adaptationSet *AdaptationSet, representation *Representation) *SegmentTemplate {
+	var lowest *SegmentTemplate
+
+	for _, template := range []*SegmentTemplate{representation.SegmentTemplate, adaptationSet.SegmentTemplate, period.SegmentTemplate} {
+		if template == nil {
+			continue
+		}
+
+		if template.SegmentTimeline != nil {
+			return template
+		}
+
+		if lowest == nil {
+			lowest = template
+		}
+	}
+
+	return lowest
+}
+
+func (p *Period) representationByID(id string) (*AdaptationSet, *Representation) {
+	for i := range p.AdaptationSet {
+		adaptationSet := &p.AdaptationSet[i]
+
+		for j := range adaptationSet.Representation {
+			if adaptationSet.Representation[j].ID == id {
+				return adaptationSet, &adaptationSet.Representation[j]
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// last returns the start and duration of the last segment of the SegmentTimeline.
+func (t *SegmentTimeline) last() (uint64, uint64, bool) {
+	if len(t.S) == 0 {
+		return 0, 0, false
+	}
+
+	end, last := t.end(), &t.S[len(t.S)-1]
+
+	return end - last.D, last.D, true
+}
@@ -0,0 +1,193 @@
+package mpd_test
+
+import (
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"go.eigsys.de/go-mpd"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newPackagerMPD() *mpd.MPD {
+	return &mpd.MPD{
+		Profiles:              mpd.Profile("urn:mpeg:dash:profile:isoff-live:2011"),
+		Type:                  mpd.DynamicPresentationType,
+		AvailabilityStartTime: "2024-01-01T00:00:00Z",
+		TimeShiftBufferDepth:  "PT10S",
+		MinBufferTime:         "PT2S",
+		Period: []mpd.Period{{
+			ID: "live",
+			AdaptationSet: []mpd.AdaptationSet{
+				{
+					RepresentationBase: mpd.RepresentationBase{MIMEType: mpd.VideoMP4MIMEType},
+					SegmentTemplate: &mpd.SegmentTemplate{
+						Media:               "video/$RepresentationID$/$Number$.m4s",
+						MultipleSegmentBase: mpd.MultipleSegmentBase{SegmentBase: mpd.SegmentBase{Timescale: 1000}},
+					},
+					Representation: []mpd.Representation{{ID: "v1", Bandwidth: 1000000}, {ID: "v2", Bandwidth: 2000000}},
+				},
+				{
+					RepresentationBase: mpd.RepresentationBase{MIMEType: mpd.AudioMP4MIMEType},
+					Representation: []mpd.Representation{{
+						ID:          "a1",
+						Bandwidth:   128000,
+						SegmentBase: &mpd.SegmentBase{IndexRange: "0-99"},
+					}},
+				},
+			},
+		}},
+	}
+}
+
+func TestLivePackager_Append(t *testing.T) {
+	packager := mpd.NewLivePackager(newPackagerMPD())
+	packager.Now = func() time.Time { return time.Date(2024, 1, 1, 0, 0, 30, 0, time.UTC) }
+
+	for _, start := range []uint64{0, 2000, 4000, 6000} {
+		for _, id := range []string{"v1", "v2"} {
+			if err := packager.Append("live", id, start, 2000); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+	}
+
+	snapshot := packager.Snapshot()
+	template := snapshot.Period[0].AdaptationSet[0].SegmentTemplate
+
+	if diff := cmp.Diff(template.SegmentTimeline.S, []mpd.S{{T: uint64Ptr(0), D: 2000, R: 3}}); diff != "" {
+		t.Errorf("wrong SegmentTimeline: %s", diff)
+	}
+
+	if template.StartNumber != 0 || snapshot.PublishTime != "2024-01-01T00:00:30Z" {
+		t.Errorf("wrong StartNumber or PublishTime: %d %s", template.StartNumber, snapshot.PublishTime)
+	}
+
+	// After the discontinuity, the time shift buffer of 10s starts at 12s.
+	for start := uint64(10000); start < 22000; start += 2000 {
+		if err := packager.Append("live", "v1", start, 2000); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	template = packager.Snapshot().Period[0].AdaptationSet[0].SegmentTemplate
+
+	if diff := cmp.Diff(template.SegmentTimeline.S, []mpd.S{{T: uint64Ptr(12000), D: 2000, R: 4}}); diff != "" {
+		t.Errorf("wrong SegmentTimeline: %s", diff)
+	}
+
+	if template.StartNumber != 6 {
+		t.Errorf("wrong StartNumber: %d", template.StartNumber)
+	}
+
+	if diff := cmp.Diff(snapshot.Period[0].AdaptationSet[0].SegmentTemplate.SegmentTimeline.S, []mpd.S{{T: uint64Ptr(0), D: 2000, R: 3}}); diff != "" {
+		t.Errorf("snapshot was modified: %s", diff)
+	}
+
+	err := packager.Update(func(m *mpd.MPD) error {
+		representation := &m.Period[0].AdaptationSet[0].Representation[1]
+		representation.SegmentTemplate = &mpd.SegmentTemplate{
+			Media:               "v2/$Time$.m4s",
+			MultipleSegmentBase: mpd.MultipleSegmentBase{SegmentTimeline: &mpd.SegmentTimeline{}},
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := packager.Append("live", "v2", 22000, 2000); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	period := &packager.Snapshot().Period[0]
+
+	if diff := cmp.Diff(period.AdaptationSet[0].Representation[1].SegmentTemplate.SegmentTimeline.S, []mpd.S{{T: uint64Ptr(22000), D: 2000}}); diff != "" {
+		t.Errorf("wrong SegmentTimeline: %s", diff)
+	}
+
+	if end := period.AdaptationSet[0].SegmentTemplate.SegmentTimeline.S[0].R; end != 4 {
+		t.Errorf("wrong repeat count: %d", end)
+	}
+}
+
+func TestLivePackager_Snapshot(t *testing.T) {
+	packager := mpd.NewLivePackager(newPackagerMPD())
+	if err := packager.Append("live", "v1", 0, 2000); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		for start := uint64(2000); start < 100000; start += 2000 {
+			if err := packager.Append("live", "v1", start, 2000); err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 50; j++ {
+				snapshot := packager.Snapshot()
+				if err := snapshot.Validate(); err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	template := packager.Snapshot().Period[0].AdaptationSet[0].SegmentTemplate
+	if template.StartNumber != 46 || template.SegmentTimeline.S[0].R != 4 {
+		t.Errorf("wrong SegmentTemplate: %d %+v", template.StartNumber, template.SegmentTimeline.S)
+	}
+}
+
+func TestLivePackager_Append_Errors(t *testing.T) {
+	testCases := map[string]struct {
+		mutate          func(*mpd.MPD)
+		periodID, id    string
+		start, duration uint64
+	}{
+		"zero duration":           {periodID: "live", id: "v1"},
+		"missing period":          {periodID: "vod", id: "v1", duration: 2000},
+		"missing representation":  {periodID: "live", id: "v3", duration: 2000},
+		"missing SegmentTemplate": {periodID: "live", id: "a1", duration: 2000},
+		"invalid buffer depth":    {mutate: func(m *mpd.MPD) { m.TimeShiftBufferDepth = "invalid" }, periodID: "live", id: "v1", duration: 2000},
+		"overlapping segment":     {periodID: "live", id: "v1", start: 3000, duration: 2000},
+		"open-ended SegmentTimeline": {
+			mutate: func(m *mpd.MPD) {
+				m.Period[0].AdaptationSet[0].SegmentTemplate.SegmentTimeline = &mpd.SegmentTimeline{S: []mpd.S{{D: 2000, R: -1}}}
+			},
+			periodID: "live", id: "v1", start: 8000, duration: 2000,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testMPD := newPackagerMPD()
+		testMPD.Period[0].AdaptationSet[0].SegmentTemplate.SegmentTimeline = &mpd.SegmentTimeline{S: []mpd.S{{T: uint64Ptr(0), D: 2000, R: 1}}}
+
+		if testCase.mutate != nil {
+			testCase.mutate(testMPD)
+		}
+
+		packager := mpd.NewLivePackager(testMPD)
+		if err := packager.Append(testCase.periodID, testCase.id, testCase.start, testCase.duration); !errors.Is(err, mpd.ErrAppendSegment) {
+			t.Errorf("%s: wrong error: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package mpd
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var (
+	ErrSegmentAvailability = errors.New("cannot resolve segment availability")
+	ErrSegmentLatency      = errors.New("cannot compute segment latency")
+)
+
+// SegmentAvailability is the availability of a segment in wall-clock time.
+type SegmentAvailability struct {
+	// Start is the availability start time adjusted by the AvailabilityTimeOffset. If it is before Complete, the
+	// segment is still being produced and delivered in chunks until Complete.
+	Start time.Time
+
+	// Complete is the time from which the segment is completely available.
+	Complete time.Time
+
+	// End is the availability end time, or zero if the segment is available indefinitely.
+	End time.Time
+}
+
+// SegmentAvailability returns the availability of a segment of the Representation in the dynamic MPD. The
+// AvailabilityTimeOffsets of the BaseURLs of all levels and of the segment information are added, with an infinite
+// offset making the segment available from the AvailabilityStartTime. A segment is complete at its adjusted
+// availability start time unless AvailabilityTimeComplete is `false` on any of these levels. The availability ends
+// after the segment duration and the TimeShiftBufferDepth of the lowest BaseURL or the MPD have passed from the
+// adjusted availability start time, or at the AvailabilityEndTime of the MPD if that is earlier. With an infinite
+// offset, only the AvailabilityEndTime applies.
+func (m *MPD) SegmentAvailability(period *Period, adaptationSet *AdaptationSet, representation *Representation, segment *Segment) (*SegmentAvailability, error) {
+	if m.AvailabilityStartTime == "" {
+		return nil, fmt.Errorf("%w: no availabilityStartTime", ErrSegmentAvailability)
+	}
+
+	availabilityStartTime, err := ParseDateTime(m.AvailabilityStartTime)
+	if err != nil {
+		return nil, errors.Join(ErrSegmentAvailability, err)
+	}
+
+	segmentEnd := availabilityStartTime.Add(segment.PresentationTime + segment.PresentationDuration)
+	availability := &SegmentAvailability{Start: segmentEnd, Complete: segmentEnd}
+
+	offset, complete := m.availabilityTimeOffset(period, adaptationSet, representation)
+
+	switch {
+	case math.IsInf(offset, 1):
+		availability.Start = availabilityStartTime
+	case offset > 0:
+		availability.Start = segmentEnd.Add(-time.Duration(offset * float64(time.Second)))
+	}
+
+	if complete {
+		availability.Complete = availability.Start
+	}
+
+	timeShiftBufferDepth := m.TimeShiftBufferDepth
+
+	for _, baseURLs := range [][]BaseURL{period.BaseURL, adaptationSet.BaseURL, representation.BaseURL} {
+		if len(baseURLs) > 0 && baseURLs[0].TimeShiftBufferDepth != "" {
+			timeShiftBufferDepth = baseURLs[0].TimeShiftBufferDepth
+		}
+	}
+
+	if timeShiftBufferDepth != "" {
+		depth, err := ParseDuration(timeShiftBufferDepth)
+		if err != nil {
+			return nil, errors.Join(ErrSegmentAvailability, err)
+		}
+
+		if !math.IsInf(offset, 1) {
+			availability.End = availability.Start.Add(segment.PresentationDuration + depth)
+		}
+	}
+
+	if m.AvailabilityEndTime != "" {
+		availabilityEndTime, err := ParseDateTime(m.AvailabilityEndTime)
+		if err != nil {
+			return nil, errors.Join(ErrSegmentAvailability, err)
+		}
+
+		if availability.End.IsZero() || availabilityEndTime.Before(availability.End) {
+			availability.End = availabilityEndTime
+		}
+	}
+
+	return availability, nil
+}
+
+// availabilityTimeOffset returns the sum of the AvailabilityTimeOffsets in seconds of the BaseURLs used by
+// resolveBaseURL and of the segment information of the Representation, and whether none of them sets
+// AvailabilityTimeComplete to `false`.
+func (m *MPD) availabilityTimeOffset(period *Period, adaptationSet *AdaptationSet, representation *Representation) (float64, bool) {
+	segmentBase := effectiveSegmentBase(period, adaptationSet, representation)
+	offset, complete := segmentBase.AvailabilityTimeOffset, segmentBase.AvailabilityTimeComplete == nil || *segmentBase.AvailabilityTimeComplete
+
+	for _, baseURLs := range [][]BaseURL{m.BaseURL, period.BaseURL, adaptationSet.BaseURL, representation.BaseURL} {
+		if len(baseURLs) > 0 {
+			offset += baseURLs[0].AvailabilityTimeOffset
+			complete = complete && (baseURLs[0].AvailabilityTimeComplete == nil || *baseURLs[0].AvailabilityTimeComplete)
+		}
+	}
+
+	return offset, complete
+}
+
+// IsLowLatency reports whether the MPD is dynamic and any Representation has a positive AvailabilityTimeOffset,
+// which makes its segments available in chunks before they are complete.
+func (m *MPD) IsLowLatency() bool {
+	if m.Type != DynamicPresentationType {
+		return false
+	}
+
+	for i := range m.Period {
+		period := &m.Period[i]
+
+		for j := range period.AdaptationSet {
+			adaptationSet := &period.AdaptationSet[j]
+
+			for k := range adaptationSet.Representation {
+				if offset, _ := m.availabilityTimeOffset(period, adaptationSet, &adaptationSet.Representation[k]); offset > 0 {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// ServiceLatency is the latency and playback rate of a ServiceDescription. Unsignalled values are zero.
+type ServiceLatency struct {
+	// ReferenceID is the ID of the ProducerReferenceTime the latency is measured against.
+	ReferenceID uint
+
+	Target time.Duration
+	Min    time.Duration
+	Max    time.Duration
+
+	MinPlaybackRate float64
+	MaxPlaybackRate float64
+}
+
+// ServiceLatency returns the latency settings of the first ServiceDescription without Scope of the Period, or of the
+// MPD if the Period is nil or has none. It returns nil if there is no such ServiceDescription.
+func (m *MPD) ServiceLatency(period *Period) *ServiceLatency {
+	serviceDescriptions := m.ServiceDescription
+	if period != nil && len(period.ServiceDescription) > 0 {
+		serviceDescriptions = period.ServiceDescription
+	}
+
+	for _, serviceDescription := range serviceDescriptions {
+		if len(serviceDescription.Scope) > 0 {
+			continue
+		}
+
+		serviceLatency := &ServiceLatency{}
+
+		if len(serviceDescription.Latency) > 0 {
+			latency := serviceDescription.Latency[0]
+			serviceLatency.ReferenceID = latency.ReferenceID
+			serviceLatency.Target = time.Duration(latency.Target) * time.Millisecond
+			serviceLatency.Min = time.Duration(latency.Min) * time.Millisecond
+			serviceLatency.Max = time.Duration(latency.Max) * time.Millisecond
+		}
+
+		if len(serviceDescription.PlaybackRate) > 0 {
+			serviceLatency.MinPlaybackRate = serviceDescription.PlaybackRate[0].Min
+			serviceLatency.MaxPlaybackRate = serviceDescription.PlaybackRate[0].Max
+		}
+
+		return serviceLatency
+	}
+
+	return nil
+}
+
+// SegmentLatency returns the end-to-end latency of a segment of the Representation if its start is presented at the
+// wall-clock time presented. The segment is mapped to the time it was produced with the ProducerReferenceTime of the
+// Representation, or of the AdaptationSet if the Representation has none. If the ServiceLatency of the Period refers
+// to a ProducerReferenceTime, that one is used, otherwise the first.
+func (m *MPD) SegmentLatency(period *Period, adaptationSet *AdaptationSet, representation *Representation, segment *Segment, presented time.Time) (time.Duration, error) {
+	producerReferenceTimes := representation.ProducerReferenceTime
+	if len(producerReferenceTimes) == 0 {
+		producerReferenceTimes = adaptationSet.ProducerReferenceTime
+	}
+
+	var referenceID uint
+	if serviceLatency := m.ServiceLatency(period); serviceLatency != nil {
+		referenceID = serviceLatency.ReferenceID
+	}
+
+	for i := range producerReferenceTimes {
+		producerReferenceTime := &producerReferenceTimes[i]
+		if referenceID != 0 && producerReferenceTime.ID != referenceID {
+			continue
+		}
+
+		wallClockTime, err := ParseDateTime(producerReferenceTime.WallClockTime)
+		if err != nil {
+			return 0, errors.Join(ErrSegmentLatency, err)
+		}
+
+		produced := wallClockTime.Add(scaledDuration(int64(segment.Time)-int64(producerReferenceTime.PresentationTime), segment.Timescale))
+
+		return presented.Sub(produced), nil
+	}
+
+	return 0, fmt.Errorf("%w: no ProducerReferenceTime", ErrSegmentLatency)
+}
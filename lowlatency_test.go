@@ -0,0 +1,249 @@
+package mpd_test
+
+import (
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"go.eigsys.de/go-mpd"
+	"math"
+	"testing"
+	"time"
+)
+
+func newLowLatencyMPD() *mpd.MPD {
+	return &mpd.MPD{
+		Profiles:              mpd.Live2011Profile,
+		Type:                  mpd.DynamicPresentationType,
+		MinBufferTime:         "PT1S",
+		AvailabilityStartTime: "2024-01-01T00:00:00Z",
+		TimeShiftBufferDepth:  "PT30S",
+		BaseURL:               []mpd.BaseURL{{Value: "https://cdn.example.com/", AvailabilityTimeOffset: 0.25}},
+		UTCTiming:             []mpd.Descriptor{{SchemeIDURI: "urn:mpeg:dash:utc:http-iso:2014", Value: "https://time.example.com/"}},
+		ServiceDescription: []mpd.ServiceDescription{
+			{Scope: []mpd.Descriptor{{SchemeIDURI: "urn:example:scope"}}, Latency: []mpd.Latency{{Target: 10000}}},
+			{
+				Latency:      []mpd.Latency{{ReferenceID: 7, Target: 3000, Min: 2000, Max: 6000}},
+				PlaybackRate: []mpd.PlaybackRate{{Min: 0.96, Max: 1.04}},
+			},
+		},
+		Period: []mpd.Period{{
+			ID:       "live",
+			Duration: "PT20S",
+			AdaptationSet: []mpd.AdaptationSet{{
+				RepresentationBase: mpd.RepresentationBase{
+					MIMEType: mpd.VideoMP4MIMEType,
+					ProducerReferenceTime: []mpd.ProducerReferenceTime{
+						{ID: 1, WallClockTime: "2024-01-01T00:00:01Z"},
+						{ID: 7, WallClockTime: "2024-01-01T00:00:00.5Z"},
+					},
+				},
+				SegmentTemplate: &mpd.SegmentTemplate{
+					Media: "video/$Number$.m4s",
+					MultipleSegmentBase: mpd.MultipleSegmentBase{
+						SegmentBase:     mpd.SegmentBase{Timescale: 1000, AvailabilityTimeOffset: 1.5},
+						SegmentTimeline: &mpd.SegmentTimeline{S: []mpd.S{{T: uint64Ptr(0), D: 2000, R: 9}}},
+					},
+				},
+				Representation: []mpd.Representation{{ID: "v1", Bandwidth: 1000000}},
+			}},
+		}},
+	}
+}
+
+func boolPtr(value bool) *bool {
+	return &value
+}
+
+func TestMPD_SegmentAvailability(t *testing.T) {
+	testMPD := newLowLatencyMPD()
+	period := &testMPD.Period[0]
+	adaptationSet := &period.AdaptationSet[0]
+	representation := &adaptationSet.Representation[0]
+	segment := &mpd.Segment{Number: 2, Time: 2000, Duration: 2000, Timescale: 1000, PresentationTime: 2 * time.Second, PresentationDuration: 2 * time.Second}
+	availabilityStartTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Without AvailabilityTimeComplete, the segment is complete at its adjusted availability start time.
+	availability, err := testMPD.SegmentAvailability(period, adaptationSet, representation, segment)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantAvailability := &mpd.SegmentAvailability{
+		Start:    availabilityStartTime.Add(2250 * time.Millisecond),
+		Complete: availabilityStartTime.Add(2250 * time.Millisecond),
+		End:      availabilityStartTime.Add(34250 * time.Millisecond),
+	}
+	if diff := cmp.Diff(availability, wantAvailability); diff != "" {
+		t.Errorf("wrong availability: %s", diff)
+	}
+
+	adaptationSet.SegmentTemplate.AvailabilityTimeComplete = boolPtr(false)
+	testMPD.AvailabilityEndTime = "2024-01-01T00:00:20Z"
+
+	wantAvailability = &mpd.SegmentAvailability{
+		Start:    availabilityStartTime.Add(2250 * time.Millisecond),
+		Complete: availabilityStartTime.Add(4 * time.Second),
+		End:      availabilityStartTime.Add(20 * time.Second),
+	}
+	if availability, err := testMPD.SegmentAvailability(period, adaptationSet, representation, segment); err != nil || cmp.Diff(availability, wantAvailability) != "" {
+		t.Errorf("unexpected result: %+v %v", availability, err)
+	}
+
+	adaptationSet.SegmentTemplate.AvailabilityTimeComplete = nil
+	testMPD.BaseURL[0].AvailabilityTimeComplete = boolPtr(false)
+	testMPD.TimeShiftBufferDepth = ""
+	testMPD.AvailabilityEndTime = "2024-01-01T01:00:00Z"
+
+	wantAvailability.End = availabilityStartTime.Add(time.Hour)
+	if availability, err := testMPD.SegmentAvailability(period, adaptationSet, representation, segment); err != nil || cmp.Diff(availability, wantAvailability) != "" {
+		t.Errorf("unexpected result: %+v %v", availability, err)
+	}
+
+	testMPD.BaseURL[0].AvailabilityTimeComplete = nil
+	testMPD.AvailabilityEndTime = ""
+	representation.BaseURL = []mpd.BaseURL{{Value: "v1/", AvailabilityTimeOffset: 0.25, TimeShiftBufferDepth: "PT10S"}}
+
+	wantAvailability = &mpd.SegmentAvailability{
+		Start:    availabilityStartTime.Add(2 * time.Second),
+		Complete: availabilityStartTime.Add(2 * time.Second),
+		End:      availabilityStartTime.Add(14 * time.Second),
+	}
+	if availability, err := testMPD.SegmentAvailability(period, adaptationSet, representation, segment); err != nil || cmp.Diff(availability, wantAvailability) != "" {
+		t.Errorf("unexpected result: %+v %v", availability, err)
+	}
+
+	representation.BaseURL[0].AvailabilityTimeOffset = math.Inf(1)
+
+	wantAvailability = &mpd.SegmentAvailability{Start: availabilityStartTime, Complete: availabilityStartTime}
+	if availability, err := testMPD.SegmentAvailability(period, adaptationSet, representation, segment); err != nil || cmp.Diff(availability, wantAvailability) != "" {
+		t.Errorf("unexpected result: %+v %v", availability, err)
+	}
+
+	testMPD = newLowLatencyMPD()
+	testMPD.BaseURL = nil
+	testMPD.TimeShiftBufferDepth = ""
+	testMPD.Period[0].AdaptationSet[0].SegmentTemplate.AvailabilityTimeOffset = 0
+	period = &testMPD.Period[0]
+
+	wantAvailability = &mpd.SegmentAvailability{Start: availabilityStartTime.Add(4 * time.Second), Complete: availabilityStartTime.Add(4 * time.Second)}
+	if availability, err := testMPD.SegmentAvailability(period, &period.AdaptationSet[0], &period.AdaptationSet[0].Representation[0], segment); err != nil || cmp.Diff(availability, wantAvailability) != "" {
+		t.Errorf("unexpected result: %+v %v", availability, err)
+	}
+}
+
+func TestMPD_SegmentAvailability_Errors(t *testing.T) {
+	testCases := map[string]func(*mpd.MPD){
+		"no availabilityStartTime":      func(m *mpd.MPD) { m.AvailabilityStartTime = "" },
+		"invalid availabilityStartTime": func(m *mpd.MPD) { m.AvailabilityStartTime = "invalid" },
+		"invalid timeShiftBufferDepth":  func(m *mpd.MPD) { m.Period[0].BaseURL = []mpd.BaseURL{{TimeShiftBufferDepth: "invalid"}} },
+		"invalid availabilityEndTime": func(m *mpd.MPD) {
+			m.TimeShiftBufferDepth = ""
+			m.AvailabilityEndTime = "invalid"
+		},
+	}
+
+	for name, mutate := range testCases {
+		testMPD := newLowLatencyMPD()
+		mutate(testMPD)
+
+		period := &testMPD.Period[0]
+		if _, err := testMPD.SegmentAvailability(period, &period.AdaptationSet[0], &period.AdaptationSet[0].Representation[0], &mpd.Segment{}); !errors.Is(err, mpd.ErrSegmentAvailability) {
+			t.Errorf("%s: wrong error: %v", name, err)
+		}
+	}
+}
+
+func TestMPD_IsLowLatency(t *testing.T) {
+	testMPD := newLowLatencyMPD()
+	if !testMPD.IsLowLatency() {
+		t.Error("MPD is not low-latency")
+	}
+
+	if err := testMPD.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	testMPD.BaseURL = nil
+	testMPD.Period[0].AdaptationSet[0].SegmentTemplate.AvailabilityTimeOffset = 0
+
+	if testMPD.IsLowLatency() {
+		t.Error("MPD is low-latency")
+	}
+
+	testMPD = newLowLatencyMPD()
+	testMPD.Type = mpd.StaticPresentationType
+
+	if testMPD.IsLowLatency() {
+		t.Error("static MPD is low-latency")
+	}
+}
+
+func TestMPD_ServiceLatency(t *testing.T) {
+	testMPD := newLowLatencyMPD()
+
+	wantServiceLatency := &mpd.ServiceLatency{
+		ReferenceID: 7, Target: 3 * time.Second, Min: 2 * time.Second, Max: 6 * time.Second,
+		MinPlaybackRate: 0.96, MaxPlaybackRate: 1.04,
+	}
+	if diff := cmp.Diff(testMPD.ServiceLatency(&testMPD.Period[0]), wantServiceLatency); diff != "" {
+		t.Errorf("wrong service latency: %s", diff)
+	}
+
+	testMPD.Period[0].ServiceDescription = []mpd.ServiceDescription{{Latency: []mpd.Latency{{Target: 1500}}}}
+
+	if diff := cmp.Diff(testMPD.ServiceLatency(&testMPD.Period[0]), &mpd.ServiceLatency{Target: 1500 * time.Millisecond}); diff != "" {
+		t.Errorf("wrong service latency: %s", diff)
+	}
+
+	if diff := cmp.Diff(testMPD.ServiceLatency(nil), wantServiceLatency); diff != "" {
+		t.Errorf("wrong service latency: %s", diff)
+	}
+
+	testMPD.ServiceDescription = testMPD.ServiceDescription[:1]
+
+	if serviceLatency := testMPD.ServiceLatency(nil); serviceLatency != nil {
+		t.Errorf("unexpected service latency: %+v", serviceLatency)
+	}
+}
+
+func TestMPD_SegmentLatency(t *testing.T) {
+	testMPD := newLowLatencyMPD()
+	period := &testMPD.Period[0]
+	adaptationSet := &period.AdaptationSet[0]
+	representation := &adaptationSet.Representation[0]
+	segment := &mpd.Segment{Number: 2, Time: 2000, Duration: 2000, Timescale: 1000}
+	presented := time.Date(2024, 1, 1, 0, 0, 5, 500000000, time.UTC)
+
+	latency, err := testMPD.SegmentLatency(period, adaptationSet, representation, segment, presented)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if latency != 3*time.Second {
+		t.Errorf("wrong latency: %s", latency)
+	}
+
+	testMPD.ServiceDescription = nil
+	representation.ProducerReferenceTime = []mpd.ProducerReferenceTime{{ID: 2, WallClockTime: "2024-01-01T00:00:01Z", PresentationTime: 1000}}
+
+	if latency, err := testMPD.SegmentLatency(period, adaptationSet, representation, segment, presented); err != nil || latency != 3500*time.Millisecond {
+		t.Errorf("unexpected result: %s %v", latency, err)
+	}
+}
+
+func TestMPD_SegmentLatency_Errors(t *testing.T) {
+	testCases := map[string]func(*mpd.MPD){
+		"no ProducerReferenceTime": func(m *mpd.MPD) { m.Period[0].AdaptationSet[0].ProducerReferenceTime = nil },
+		"unknown referenceID":      func(m *mpd.MPD) { m.ServiceDescription[1].Latency[0].ReferenceID = 3 },
+		"invalid wallClockTime":    func(m *mpd.MPD) { m.Period[0].AdaptationSet[0].ProducerReferenceTime[1].WallClockTime = "invalid" },
+	}
+
+	for name, mutate := range testCases {
+		testMPD := newLowLatencyMPD()
+		mutate(testMPD)
+
+		period := &testMPD.Period[0]
+		if _, err := testMPD.SegmentLatency(period, &period.AdaptationSet[0], &period.AdaptationSet[0].Representation[0], &mpd.Segment{}, time.Now()); !errors.Is(err, mpd.ErrSegmentLatency) {
+			t.Errorf("%s: wrong error: %v", name, err)
+		}
+	}
+}
@@ -2,6 +2,7 @@
 package mpd
 
 import (
+	"encoding/json"
 	"errors"
 	"go.eigsys.de/go-mpd/third_party/encoding/xml"
 	"io"
@@ -33,9 +34,10 @@ type Codecs string
 type MIMEType string
 
 const (
-	VideoMP4MIMEType MIMEType = "video/mp4"
-	AudioMP4MIMEType MIMEType = "audio/mp4"
-	TextVTTMIMEType  MIMEType = "text/vtt"
+	VideoMP4MIMEType       MIMEType = "video/mp4"
+	AudioMP4MIMEType       MIMEType = "audio/mp4"
+	ApplicationMP4MIMEType MIMEType = "application/mp4"
+	TextVTTMIMEType        MIMEType = "text/vtt"
 )
 
 type SchemeIDURI string
@@ -43,9 +45,16 @@ type SchemeIDURI string
 const (
 	AudioChannelConfiguration2011SchemeIDURI SchemeIDURI = "urn:mpeg:dash:23003:3:audio_channel_configuration:2011"
 	MP4Protection2011SchemeIDURI             SchemeIDURI = "urn:mpeg:dash:mp4protection:2011"
+	ClearKeySchemeIDURI                      SchemeIDURI = "urn:uuid:e2719d58-a985-b3c9-781a-b030af78d30e"
 	FairPlaySchemeIDURI                      SchemeIDURI = "urn:uuid:94ce86fb-07ff-4f43-adb8-93d2fa968ca2"
 	PlayReadySchemeIDURI                     SchemeIDURI = "urn:uuid:9a04f079-9840-4286-ab92-e65be0885f95"
 	WidevineSchemeIDURI                      SchemeIDURI = "urn:uuid:edef8ba9-79d6-4ace-a3c8-27dcd51d21ed"
+	Role2011SchemeIDURI                      SchemeIDURI = "urn:mpeg:dash:role:2011"
+	CICPTransferCharacteristicsSchemeIDURI   SchemeIDURI = "urn:mpeg:mpegB:cicp:TransferCharacteristics"
+	PeriodContinuitySchemeIDURI              SchemeIDURI = "urn:mpeg:dash:period-continuity:2015"
+	PeriodConnectivitySchemeIDURI            SchemeIDURI = "urn:mpeg:dash:period-connectivity:2015"
+	SCTE35BinSchemeIDURI                     SchemeIDURI = "urn:scte:scte35:2013:bin"
+	SCTE35XMLBinSchemeIDURI                  SchemeIDURI = "urn:scte:scte35:2014:xml+bin"
 )
 
 type ContentEncoding string
@@ -186,172 +195,175 @@ var (
 type AdaptationSet struct {
 	RepresentationBase
 
-	Accessibility    []Descriptor       `xml:"Accessibility,omitempty"`
-	Role             []Descriptor       `xml:"Role,omitempty"`
-	Rating           []Descriptor       `xml:"Rating,omitempty"`
-	Viewpoint        []Descriptor       `xml:"Viewpoint,omitempty"`
-	ContentComponent []ContentComponent `xml:"ContentComponent,omitempty"`
-	BaseURL          []BaseURL          `xml:"BaseURL,omitempty"`
-	SegmentBase      *SegmentBase       `xml:"SegmentBase,omitempty"`
-	SegmentList      *SegmentList       `xml:"SegmentList,omitempty"`
-	SegmentTemplate  *SegmentTemplate   `xml:"SegmentTemplate,omitempty"`
-	Representation   []Representation   `xml:"Representation,omitempty"`
-	XLinkHref        string             `xml:"http://www.w3.org/1999/xlink xlink:href,attr,omitempty"`
+	Accessibility    []Descriptor       `xml:"Accessibility,omitempty" json:"accessibility,omitempty"`
+	Role             []Descriptor       `xml:"Role,omitempty" json:"role,omitempty"`
+	Rating           []Descriptor       `xml:"Rating,omitempty" json:"rating,omitempty"`
+	Viewpoint        []Descriptor       `xml:"Viewpoint,omitempty" json:"viewpoint,omitempty"`
+	ContentComponent []ContentComponent `xml:"ContentComponent,omitempty" json:"contentComponent,omitempty"`
+	BaseURL          []BaseURL          `xml:"BaseURL,omitempty" json:"baseURL,omitempty"`
+	SegmentBase      *SegmentBase       `xml:"SegmentBase,omitempty" json:"segmentBase,omitempty"`
+	SegmentList      *SegmentList       `xml:"SegmentList,omitempty" json:"segmentList,omitempty"`
+	SegmentTemplate  *SegmentTemplate   `xml:"SegmentTemplate,omitempty" json:"segmentTemplate,omitempty"`
+	Representation   []Representation   `xml:"Representation,omitempty" json:"representation,omitempty"`
+	XLinkHref        string             `xml:"http://www.w3.org/1999/xlink xlink:href,attr,omitempty" json:"xlinkHref,omitempty"`
 
 	// XLinkActuate defaults to OnRequestXLinkActuate.
-	XLinkActuate XLinkActuate `xml:"http://www.w3.org/1999/xlink xlink:actuate,attr,omitempty"`
+	XLinkActuate XLinkActuate `xml:"http://www.w3.org/1999/xlink xlink:actuate,attr,omitempty" json:"xlinkActuate,omitempty"`
 
 	// XLinkType must be SimpleXLinkType.
-	XLinkType XLinkType `xml:"http://www.w3.org/1999/xlink xlink:type,attr,omitempty"`
+	XLinkType XLinkType `xml:"http://www.w3.org/1999/xlink xlink:type,attr,omitempty" json:"xlinkType,omitempty"`
 
 	// XLinkShow must be EmbedXLinkShow.
-	XLinkShow XLinkShow `xml:"http://www.w3.org/1999/xlink xlink:show,attr,omitempty"`
-
-	ID           uint        `xml:"id,attr,omitempty"`
-	Group        uint        `xml:"group,attr,omitempty"`
-	Lang         string      `xml:"lang,attr,omitempty"`
-	ContentType  ContentType `xml:"contentType,attr,omitempty"`
-	PAR          Ratio       `xml:"par,attr,omitempty"`
-	MinBandwidth uint        `xml:"minBandwidth,attr,omitempty"`
-	MaxBandwidth uint        `xml:"maxBandwidth,attr,omitempty"`
-	MinWidth     uint        `xml:"minWidth,attr,omitempty"`
-	MaxWidth     uint        `xml:"maxWidth,attr,omitempty"`
-	MinHeight    uint        `xml:"minHeight,attr,omitempty"`
-	MaxHeight    uint        `xml:"maxHeight,attr,omitempty"`
-	MinFrameRate FrameRate   `xml:"minFrameRate,attr,omitempty"`
-	MaxFrameRate FrameRate   `xml:"maxFrameRate,attr,omitempty"`
+	XLinkShow XLinkShow `xml:"http://www.w3.org/1999/xlink xlink:show,attr,omitempty" json:"xlinkShow,omitempty"`
+
+	ID           uint        `xml:"id,attr,omitempty" json:"id,omitempty"`
+	Group        uint        `xml:"group,attr,omitempty" json:"group,omitempty"`
+	Lang         string      `xml:"lang,attr,omitempty" json:"lang,omitempty"`
+	ContentType  ContentType `xml:"contentType,attr,omitempty" json:"contentType,omitempty"`
+	PAR          Ratio       `xml:"par,attr,omitempty" json:"par,omitempty"`
+	MinBandwidth uint        `xml:"minBandwidth,attr,omitempty" json:"minBandwidth,omitempty"`
+	MaxBandwidth uint        `xml:"maxBandwidth,attr,omitempty" json:"maxBandwidth,omitempty"`
+	MinWidth     uint        `xml:"minWidth,attr,omitempty" json:"minWidth,omitempty"`
+	MaxWidth     uint        `xml:"maxWidth,attr,omitempty" json:"maxWidth,omitempty"`
+	MinHeight    uint        `xml:"minHeight,attr,omitempty" json:"minHeight,omitempty"`
+	MaxHeight    uint        `xml:"maxHeight,attr,omitempty" json:"maxHeight,omitempty"`
+	MinFrameRate FrameRate   `xml:"minFrameRate,attr,omitempty" json:"minFrameRate,omitempty"`
+	MaxFrameRate FrameRate   `xml:"maxFrameRate,attr,omitempty" json:"maxFrameRate,omitempty"`
 
 	// SegmentAlignment defaults to `false`.
-	SegmentAlignment bool `xml:"segmentAlignment,attr,omitempty"`
+	SegmentAlignment bool `xml:"segmentAlignment,attr,omitempty" json:"segmentAlignment,omitempty"`
 
 	// SubsegmentAlignment defaults to `false`.
-	SubsegmentAlignment bool `xml:"subsegmentAlignment,attr,omitempty"`
+	SubsegmentAlignment bool `xml:"subsegmentAlignment,attr,omitempty" json:"subsegmentAlignment,omitempty"`
 
 	// SubsegmentStartsWithSAP defaults to `0`.
-	SubsegmentStartsWithSAP uint `xml:"subsegmentStartsWithSAP,attr,omitempty"`
+	SubsegmentStartsWithSAP uint `xml:"subsegmentStartsWithSAP,attr,omitempty" json:"subsegmentStartsWithSAP,omitempty"`
 
-	BitstreamSwitching      bool       `xml:"bitstreamSwitching,attr,omitempty"`
-	InitializationSetRef    UIntVector `xml:"initializationSetRef,attr,omitempty"`
-	InitializationPrincipal string     `xml:"initializationPrincipal,attr,omitempty"`
+	BitstreamSwitching      bool       `xml:"bitstreamSwitching,attr,omitempty" json:"bitstreamSwitching,omitempty"`
+	InitializationSetRef    UIntVector `xml:"initializationSetRef,attr,omitempty" json:"initializationSetRef,omitempty"`
+	InitializationPrincipal string     `xml:"initializationPrincipal,attr,omitempty" json:"initializationPrincipal,omitempty"`
 }
 
 type BaseURL struct {
-	Value                    string  `xml:",chardata"`
-	ServiceLocation          string  `xml:"serviceLocation,attr,omitempty"`
-	ByteRange                string  `xml:"byteRange,attr,omitempty"`
-	AvailabilityTimeOffset   float64 `xml:"availabilityTimeOffset,attr,omitempty"`
-	AvailabilityTimeComplete bool    `xml:"availabilityTimeComplete,attr,omitempty"`
-	TimeShiftBufferDepth     string  `xml:"timeShiftBufferDepth,attr,omitempty"`
+	Value                  string  `xml:",chardata" json:"value,omitempty"`
+	ServiceLocation        string  `xml:"serviceLocation,attr,omitempty" json:"serviceLocation,omitempty"`
+	ByteRange              string  `xml:"byteRange,attr,omitempty" json:"byteRange,omitempty"`
+	AvailabilityTimeOffset float64 `xml:"availabilityTimeOffset,attr,omitempty" json:"availabilityTimeOffset,omitempty"`
+
+	// AvailabilityTimeComplete defaults to `true`.
+	AvailabilityTimeComplete *bool `xml:"availabilityTimeComplete,attr,omitempty" json:"availabilityTimeComplete,omitempty"`
+
+	TimeShiftBufferDepth string `xml:"timeShiftBufferDepth,attr,omitempty" json:"timeShiftBufferDepth,omitempty"`
 
 	// RangeAccess defaults to `false`.
-	RangeAccess bool `xml:"rangeAccess,attr,omitempty"`
+	RangeAccess bool `xml:"rangeAccess,attr,omitempty" json:"rangeAccess,omitempty"`
 }
 
 type ContentComponent struct {
-	Items         []string           `xml:",any"`
-	Accessibility []Descriptor       `xml:"Accessibility,omitempty"`
-	Role          []Descriptor       `xml:"Role,omitempty"`
-	Rating        []Descriptor       `xml:"Rating,omitempty"`
-	Viewpoint     []Descriptor       `xml:"Viewpoint,omitempty"`
-	Lang          string             `xml:"lang,attr,omitempty"`
-	ContentType   RFC6838ContentType `xml:"contentType,attr,omitempty"`
-	PAR           Ratio              `xml:"par,attr,omitempty"`
-	Tag           Tag                `xml:"tag,attr,omitempty"`
+	Items         []string           `xml:",any" json:"items,omitempty"`
+	Accessibility []Descriptor       `xml:"Accessibility,omitempty" json:"accessibility,omitempty"`
+	Role          []Descriptor       `xml:"Role,omitempty" json:"role,omitempty"`
+	Rating        []Descriptor       `xml:"Rating,omitempty" json:"rating,omitempty"`
+	Viewpoint     []Descriptor       `xml:"Viewpoint,omitempty" json:"viewpoint,omitempty"`
+	Lang          string             `xml:"lang,attr,omitempty" json:"lang,omitempty"`
+	ContentType   RFC6838ContentType `xml:"contentType,attr,omitempty" json:"contentType,omitempty"`
+	PAR           Ratio              `xml:"par,attr,omitempty" json:"par,omitempty"`
+	Tag           Tag                `xml:"tag,attr,omitempty" json:"tag,omitempty"`
 }
 
 type Descriptor struct {
-	Items       []string    `xml:",any"`
-	SchemeIDURI SchemeIDURI `xml:"schemeIdUri,attr,omitempty"`
-	Value       string      `xml:"value,attr,omitempty"`
+	Items       []string    `xml:",any" json:"items,omitempty"`
+	SchemeIDURI SchemeIDURI `xml:"schemeIdUri,attr,omitempty" json:"schemeIdUri,omitempty"`
+	Value       string      `xml:"value,attr,omitempty" json:"value,omitempty"`
 }
 
 type EventStream struct {
-	Items     []string `xml:",any"`
-	Event     []Event  `xml:"Event,omitempty"`
-	XLinkHref string   `xml:"http://www.w3.org/1999/xlink xlink:href,attr,omitempty"`
+	Items     []string `xml:",any" json:"items,omitempty"`
+	Event     []Event  `xml:"Event,omitempty" json:"event,omitempty"`
+	XLinkHref string   `xml:"http://www.w3.org/1999/xlink xlink:href,attr,omitempty" json:"xlinkHref,omitempty"`
 
 	// XLinkActuate defaults to OnRequestXLinkActuate.
-	XLinkActuate XLinkActuate `xml:"http://www.w3.org/1999/xlink xlink:actuate,attr,omitempty"`
+	XLinkActuate XLinkActuate `xml:"http://www.w3.org/1999/xlink xlink:actuate,attr,omitempty" json:"xlinkActuate,omitempty"`
 
 	// XLinkType must be SimpleXLinkType.
-	XLinkType XLinkType `xml:"http://www.w3.org/1999/xlink xlink:type,attr,omitempty"`
+	XLinkType XLinkType `xml:"http://www.w3.org/1999/xlink xlink:type,attr,omitempty" json:"xlinkType,omitempty"`
 
 	// XLinkShow must be EmbedXLinkShow.
-	XLinkShow XLinkShow `xml:"http://www.w3.org/1999/xlink xlink:show,attr,omitempty"`
+	XLinkShow XLinkShow `xml:"http://www.w3.org/1999/xlink xlink:show,attr,omitempty" json:"xlinkShow,omitempty"`
 
-	SchemeIdURI SchemeIDURI `xml:"schemeIdUri,attr"`
-	Value       string      `xml:"value,attr,omitempty"`
-	Timescale   uint        `xml:"timescale,attr,omitempty"`
+	SchemeIdURI SchemeIDURI `xml:"schemeIdUri,attr" json:"schemeIdUri,omitempty"`
+	Value       string      `xml:"value,attr,omitempty" json:"value,omitempty"`
+	Timescale   uint        `xml:"timescale,attr,omitempty" json:"timescale,omitempty"`
 
 	// PresentationTimeOffset defaults to `0`.
-	PresentationTimeOffset uint `xml:"presentationTimeOffset,attr,omitempty"`
+	PresentationTimeOffset uint `xml:"presentationTimeOffset,attr,omitempty" json:"presentationTimeOffset,omitempty"`
 }
 
 type Event struct {
-	Items           []string        `xml:",any"`
-	Value           string          `xml:",chardata"`
-	SelectionInfo   []SelectionInfo `xml:"SelectionInfo,omitempty"`
-	SCTE35Signal    []SCTE35Signal  `xml:"urn:scte:scte35:2014:xml+bin Signal,omitempty"`
-	ID              string          `xml:"id,attr,omitempty"`
-	ContentEncoding ContentEncoding `xml:"contentEncoding,attr,omitempty"`
+	Items           []string        `xml:",any" json:"items,omitempty"`
+	Value           string          `xml:",chardata" json:"value,omitempty"`
+	SelectionInfo   []SelectionInfo `xml:"SelectionInfo,omitempty" json:"selectionInfo,omitempty"`
+	SCTE35Signal    []SCTE35Signal  `xml:"urn:scte:scte35:2014:xml+bin Signal,omitempty" json:"signal,omitempty"`
+	ID              string          `xml:"id,attr,omitempty" json:"id,omitempty"`
+	ContentEncoding ContentEncoding `xml:"contentEncoding,attr,omitempty" json:"contentEncoding,omitempty"`
 
 	// PresentationTime defaults to `0`.
-	PresentationTime uint64 `xml:"presentationTime,attr,omitempty"`
+	PresentationTime uint64 `xml:"presentationTime,attr,omitempty" json:"presentationTime,omitempty"`
 
-	Duration    uint64 `xml:"duration,attr,omitempty"`
-	MessageData string `xml:"messageData,attr,omitempty"`
+	Duration    uint64 `xml:"duration,attr,omitempty" json:"duration,omitempty"`
+	MessageData string `xml:"messageData,attr,omitempty" json:"messageData,omitempty"`
 }
 
 type SelectionInfo struct {
-	Selection     []Selection `xml:"Selection,omitempty"`
-	SelectionInfo string      `xml:"selectionInfo,attr,omitempty"`
-	ContactURL    string      `xml:"contactURL,attr"`
+	Selection     []Selection `xml:"Selection,omitempty" json:"selection,omitempty"`
+	SelectionInfo string      `xml:"selectionInfo,attr,omitempty" json:"selectionInfo,omitempty"`
+	ContactURL    string      `xml:"contactURL,attr" json:"contactURL,omitempty"`
 }
 
 type Selection struct {
-	DataEncoding ContentEncoding `xml:"dataEncoding,attr,omitempty"`
-	Parameter    string          `xml:"parameter,attr"`
-	Data         string          `xml:"data,attr"`
+	DataEncoding ContentEncoding `xml:"dataEncoding,attr,omitempty" json:"dataEncoding,omitempty"`
+	Parameter    string          `xml:"parameter,attr" json:"parameter,omitempty"`
+	Data         string          `xml:"data,attr" json:"data,omitempty"`
 }
 
 type SCTE35Signal struct {
-	Items  []string `xml:",any"`
-	Binary string   `xml:"urn:scte:scte35:2014:xml+bin Binary,omitempty"`
+	Items  []string `xml:",any" json:"items,omitempty"`
+	Binary string   `xml:"urn:scte:scte35:2014:xml+bin Binary,omitempty" json:"binary,omitempty"`
 }
 
 type MPD struct {
-	Items                      []string               `xml:",any"`
-	ProgramInformation         []ProgramInformation   `xml:"ProgramInformation,omitempty"`
-	BaseURL                    []BaseURL              `xml:"BaseURL,omitempty"`
-	Location                   []string               `xml:"Location,omitempty"`
-	PatchLocation              []PatchLocation        `xml:"PatchLocation,omitempty"`
-	ServiceDescription         []ServiceDescription   `xml:"ServiceDescription,omitempty"`
-	InitializationSet          []InitializationSet    `xml:"InitializationSet,omitempty"`
-	InitializationGroup        []UIntVWithID          `xml:"InitializationGroup,omitempty"`
-	InitializationPresentation []UIntVWithID          `xml:"InitializationPresentation,omitempty"`
-	ContentProtection          []ContentProtection    `xml:"ContentProtection,omitempty"`
-	Period                     []Period               `xml:"Period"`
-	Metrics                    []Metrics              `xml:"Metrics,omitempty"`
-	EssentialProperty          []Descriptor           `xml:"EssentialProperty,omitempty"`
-	SupplementalProperty       []Descriptor           `xml:"SupplementalProperty,omitempty"`
-	UTCTiming                  []Descriptor           `xml:"UTCTiming,omitempty"`
-	LeapSecondInformation      *LeapSecondInformation `xml:"LeapSecondInformation,omitempty"`
-	XMLNS                      Namespace              `xml:"xmlns,attr,omitempty"`
-	Profiles                   Profile                `xml:"profiles,attr"`
+	Items                      []string               `xml:",any" json:"items,omitempty"`
+	ProgramInformation         []ProgramInformation   `xml:"ProgramInformation,omitempty" json:"programInformation,omitempty"`
+	BaseURL                    []BaseURL              `xml:"BaseURL,omitempty" json:"baseURL,omitempty"`
+	Location                   []string               `xml:"Location,omitempty" json:"location,omitempty"`
+	PatchLocation              []PatchLocation        `xml:"PatchLocation,omitempty" json:"patchLocation,omitempty"`
+	ServiceDescription         []ServiceDescription   `xml:"ServiceDescription,omitempty" json:"serviceDescription,omitempty"`
+	InitializationSet          []InitializationSet    `xml:"InitializationSet,omitempty" json:"initializationSet,omitempty"`
+	InitializationGroup        []UIntVWithID          `xml:"InitializationGroup,omitempty" json:"initializationGroup,omitempty"`
+	InitializationPresentation []UIntVWithID          `xml:"InitializationPresentation,omitempty" json:"initializationPresentation,omitempty"`
+	ContentProtection          []ContentProtection    `xml:"ContentProtection,omitempty" json:"contentProtection,omitempty"`
+	Period                     []Period               `xml:"Period" json:"period,omitempty"`
+	Metrics                    []Metrics              `xml:"Metrics,omitempty" json:"metrics,omitempty"`
+	EssentialProperty          []Descriptor           `xml:"EssentialProperty,omitempty" json:"essentialProperty,omitempty"`
+	SupplementalProperty       []Descriptor           `xml:"SupplementalProperty,omitempty" json:"supplementalProperty,omitempty"`
+	UTCTiming                  []Descriptor           `xml:"UTCTiming,omitempty" json:"utcTiming,omitempty"`
+	LeapSecondInformation      *LeapSecondInformation `xml:"LeapSecondInformation,omitempty" json:"leapSecondInformation,omitempty"`
+	XMLNS                      Namespace              `xml:"xmlns,attr,omitempty" json:"xmlns,omitempty"`
+	Profiles                   Profile                `xml:"profiles,attr" json:"profiles,omitempty"`
 
 	// Type defaults to StaticPresentationType.
-	Type PresentationType `xml:"type,attr,omitempty"`
+	Type PresentationType `xml:"type,attr,omitempty" json:"type,omitempty"`
 
-	AvailabilityStartTime      string `xml:"availabilityStartTime,attr,omitempty"`
-	AvailabilityEndTime        string `xml:"availabilityEndTime,attr,omitempty"`
-	PublishTime                string `xml:"publishTime,attr,omitempty"`
-	MediaPresentationDuration  string `xml:"mediaPresentationDuration,attr,omitempty"`
-	MinimumUpdatePeriod        string `xml:"minimumUpdatePeriod,attr,omitempty"`
-	MinBufferTime              string `xml:"minBufferTime,attr"`
-	TimeShiftBufferDepth       string `xml:"timeShiftBufferDepth,attr,omitempty"`
-	SuggestedPresentationDelay string `xml:"suggestedPresentationDelay,attr,omitempty"`
-	MaxSegmentDuration         string `xml:"maxSegmentDuration,attr,omitempty"`
-	MaxSubsegmentDuration      string `xml:"maxSubsegmentDuration,attr,omitempty"`
+	AvailabilityStartTime      string `xml:"availabilityStartTime,attr,omitempty" json:"availabilityStartTime,omitempty"`
+	AvailabilityEndTime        string `xml:"availabilityEndTime,attr,omitempty" json:"availabilityEndTime,omitempty"`
+	PublishTime                string `xml:"publishTime,attr,omitempty" json:"publishTime,omitempty"`
+	MediaPresentationDuration  string `xml:"mediaPresentationDuration,attr,omitempty" json:"mediaPresentationDuration,omitempty"`
+	MinimumUpdatePeriod        string `xml:"minimumUpdatePeriod,attr,omitempty" json:"minimumUpdatePeriod,omitempty"`
+	MinBufferTime              string `xml:"minBufferTime,attr" json:"minBufferTime,omitempty"`
+	TimeShiftBufferDepth       string `xml:"timeShiftBufferDepth,attr,omitempty" json:"timeShiftBufferDepth,omitempty"`
+	SuggestedPresentationDelay string `xml:"suggestedPresentationDelay,attr,omitempty" json:"suggestedPresentationDelay,omitempty"`
+	MaxSegmentDuration         string `xml:"maxSegmentDuration,attr,omitempty" json:"maxSegmentDuration,omitempty"`
+	MaxSubsegmentDuration      string `xml:"maxSubsegmentDuration,attr,omitempty" json:"maxSubsegmentDuration,omitempty"`
 }
 
 // New creates a new instance of MPD and sets the XML namespace to MPD2011Namespace.
@@ -386,437 +398,471 @@ func (m *MPD) Bytes() ([]byte, error) {
 	return append([]byte(xml.Header), xmlData...), nil
 }
 
+// ReadJSON creates a new instance of MPD and reads the JSON representation from an io.ReadCloser.
+//
+// JSON keys are the camelCase XML names of the respective attributes and elements. The Initialization and
+// BitstreamSwitching elements use the keys initializationElement and bitstreamSwitchingElement, as a SegmentTemplate
+// has attributes of the same names.
+func ReadJSON(reader io.ReadCloser) (*MPD, error) {
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Join(ErrReadMPD, err)
+	}
+
+	_ = reader.Close()
+
+	mpd := &MPD{}
+	if err := json.Unmarshal(body, mpd); err != nil {
+		return nil, errors.Join(ErrUnmarshalMPD, err)
+	}
+
+	return mpd, nil
+}
+
+// JSON marshals the MPD to a JSON document with indentations. Attributes and elements having default values are
+// omitted.
+func (m *MPD) JSON() ([]byte, error) {
+	jsonData, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, errors.Join(ErrMarshalMPD, err)
+	}
+
+	return jsonData, nil
+}
+
 type PatchLocation struct {
-	Items []string `xml:",any"`
-	TTL   float64  `xml:"ttl,omitempty"`
+	Items []string `xml:",any" json:"items,omitempty"`
+	TTL   float64  `xml:"ttl,omitempty" json:"ttl,omitempty"`
 }
 
 type InitializationSet struct {
 	RepresentationBase
 
-	Accessibility []Descriptor `xml:"Accessibility,omitempty"`
-	Role          []Descriptor `xml:"Role,omitempty"`
-	Rating        []Descriptor `xml:"Rating,omitempty"`
-	Viewpoint     []Descriptor `xml:"Viewpoint,omitempty"`
-	XLinkHref     string       `xml:"http://www.w3.org/1999/xlink xlink:href,attr,omitempty"`
+	Accessibility []Descriptor `xml:"Accessibility,omitempty" json:"accessibility,omitempty"`
+	Role          []Descriptor `xml:"Role,omitempty" json:"role,omitempty"`
+	Rating        []Descriptor `xml:"Rating,omitempty" json:"rating,omitempty"`
+	Viewpoint     []Descriptor `xml:"Viewpoint,omitempty" json:"viewpoint,omitempty"`
+	XLinkHref     string       `xml:"http://www.w3.org/1999/xlink xlink:href,attr,omitempty" json:"xlinkHref,omitempty"`
 
 	// XLinkActuate defaults to OnRequestXLinkActuate.
-	XLinkActuate XLinkActuate `xml:"http://www.w3.org/1999/xlink xlink:actuate,attr,omitempty"`
+	XLinkActuate XLinkActuate `xml:"http://www.w3.org/1999/xlink xlink:actuate,attr,omitempty" json:"xlinkActuate,omitempty"`
 
 	// XLinkType must be SimpleXLinkType.
-	XLinkType XLinkType `xml:"http://www.w3.org/1999/xlink xlink:type,attr,omitempty"`
+	XLinkType XLinkType `xml:"http://www.w3.org/1999/xlink xlink:type,attr,omitempty" json:"xlinkType,omitempty"`
 
-	ID uint `xml:"id,attr"`
+	ID uint `xml:"id,attr" json:"id,omitempty"`
 
 	// InAllPeriods defaults to `true`.
-	InAllPeriods bool `xml:"inAllPeriods,attr,omitempty"`
+	InAllPeriods bool `xml:"inAllPeriods,attr,omitempty" json:"inAllPeriods,omitempty"`
 
-	ContentType    RFC6838ContentType `xml:"contentType,attr,omitempty"`
-	PAR            Ratio              `xml:"par,attr,omitempty"`
-	MaxWidth       uint               `xml:"maxWidth,attr,omitempty"`
-	MaxHeight      uint               `xml:"maxHeight,attr,omitempty"`
-	MaxFrameRate   FrameRate          `xml:"maxFrameRate,attr,omitempty"`
-	Initialization string             `xml:"initialization,attr,omitempty"`
+	ContentType    RFC6838ContentType `xml:"contentType,attr,omitempty" json:"contentType,omitempty"`
+	PAR            Ratio              `xml:"par,attr,omitempty" json:"par,omitempty"`
+	MaxWidth       uint               `xml:"maxWidth,attr,omitempty" json:"maxWidth,omitempty"`
+	MaxHeight      uint               `xml:"maxHeight,attr,omitempty" json:"maxHeight,omitempty"`
+	MaxFrameRate   FrameRate          `xml:"maxFrameRate,attr,omitempty" json:"maxFrameRate,omitempty"`
+	Initialization string             `xml:"initialization,attr,omitempty" json:"initialization,omitempty"`
 }
 
 type ServiceDescription struct {
-	Items              []string             `xml:",any"`
-	Scope              []Descriptor         `xml:"Scope,omitempty"`
-	Latency            []Latency            `xml:"Latency,omitempty"`
-	PlaybackRate       []PlaybackRate       `xml:"PlaybackRate,omitempty"`
-	OperatingQuality   []OperatingQuality   `xml:"OperatingQuality,omitempty"`
-	OperatingBandwidth []OperatingBandwidth `xml:"OperatingBandwidth,omitempty"`
-	ID                 uint                 `xml:"id,attr,omitempty"`
+	Items              []string             `xml:",any" json:"items,omitempty"`
+	Scope              []Descriptor         `xml:"Scope,omitempty" json:"scope,omitempty"`
+	Latency            []Latency            `xml:"Latency,omitempty" json:"latency,omitempty"`
+	PlaybackRate       []PlaybackRate       `xml:"PlaybackRate,omitempty" json:"playbackRate,omitempty"`
+	OperatingQuality   []OperatingQuality   `xml:"OperatingQuality,omitempty" json:"operatingQuality,omitempty"`
+	OperatingBandwidth []OperatingBandwidth `xml:"OperatingBandwidth,omitempty" json:"operatingBandwidth,omitempty"`
+	ID                 uint                 `xml:"id,attr,omitempty" json:"id,omitempty"`
 }
 
 type Latency struct {
-	Items          []string          `xml:",any"`
-	QualityLatency []UIntPairsWithID `xml:"QualityLatency,omitempty"`
-	ReferenceID    uint              `xml:"referenceID,attr,omitempty"`
-	Target         uint              `xml:"target,attr,omitempty"`
-	Max            uint              `xml:"max,attr,omitempty"`
-	Min            uint              `xml:"min,attr,omitempty"`
+	Items          []string          `xml:",any" json:"items,omitempty"`
+	QualityLatency []UIntPairsWithID `xml:"QualityLatency,omitempty" json:"qualityLatency,omitempty"`
+	ReferenceID    uint              `xml:"referenceID,attr,omitempty" json:"referenceID,omitempty"`
+	Target         uint              `xml:"target,attr,omitempty" json:"target,omitempty"`
+	Max            uint              `xml:"max,attr,omitempty" json:"max,omitempty"`
+	Min            uint              `xml:"min,attr,omitempty" json:"min,omitempty"`
 }
 
 type PlaybackRate struct {
-	Max float64 `xml:"max,attr,omitempty"`
-	Min float64 `xml:"min,attr,omitempty"`
+	Max float64 `xml:"max,attr,omitempty" json:"max,omitempty"`
+	Min float64 `xml:"min,attr,omitempty" json:"min,omitempty"`
 }
 
 type OperatingQuality struct {
 	// MediaType defaults to AnyMediaType.
-	MediaType OperatingQualityMediaType `xml:"mediaType,attr,omitempty"`
+	MediaType OperatingQualityMediaType `xml:"mediaType,attr,omitempty" json:"mediaType,omitempty"`
 
-	Min           uint   `xml:"min,attr,omitempty"`
-	Max           uint   `xml:"max,attr,omitempty"`
-	Target        uint   `xml:"target,attr,omitempty"`
-	Type          string `xml:"type,attr,omitempty"`
-	MaxDifference uint   `xml:"maxDifference,attr,omitempty"`
+	Min           uint   `xml:"min,attr,omitempty" json:"min,omitempty"`
+	Max           uint   `xml:"max,attr,omitempty" json:"max,omitempty"`
+	Target        uint   `xml:"target,attr,omitempty" json:"target,omitempty"`
+	Type          string `xml:"type,attr,omitempty" json:"type,omitempty"`
+	MaxDifference uint   `xml:"maxDifference,attr,omitempty" json:"maxDifference,omitempty"`
 }
 
 type OperatingBandwidth struct {
 	// MediaType defaults to AllMediaType.
-	MediaType OperatingBandwidthMediaType `xml:"mediaType,attr,omitempty"`
+	MediaType OperatingBandwidthMediaType `xml:"mediaType,attr,omitempty" json:"mediaType,omitempty"`
 
-	Min    uint `xml:"min,attr,omitempty"`
-	Max    uint `xml:"max,attr,omitempty"`
-	Target uint `xml:"target,attr,omitempty"`
+	Min    uint `xml:"min,attr,omitempty" json:"min,omitempty"`
+	Max    uint `xml:"max,attr,omitempty" json:"max,omitempty"`
+	Target uint `xml:"target,attr,omitempty" json:"target,omitempty"`
 }
 
 type UIntPairsWithID struct {
-	UIntVector
+	UIntVector `json:"uintVector,omitempty"`
 
-	Type string `xml:"type,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty" json:"type,omitempty"`
 }
 
 type UIntVWithID struct {
-	UIntVector
+	UIntVector `json:"uintVector,omitempty"`
 
-	ID          uint               `xml:"id,attr"`
-	Profiles    ListOfProfiles     `xml:"profiles,attr,omitempty"`
-	ContentType RFC6838ContentType `xml:"contentType,attr,omitempty"`
+	ID          uint               `xml:"id,attr" json:"id,omitempty"`
+	Profiles    ListOfProfiles     `xml:"profiles,attr,omitempty" json:"profiles,omitempty"`
+	ContentType RFC6838ContentType `xml:"contentType,attr,omitempty" json:"contentType,omitempty"`
 }
 
 type Metrics struct {
-	Items     []string     `xml:",any"`
-	Reporting []Descriptor `xml:"Reporting"`
-	Range     []Range      `xml:"Range,omitempty"`
-	Metrics   string       `xml:"metrics,attr"`
+	Items     []string     `xml:",any" json:"items,omitempty"`
+	Reporting []Descriptor `xml:"Reporting" json:"reporting,omitempty"`
+	Range     []Range      `xml:"Range,omitempty" json:"range,omitempty"`
+	Metrics   string       `xml:"metrics,attr" json:"metrics,omitempty"`
 }
 
 type Period struct {
-	Items                []string             `xml:",any"`
-	BaseURL              []BaseURL            `xml:"BaseURL,omitempty"`
-	SegmentBase          *SegmentBase         `xml:"SegmentBase,omitempty"`
-	SegmentList          *SegmentList         `xml:"SegmentList,omitempty"`
-	SegmentTemplate      *SegmentTemplate     `xml:"SegmentTemplate,omitempty"`
-	AssetIdentifier      *Descriptor          `xml:"AssetIdentifier,omitempty"`
-	EventStream          []EventStream        `xml:"EventStream,omitempty"`
-	ServiceDescription   []ServiceDescription `xml:"ServiceDescription,omitempty"`
-	ContentProtection    []ContentProtection  `xml:"ContentProtection,omitempty"`
-	AdaptationSet        []AdaptationSet      `xml:"AdaptationSet,omitempty"`
-	Subset               []Subset             `xml:"Subset,omitempty"`
-	SupplementalProperty []Descriptor         `xml:"SupplementalProperty,omitempty"`
-	EmptyAdaptationSet   []AdaptationSet      `xml:"EmptyAdaptationSet,omitempty"`
-	GroupLabel           []Label              `xml:"GroupLabel,omitempty"`
-	Preselection         []Preselection       `xml:"Preselection,omitempty"`
-	XLinkHref            string               `xml:"http://www.w3.org/1999/xlink xlink:href,attr,omitempty"`
+	Items                []string             `xml:",any" json:"items,omitempty"`
+	BaseURL              []BaseURL            `xml:"BaseURL,omitempty" json:"baseURL,omitempty"`
+	SegmentBase          *SegmentBase         `xml:"SegmentBase,omitempty" json:"segmentBase,omitempty"`
+	SegmentList          *SegmentList         `xml:"SegmentList,omitempty" json:"segmentList,omitempty"`
+	SegmentTemplate      *SegmentTemplate     `xml:"SegmentTemplate,omitempty" json:"segmentTemplate,omitempty"`
+	AssetIdentifier      *Descriptor          `xml:"AssetIdentifier,omitempty" json:"assetIdentifier,omitempty"`
+	EventStream          []EventStream        `xml:"EventStream,omitempty" json:"eventStream,omitempty"`
+	ServiceDescription   []ServiceDescription `xml:"ServiceDescription,omitempty" json:"serviceDescription,omitempty"`
+	ContentProtection    []ContentProtection  `xml:"ContentProtection,omitempty" json:"contentProtection,omitempty"`
+	AdaptationSet        []AdaptationSet      `xml:"AdaptationSet,omitempty" json:"adaptationSet,omitempty"`
+	Subset               []Subset             `xml:"Subset,omitempty" json:"subset,omitempty"`
+	SupplementalProperty []Descriptor         `xml:"SupplementalProperty,omitempty" json:"supplementalProperty,omitempty"`
+	EmptyAdaptationSet   []AdaptationSet      `xml:"EmptyAdaptationSet,omitempty" json:"emptyAdaptationSet,omitempty"`
+	GroupLabel           []Label              `xml:"GroupLabel,omitempty" json:"groupLabel,omitempty"`
+	Preselection         []Preselection       `xml:"Preselection,omitempty" json:"preselection,omitempty"`
+	XLinkHref            string               `xml:"http://www.w3.org/1999/xlink xlink:href,attr,omitempty" json:"xlinkHref,omitempty"`
 
 	// XLinkActuate defaults to OnRequestXLinkActuate.
-	XLinkActuate XLinkActuate `xml:"http://www.w3.org/1999/xlink xlink:actuate,attr,omitempty"`
+	XLinkActuate XLinkActuate `xml:"http://www.w3.org/1999/xlink xlink:actuate,attr,omitempty" json:"xlinkActuate,omitempty"`
 
 	// XLinkType must be SimpleXLinkType.
-	XLinkType XLinkType `xml:"http://www.w3.org/1999/xlink xlink:type,attr,omitempty"`
+	XLinkType XLinkType `xml:"http://www.w3.org/1999/xlink xlink:type,attr,omitempty" json:"xlinkType,omitempty"`
 
 	// XLinkShow must be EmbedXLinkShow.
-	XLinkShow XLinkShow `xml:"http://www.w3.org/1999/xlink xlink:show,attr,omitempty"`
+	XLinkShow XLinkShow `xml:"http://www.w3.org/1999/xlink xlink:show,attr,omitempty" json:"xlinkShow,omitempty"`
 
-	ID       string `xml:"id,attr,omitempty"`
-	Actuate  string `xml:"actuate,attr,omitempty"`
-	Start    string `xml:"start,attr,omitempty"`
-	Duration string `xml:"duration,attr,omitempty"`
+	ID       string `xml:"id,attr,omitempty" json:"id,omitempty"`
+	Actuate  string `xml:"actuate,attr,omitempty" json:"actuate,omitempty"`
+	Start    string `xml:"start,attr,omitempty" json:"start,omitempty"`
+	Duration string `xml:"duration,attr,omitempty" json:"duration,omitempty"`
 
 	// BitstreamSwitching defaults to `false`.
-	BitstreamSwitching bool `xml:"bitstreamSwitching,attr,omitempty"`
+	BitstreamSwitching bool `xml:"bitstreamSwitching,attr,omitempty" json:"bitstreamSwitching,omitempty"`
 }
 
 type Range struct {
-	StartTime string `xml:"starttime,attr,omitempty"`
-	Duration  string `xml:"duration,attr,omitempty"`
+	StartTime string `xml:"starttime,attr,omitempty" json:"starttime,omitempty"`
+	Duration  string `xml:"duration,attr,omitempty" json:"duration,omitempty"`
 }
 
 type RepresentationBase struct {
-	Items                     []string                `xml:",any"`
-	FramePacking              []Descriptor            `xml:"FramePacking,omitempty"`
-	AudioChannelConfiguration []*Descriptor           `xml:"AudioChannelConfiguration,omitempty"`
-	ContentProtection         []ContentProtection     `xml:"ContentProtection,omitempty"`
-	OutputProtection          *Descriptor             `xml:"OutputProtection,omitempty"`
-	EssentialProperty         []Descriptor            `xml:"EssentialProperty,omitempty"`
-	SupplementalProperty      []Descriptor            `xml:"SupplementalProperty,omitempty"`
-	InbandEventStream         []EventStream           `xml:"InbandEventStream,omitempty"`
-	Switching                 []Switching             `xml:"Switching,omitempty"`
-	RandomAccess              []RandomAccess          `xml:"RandomAccess,omitempty"`
-	GroupLabel                []Label                 `xml:"GroupLabel,omitempty"`
-	Label                     []Label                 `xml:"Label,omitempty"`
-	ProducerReferenceTime     []ProducerReferenceTime `xml:"ProducerReferenceTime,omitempty"`
-	ContentPopularityRate     []ContentPopularityRate `xml:"ContentPopularityRate,omitempty"`
-	Resync                    []Resync                `xml:"Resync,omitempty"`
-	Profiles                  ListOfProfiles          `xml:"profiles,attr,omitempty"`
-	Width                     uint                    `xml:"width,attr,omitempty"`
-	Height                    uint                    `xml:"height,attr,omitempty"`
-	SAR                       Ratio                   `xml:"sar,attr,omitempty"`
-	FrameRate                 FrameRate               `xml:"frameRate,attr,omitempty"`
-	AudioSamplingRate         *AudioSamplingRate      `xml:"audioSamplingRate,attr,omitempty"`
-	MIMEType                  MIMEType                `xml:"mimeType,attr,omitempty"`
-	SegmentProfiles           *ListOf4CC              `xml:"segmentProfiles,attr,omitempty"`
-	Codecs                    Codecs                  `xml:"codecs,attr,omitempty"`
-	ContainerProfiles         *ListOf4CC              `xml:"containerProfiles,attr,omitempty"`
-	MaximumSAPPeriod          float64                 `xml:"maximumSAPPeriod,attr,omitempty"`
-	StartWithSAP              uint                    `xml:"startWithSAP,attr,omitempty"`
-	MaxPlayoutRate            float64                 `xml:"maxPlayoutRate,attr,omitempty"`
-	CodingDependency          bool                    `xml:"codingDependency,attr,omitempty"`
-	ScanType                  VideoScan               `xml:"scanType,attr,omitempty"`
+	Items                     []string                `xml:",any" json:"items,omitempty"`
+	FramePacking              []Descriptor            `xml:"FramePacking,omitempty" json:"framePacking,omitempty"`
+	AudioChannelConfiguration []*Descriptor           `xml:"AudioChannelConfiguration,omitempty" json:"audioChannelConfiguration,omitempty"`
+	ContentProtection         []ContentProtection     `xml:"ContentProtection,omitempty" json:"contentProtection,omitempty"`
+	OutputProtection          *Descriptor             `xml:"OutputProtection,omitempty" json:"outputProtection,omitempty"`
+	EssentialProperty         []Descriptor            `xml:"EssentialProperty,omitempty" json:"essentialProperty,omitempty"`
+	SupplementalProperty      []Descriptor            `xml:"SupplementalProperty,omitempty" json:"supplementalProperty,omitempty"`
+	InbandEventStream         []EventStream           `xml:"InbandEventStream,omitempty" json:"inbandEventStream,omitempty"`
+	Switching                 []Switching             `xml:"Switching,omitempty" json:"switching,omitempty"`
+	RandomAccess              []RandomAccess          `xml:"RandomAccess,omitempty" json:"randomAccess,omitempty"`
+	GroupLabel                []Label                 `xml:"GroupLabel,omitempty" json:"groupLabel,omitempty"`
+	Label                     []Label                 `xml:"Label,omitempty" json:"label,omitempty"`
+	ProducerReferenceTime     []ProducerReferenceTime `xml:"ProducerReferenceTime,omitempty" json:"producerReferenceTime,omitempty"`
+	ContentPopularityRate     []ContentPopularityRate `xml:"ContentPopularityRate,omitempty" json:"contentPopularityRate,omitempty"`
+	Resync                    []Resync                `xml:"Resync,omitempty" json:"resync,omitempty"`
+	Profiles                  ListOfProfiles          `xml:"profiles,attr,omitempty" json:"profiles,omitempty"`
+	Width                     uint                    `xml:"width,attr,omitempty" json:"width,omitempty"`
+	Height                    uint                    `xml:"height,attr,omitempty" json:"height,omitempty"`
+	SAR                       Ratio                   `xml:"sar,attr,omitempty" json:"sar,omitempty"`
+	FrameRate                 FrameRate               `xml:"frameRate,attr,omitempty" json:"frameRate,omitempty"`
+	AudioSamplingRate         *AudioSamplingRate      `xml:"audioSamplingRate,attr,omitempty" json:"audioSamplingRate,omitempty"`
+	MIMEType                  MIMEType                `xml:"mimeType,attr,omitempty" json:"mimeType,omitempty"`
+	SegmentProfiles           *ListOf4CC              `xml:"segmentProfiles,attr,omitempty" json:"segmentProfiles,omitempty"`
+	Codecs                    Codecs                  `xml:"codecs,attr,omitempty" json:"codecs,omitempty"`
+	ContainerProfiles         *ListOf4CC              `xml:"containerProfiles,attr,omitempty" json:"containerProfiles,omitempty"`
+	MaximumSAPPeriod          float64                 `xml:"maximumSAPPeriod,attr,omitempty" json:"maximumSAPPeriod,omitempty"`
+	StartWithSAP              uint                    `xml:"startWithSAP,attr,omitempty" json:"startWithSAP,omitempty"`
+	MaxPlayoutRate            float64                 `xml:"maxPlayoutRate,attr,omitempty" json:"maxPlayoutRate,omitempty"`
+	CodingDependency          bool                    `xml:"codingDependency,attr,omitempty" json:"codingDependency,omitempty"`
+	ScanType                  VideoScan               `xml:"scanType,attr,omitempty" json:"scanType,omitempty"`
 
 	// SelectionPriority defaults to `1`.
-	SelectionPriority uint `xml:"selectionPriority,attr,omitempty"`
+	SelectionPriority uint `xml:"selectionPriority,attr,omitempty" json:"selectionPriority,omitempty"`
 
-	Tag Tag `xml:"tag,attr,omitempty"`
+	Tag Tag `xml:"tag,attr,omitempty" json:"tag,omitempty"`
 }
 
 type ContentProtection struct {
 	Descriptor
 
-	MSPro          []string `xml:"urn:microsoft:playready mspr:pro,omitempty"`
-	CENCPSSH       []string `xml:"urn:mpeg:cenc:2013 cenc:pssh,omitempty"`
-	CENCDefaultKID string   `xml:"urn:mpeg:cenc:2013 cenc:default_KID,attr,omitempty"`
-	Robustness     string   `xml:"robustness,attr,omitempty"`
-	RefID          string   `xml:"refId,attr,omitempty"`
-	Ref            string   `xml:"ref,attr,omitempty"`
+	MSPro          []string `xml:"urn:microsoft:playready mspr:pro,omitempty" json:"msprPro,omitempty"`
+	CENCPSSH       []string `xml:"urn:mpeg:cenc:2013 cenc:pssh,omitempty" json:"cencPssh,omitempty"`
+	CENCDefaultKID string   `xml:"urn:mpeg:cenc:2013 cenc:default_KID,attr,omitempty" json:"cencDefaultKID,omitempty"`
+	Robustness     string   `xml:"robustness,attr,omitempty" json:"robustness,omitempty"`
+	RefID          string   `xml:"refId,attr,omitempty" json:"refId,omitempty"`
+	Ref            string   `xml:"ref,attr,omitempty" json:"ref,omitempty"`
 }
 
 type Resync struct {
 	// Type defaults to `0`.
-	Type SAPType `xml:"type,attr,omitempty"`
+	Type SAPType `xml:"type,attr,omitempty" json:"type,omitempty"`
 
-	DT    float32 `xml:"dT,attr,omitempty"`
-	DIMax float32 `xml:"dImax,attr,omitempty"`
+	DT    float32 `xml:"dT,attr,omitempty" json:"dT,omitempty"`
+	DIMax float32 `xml:"dImax,attr,omitempty" json:"dImax,omitempty"`
 
 	// DIMin defaults to `0`.
-	DIMin float32 `xml:"dImin,attr,omitempty"`
+	DIMin float32 `xml:"dImin,attr,omitempty" json:"dImin,omitempty"`
 
 	// Marker defaults to `false`.
-	Marker bool `xml:"marker,attr,omitempty"`
+	Marker bool `xml:"marker,attr,omitempty" json:"marker,omitempty"`
 }
 
 type ContentPopularityRate struct {
-	PR                []PR   `xml:"PR"`
-	Source            Source `xml:"source,attr"`
-	SourceDescription string `xml:"source_description,attr,omitempty"`
+	PR                []PR   `xml:"PR" json:"pr,omitempty"`
+	Source            Source `xml:"source,attr" json:"source,omitempty"`
+	SourceDescription string `xml:"source_description,attr,omitempty" json:"sourceDescription,omitempty"`
 }
 
 type PR struct {
-	PopularityRate uint   `xml:"popularityRate,attr,omitempty"`
-	Start          uint64 `xml:"start,attr,omitempty"`
+	PopularityRate uint   `xml:"popularityRate,attr,omitempty" json:"popularityRate,omitempty"`
+	Start          uint64 `xml:"start,attr,omitempty" json:"start,omitempty"`
 
 	// R defaults to `0`.
-	R int `xml:"r,attr,omitempty"`
+	R int `xml:"r,attr,omitempty" json:"r,omitempty"`
 }
 
 type Label struct {
 	// ID defaults to `0`.
-	ID uint `xml:"id,attr,omitempty"`
+	ID uint `xml:"id,attr,omitempty" json:"id,omitempty"`
 
-	Lang  string   `xml:"lang,attr,omitempty"`
-	Items []string `xml:",chardata"`
+	Lang  string `xml:"lang,attr,omitempty" json:"lang,omitempty"`
+	Value string `xml:",chardata" json:"value,omitempty"`
 }
 
 type ProducerReferenceTime struct {
-	Items     []string    `xml:",any"`
-	UTCTiming *Descriptor `xml:"UTCTiming,omitempty"`
-	ID        uint        `xml:"id,attr"`
+	Items     []string    `xml:",any" json:"items,omitempty"`
+	UTCTiming *Descriptor `xml:"UTCTiming,omitempty" json:"utcTiming,omitempty"`
+	ID        uint        `xml:"id,attr" json:"id,omitempty"`
 
 	// Inband defaults to `false`.
-	Inband bool `xml:"inband,attr,omitempty"`
+	Inband bool `xml:"inband,attr,omitempty" json:"inband,omitempty"`
 
 	// Type defaults to EncoderProducerReferenceTimeType.
-	Type ProducerReferenceTimeType `xml:"type,attr,omitempty"`
+	Type ProducerReferenceTimeType `xml:"type,attr,omitempty" json:"type,omitempty"`
 
-	ApplicationScheme string `xml:"applicationScheme,attr,omitempty"`
-	WallClockTime     string `xml:"wallClockTime,attr"`
-	PresentationTime  uint64 `xml:"presentationTime,attr"`
+	ApplicationScheme string `xml:"applicationScheme,attr,omitempty" json:"applicationScheme,omitempty"`
+	WallClockTime     string `xml:"wallClockTime,attr" json:"wallClockTime,omitempty"`
+	PresentationTime  uint64 `xml:"presentationTime,attr" json:"presentationTime,omitempty"`
 }
 
 type Preselection struct {
 	RepresentationBase
 
-	Accessibility []Descriptor `xml:"Accessibility,omitempty"`
-	Role          []Descriptor `xml:"Role,omitempty"`
-	Rating        []Descriptor `xml:"Rating,omitempty"`
-	Viewpoint     []Descriptor `xml:"Viewpoint,omitempty"`
+	Accessibility []Descriptor `xml:"Accessibility,omitempty" json:"accessibility,omitempty"`
+	Role          []Descriptor `xml:"Role,omitempty" json:"role,omitempty"`
+	Rating        []Descriptor `xml:"Rating,omitempty" json:"rating,omitempty"`
+	Viewpoint     []Descriptor `xml:"Viewpoint,omitempty" json:"viewpoint,omitempty"`
 
 	// ID defaults to `1`.
-	ID string `xml:"id,attr,omitempty"`
+	ID string `xml:"id,attr,omitempty" json:"id,omitempty"`
 
-	PreselectionComponents StringVector `xml:"preselectionComponents,attr"`
-	Lang                   string       `xml:"lang,attr,omitempty"`
+	PreselectionComponents StringVector `xml:"preselectionComponents,attr" json:"preselectionComponents,omitempty"`
+	Lang                   string       `xml:"lang,attr,omitempty" json:"lang,omitempty"`
 
 	// Order defaults to UndefinedPreselectionOrder.
-	Order PreselectionOrder `xml:"order,attr,omitempty"`
+	Order PreselectionOrder `xml:"order,attr,omitempty" json:"order,omitempty"`
 }
 
 type Representation struct {
 	RepresentationBase
 
-	BaseURL                []BaseURL           `xml:"BaseURL,omitempty"`
-	ExtendedBandwidth      []ExtendedBandwidth `xml:"ExtendedBandwidth,omitempty"`
-	SubRepresentation      []SubRepresentation `xml:"SubRepresentation,omitempty"`
-	SegmentBase            *SegmentBase        `xml:"SegmentBase,omitempty"`
-	SegmentList            *SegmentList        `xml:"SegmentList,omitempty"`
-	SegmentTemplate        *SegmentTemplate    `xml:"SegmentTemplate,omitempty"`
-	Bandwidth              uint                `xml:"bandwidth,attr"`
-	ID                     string              `xml:"id,attr,omitempty"`
-	QualityRanking         uint                `xml:"qualityRanking,attr,omitempty"`
-	DependencyId           StringVector        `xml:"dependencyId,attr,omitempty"`
-	AssociationId          StringVector        `xml:"associationId,attr,omitempty"`
-	AssociationType        ListOf4CC           `xml:"associationType,attr,omitempty"`
-	MediaStreamStructureId StringVector        `xml:"mediaStreamStructureId,attr,omitempty"`
+	BaseURL                []BaseURL           `xml:"BaseURL,omitempty" json:"baseURL,omitempty"`
+	ExtendedBandwidth      []ExtendedBandwidth `xml:"ExtendedBandwidth,omitempty" json:"extendedBandwidth,omitempty"`
+	SubRepresentation      []SubRepresentation `xml:"SubRepresentation,omitempty" json:"subRepresentation,omitempty"`
+	SegmentBase            *SegmentBase        `xml:"SegmentBase,omitempty" json:"segmentBase,omitempty"`
+	SegmentList            *SegmentList        `xml:"SegmentList,omitempty" json:"segmentList,omitempty"`
+	SegmentTemplate        *SegmentTemplate    `xml:"SegmentTemplate,omitempty" json:"segmentTemplate,omitempty"`
+	Bandwidth              uint                `xml:"bandwidth,attr" json:"bandwidth,omitempty"`
+	ID                     string              `xml:"id,attr,omitempty" json:"id,omitempty"`
+	QualityRanking         uint                `xml:"qualityRanking,attr,omitempty" json:"qualityRanking,omitempty"`
+	DependencyId           StringVector        `xml:"dependencyId,attr,omitempty" json:"dependencyId,omitempty"`
+	AssociationId          StringVector        `xml:"associationId,attr,omitempty" json:"associationId,omitempty"`
+	AssociationType        ListOf4CC           `xml:"associationType,attr,omitempty" json:"associationType,omitempty"`
+	MediaStreamStructureId StringVector        `xml:"mediaStreamStructureId,attr,omitempty" json:"mediaStreamStructureId,omitempty"`
 }
 
 type ExtendedBandwidth struct {
-	Items     []string    `xml:",any"`
-	ModelPair []ModelPair `xml:"ModelPair,omitempty"`
+	Items     []string    `xml:",any" json:"items,omitempty"`
+	ModelPair []ModelPair `xml:"ModelPair,omitempty" json:"modelPair,omitempty"`
 
 	// VBR defaults to `false`.
-	VBR bool `xml:"vbr,attr,omitempty"`
+	VBR bool `xml:"vbr,attr,omitempty" json:"vbr,omitempty"`
 }
 
 type ModelPair struct {
-	Items      []string `xml:",any"`
-	BufferTime string   `xml:"bufferTime,attr"`
-	Bandwidth  uint     `xml:"bandwidth,attr"`
+	Items      []string `xml:",any" json:"items,omitempty"`
+	BufferTime string   `xml:"bufferTime,attr" json:"bufferTime,omitempty"`
+	Bandwidth  uint     `xml:"bandwidth,attr" json:"bandwidth,omitempty"`
 }
 
 type SubRepresentation struct {
 	RepresentationBase
 
-	Level            uint         `xml:"level,attr,omitempty"`
-	DependencyLevel  UIntVector   `xml:"dependencyLevel,attr,omitempty"`
-	Bandwidth        uint         `xml:"bandwidth,attr,omitempty"`
-	ContentComponent StringVector `xml:"contentComponent,attr,omitempty"`
+	Level            uint         `xml:"level,attr,omitempty" json:"level,omitempty"`
+	DependencyLevel  UIntVector   `xml:"dependencyLevel,attr,omitempty" json:"dependencyLevel,omitempty"`
+	Bandwidth        uint         `xml:"bandwidth,attr,omitempty" json:"bandwidth,omitempty"`
+	ContentComponent StringVector `xml:"contentComponent,attr,omitempty" json:"contentComponent,omitempty"`
 }
 
 type Subset struct {
-	Contains UIntVector `xml:"contains,attr"`
-	ID       string     `xml:"id,attr,omitempty"`
+	Contains UIntVector `xml:"contains,attr" json:"contains,omitempty"`
+	ID       string     `xml:"id,attr,omitempty" json:"id,omitempty"`
 }
 
 type Switching struct {
-	Interval uint `xml:"interval,attr"`
+	Interval uint `xml:"interval,attr" json:"interval,omitempty"`
 
 	// Type defaults to MediaSwitchingType.
-	Type SwitchingType `xml:"type,attr,omitempty"`
+	Type SwitchingType `xml:"type,attr,omitempty" json:"type,omitempty"`
 }
 
 type RandomAccess struct {
-	Interval uint `xml:"interval,attr"`
+	Interval uint `xml:"interval,attr" json:"interval,omitempty"`
 
 	// Type defaults to ClosedRandomAccessType.
-	Type RandomAccessType `xml:"type,attr,omitempty"`
+	Type RandomAccessType `xml:"type,attr,omitempty" json:"type,omitempty"`
 
-	MinBufferTime string `xml:"minBufferTime,attr,omitempty"`
-	Bandwidth     uint   `xml:"bandwidth,attr,omitempty"`
+	MinBufferTime string `xml:"minBufferTime,attr,omitempty" json:"minBufferTime,omitempty"`
+	Bandwidth     uint   `xml:"bandwidth,attr,omitempty" json:"bandwidth,omitempty"`
 }
 
 type S struct {
-	T *uint64 `xml:"t,attr,omitempty"`
-	N uint64  `xml:"n,attr,omitempty"`
-	D uint64  `xml:"d,attr"`
+	T *uint64 `xml:"t,attr,omitempty" json:"t,omitempty"`
+	N uint64  `xml:"n,attr,omitempty" json:"n,omitempty"`
+	D uint64  `xml:"d,attr" json:"d,omitempty"`
 
 	// R defaults to `0`.
-	R int `xml:"r,attr,omitempty"`
+	R int `xml:"r,attr,omitempty" json:"r,omitempty"`
 
 	// K defaults to `1`.
-	K uint64 `xml:"k,attr,omitempty"`
+	K uint64 `xml:"k,attr,omitempty" json:"k,omitempty"`
 }
 
 type SegmentBase struct {
-	Items                  []string         `xml:",any"`
-	Initialization         *URL             `xml:"Initialization,omitempty"`
-	RepresentationIndex    *URL             `xml:"RepresentationIndex,omitempty"`
-	FailoverContent        *FailoverContent `xml:"FailoverContent,omitempty"`
-	Timescale              uint             `xml:"timescale,attr,omitempty"`
-	EPTDelta               int              `xml:"eptDelta,attr,omitempty"`
-	PDDelta                int              `xml:"pdDelta,attr,omitempty"`
-	PresentationTimeOffset uint64           `xml:"presentationTimeOffset,attr,omitempty"`
-	PresentationDuration   uint64           `xml:"presentationDuration,attr,omitempty"`
+	Items                  []string         `xml:",any" json:"items,omitempty"`
+	Initialization         *URL             `xml:"Initialization,omitempty" json:"initializationElement,omitempty"`
+	RepresentationIndex    *URL             `xml:"RepresentationIndex,omitempty" json:"representationIndex,omitempty"`
+	FailoverContent        *FailoverContent `xml:"FailoverContent,omitempty" json:"failoverContent,omitempty"`
+	Timescale              uint             `xml:"timescale,attr,omitempty" json:"timescale,omitempty"`
+	EPTDelta               int              `xml:"eptDelta,attr,omitempty" json:"eptDelta,omitempty"`
+	PDDelta                int              `xml:"pdDelta,attr,omitempty" json:"pdDelta,omitempty"`
+	PresentationTimeOffset uint64           `xml:"presentationTimeOffset,attr,omitempty" json:"presentationTimeOffset,omitempty"`
+	PresentationDuration   uint64           `xml:"presentationDuration,attr,omitempty" json:"presentationDuration,omitempty"`
 
 	// IndexRange defaults to `false`.
-	IndexRange SingleRFC7233Range `xml:"indexRange,attr,omitempty"`
+	IndexRange SingleRFC7233Range `xml:"indexRange,attr,omitempty" json:"indexRange,omitempty"`
+
+	IndexRangeExact        bool    `xml:"indexRangeExact,attr,omitempty" json:"indexRangeExact,omitempty"`
+	AvailabilityTimeOffset float64 `xml:"availabilityTimeOffset,attr,omitempty" json:"availabilityTimeOffset,omitempty"`
 
-	IndexRangeExact          bool    `xml:"indexRangeExact,attr,omitempty"`
-	AvailabilityTimeOffset   float64 `xml:"availabilityTimeOffset,attr,omitempty"`
-	AvailabilityTimeComplete bool    `xml:"availabilityTimeComplete,attr,omitempty"`
+	// AvailabilityTimeComplete defaults to `true`.
+	AvailabilityTimeComplete *bool `xml:"availabilityTimeComplete,attr,omitempty" json:"availabilityTimeComplete,omitempty"`
 }
 
 type MultipleSegmentBase struct {
 	SegmentBase
 
-	SegmentTimeline    *SegmentTimeline `xml:"SegmentTimeline,omitempty"`
-	BitstreamSwitching *URL             `xml:"BitstreamSwitching,omitempty"`
-	Duration           uint             `xml:"duration,attr,omitempty"`
-	StartNumber        uint             `xml:"startNumber,attr,omitempty"`
-	EndNumber          uint             `xml:"endNumber,attr,omitempty"`
+	SegmentTimeline    *SegmentTimeline `xml:"SegmentTimeline,omitempty" json:"segmentTimeline,omitempty"`
+	BitstreamSwitching *URL             `xml:"BitstreamSwitching,omitempty" json:"bitstreamSwitchingElement,omitempty"`
+	Duration           uint             `xml:"duration,attr,omitempty" json:"duration,omitempty"`
+	StartNumber        uint             `xml:"startNumber,attr,omitempty" json:"startNumber,omitempty"`
+	EndNumber          uint             `xml:"endNumber,attr,omitempty" json:"endNumber,omitempty"`
 }
 
 type FailoverContent struct {
-	FCS []FCS `xml:"FCS"`
+	FCS []FCS `xml:"FCS" json:"fcs,omitempty"`
 
 	// Valid defaults to `true`.
-	Valid bool `xml:"valid,attr,omitempty"`
+	Valid bool `xml:"valid,attr,omitempty" json:"valid,omitempty"`
 }
 
 type FCS struct {
-	T uint64 `xml:"t,attr"`
-	D uint64 `xml:"d,attr,omitempty"`
+	T uint64 `xml:"t,attr" json:"t,omitempty"`
+	D uint64 `xml:"d,attr,omitempty" json:"d,omitempty"`
 }
 
 type SegmentList struct {
 	MultipleSegmentBase
 
-	Items      []string     `xml:",any"`
-	SegmentURL []SegmentURL `xml:"SegmentURL,omitempty"`
-	XLinkHref  string       `xml:"http://www.w3.org/1999/xlink xlink:href,attr,omitempty"`
+	Items      []string     `xml:",any" json:"items,omitempty"`
+	SegmentURL []SegmentURL `xml:"SegmentURL,omitempty" json:"segmentURL,omitempty"`
+	XLinkHref  string       `xml:"http://www.w3.org/1999/xlink xlink:href,attr,omitempty" json:"xlinkHref,omitempty"`
 
 	// XLinkActuate defaults to OnRequestXLinkActuate.
-	XLinkActuate XLinkActuate `xml:"http://www.w3.org/1999/xlink xlink:actuate,attr,omitempty"`
+	XLinkActuate XLinkActuate `xml:"http://www.w3.org/1999/xlink xlink:actuate,attr,omitempty" json:"xlinkActuate,omitempty"`
 
 	// XLinkType must be SimpleXLinkType.
-	XLinkType XLinkType `xml:"http://www.w3.org/1999/xlink xlink:type,attr,omitempty"`
+	XLinkType XLinkType `xml:"http://www.w3.org/1999/xlink xlink:type,attr,omitempty" json:"xlinkType,omitempty"`
 
 	// XLinkShow must be EmbedXLinkShow.
-	XLinkShow XLinkShow `xml:"http://www.w3.org/1999/xlink xlink:show,attr,omitempty"`
+	XLinkShow XLinkShow `xml:"http://www.w3.org/1999/xlink xlink:show,attr,omitempty" json:"xlinkShow,omitempty"`
 }
 
 type SegmentURL struct {
-	Items      []string           `xml:",any"`
-	Media      string             `xml:"media,attr,omitempty"`
-	MediaRange SingleRFC7233Range `xml:"mediaRange,attr,omitempty"`
-	Index      string             `xml:"index,attr,omitempty"`
-	IndexRange SingleRFC7233Range `xml:"indexRange,attr,omitempty"`
+	Items      []string           `xml:",any" json:"items,omitempty"`
+	Media      string             `xml:"media,attr,omitempty" json:"media,omitempty"`
+	MediaRange SingleRFC7233Range `xml:"mediaRange,attr,omitempty" json:"mediaRange,omitempty"`
+	Index      string             `xml:"index,attr,omitempty" json:"index,omitempty"`
+	IndexRange SingleRFC7233Range `xml:"indexRange,attr,omitempty" json:"indexRange,omitempty"`
 }
 
 type SegmentTemplate struct {
 	MultipleSegmentBase
 
-	Media              string `xml:"media,attr,omitempty"`
-	Index              string `xml:"index,attr,omitempty"`
-	Initialization     string `xml:"initialization,attr,omitempty"`
-	BitstreamSwitching string `xml:"bitstreamSwitching,attr,omitempty"`
+	Media              string `xml:"media,attr,omitempty" json:"media,omitempty"`
+	Index              string `xml:"index,attr,omitempty" json:"index,omitempty"`
+	Initialization     string `xml:"initialization,attr,omitempty" json:"initialization,omitempty"`
+	BitstreamSwitching string `xml:"bitstreamSwitching,attr,omitempty" json:"bitstreamSwitching,omitempty"`
 }
 
 type SegmentTimeline struct {
-	Items []string `xml:",any"`
-	S     []S      `xml:"S"`
+	Items []string `xml:",any" json:"items,omitempty"`
+	S     []S      `xml:"S" json:"s,omitempty"`
 }
 
 type URL struct {
-	Items     []string           `xml:",any"`
-	SourceURL string             `xml:"sourceURL,attr,omitempty"`
-	Range     SingleRFC7233Range `xml:"range,attr,omitempty"`
+	Items     []string           `xml:",any" json:"items,omitempty"`
+	SourceURL string             `xml:"sourceURL,attr,omitempty" json:"sourceURL,omitempty"`
+	Range     SingleRFC7233Range `xml:"range,attr,omitempty" json:"range,omitempty"`
 }
 
 type ProgramInformation struct {
-	Items              []string `xml:",any"`
-	Title              string   `xml:"Title,omitempty"`
-	Source             string   `xml:"Source,omitempty"`
-	Copyright          string   `xml:"Copyright,omitempty"`
-	Lang               string   `xml:"lang,attr,omitempty"`
-	MoreInformationURL string   `xml:"moreInformationURL,attr,omitempty"`
+	Items              []string `xml:",any" json:"items,omitempty"`
+	Title              string   `xml:"Title,omitempty" json:"title,omitempty"`
+	Source             string   `xml:"Source,omitempty" json:"source,omitempty"`
+	Copyright          string   `xml:"Copyright,omitempty" json:"copyright,omitempty"`
+	Lang               string   `xml:"lang,attr,omitempty" json:"lang,omitempty"`
+	MoreInformationURL string   `xml:"moreInformationURL,attr,omitempty" json:"moreInformationURL,omitempty"`
 }
 
 type LeapSecondInformation struct {
-	Items                           []string `xml:",any"`
-	AvailabilityStartLeapOffset     int      `xml:"availabilityStartLeapOffset,attr,omitempty"`
-	NextAvailabilityStartLeapOffset int      `xml:"nextAvailabilityStartLeapOffset,attr,omitempty"`
-	NextLeapChangeTime              string   `xml:"nextLeapChangeTime,attr,omitempty"`
+	Items                           []string `xml:",any" json:"items,omitempty"`
+	AvailabilityStartLeapOffset     int      `xml:"availabilityStartLeapOffset,attr,omitempty" json:"availabilityStartLeapOffset,omitempty"`
+	NextAvailabilityStartLeapOffset int      `xml:"nextAvailabilityStartLeapOffset,attr,omitempty" json:"nextAvailabilityStartLeapOffset,omitempty"`
+	NextLeapChangeTime              string   `xml:"nextLeapChangeTime,attr,omitempty" json:"nextLeapChangeTime,omitempty"`
 }
@@ -43,3 +43,30 @@ func ExampleMPD_Bytes() {
 	//   <Period id="period-0"></Period>
 	// </MPD>
 }
+
+func ExampleMPD_JSON() {
+	example := mpd.New()
+	example.Profiles = mpd.OnDemand2011Profile
+	example.Type = mpd.StaticPresentationType
+	example.MinBufferTime = "PT2S"
+	example.Period = []mpd.Period{{ID: "period-0", Duration: "PT30S"}}
+
+	exampleJSON, err := example.JSON()
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	fmt.Printf("%s", exampleJSON)
+	// Output: {
+	//   "period": [
+	//     {
+	//       "id": "period-0",
+	//       "duration": "PT30S"
+	//     }
+	//   ],
+	//   "xmlns": "urn:mpeg:dash:schema:mpd:2011",
+	//   "profiles": "urn:mpeg:dash:profile:isoff-on-demand:2011",
+	//   "type": "static",
+	//   "minBufferTime": "PT2S"
+	// }
+}
@@ -2,15 +2,18 @@ package mpd_test
 
 import (
 	"aqwari.net/xml/xmltree"
+	"bytes"
 	"errors"
 	"github.com/google/go-cmp/cmp"
 	"go.eigsys.de/go-mpd"
 	"go.eigsys.de/go-mpd/third_party/encoding/xml"
 	"io"
 	"log"
+	"math"
 	"os"
 	"path"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -197,3 +200,119 @@ func TestRoundTrip(t *testing.T) {
 		})
 	}
 }
+
+func TestJSONRoundTrip(t *testing.T) {
+	testCases := []string{
+		"zencoder/adaptationset_switching.mpd",
+		"zencoder/audio_channel_configuration.mpd",
+		"zencoder/events.mpd",
+		"zencoder/hbbtv_profile.mpd",
+		"zencoder/inband_event_stream.mpd",
+		"zencoder/live_profile.mpd",
+		"zencoder/live_profile_dynamic.mpd",
+		"zencoder/live_profile_multi_base_url.mpd",
+		"zencoder/location.mpd",
+		"zencoder/multiple_supplementals.mpd",
+		"zencoder/newperiod.mpd",
+		"zencoder/ondemand_profile.mpd",
+		"zencoder/segment_list.mpd",
+		"zencoder/segment_timeline.mpd",
+		"zencoder/segment_timeline_multi_period.mpd",
+		"zencoder/truncate.mpd",
+		"zencoder/truncate_short.mpd",
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			testMPD, err := mpd.Read(mustOpenFixture(testCase))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			jsonData, err := testMPD.JSON()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			jsonMPD, err := mpd.ReadJSON(io.NopCloser(bytes.NewReader(jsonData)))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(testMPD, jsonMPD); diff != "" {
+				t.Errorf("wrong MPD: %s", diff)
+			}
+
+			output, err := jsonMPD.Bytes()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			input, err := io.ReadAll(mustOpenFixture(testCase))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			inputDoc, err := xmltree.Parse(input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			outputDoc, err := xmltree.Parse(output)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !xmltree.Equal(inputDoc, outputDoc) {
+				diff := cmp.Diff(xmltree.MarshalIndent(inputDoc, "", "  "), xmltree.MarshalIndent(outputDoc, "", "  "))
+				t.Errorf("wrong MPD: %s", diff)
+			}
+		})
+	}
+}
+
+func TestJSONRoundTrip_SegmentTemplate(t *testing.T) {
+	testMPD := mpd.New()
+	testMPD.Period = []mpd.Period{{AdaptationSet: []mpd.AdaptationSet{{
+		SegmentTemplate: &mpd.SegmentTemplate{
+			MultipleSegmentBase: mpd.MultipleSegmentBase{
+				SegmentBase:        mpd.SegmentBase{Initialization: &mpd.URL{SourceURL: "init.mp4"}},
+				BitstreamSwitching: &mpd.URL{SourceURL: "switch.mp4"},
+			},
+			Media:              "$Number$.m4s",
+			Initialization:     "$RepresentationID$/init.mp4",
+			BitstreamSwitching: "$RepresentationID$/switch.mp4",
+		},
+	}}}}
+
+	jsonData, err := testMPD.JSON()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	jsonMPD, err := mpd.ReadJSON(io.NopCloser(bytes.NewReader(jsonData)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(testMPD, jsonMPD); diff != "" {
+		t.Errorf("wrong MPD: %s", diff)
+	}
+}
+
+func TestReadJSON_Errors(t *testing.T) {
+	if _, err := mpd.ReadJSON(NopReadCloser{}); !errors.Is(err, mpd.ErrReadMPD) {
+		t.Errorf("wrong error: %v", err)
+	}
+
+	if _, err := mpd.ReadJSON(io.NopCloser(strings.NewReader(`{"period": {}}`))); !errors.Is(err, mpd.ErrUnmarshalMPD) {
+		t.Errorf("wrong error: %v", err)
+	}
+}
+
+func TestMPD_JSON_Error(t *testing.T) {
+	testMPD := &mpd.MPD{BaseURL: []mpd.BaseURL{{AvailabilityTimeOffset: math.Inf(1)}}}
+	if _, err := testMPD.JSON(); !errors.Is(err, mpd.ErrMarshalMPD) {
+		t.Errorf("wrong error: %v", err)
+	}
+}
@@ -0,0 +1,771 @@
+package mpd
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrConvertToHLS = errors.New("cannot convert MPD to HLS")
+
+// HLSOptions configures the conversion of an MPD to HLS playlists.
+type HLSOptions struct {
+	// Now is the wall clock time a dynamic MPD is converted at. The media playlists then contain the segments
+	// that are available within the time shift buffer.
+	Now time.Time
+
+	// PlaylistURI returns the URI of the media playlist of a Representation.
+	// Representations with the same URI in different Periods are joined with discontinuities.
+	// It defaults to the content type and the escaped Representation ID, e.g. video_1.m3u8.
+	PlaylistURI func(adaptationSet *AdaptationSet, representation *Representation) string
+
+	// ReadSegmentIndex reads the SegmentIndex of Representations using SegmentBase, so that their subsegments
+	// are addressed by byte ranges. Without it, every resource is a single segment.
+	ReadSegmentIndex func(url string, indexRange SingleRFC7233Range) (*SegmentIndex, error)
+
+	// FairPlayKeyURI is the skd URI of the FairPlay EXT-X-KEY, which is not part of the MPD.
+	// It is only used for content protected with the cbcs scheme or signalling FairPlay.
+	FairPlayKeyURI string
+}
+
+// HLSPlaylists are the result of the conversion of an MPD.
+type HLSPlaylists struct {
+	Multivariant *HLSMultivariantPlaylist
+
+	// Media maps the URIs of the media playlists referenced by Multivariant to the playlists.
+	Media map[string]*HLSMediaPlaylist
+}
+
+const (
+	playReadyHLSKeyFormat = "com.microsoft.playready"
+	fairPlayHLSKeyFormat  = "com.apple.streamingkeydelivery"
+)
+
+// ConvertToHLS converts the MPD to an HLS multivariant playlist with a media playlist per Representation.
+// Audio and text AdaptationSets become EXT-X-MEDIA renditions grouped by codecs, video Representations become
+// variant streams referencing every audio group. Without video, audio Representations become the variant streams.
+// ContentProtection is mapped to EXT-X-KEY and EXT-X-SESSION-KEY, SCTE-35 Events to EXT-X-DATERANGE.
+// Static MPDs result in VOD playlists, dynamic MPDs in sliding window playlists as of HLSOptions.Now.
+func (m *MPD) ConvertToHLS(options *HLSOptions) (*HLSPlaylists, error) {
+	if options == nil {
+		options = &HLSOptions{}
+	}
+
+	c := &hlsConverter{mpd: m, options: options, renditions: map[string]*hlsRendition{}}
+	if err := c.init(); err != nil {
+		return nil, err
+	}
+
+	for i := range m.Period {
+		period := &m.Period[i]
+
+		for j := range period.AdaptationSet {
+			adaptationSet := &period.AdaptationSet[j]
+
+			for k := range adaptationSet.Representation {
+				if err := c.addRepresentation(i, adaptationSet, &adaptationSet.Representation[k]); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if err := c.addDateRanges(); err != nil {
+		return nil, err
+	}
+
+	return c.playlists(), nil
+}
+
+type hlsConverter struct {
+	mpd     *MPD
+	options *HLSOptions
+
+	// filter selects the segments of dynamic MPDs and is nil for static MPDs.
+	filter *SegmentFilter
+
+	// epoch is the wall clock time of the start of the media presentation.
+	epoch time.Time
+
+	renditions map[string]*hlsRendition
+	order      []*hlsRendition
+}
+
+type hlsRendition struct {
+	uri            string
+	contentType    ContentType
+	adaptationSet  *AdaptationSet
+	representation *Representation
+	playlist       *HLSMediaPlaylist
+
+	// presentationTime is the presentation time of every segment of the playlist.
+	presentationTime []time.Duration
+
+	// encrypted reports whether the last EXT-X-KEY of the playlist encrypts the segments.
+	encrypted bool
+}
+
+func (c *hlsConverter) init() error {
+	c.epoch = time.Unix(0, 0).UTC()
+
+	if c.mpd.AvailabilityStartTime != "" {
+		var err error
+		if c.epoch, err = ParseDateTime(c.mpd.AvailabilityStartTime); err != nil {
+			return errors.Join(ErrConvertToHLS, err)
+		}
+	}
+
+	if c.mpd.Type != DynamicPresentationType {
+		return nil
+	}
+
+	if c.options.Now.IsZero() {
+		return fmt.Errorf("%w: dynamic MPD requires the current time", ErrConvertToHLS)
+	}
+
+	now := c.options.Now.Sub(c.epoch)
+	c.filter = &SegmentFilter{End: now}
+
+	if c.mpd.TimeShiftBufferDepth != "" {
+		timeShiftBufferDepth, err := ParseDuration(c.mpd.TimeShiftBufferDepth)
+		if err != nil {
+			return errors.Join(ErrConvertToHLS, err)
+		}
+
+		if now > timeShiftBufferDepth {
+			c.filter.Start = now - timeShiftBufferDepth
+		}
+	}
+
+	return nil
+}
+
+func (c *hlsConverter) addRepresentation(periodIndex int, adaptationSet *AdaptationSet, representation *Representation) error {
+	contentType := hlsContentType(adaptationSet, representation)
+	if contentType == "" {
+		return nil
+	}
+
+	period := &c.mpd.Period[periodIndex]
+
+	segments, err := c.segments(period, adaptationSet, representation)
+	if err != nil {
+		return err
+	}
+
+	uri := url.PathEscape(string(contentType)) + "_" + url.PathEscape(representation.ID) + ".m3u8"
+	if c.options.PlaylistURI != nil {
+		uri = c.options.PlaylistURI(adaptationSet, representation)
+	}
+
+	rendition, ok := c.renditions[uri]
+	if !ok {
+		rendition = &hlsRendition{
+			uri:            uri,
+			contentType:    contentType,
+			adaptationSet:  adaptationSet,
+			representation: representation,
+			playlist:       &HLSMediaPlaylist{DiscontinuitySequence: uint64(periodIndex), MediaSequence: math.MaxUint64},
+		}
+		c.renditions[uri] = rendition
+		c.order = append(c.order, rendition)
+	}
+
+	if len(segments) == 0 {
+		return nil
+	}
+
+	playlist := rendition.playlist
+	first := len(playlist.Segment)
+
+	if first == 0 && c.filter != nil {
+		playlist.MediaSequence = segments[0].Number
+		playlist.DiscontinuitySequence = uint64(periodIndex)
+	}
+
+	for _, segment := range segments {
+		hlsSegment := HLSSegment{URI: segment.URL, Duration: segment.PresentationDuration}
+		if segment.Range != "" {
+			if hlsSegment.ByteRange, err = hlsByteRange(segment.Range); err != nil {
+				return err
+			}
+		}
+
+		playlist.Segment = append(playlist.Segment, hlsSegment)
+		rendition.presentationTime = append(rendition.presentationTime, segment.PresentationTime)
+	}
+
+	start := &playlist.Segment[first]
+	start.Discontinuity = first > 0
+
+	if c.filter != nil {
+		start.ProgramDateTime = c.epoch.Add(segments[0].PresentationTime)
+	}
+
+	initialization, err := c.mpd.InitializationSegment(period, adaptationSet, representation)
+	if err != nil {
+		return errors.Join(ErrConvertToHLS, err)
+	}
+
+	if initialization != nil {
+		start.Map = &HLSMap{URI: initialization.URL}
+		if initialization.Range != "" {
+			if start.Map.ByteRange, err = hlsByteRange(initialization.Range); err != nil {
+				return err
+			}
+		}
+	}
+
+	contentProtection, err := c.mpd.EffectiveContentProtection(period, adaptationSet, representation)
+	if err != nil {
+		return errors.Join(ErrConvertToHLS, err)
+	}
+
+	if start.Key, err = hlsKeys(contentProtection, c.options.FairPlayKeyURI); err != nil {
+		return err
+	}
+
+	if len(start.Key) == 0 && rendition.encrypted {
+		start.Key = []HLSKey{{Method: NoneHLSKeyMethod}}
+	}
+
+	rendition.encrypted = len(start.Key) > 0 && start.Key[0].Method != NoneHLSKeyMethod
+
+	return nil
+}
+
+// segments returns the available segments of the Representation, addressing the subsegments of SegmentBase
+// if HLSOptions.ReadSegmentIndex is set.
+func (c *hlsConverter) segments(period *Period, adaptationSet *AdaptationSet, representation *Representation) ([]Segment, error) {
+	if segmentBase, _, _ := segmentInformation(period, adaptationSet, representation); segmentBase != nil && c.options.ReadSegmentIndex != nil {
+		indexURL := c.mpd.resolveBaseURL(period, adaptationSet, representation)
+		indexRange := segmentBase.IndexRange
+
+		if segmentBase.RepresentationIndex != nil {
+			indexURL = resolveURL(indexURL, segmentBase.RepresentationIndex.SourceURL)
+			indexRange = segmentBase.RepresentationIndex.Range
+		}
+
+		index, err := c.options.ReadSegmentIndex(indexURL, indexRange)
+		if err != nil {
+			return nil, errors.Join(ErrConvertToHLS, err)
+		}
+
+		indexed := *representation
+		indexed.SegmentBase = segmentBase
+		indexed.ConvertSegmentBaseToSegmentList(index)
+		representation = &indexed
+	}
+
+	segments, err := c.mpd.Segments(period, adaptationSet, representation, c.filter)
+	if err != nil {
+		return nil, errors.Join(ErrConvertToHLS, err)
+	}
+
+	if c.filter != nil {
+		available := segments[:0]
+		for _, segment := range segments {
+			if segment.PresentationTime+segment.PresentationDuration <= c.filter.End {
+				available = append(available, segment)
+			}
+		}
+
+		segments = available
+	}
+
+	return segments, nil
+}
+
+// addDateRanges adds EXT-X-DATERANGE tags for SCTE-35 Events to the segments they start in.
+func (c *hlsConverter) addDateRanges() error {
+	var filter *EventFilter
+	if c.filter != nil {
+		filter = &EventFilter{Start: c.filter.Start, End: c.filter.End}
+	}
+
+	events, err := c.mpd.Events(filter)
+	if err != nil {
+		return errors.Join(ErrConvertToHLS, err)
+	}
+
+	for i := range events {
+		event := &events[i]
+
+		dateRange, ok, err := hlsDateRange(event, c.epoch)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			continue
+		}
+
+		for _, rendition := range c.order {
+			for j, presentationTime := range rendition.presentationTime {
+				segment := &rendition.playlist.Segment[j]
+				if presentationTime+segment.Duration <= event.PresentationTime {
+					continue
+				}
+
+				if start := &rendition.playlist.Segment[0]; start.ProgramDateTime.IsZero() {
+					start.ProgramDateTime = c.epoch.Add(rendition.presentationTime[0])
+				}
+
+				segment.DateRange = append(segment.DateRange, dateRange)
+
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// playlists builds the multivariant playlist and finishes the media playlists.
+func (c *hlsConverter) playlists() *HLSPlaylists {
+	result := &HLSPlaylists{Multivariant: &HLSMultivariantPlaylist{IndependentSegments: true}, Media: map[string]*HLSMediaPlaylist{}}
+
+	var audio, subtitles, video []*hlsRendition
+
+	for _, rendition := range c.order {
+		c.finish(rendition)
+		result.Media[rendition.uri] = rendition.playlist
+
+		if rendition.playlist.Version > result.Multivariant.Version {
+			result.Multivariant.Version = rendition.playlist.Version
+		}
+
+		startWithSAP := rendition.representation.StartWithSAP
+		if startWithSAP == 0 {
+			startWithSAP = rendition.adaptationSet.StartWithSAP
+		}
+
+		if startWithSAP != 1 && startWithSAP != 2 {
+			result.Multivariant.IndependentSegments = false
+		}
+
+		for _, segment := range rendition.playlist.Segment {
+			for _, key := range segment.Key {
+				if key.Method != NoneHLSKeyMethod && !containsHLSKey(result.Multivariant.SessionKey, key) {
+					result.Multivariant.SessionKey = append(result.Multivariant.SessionKey, key)
+				}
+			}
+		}
+
+		switch rendition.contentType {
+		case AudioContentType:
+			audio = append(audio, rendition)
+		case SubtitlesContentType:
+			subtitles = append(subtitles, rendition)
+		default:
+			video = append(video, rendition)
+		}
+	}
+
+	var subtitlesGroupID string
+	if len(subtitles) > 0 {
+		subtitlesGroupID = "subtitles"
+		result.Multivariant.Media = append(result.Multivariant.Media, hlsMediaGroup(SubtitlesHLSMediaType, subtitlesGroupID, subtitles)...)
+	}
+
+	if len(video) == 0 {
+		for _, rendition := range audio {
+			result.Multivariant.Variant = append(result.Multivariant.Variant, HLSVariant{
+				URI:       rendition.uri,
+				Bandwidth: uint64(rendition.representation.Bandwidth),
+				Codecs:    string(rendition.codecs()),
+				Subtitles: subtitlesGroupID,
+			})
+		}
+
+		return result
+	}
+
+	type audioGroup struct {
+		id        string
+		codecs    Codecs
+		bandwidth uint64
+	}
+
+	var groups []*audioGroup
+
+	groupRenditions := map[Codecs][]*hlsRendition{}
+
+	for _, rendition := range audio {
+		codecs := rendition.codecs()
+		if _, ok := groupRenditions[codecs]; !ok {
+			groups = append(groups, &audioGroup{id: "audio", codecs: codecs})
+		}
+
+		groupRenditions[codecs] = append(groupRenditions[codecs], rendition)
+	}
+
+	for i, group := range groups {
+		if len(groups) > 1 {
+			group.id = "audio-" + strconv.Itoa(i+1)
+		}
+
+		for _, rendition := range groupRenditions[group.codecs] {
+			if bandwidth := uint64(rendition.representation.Bandwidth); bandwidth > group.bandwidth {
+				group.bandwidth = bandwidth
+			}
+		}
+
+		result.Multivariant.Media = append(result.Multivariant.Media, hlsMediaGroup(AudioHLSMediaType, group.id, groupRenditions[group.codecs])...)
+	}
+
+	if len(groups) == 0 {
+		groups = append(groups, &audioGroup{})
+	}
+
+	for _, rendition := range video {
+		representation := rendition.representation
+
+		width, height := representation.Width, representation.Height
+		if width == 0 || height == 0 {
+			width, height = rendition.adaptationSet.Width, rendition.adaptationSet.Height
+		}
+
+		frameRate := representation.FrameRate
+		if frameRate == "" {
+			frameRate = rendition.adaptationSet.FrameRate
+		}
+
+		for _, group := range groups {
+			codecs := string(rendition.codecs())
+			if group.codecs != "" {
+				codecs += "," + string(group.codecs)
+			}
+
+			result.Multivariant.Variant = append(result.Multivariant.Variant, HLSVariant{
+				URI:       rendition.uri,
+				Bandwidth: uint64(representation.Bandwidth) + group.bandwidth,
+				Codecs:    strings.Trim(codecs, ","),
+				Width:     width,
+				Height:    height,
+				FrameRate: frameRateValue(frameRate),
+				Audio:     group.id,
+				Subtitles: subtitlesGroupID,
+			})
+		}
+	}
+
+	return result
+}
+
+// finish sets the target duration, version and type of the media playlist.
+func (c *hlsConverter) finish(rendition *hlsRendition) {
+	playlist := rendition.playlist
+	playlist.Version = 3
+	playlist.TargetDuration = 1
+
+	if c.filter == nil {
+		playlist.PlaylistType = VODHLSPlaylistType
+		playlist.EndList = true
+		playlist.MediaSequence = 0
+		playlist.DiscontinuitySequence = 0
+	} else if playlist.MediaSequence == math.MaxUint64 {
+		playlist.MediaSequence = 0
+	}
+
+	for _, segment := range playlist.Segment {
+		if seconds := uint64(math.Round(segment.Duration.Seconds())); seconds > playlist.TargetDuration {
+			playlist.TargetDuration = seconds
+		}
+
+		if segment.ByteRange != nil && playlist.Version < 4 {
+			playlist.Version = 4
+		}
+
+		for _, key := range segment.Key {
+			if key.KeyFormat != "" && playlist.Version < 5 {
+				playlist.Version = 5
+			}
+		}
+
+		if segment.Map != nil {
+			playlist.Version = 6
+		}
+	}
+}
+
+func (r *hlsRendition) codecs() Codecs {
+	if r.representation.Codecs != "" {
+		return r.representation.Codecs
+	}
+
+	return r.adaptationSet.Codecs
+}
+
+// hlsMediaGroup converts renditions to EXT-X-MEDIA tags of a group. The first rendition with the main Role
+// is the default, or the first one if there is none.
+func hlsMediaGroup(mediaType HLSMediaType, groupID string, renditions []*hlsRendition) []HLSMedia {
+	media := make([]HLSMedia, 0, len(renditions))
+	names := map[string]bool{}
+	defaultIndex := -1
+
+	for i, rendition := range renditions {
+		adaptationSet := rendition.adaptationSet
+
+		name := adaptationSet.Lang
+		for _, label := range append(rendition.representation.Label, adaptationSet.Label...) {
+			if text := strings.TrimSpace(label.Value); text != "" {
+				name = text
+				break
+			}
+		}
+
+		if name == "" || names[name] {
+			name = strings.TrimSpace(name + " " + rendition.representation.ID)
+		}
+
+		names[name] = true
+
+		var channels string
+
+		for _, configuration := range append(rendition.representation.AudioChannelConfiguration, adaptationSet.AudioChannelConfiguration...) {
+			if configuration != nil && configuration.SchemeIDURI == AudioChannelConfiguration2011SchemeIDURI {
+				channels = configuration.Value
+				break
+			}
+		}
+
+		for _, role := range adaptationSet.Role {
+			if role.Value == "main" && defaultIndex < 0 {
+				defaultIndex = i
+			}
+		}
+
+		media = append(media, HLSMedia{
+			Type:       mediaType,
+			URI:        rendition.uri,
+			GroupID:    groupID,
+			Language:   adaptationSet.Lang,
+			Name:       name,
+			Autoselect: true,
+			Channels:   channels,
+		})
+	}
+
+	if defaultIndex < 0 {
+		defaultIndex = 0
+	}
+
+	media[defaultIndex].Default = true
+
+	return media
+}
+
+// hlsContentType returns the content type of the Representation, or an empty string if it cannot be converted.
+func hlsContentType(adaptationSet *AdaptationSet, representation *Representation) ContentType {
+	if adaptationSet.ContentType != "" {
+		return adaptationSet.ContentType
+	}
+
+	mimeType := representation.MIMEType
+	if mimeType == "" {
+		mimeType = adaptationSet.MIMEType
+	}
+
+	codecs := representation.Codecs
+	if codecs == "" {
+		codecs = adaptationSet.Codecs
+	}
+
+	switch {
+	case strings.HasPrefix(string(mimeType), "video/"):
+		return VideoContentType
+	case strings.HasPrefix(string(mimeType), "audio/"):
+		return AudioContentType
+	case strings.HasPrefix(string(mimeType), "text/"),
+		mimeType == ApplicationMP4MIMEType && (strings.HasPrefix(string(codecs), "stpp") || strings.HasPrefix(string(codecs), "wvtt")):
+		return SubtitlesContentType
+	default:
+		return ""
+	}
+}
+
+func hlsByteRange(byteRange SingleRFC7233Range) (*HLSByteRange, error) {
+	first, last, err := byteRange.Bounds()
+	if err != nil {
+		return nil, errors.Join(ErrConvertToHLS, err)
+	}
+
+	return &HLSByteRange{Length: last - first + 1, Offset: first}, nil
+}
+
+// hlsKeys converts ContentProtection to EXT-X-KEY tags for Widevine and PlayReady, as well as FairPlay
+// if fairPlayKeyURI is set. The method is SAMPLE-AES for the cbcs and cbc1 schemes, otherwise SAMPLE-AES-CTR.
+func hlsKeys(contentProtection []ContentProtection, fairPlayKeyURI string) ([]HLSKey, error) {
+	method := SampleAESCTRHLSKeyMethod
+	fairPlay := false
+
+	var kid *UUID
+
+	for i := range contentProtection {
+		element := &contentProtection[i]
+
+		switch element.SchemeIDURI {
+		case MP4Protection2011SchemeIDURI:
+			if element.Value == "cbcs" || element.Value == "cbc1" {
+				method = SampleAESHLSKeyMethod
+				fairPlay = true
+			}
+
+			if defaultKID, err := element.DefaultKID(); err == nil {
+				kid = &defaultKID
+			}
+		case FairPlaySchemeIDURI:
+			fairPlay = true
+		}
+	}
+
+	var keys []HLSKey
+
+	for i := range contentProtection {
+		element := &contentProtection[i]
+
+		switch element.SchemeIDURI {
+		case WidevineSchemeIDURI:
+			boxes, err := element.PSSH()
+			if err != nil {
+				return nil, errors.Join(ErrConvertToHLS, err)
+			}
+
+			for _, box := range boxes {
+				keys = append(keys, HLSKey{
+					Method:            method,
+					URI:               "data:text/plain;base64," + box.Base64(),
+					KeyID:             kid,
+					KeyFormat:         string(WidevineSchemeIDURI),
+					KeyFormatVersions: "1",
+				})
+			}
+		case PlayReadySchemeIDURI:
+			if len(element.MSPro) == 0 {
+				continue
+			}
+
+			object, err := element.PlayReadyObject()
+			if err != nil {
+				return nil, errors.Join(ErrConvertToHLS, err)
+			}
+
+			encoded, err := object.Base64()
+			if err != nil {
+				return nil, errors.Join(ErrConvertToHLS, err)
+			}
+
+			keys = append(keys, HLSKey{
+				Method:            method,
+				URI:               "data:text/plain;charset=UTF-16;base64," + encoded,
+				KeyID:             kid,
+				KeyFormat:         playReadyHLSKeyFormat,
+				KeyFormatVersions: "1",
+			})
+		}
+	}
+
+	if fairPlay && fairPlayKeyURI != "" {
+		keys = append(keys, HLSKey{
+			Method:            SampleAESHLSKeyMethod,
+			URI:               fairPlayKeyURI,
+			KeyFormat:         fairPlayHLSKeyFormat,
+			KeyFormatVersions: "1",
+		})
+	}
+
+	return keys, nil
+}
+
+func containsHLSKey(keys []HLSKey, key HLSKey) bool {
+	for i := range keys {
+		if keys[i].attributes() == key.attributes() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hlsDateRange converts an Event of an SCTE-35 EventStream to an EXT-X-DATERANGE. A splice_insert is mapped
+// to SCTE35-OUT or SCTE35-IN depending on its out_of_network_indicator, other commands to SCTE35-CMD.
+// It reports false for Events of other schemes.
+func hlsDateRange(event *TimedEvent, epoch time.Time) (HLSDateRange, bool, error) {
+	var value string
+
+	switch event.EventStream.SchemeIdURI {
+	case SCTE35XMLBinSchemeIDURI:
+		if len(event.Event.SCTE35Signal) == 0 {
+			return HLSDateRange{}, false, nil
+		}
+
+		value = event.Event.SCTE35Signal[0].Binary
+	case SCTE35BinSchemeIDURI:
+		value = event.Event.Value
+		if value == "" {
+			value = event.Event.MessageData
+		}
+	default:
+		return HLSDateRange{}, false, nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return HLSDateRange{}, false, errors.Join(ErrConvertToHLS, ErrParseSCTE35, err)
+	}
+
+	section, err := ParseSCTE35(data)
+	if err != nil {
+		return HLSDateRange{}, false, errors.Join(ErrConvertToHLS, err)
+	}
+
+	dateRange := HLSDateRange{
+		ID:        event.Event.ID,
+		StartDate: epoch.Add(event.PresentationTime),
+		Duration:  event.Duration,
+	}
+
+	if dateRange.ID == "" {
+		dateRange.ID = strconv.FormatInt(event.PresentationTime.Milliseconds(), 10)
+	}
+
+	switch insert := section.SpliceInsert; {
+	case insert != nil && insert.OutOfNetworkIndicator:
+		dateRange.SCTE35Out = data
+
+		if insert.BreakDuration != nil {
+			dateRange.PlannedDuration = scaledDuration(int64(insert.BreakDuration.Duration), 90000)
+		}
+	case insert != nil:
+		dateRange.SCTE35In = data
+	default:
+		dateRange.SCTE35Cmd = data
+	}
+
+	return dateRange, true, nil
+}
+
+// frameRateValue returns the frame rate in frames per second, or 0 if it is invalid.
+func frameRateValue(frameRate FrameRate) float64 {
+	numerator, denominator, found := strings.Cut(string(frameRate), "/")
+
+	n, err := strconv.ParseUint(numerator, 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	d := uint64(1)
+	if found {
+		if d, err = strconv.ParseUint(denominator, 10, 64); err != nil || d == 0 {
+			return 0
+		}
+	}
+
+	return float64(n) / float64(d)
+}
@@ -0,0 +1,395 @@
+package mpd_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"go.eigsys.de/go-mpd"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMPD_ConvertToHLS_VOD(t *testing.T) {
+	testMPD, err := mpd.Read(mustOpenFixture("zencoder/live_profile.mpd"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	playlists, err := testMPD.ConvertToHLS(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantMedia := []mpd.HLSMedia{
+		{Type: mpd.SubtitlesHLSMediaType, URI: "text_subtitle_en.m3u8", GroupID: "subtitles", Language: "en", Name: "en", Default: true, Autoselect: true},
+		{Type: mpd.AudioHLSMediaType, URI: "audio_800.m3u8", GroupID: "audio", Language: "en", Name: "en", Default: true, Autoselect: true},
+	}
+	if diff := cmp.Diff(playlists.Multivariant.Media, wantMedia); diff != "" {
+		t.Errorf("wrong media: %s", diff)
+	}
+
+	wantVariants := []mpd.HLSVariant{
+		{URI: "video_800.m3u8", Bandwidth: 1585759, Codecs: "avc1.4d401f,mp4a.40.2", Width: 960, Height: 540, FrameRate: 30000.0 / 1001, Audio: "audio", Subtitles: "subtitles"},
+		{URI: "video_1000.m3u8", Bandwidth: 1978870, Codecs: "avc1.4d401f,mp4a.40.2", Width: 1024, Height: 576, FrameRate: 30000.0 / 1001, Audio: "audio", Subtitles: "subtitles"},
+		{URI: "video_1200.m3u8", Bandwidth: 2362253, Codecs: "avc1.4d401f,mp4a.40.2", Width: 1024, Height: 576, FrameRate: 30000.0 / 1001, Audio: "audio", Subtitles: "subtitles"},
+		{URI: "video_1500.m3u8", Bandwidth: 2847827, Codecs: "avc1.4d401f,mp4a.40.2", Width: 1280, Height: 720, FrameRate: 30000.0 / 1001, Audio: "audio", Subtitles: "subtitles"},
+	}
+	if diff := cmp.Diff(playlists.Multivariant.Variant, wantVariants); diff != "" {
+		t.Errorf("wrong variants: %s", diff)
+	}
+
+	if len(playlists.Multivariant.SessionKey) != 2 || playlists.Multivariant.Version != 6 || playlists.Multivariant.IndependentSegments {
+		t.Errorf("wrong multivariant playlist: %+v", playlists.Multivariant)
+	}
+
+	audio := playlists.Media["audio_800.m3u8"]
+	if audio.PlaylistType != mpd.VODHLSPlaylistType || !audio.EndList || audio.TargetDuration != 2 || len(audio.Segment) == 0 {
+		t.Fatalf("wrong audio playlist: %+v", audio)
+	}
+
+	first := audio.Segment[0]
+	if first.URI != "800/audio/en/seg-1.m4f" || first.Map.URI != "800/audio/en/init.mp4" || len(first.Key) != 2 || first.Key[0].KeyFormat != string(mpd.WidevineSchemeIDURI) {
+		t.Errorf("wrong first segment: %+v", first)
+	}
+
+	subtitles := playlists.Media["text_subtitle_en.m3u8"]
+	if len(subtitles.Segment) != 1 || subtitles.Segment[0].URI != "http://example.com/content/sintel/subtitles/subtitles_en.vtt" || subtitles.Version != 3 {
+		t.Errorf("wrong subtitles playlist: %+v", subtitles)
+	}
+
+	// The content type must not add path segments to the playlist URIs.
+	testMPD.Period[0].AdaptationSet[0].ContentType = "../video"
+
+	if playlists, err = testMPD.ConvertToHLS(nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for uri := range playlists.Media {
+		if strings.Contains(uri, "/") {
+			t.Errorf("wrong playlist URI: %s", uri)
+		}
+	}
+}
+
+func TestMPD_ConvertToHLS_Live(t *testing.T) {
+	kid := mustParseUUID("08e36702-8f33-436c-a5dd-60ffe5571e60")
+	pts := uint64(5760000)
+	section := &mpd.SCTE35SpliceInfoSection{
+		SpliceCommandType: mpd.SCTE35SpliceInsertCommandType,
+		SpliceInsert: &mpd.SCTE35SpliceInsert{
+			SpliceEventID:         1,
+			OutOfNetworkIndicator: true,
+			SpliceTime:            &mpd.SCTE35SpliceTime{PTSTime: &pts},
+			BreakDuration:         &mpd.SCTE35BreakDuration{AutoReturn: true, Duration: 2700000},
+		},
+	}
+
+	signal, err := mpd.NewSCTE35Signal(section)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := section.Bytes()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	adaptationSet := func(contentProtection ...mpd.ContentProtection) mpd.AdaptationSet {
+		adaptationSet := mpd.AdaptationSet{
+			SegmentTemplate: &mpd.SegmentTemplate{
+				Media:               "$RepresentationID$/$Number$.m4s",
+				Initialization:      "$RepresentationID$/init.mp4",
+				MultipleSegmentBase: mpd.MultipleSegmentBase{Duration: 4},
+			},
+			Representation: []mpd.Representation{{ID: "v", Bandwidth: 1000000}},
+		}
+		adaptationSet.MIMEType = mpd.VideoMP4MIMEType
+		adaptationSet.StartWithSAP = 1
+		adaptationSet.ContentProtection = contentProtection
+
+		return adaptationSet
+	}
+
+	widevine := mpd.NewWidevineContentProtection([]byte{1, 2, 3}, kid)
+	testMPD := &mpd.MPD{
+		Type:                  mpd.DynamicPresentationType,
+		AvailabilityStartTime: "2024-01-01T00:00:00Z",
+		TimeShiftBufferDepth:  "PT16S",
+		Period: []mpd.Period{
+			{
+				Duration:      "PT60S",
+				AdaptationSet: []mpd.AdaptationSet{adaptationSet(mpd.NewMP4ProtectionContentProtection("cbcs", kid), widevine)},
+			},
+			{
+				Start: "PT60S",
+				EventStream: []mpd.EventStream{{
+					SchemeIdURI: mpd.SCTE35XMLBinSchemeIDURI,
+					Timescale:   1,
+					Event: []mpd.Event{
+						{ID: "1", PresentationTime: 4, Duration: 30, SCTE35Signal: []mpd.SCTE35Signal{signal}},
+						{PresentationTime: 9, SCTE35Signal: []mpd.SCTE35Signal{signal}},
+						{PresentationTime: 5},
+					},
+				}},
+				AdaptationSet: []mpd.AdaptationSet{adaptationSet()},
+			},
+		},
+	}
+
+	playlists, err := testMPD.ConvertToHLS(&mpd.HLSOptions{
+		Now:            time.Date(2024, 1, 1, 0, 1, 10, 0, time.UTC),
+		FairPlayKeyURI: "skd://08e367028f33436ca5dd60ffe5571e60",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	boxes, err := widevine.PSSH()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `#EXTM3U
+#EXT-X-VERSION:6
+#EXT-X-INDEPENDENT-SEGMENTS
+#EXT-X-TARGETDURATION:4
+#EXT-X-MEDIA-SEQUENCE:14
+#EXT-X-KEY:METHOD=SAMPLE-AES,URI="data:text/plain;base64,` + boxes[0].Base64() + `",KEYID=0x08E367028F33436CA5DD60FFE5571E60,KEYFORMAT="urn:uuid:edef8ba9-79d6-4ace-a3c8-27dcd51d21ed",KEYFORMATVERSIONS="1"
+#EXT-X-KEY:METHOD=SAMPLE-AES,URI="skd://08e367028f33436ca5dd60ffe5571e60",KEYFORMAT="com.apple.streamingkeydelivery",KEYFORMATVERSIONS="1"
+#EXT-X-MAP:URI="v/init.mp4"
+#EXT-X-PROGRAM-DATE-TIME:2024-01-01T00:00:52Z
+#EXTINF:4,
+v/14.m4s
+#EXTINF:4,
+v/15.m4s
+#EXT-X-DISCONTINUITY
+#EXT-X-KEY:METHOD=NONE
+#EXT-X-MAP:URI="v/init.mp4"
+#EXT-X-PROGRAM-DATE-TIME:2024-01-01T00:01:00Z
+#EXTINF:4,
+v/1.m4s
+#EXT-X-DATERANGE:ID="1",START-DATE="2024-01-01T00:01:04Z",DURATION=30,PLANNED-DURATION=30,SCTE35-OUT=0x` + fmt.Sprintf("%X", data) + `
+#EXTINF:4,
+v/2.m4s
+`
+
+	playlists.Media["video_v.m3u8"].IndependentSegments = true
+	if diff := cmp.Diff(string(playlists.Media["video_v.m3u8"].Bytes()), want); diff != "" {
+		t.Errorf("wrong media playlist: %s", diff)
+	}
+
+	wantVariants := []mpd.HLSVariant{{URI: "video_v.m3u8", Bandwidth: 1000000}}
+	if diff := cmp.Diff(playlists.Multivariant.Variant, wantVariants); diff != "" {
+		t.Errorf("wrong variants: %s", diff)
+	}
+
+	if len(playlists.Multivariant.SessionKey) != 2 || !playlists.Multivariant.IndependentSegments {
+		t.Errorf("wrong multivariant playlist: %+v", playlists.Multivariant)
+	}
+
+	testMPD.TimeShiftBufferDepth = ""
+	testMPD.Period[1].EventStream[0].Event = testMPD.Period[1].EventStream[0].Event[:1]
+	testMPD.Period[1].EventStream[0].Event[0].SCTE35Signal = nil
+
+	playlists, err = testMPD.ConvertToHLS(&mpd.HLSOptions{Now: time.Date(2024, 1, 1, 0, 0, 3, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if playlist := playlists.Media["video_v.m3u8"]; playlist.MediaSequence != 0 || len(playlist.Segment) != 0 {
+		t.Errorf("wrong empty playlist: %+v", playlist)
+	}
+}
+
+func TestMPD_ConvertToHLS_SegmentBase(t *testing.T) {
+	testMPD, err := mpd.Read(mustOpenFixture("zencoder/ondemand_profile.mpd"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	index := &mpd.SegmentIndex{
+		Timescale: 1000,
+		Anchor:    757,
+		Reference: []mpd.SegmentIndexReference{
+			{ReferencedSize: 1000, SubsegmentDuration: 15000},
+			{ReferencedSize: 2000, SubsegmentDuration: 15000},
+		},
+	}
+
+	playlists, err := testMPD.ConvertToHLS(&mpd.HLSOptions{
+		PlaylistURI: func(adaptationSet *mpd.AdaptationSet, representation *mpd.Representation) string {
+			return representation.ID + ".m3u8"
+		},
+		ReadSegmentIndex: func(url string, indexRange mpd.SingleRFC7233Range) (*mpd.SegmentIndex, error) {
+			if url != "800k/output-audio-und.mp4" || indexRange != "629-756" {
+				return nil, errors.New("unexpected index")
+			}
+
+			return index, nil
+		},
+	})
+	if !errors.Is(err, mpd.ErrConvertToHLS) {
+		t.Errorf("wrong error: %v", err)
+	}
+
+	testMPD.Period[0].AdaptationSet = testMPD.Period[0].AdaptationSet[:1]
+	representation := &testMPD.Period[0].AdaptationSet[0].Representation[0]
+	representation.SegmentBase.RepresentationIndex = &mpd.URL{Range: "629-756"}
+
+	readSegmentIndex := func(url string, indexRange mpd.SingleRFC7233Range) (*mpd.SegmentIndex, error) {
+		return index, nil
+	}
+
+	playlists, err = testMPD.ConvertToHLS(&mpd.HLSOptions{ReadSegmentIndex: readSegmentIndex})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantVariants := []mpd.HLSVariant{{URI: "audio_800k%2Faudio-und.m3u8", Bandwidth: 128558, Codecs: "mp4a.40.5"}}
+	if diff := cmp.Diff(playlists.Multivariant.Variant, wantVariants); diff != "" {
+		t.Errorf("wrong variants: %s", diff)
+	}
+
+	playlist := playlists.Media["audio_800k%2Faudio-und.m3u8"]
+	wantSegments := []mpd.HLSSegment{
+		{
+			URI:       "800k/output-audio-und.mp4",
+			Duration:  15 * time.Second,
+			ByteRange: &mpd.HLSByteRange{Length: 1000, Offset: 757},
+			Key:       playlist.Segment[0].Key,
+			Map:       &mpd.HLSMap{URI: "800k/output-audio-und.mp4", ByteRange: &mpd.HLSByteRange{Length: 629}},
+		},
+		{URI: "800k/output-audio-und.mp4", Duration: 15 * time.Second, ByteRange: &mpd.HLSByteRange{Length: 2000, Offset: 1757}},
+	}
+	if diff := cmp.Diff(playlist.Segment, wantSegments); diff != "" {
+		t.Errorf("wrong segments: %s", diff)
+	}
+
+	if playlist.Version != 6 || playlist.TargetDuration != 15 {
+		t.Errorf("wrong playlist: %+v", playlist)
+	}
+}
+
+func TestMPD_ConvertToHLS_AudioGroups(t *testing.T) {
+	audio := func(lang string, codecs mpd.Codecs, bandwidth uint, ids ...string) mpd.AdaptationSet {
+		adaptationSet := mpd.AdaptationSet{Lang: lang, ContentType: mpd.AudioContentType}
+		adaptationSet.Codecs = codecs
+
+		for _, id := range ids {
+			adaptationSet.Representation = append(adaptationSet.Representation, mpd.Representation{ID: id, Bandwidth: bandwidth, BaseURL: []mpd.BaseURL{{Value: id + ".mp4"}}})
+		}
+
+		return adaptationSet
+	}
+
+	german := audio("de", "mp4a.40.2", 192000, "de")
+	german.Role = []mpd.Descriptor{{SchemeIDURI: "urn:mpeg:dash:role:2011", Value: "main"}}
+	german.Label = []mpd.Label{{Value: "Deutsch"}}
+	german.AudioChannelConfiguration = []*mpd.Descriptor{{SchemeIDURI: mpd.AudioChannelConfiguration2011SchemeIDURI, Value: "6"}}
+
+	video := mpd.AdaptationSet{Representation: []mpd.Representation{{ID: "hd", Bandwidth: 3000000, BaseURL: []mpd.BaseURL{{Value: "hd.mp4"}}}}}
+	video.MIMEType = mpd.VideoMP4MIMEType
+	video.Codecs = "avc1.640028"
+	video.Width = 1920
+	video.Height = 1080
+	video.FrameRate = "invalid"
+
+	testMPD := &mpd.MPD{
+		MediaPresentationDuration: "PT10S",
+		Period: []mpd.Period{{AdaptationSet: []mpd.AdaptationSet{
+			audio("en", "mp4a.40.2", 128000, "en-1", "en-2"),
+			german,
+			audio("en", "ec-3", 384000, "en-3"),
+			video,
+			{Representation: []mpd.Representation{{ID: "thumbnails"}}},
+		}}},
+	}
+
+	playlists, err := testMPD.ConvertToHLS(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantMedia := []mpd.HLSMedia{
+		{Type: mpd.AudioHLSMediaType, URI: "audio_en-1.m3u8", GroupID: "audio-1", Language: "en", Name: "en", Autoselect: true},
+		{Type: mpd.AudioHLSMediaType, URI: "audio_en-2.m3u8", GroupID: "audio-1", Language: "en", Name: "en en-2", Autoselect: true},
+		{Type: mpd.AudioHLSMediaType, URI: "audio_de.m3u8", GroupID: "audio-1", Language: "de", Name: "Deutsch", Default: true, Autoselect: true, Channels: "6"},
+		{Type: mpd.AudioHLSMediaType, URI: "audio_en-3.m3u8", GroupID: "audio-2", Language: "en", Name: "en", Default: true, Autoselect: true},
+	}
+	if diff := cmp.Diff(playlists.Multivariant.Media, wantMedia); diff != "" {
+		t.Errorf("wrong media: %s", diff)
+	}
+
+	wantVariants := []mpd.HLSVariant{
+		{URI: "video_hd.m3u8", Bandwidth: 3192000, Codecs: "avc1.640028,mp4a.40.2", Width: 1920, Height: 1080, Audio: "audio-1"},
+		{URI: "video_hd.m3u8", Bandwidth: 3384000, Codecs: "avc1.640028,ec-3", Width: 1920, Height: 1080, Audio: "audio-2"},
+	}
+	if diff := cmp.Diff(playlists.Multivariant.Variant, wantVariants); diff != "" {
+		t.Errorf("wrong variants: %s", diff)
+	}
+
+	if len(playlists.Media) != 5 || playlists.Multivariant.Version != 3 {
+		t.Errorf("wrong playlists: %+v", playlists)
+	}
+}
+
+func TestMPD_ConvertToHLS_Errors(t *testing.T) {
+	valid := func() *mpd.MPD {
+		adaptationSet := mpd.AdaptationSet{
+			SegmentTemplate: &mpd.SegmentTemplate{Media: "$Number$.m4s", MultipleSegmentBase: mpd.MultipleSegmentBase{Duration: 1}},
+			Representation:  []mpd.Representation{{ID: "1"}},
+		}
+		adaptationSet.MIMEType = mpd.VideoMP4MIMEType
+
+		return &mpd.MPD{MediaPresentationDuration: "PT2S", Period: []mpd.Period{{AdaptationSet: []mpd.AdaptationSet{adaptationSet}}}}
+	}
+
+	testCases := map[string]func(m *mpd.MPD){
+		"dynamic without now":          func(m *mpd.MPD) { m.Type = mpd.DynamicPresentationType },
+		"invalid availability start":   func(m *mpd.MPD) { m.AvailabilityStartTime = "invalid" },
+		"invalid time shift buffer":    func(m *mpd.MPD) { m.Type, m.TimeShiftBufferDepth = mpd.DynamicPresentationType, "invalid" },
+		"invalid segment information":  func(m *mpd.MPD) { m.Period[0].AdaptationSet[0].SegmentTemplate.Media = "" },
+		"invalid initialization range": func(m *mpd.MPD) { m.Period[0].AdaptationSet[0].SegmentTemplate.IndexRange = "invalid" },
+		"invalid segment range": func(m *mpd.MPD) {
+			m.Period[0].AdaptationSet[0].SegmentTemplate = nil
+			m.Period[0].AdaptationSet[0].SegmentList = &mpd.SegmentList{SegmentURL: []mpd.SegmentURL{{MediaRange: "invalid"}}}
+		},
+		"invalid initialization byte range": func(m *mpd.MPD) {
+			m.Period[0].AdaptationSet[0].SegmentTemplate.SegmentBase.Initialization = &mpd.URL{Range: "invalid"}
+		},
+		"dangling ContentProtection ref": func(m *mpd.MPD) {
+			m.Period[0].AdaptationSet[0].ContentProtection = []mpd.ContentProtection{{Ref: "missing"}}
+		},
+		"invalid PSSH": func(m *mpd.MPD) {
+			m.Period[0].AdaptationSet[0].ContentProtection = []mpd.ContentProtection{{Descriptor: mpd.Descriptor{SchemeIDURI: mpd.WidevineSchemeIDURI}, CENCPSSH: []string{"AAAA"}}}
+		},
+		"invalid PlayReady object": func(m *mpd.MPD) {
+			m.Period[0].AdaptationSet[0].ContentProtection = []mpd.ContentProtection{{Descriptor: mpd.Descriptor{SchemeIDURI: mpd.PlayReadySchemeIDURI}, MSPro: []string{"AAAA"}}}
+		},
+		"invalid SCTE-35 base64": func(m *mpd.MPD) {
+			m.Period[0].EventStream = []mpd.EventStream{{SchemeIdURI: mpd.SCTE35BinSchemeIDURI, Event: []mpd.Event{{Value: "%"}}}}
+		},
+		"invalid SCTE-35 section": func(m *mpd.MPD) {
+			m.Period[0].EventStream = []mpd.EventStream{{SchemeIdURI: mpd.SCTE35BinSchemeIDURI, Event: []mpd.Event{{MessageData: "AAAA"}}}}
+		},
+		"invalid event timing": func(m *mpd.MPD) {
+			m.Period = append(m.Period, mpd.Period{Start: "invalid", EventStream: []mpd.EventStream{{}}})
+		},
+	}
+
+	for name, modify := range testCases {
+		t.Run(name, func(t *testing.T) {
+			testMPD := valid()
+			modify(testMPD)
+
+			if _, err := testMPD.ConvertToHLS(&mpd.HLSOptions{}); !errors.Is(err, mpd.ErrConvertToHLS) {
+				t.Errorf("wrong error: %v", err)
+			}
+		})
+	}
+
+	if _, err := valid().ConvertToHLS(nil); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package mpd
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+var ErrPeriodTiming = errors.New("cannot determine period timing")
+
+// PeriodStart returns the start of the Period at the index relative to the start of the media presentation.
+// Without Period.Start, the Period starts at the end of the previous Period, or at 0 if it is the first one.
+func (m *MPD) PeriodStart(index int) (time.Duration, error) {
+	start, err := m.periodStartSeconds(index)
+	if err != nil {
+		return 0, err
+	}
+
+	if durationOutOfRange(start) {
+		return 0, fmt.Errorf("%w: start of period %d out of range", ErrPeriodTiming, index)
+	}
+
+	return roundDuration(start), nil
+}
+
+// periodStartSeconds returns the start of the Period at the index like PeriodStart in exact seconds.
+func (m *MPD) periodStartSeconds(index int) (*big.Rat, error) {
+	if index < 0 || index >= len(m.Period) {
+		return nil, fmt.Errorf("%w: no period %d", ErrPeriodTiming, index)
+	}
+
+	period := &m.Period[index]
+	if period.Start != "" {
+		start, err := parseDurationSeconds(period.Start)
+		if err != nil {
+			return nil, errors.Join(ErrPeriodTiming, err)
+		}
+
+		return start, nil
+	}
+
+	if index == 0 {
+		return new(big.Rat), nil
+	}
+
+	previous := &m.Period[index-1]
+	if previous.Duration == "" {
+		return nil, fmt.Errorf("%w: period %d lacks start and previous period lacks duration", ErrPeriodTiming, index)
+	}
+
+	start, err := m.periodStartSeconds(index - 1)
+	if err != nil {
+		return nil, err
+	}
+
+	duration, err := parseDurationSeconds(previous.Duration)
+	if err != nil {
+		return nil, errors.Join(ErrPeriodTiming, err)
+	}
+
+	return start.Add(start, duration), nil
+}
+
+// PeriodDuration returns the duration of the Period at the index. Without Period.Duration, the Period ends at the
+// start of the next Period or at the end of the media presentation. It is 0 if the end is unknown, e.g. for the
+// last Period of a dynamic MPD.
+func (m *MPD) PeriodDuration(index int) (time.Duration, error) {
+	start, err := m.PeriodStart(index)
+	if err != nil {
+		return 0, err
+	}
+
+	period := &m.Period[index]
+
+	var end time.Duration
+
+	switch {
+	case period.Duration != "":
+		duration, err := ParseDuration(period.Duration)
+		if err != nil {
+			return 0, errors.Join(ErrPeriodTiming, err)
+		}
+
+		return duration, nil
+	case index+1 < len(m.Period):
+		if end, err = m.PeriodStart(index + 1); err != nil {
+			return 0, err
+		}
+	case m.MediaPresentationDuration != "":
+		if end, err = ParseDuration(m.MediaPresentationDuration); err != nil {
+			return 0, errors.Join(ErrPeriodTiming, err)
+		}
+	default:
+		return 0, nil
+	}
+
+	if end < start {
+		return 0, fmt.Errorf("%w: period %d ends before it starts", ErrPeriodTiming, index)
+	}
+
+	return end - start, nil
+}
+
+// periodIndex returns the index of the Period in the MPD, or -1 if the Period is not part of it.
+func (m *MPD) periodIndex(period *Period) int {
+	for i := range m.Period {
+		if &m.Period[i] == period {
+			return i
+		}
+	}
+
+	return -1
+}
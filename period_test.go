@@ -0,0 +1,91 @@
+package mpd_test
+
+import (
+	"errors"
+	"go.eigsys.de/go-mpd"
+	"testing"
+	"time"
+)
+
+func TestMPD_PeriodStart(t *testing.T) {
+	testMPD := &mpd.MPD{Period: []mpd.Period{
+		{Duration: "PT10S"},
+		{Duration: "PT20S"},
+		{Start: "PT1M"},
+		{},
+		{Start: "invalid"},
+	}}
+
+	wantStarts := []time.Duration{0, 10 * time.Second, time.Minute}
+	for i, wantStart := range wantStarts {
+		if start, err := testMPD.PeriodStart(i); err != nil || start != wantStart {
+			t.Errorf("wrong start of period %d: %s, %v", i, start, err)
+		}
+	}
+
+	testCases := []int{-1, 3, 4, 5}
+	for _, index := range testCases {
+		if _, err := testMPD.PeriodStart(index); !errors.Is(err, mpd.ErrPeriodTiming) {
+			t.Errorf("wrong error for period %d: %v", index, err)
+		}
+	}
+
+	testMPD.Period[2].Start = ""
+	testMPD.Period[1].Duration = "invalid"
+	if _, err := testMPD.PeriodStart(2); !errors.Is(err, mpd.ErrPeriodTiming) {
+		t.Errorf("wrong error: %v", err)
+	}
+
+	testMPD.Period[0] = mpd.Period{Start: "invalid", Duration: "PT1S"}
+	testMPD.Period[1].Duration = "PT1S"
+	if _, err := testMPD.PeriodStart(2); !errors.Is(err, mpd.ErrPeriodTiming) {
+		t.Errorf("wrong error: %v", err)
+	}
+
+	testMPD.Period[0] = mpd.Period{Start: "PT0.6S", Duration: "PT0.0000000006S"}
+	if start, err := testMPD.PeriodStart(1); err != nil || start != 600000001 {
+		t.Errorf("wrong start: %s, %v", start, err)
+	}
+
+	testMPD.Period[0].Duration = "PT9000000000S"
+	testMPD.Period[1].Duration = "PT9000000000S"
+	if _, err := testMPD.PeriodStart(2); !errors.Is(err, mpd.ErrPeriodTiming) {
+		t.Errorf("wrong error: %v", err)
+	}
+}
+
+func TestMPD_PeriodDuration(t *testing.T) {
+	testMPD := &mpd.MPD{MediaPresentationDuration: "PT2M", Period: []mpd.Period{
+		{Duration: "PT10S"},
+		{},
+		{Start: "PT1M"},
+	}}
+
+	wantDurations := []time.Duration{10 * time.Second, 50 * time.Second, time.Minute}
+	for i, wantDuration := range wantDurations {
+		if duration, err := testMPD.PeriodDuration(i); err != nil || duration != wantDuration {
+			t.Errorf("wrong duration of period %d: %s, %v", i, duration, err)
+		}
+	}
+
+	testMPD.MediaPresentationDuration = ""
+	if duration, err := testMPD.PeriodDuration(2); err != nil || duration != 0 {
+		t.Errorf("wrong duration of open period: %s, %v", duration, err)
+	}
+
+	testCases := map[string]*mpd.MPD{
+		"no period":             {},
+		"invalid duration":      {Period: []mpd.Period{{Duration: "invalid"}}},
+		"invalid next start":    {Period: []mpd.Period{{}, {Start: "invalid"}}},
+		"invalid presentation":  {MediaPresentationDuration: "invalid", Period: []mpd.Period{{}}},
+		"ends before it starts": {MediaPresentationDuration: "PT1S", Period: []mpd.Period{{Start: "PT2S"}}},
+	}
+
+	for name, testMPD := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := testMPD.PeriodDuration(0); !errors.Is(err, mpd.ErrPeriodTiming) {
+				t.Errorf("wrong error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,413 @@
+package mpd
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"go.eigsys.de/go-mpd/third_party/encoding/xml"
+	"math"
+	"strings"
+	"unicode/utf16"
+)
+
+var (
+	ErrParsePlayReadyObject  = errors.New("cannot parse PlayReady Object")
+	ErrEncodePlayReadyObject = errors.New("cannot encode PlayReady Object")
+	ErrParsePlayReadyHeader  = errors.New("cannot parse PlayReady Header")
+	ErrEncodePlayReadyHeader = errors.New("cannot encode PlayReady Header")
+)
+
+// PlayReady Object record types.
+const (
+	PlayReadyRightsManagementHeaderRecordType uint16 = 1
+	PlayReadyEmbeddedLicenseStoreRecordType   uint16 = 3
+)
+
+// PlayReady Header versions.
+const (
+	PlayReadyHeaderVersion40 = "4.0.0.0"
+	PlayReadyHeaderVersion41 = "4.1.0.0"
+	PlayReadyHeaderVersion42 = "4.2.0.0"
+	PlayReadyHeaderVersion43 = "4.3.0.0"
+)
+
+// PlayReadyObject as used in the mspr:pro element and as PlayReady PSSH data.
+type PlayReadyObject struct {
+	Record []PlayReadyRecord
+}
+
+// PlayReadyRecord is a record of a PlayReadyObject.
+type PlayReadyRecord struct {
+	Type  uint16
+	Value []byte
+}
+
+// PlayReadyHeader is the decoded WRMHEADER of a rights management header record.
+type PlayReadyHeader struct {
+	Version string
+
+	// KID holds exactly one key in version 4.0.0.0, at most one in 4.1.0.0 and any number in later versions.
+	KID []PlayReadyKID
+
+	LAURL  string
+	LUIURL string
+	DSID   string
+
+	// CustomAttributes is the inner XML of the CUSTOMATTRIBUTES element.
+	CustomAttributes string
+
+	DecryptorSetup string
+
+	// UnknownElements holds the raw XML of other DATA child elements, which are kept when encoding.
+	UnknownElements []string
+}
+
+// PlayReadyKID is a key of a PlayReadyHeader.
+type PlayReadyKID struct {
+	ID UUID
+
+	// AlgID defaults to "AESCTR" in version 4.0.0.0.
+	AlgID string
+
+	// Checksum is the base64 encoded key checksum.
+	Checksum string
+}
+
+// NewPlayReadyObject creates a PlayReady Object with a single rights management header record.
+func NewPlayReadyObject(header *PlayReadyHeader) (*PlayReadyObject, error) {
+	object := &PlayReadyObject{}
+	if err := object.SetHeader(header); err != nil {
+		return nil, err
+	}
+
+	return object, nil
+}
+
+// ParsePlayReadyObject parses a binary PlayReady Object.
+func ParsePlayReadyObject(data []byte) (*PlayReadyObject, error) {
+	if len(data) < 6 || int(binary.LittleEndian.Uint32(data)) != len(data) {
+		return nil, ErrParsePlayReadyObject
+	}
+
+	count := int(binary.LittleEndian.Uint16(data[4:]))
+	data = data[6:]
+	object := &PlayReadyObject{Record: make([]PlayReadyRecord, 0, count)}
+
+	for i := 0; i < count; i++ {
+		if len(data) < 4 {
+			return nil, ErrParsePlayReadyObject
+		}
+
+		recordType := binary.LittleEndian.Uint16(data)
+		length := int(binary.LittleEndian.Uint16(data[2:]))
+		if len(data) < 4+length {
+			return nil, ErrParsePlayReadyObject
+		}
+
+		object.Record = append(object.Record, PlayReadyRecord{Type: recordType, Value: data[4 : 4+length]})
+		data = data[4+length:]
+	}
+
+	if len(data) > 0 {
+		return nil, ErrParsePlayReadyObject
+	}
+
+	return object, nil
+}
+
+// DecodePlayReadyObject decodes a base64 encoded PlayReady Object, as found in ContentProtection.MSPro.
+func DecodePlayReadyObject(value string) (*PlayReadyObject, error) {
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.Join(ErrParsePlayReadyObject, err)
+	}
+
+	return ParsePlayReadyObject(data)
+}
+
+// Bytes encodes the PlayReady Object. The number of records and the length of each record value are limited to
+// 65535.
+func (o *PlayReadyObject) Bytes() ([]byte, error) {
+	if len(o.Record) > math.MaxUint16 {
+		return nil, fmt.Errorf("%w: %d records", ErrEncodePlayReadyObject, len(o.Record))
+	}
+
+	length := 6
+	for _, record := range o.Record {
+		if len(record.Value) > math.MaxUint16 {
+			return nil, fmt.Errorf("%w: record value of %d bytes", ErrEncodePlayReadyObject, len(record.Value))
+		}
+
+		length += 4 + len(record.Value)
+	}
+
+	data := binary.LittleEndian.AppendUint32(make([]byte, 0, length), uint32(length))
+	data = binary.LittleEndian.AppendUint16(data, uint16(len(o.Record)))
+
+	for _, record := range o.Record {
+		data = binary.LittleEndian.AppendUint16(data, record.Type)
+		data = binary.LittleEndian.AppendUint16(data, uint16(len(record.Value)))
+		data = append(data, record.Value...)
+	}
+
+	return data, nil
+}
+
+// Base64 encodes the PlayReady Object as used by ContentProtection.MSPro.
+func (o *PlayReadyObject) Base64() (string, error) {
+	data, err := o.Bytes()
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// Header decodes the first rights management header record.
+func (o *PlayReadyObject) Header() (*PlayReadyHeader, error) {
+	for _, record := range o.Record {
+		if record.Type == PlayReadyRightsManagementHeaderRecordType {
+			return ParsePlayReadyHeader(record.Value)
+		}
+	}
+
+	return nil, ErrParsePlayReadyHeader
+}
+
+// SetHeader replaces the first rights management header record, or adds one if there is none.
+func (o *PlayReadyObject) SetHeader(header *PlayReadyHeader) error {
+	value, err := header.Bytes()
+	if err != nil {
+		return err
+	}
+
+	for i, record := range o.Record {
+		if record.Type == PlayReadyRightsManagementHeaderRecordType {
+			o.Record[i].Value = value
+			return nil
+		}
+	}
+
+	o.Record = append(o.Record, PlayReadyRecord{Type: PlayReadyRightsManagementHeaderRecordType, Value: value})
+
+	return nil
+}
+
+type wrmHeader struct {
+	XMLName xml.Name      `xml:"http://schemas.microsoft.com/DRM/2007/03/PlayReadyHeader WRMHEADER"`
+	Version string        `xml:"version,attr"`
+	Data    wrmHeaderData `xml:"DATA"`
+}
+
+type wrmHeaderData struct {
+	ProtectInfo      *wrmProtectInfo      `xml:"PROTECTINFO"`
+	KID              string               `xml:"KID,omitempty"`
+	Checksum         string               `xml:"CHECKSUM,omitempty"`
+	LAURL            string               `xml:"LA_URL,omitempty"`
+	LUIURL           string               `xml:"LUI_URL,omitempty"`
+	DSID             string               `xml:"DS_ID,omitempty"`
+	CustomAttributes *wrmCustomAttributes `xml:"CUSTOMATTRIBUTES"`
+	DecryptorSetup   string               `xml:"DECRYPTORSETUP,omitempty"`
+	Unknown          []wrmElement         `xml:",any"`
+
+	// UnknownXML is written verbatim when encoding.
+	UnknownXML string `xml:",innerxml"`
+}
+
+// wrmElement is a DATA child element not covered by wrmHeaderData.
+type wrmElement struct {
+	XMLName  xml.Name
+	Attr     []xml.Attr `xml:",any,attr"`
+	InnerXML string     `xml:",innerxml"`
+}
+
+type wrmProtectInfo struct {
+	KeyLen string   `xml:"KEYLEN,omitempty"`
+	AlgID  string   `xml:"ALGID,omitempty"`
+	KID    []wrmKID `xml:"KID"`
+	KIDs   *wrmKIDs `xml:"KIDS"`
+}
+
+type wrmKIDs struct {
+	KID []wrmKID `xml:"KID"`
+}
+
+type wrmKID struct {
+	AlgID    string `xml:"ALGID,attr,omitempty"`
+	Checksum string `xml:"CHECKSUM,attr,omitempty"`
+	Value    string `xml:"VALUE,attr"`
+}
+
+type wrmCustomAttributes struct {
+	InnerXML string `xml:",innerxml"`
+}
+
+// ParsePlayReadyHeader parses the UTF-16LE encoded WRMHEADER of a rights management header record.
+func ParsePlayReadyHeader(data []byte) (*PlayReadyHeader, error) {
+	if len(data)%2 != 0 {
+		return nil, ErrParsePlayReadyHeader
+	}
+
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(data[2*i:])
+	}
+
+	if len(units) > 0 && units[0] == 0xfeff {
+		units = units[1:]
+	}
+
+	var decoded wrmHeader
+	if err := xml.Unmarshal([]byte(string(utf16.Decode(units))), &decoded); err != nil {
+		return nil, errors.Join(ErrParsePlayReadyHeader, err)
+	}
+
+	header := &PlayReadyHeader{
+		Version:        decoded.Version,
+		LAURL:          decoded.Data.LAURL,
+		LUIURL:         decoded.Data.LUIURL,
+		DSID:           decoded.Data.DSID,
+		DecryptorSetup: decoded.Data.DecryptorSetup,
+	}
+
+	if decoded.Data.CustomAttributes != nil {
+		header.CustomAttributes = decoded.Data.CustomAttributes.InnerXML
+	}
+
+	for _, element := range decoded.Data.Unknown {
+		element.XMLName.Space = ""
+
+		raw, err := xml.Marshal(element)
+		if err != nil {
+			return nil, errors.Join(ErrParsePlayReadyHeader, err)
+		}
+
+		header.UnknownElements = append(header.UnknownElements, string(raw))
+	}
+
+	var kids []wrmKID
+	if decoded.Data.KID != "" {
+		kids = append(kids, wrmKID{Value: decoded.Data.KID, Checksum: decoded.Data.Checksum})
+		if decoded.Data.ProtectInfo != nil {
+			kids[0].AlgID = decoded.Data.ProtectInfo.AlgID
+		}
+	}
+
+	if decoded.Data.ProtectInfo != nil {
+		kids = append(kids, decoded.Data.ProtectInfo.KID...)
+		if decoded.Data.ProtectInfo.KIDs != nil {
+			kids = append(kids, decoded.Data.ProtectInfo.KIDs.KID...)
+		}
+	}
+
+	for _, kid := range kids {
+		id, err := parsePlayReadyKID(kid.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		header.KID = append(header.KID, PlayReadyKID{ID: id, AlgID: kid.AlgID, Checksum: kid.Checksum})
+	}
+
+	return header, nil
+}
+
+// Bytes encodes the WRMHEADER as UTF-16LE, using the element layout of PlayReadyHeader.Version.
+func (h *PlayReadyHeader) Bytes() ([]byte, error) {
+	encoded := wrmHeader{
+		Version: h.Version,
+		Data: wrmHeaderData{
+			LAURL:          h.LAURL,
+			LUIURL:         h.LUIURL,
+			DSID:           h.DSID,
+			DecryptorSetup: h.DecryptorSetup,
+			UnknownXML:     strings.Join(h.UnknownElements, ""),
+		},
+	}
+
+	if h.CustomAttributes != "" {
+		encoded.Data.CustomAttributes = &wrmCustomAttributes{InnerXML: h.CustomAttributes}
+	}
+
+	kids := make([]wrmKID, len(h.KID))
+	for i, kid := range h.KID {
+		kids[i] = wrmKID{AlgID: kid.AlgID, Checksum: kid.Checksum, Value: formatPlayReadyKID(kid.ID)}
+	}
+
+	switch h.Version {
+	case PlayReadyHeaderVersion40:
+		if len(kids) != 1 {
+			return nil, ErrEncodePlayReadyHeader
+		}
+
+		algID := kids[0].AlgID
+		if algID == "" {
+			algID = "AESCTR"
+		}
+
+		encoded.Data.ProtectInfo = &wrmProtectInfo{KeyLen: "16", AlgID: algID}
+		encoded.Data.KID = kids[0].Value
+		encoded.Data.Checksum = kids[0].Checksum
+	case PlayReadyHeaderVersion41:
+		if len(kids) > 1 {
+			return nil, ErrEncodePlayReadyHeader
+		}
+
+		if len(kids) > 0 {
+			encoded.Data.ProtectInfo = &wrmProtectInfo{KID: kids}
+		}
+	case PlayReadyHeaderVersion42, PlayReadyHeaderVersion43:
+		if len(kids) > 0 {
+			encoded.Data.ProtectInfo = &wrmProtectInfo{KIDs: &wrmKIDs{KID: kids}}
+		}
+	default:
+		return nil, ErrEncodePlayReadyHeader
+	}
+
+	text, err := xml.Marshal(encoded)
+	if err != nil {
+		return nil, errors.Join(ErrEncodePlayReadyHeader, err)
+	}
+
+	units := utf16.Encode([]rune(string(text)))
+	data := make([]byte, 0, 2*len(units))
+	for _, unit := range units {
+		data = binary.LittleEndian.AppendUint16(data, unit)
+	}
+
+	return data, nil
+}
+
+// parsePlayReadyKID decodes a base64 KID, which PlayReady stores as a GUID
+// with the first three fields in little-endian byte order.
+func parsePlayReadyKID(value string) (UUID, error) {
+	var uuid UUID
+
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return uuid, errors.Join(ErrParsePlayReadyHeader, err)
+	}
+
+	if len(data) != len(uuid) {
+		return uuid, ErrParsePlayReadyHeader
+	}
+
+	copy(uuid[:], data)
+
+	return swapGUID(uuid), nil
+}
+
+func formatPlayReadyKID(uuid UUID) string {
+	swapped := swapGUID(uuid)
+	return base64.StdEncoding.EncodeToString(swapped[:])
+}
+
+// swapGUID converts between the big-endian UUID and the mixed-endian GUID byte order.
+func swapGUID(uuid UUID) UUID {
+	uuid[0], uuid[1], uuid[2], uuid[3] = uuid[3], uuid[2], uuid[1], uuid[0]
+	uuid[4], uuid[5] = uuid[5], uuid[4]
+	uuid[6], uuid[7] = uuid[7], uuid[6]
+
+	return uuid
+}
@@ -0,0 +1,284 @@
+package mpd_test
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"go.eigsys.de/go-mpd"
+	"testing"
+	"unicode/utf16"
+)
+
+func encodeUTF16LE(value string) []byte {
+	var data []byte
+	for _, unit := range utf16.Encode([]rune(value)) {
+		data = binary.LittleEndian.AppendUint16(data, unit)
+	}
+
+	return data
+}
+
+func mustEncodePlayReadyObject(object *mpd.PlayReadyObject) []byte {
+	data, err := object.Bytes()
+	if err != nil {
+		panic(err)
+	}
+
+	return data
+}
+
+func mustEncodePlayReadyObjectBase64(object *mpd.PlayReadyObject) string {
+	return base64.StdEncoding.EncodeToString(mustEncodePlayReadyObject(object))
+}
+
+func TestContentProtection_PlayReadyObject(t *testing.T) {
+	testMPD, err := mpd.Read(mustOpenFixture("zencoder/live_profile.mpd"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	contentProtection := testMPD.Period[0].AdaptationSet[0].ContentProtection[2]
+
+	object, err := contentProtection.PlayReadyObject()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if encoded := mustEncodePlayReadyObjectBase64(object); encoded != contentProtection.MSPro[0] {
+		t.Errorf("wrong encoding: %s", encoded)
+	}
+
+	header, err := object.Header()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantHeader := &mpd.PlayReadyHeader{
+		Version: mpd.PlayReadyHeaderVersion40,
+		KID: []mpd.PlayReadyKID{
+			{ID: mustParseUUID("5abdd52f-554a-4f2a-b8d0-61f761425155"), AlgID: "AESCTR", Checksum: "IKzY2HZLAlI="},
+		},
+	}
+	if diff := cmp.Diff(header, wantHeader); diff != "" {
+		t.Errorf("wrong header: %s", diff)
+	}
+
+	if data, err := header.Bytes(); err != nil || string(data) != string(object.Record[0].Value) {
+		t.Errorf("wrong header encoding: %v", err)
+	}
+
+	header.LAURL = "https://license.example.com/rightsmanager.asmx"
+	if err := object.SetHeader(header); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := contentProtection.SetPlayReadyObject(object); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	object, err = contentProtection.PlayReadyObject()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if header, err := object.Header(); err != nil || header.LAURL != "https://license.example.com/rightsmanager.asmx" {
+		t.Errorf("wrong header: %+v", header)
+	}
+
+	boxes, err := contentProtection.PSSH()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if string(boxes[0].Data) != string(mustEncodePlayReadyObject(object)) {
+		t.Errorf("wrong PSSH data")
+	}
+}
+
+func TestPlayReadyHeader_Versions(t *testing.T) {
+	kids := []mpd.PlayReadyKID{
+		{ID: mustParseUUID("08e36702-8f33-436c-a5dd-60ffe5571e60"), AlgID: "AESCBC"},
+		{ID: mustParseUUID("5abdd52f-554a-4f2a-b8d0-61f761425155"), AlgID: "AESCTR", Checksum: "IKzY2HZLAlI="},
+	}
+
+	type TestCase struct {
+		name    string
+		header  mpd.PlayReadyHeader
+		wantXML string
+	}
+
+	testCases := []TestCase{
+		{
+			name:   "4.1.0.0",
+			header: mpd.PlayReadyHeader{Version: mpd.PlayReadyHeaderVersion41, KID: kids[1:], LUIURL: "https://example.com/"},
+			wantXML: `<WRMHEADER xmlns="http://schemas.microsoft.com/DRM/2007/03/PlayReadyHeader" version="4.1.0.0"><DATA>` +
+				`<PROTECTINFO><KID ALGID="AESCTR" CHECKSUM="IKzY2HZLAlI=" VALUE="L9W9WkpVKk+40GH3YUJRVQ=="></KID></PROTECTINFO>` +
+				`<LUI_URL>https://example.com/</LUI_URL></DATA></WRMHEADER>`,
+		},
+		{
+			name: "4.3.0.0",
+			header: mpd.PlayReadyHeader{
+				Version:          mpd.PlayReadyHeaderVersion43,
+				KID:              kids,
+				LAURL:            "https://example.com/?a=1&b=2",
+				DSID:             "AH+03juKbUGbHl1V/QIwRA==",
+				CustomAttributes: `<IIS_DRM_VERSION>8.1</IIS_DRM_VERSION>`,
+				DecryptorSetup:   "ONDEMAND",
+			},
+			wantXML: `<WRMHEADER xmlns="http://schemas.microsoft.com/DRM/2007/03/PlayReadyHeader" version="4.3.0.0"><DATA>` +
+				`<PROTECTINFO><KIDS><KID ALGID="AESCBC" VALUE="AmfjCDOPbEOl3WD/5VceYA=="></KID>` +
+				`<KID ALGID="AESCTR" CHECKSUM="IKzY2HZLAlI=" VALUE="L9W9WkpVKk+40GH3YUJRVQ=="></KID></KIDS></PROTECTINFO>` +
+				`<LA_URL>https://example.com/?a=1&amp;b=2</LA_URL><DS_ID>AH+03juKbUGbHl1V/QIwRA==</DS_ID>` +
+				`<CUSTOMATTRIBUTES><IIS_DRM_VERSION>8.1</IIS_DRM_VERSION></CUSTOMATTRIBUTES>` +
+				`<DECRYPTORSETUP>ONDEMAND</DECRYPTORSETUP></DATA></WRMHEADER>`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			object, err := mpd.NewPlayReadyObject(&testCase.header)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(object.Record[0].Value, encodeUTF16LE(testCase.wantXML)); diff != "" {
+				t.Errorf("wrong header encoding: %s", diff)
+			}
+
+			decoded, err := mpd.DecodePlayReadyObject(mustEncodePlayReadyObjectBase64(object))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			header, err := decoded.Header()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(*header, testCase.header); diff != "" {
+				t.Errorf("wrong header: %s", diff)
+			}
+		})
+	}
+}
+
+func TestPlayReadyHeader_Bytes_Errors(t *testing.T) {
+	kid := mpd.PlayReadyKID{ID: mustParseUUID("08e36702-8f33-436c-a5dd-60ffe5571e60")}
+
+	testCases := map[string]mpd.PlayReadyHeader{
+		"4.0.0.0 without KID":   {Version: mpd.PlayReadyHeaderVersion40},
+		"4.1.0.0 with two KIDs": {Version: mpd.PlayReadyHeaderVersion41, KID: []mpd.PlayReadyKID{kid, kid}},
+		"unknown version":       {Version: "5.0.0.0"},
+	}
+
+	for name, header := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := mpd.NewPlayReadyObject(&header); !errors.Is(err, mpd.ErrEncodePlayReadyHeader) {
+				t.Errorf("wrong error: %v", err)
+			}
+		})
+	}
+
+	if data, err := (&mpd.PlayReadyHeader{Version: mpd.PlayReadyHeaderVersion40, KID: []mpd.PlayReadyKID{kid}}).Bytes(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	} else if header, err := mpd.ParsePlayReadyHeader(append([]byte{0xff, 0xfe}, data...)); err != nil || header.KID[0].AlgID != "AESCTR" {
+		t.Errorf("wrong header: %+v", header)
+	}
+}
+
+func TestParsePlayReadyObject_Errors(t *testing.T) {
+	header := encodeUTF16LE(`<WRMHEADER xmlns="http://schemas.microsoft.com/DRM/2007/03/PlayReadyHeader" version="4.2.0.0"><DATA></DATA></WRMHEADER>`)
+	valid := mustEncodePlayReadyObject(&mpd.PlayReadyObject{Record: []mpd.PlayReadyRecord{{Type: 1, Value: header}}})
+
+	testCases := map[string][]byte{
+		"empty":          {},
+		"wrong length":   append(valid, 0),
+		"missing record": append([]byte{10, 0, 0, 0, 2, 0}, valid[6:10]...),
+		"short record":   {10, 0, 0, 0, 1, 0, 1, 0, 9, 0},
+		"trailing data":  {7, 0, 0, 0, 0, 0, 0},
+	}
+
+	for name, data := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := mpd.ParsePlayReadyObject(data); !errors.Is(err, mpd.ErrParsePlayReadyObject) {
+				t.Errorf("wrong error: %v", err)
+			}
+		})
+	}
+
+	if _, err := mpd.DecodePlayReadyObject("%"); !errors.Is(err, mpd.ErrParsePlayReadyObject) {
+		t.Errorf("wrong error: %v", err)
+	}
+
+	if _, err := (&mpd.ContentProtection{}).PlayReadyObject(); !errors.Is(err, mpd.ErrParsePlayReadyObject) {
+		t.Errorf("wrong error: %v", err)
+	}
+
+	if err := (&mpd.ContentProtection{CENCPSSH: []string{"AAAA"}}).SetPlayReadyObject(&mpd.PlayReadyObject{}); !errors.Is(err, mpd.ErrParsePSSH) {
+		t.Errorf("wrong error: %v", err)
+	}
+
+	headerErrors := map[string][]byte{
+		"no header":   mustEncodePlayReadyObject(&mpd.PlayReadyObject{Record: []mpd.PlayReadyRecord{{Type: 3}}}),
+		"odd length":  mustEncodePlayReadyObject(&mpd.PlayReadyObject{Record: []mpd.PlayReadyRecord{{Type: 1, Value: header[1:]}}}),
+		"invalid XML": mustEncodePlayReadyObject(&mpd.PlayReadyObject{Record: []mpd.PlayReadyRecord{{Type: 1, Value: header[:20]}}}),
+		"invalid KID": mustEncodePlayReadyObject(&mpd.PlayReadyObject{Record: []mpd.PlayReadyRecord{{Type: 1, Value: encodeUTF16LE(
+			`<WRMHEADER xmlns="http://schemas.microsoft.com/DRM/2007/03/PlayReadyHeader" version="4.0.0.0"><DATA><KID>AAAA</KID></DATA></WRMHEADER>`)}}}),
+		"invalid base64": mustEncodePlayReadyObject(&mpd.PlayReadyObject{Record: []mpd.PlayReadyRecord{{Type: 1, Value: encodeUTF16LE(
+			`<WRMHEADER xmlns="http://schemas.microsoft.com/DRM/2007/03/PlayReadyHeader" version="4.0.0.0"><DATA><KID>%</KID></DATA></WRMHEADER>`)}}}),
+	}
+
+	for name, data := range headerErrors {
+		t.Run(name, func(t *testing.T) {
+			object, err := mpd.ParsePlayReadyObject(data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if _, err := object.Header(); !errors.Is(err, mpd.ErrParsePlayReadyHeader) {
+				t.Errorf("wrong error: %v", err)
+			}
+		})
+	}
+}
+
+func TestPlayReadyHeader_UnknownElements(t *testing.T) {
+	header, err := mpd.ParsePlayReadyHeader(encodeUTF16LE(`<WRMHEADER xmlns="http://schemas.microsoft.com/DRM/2007/03/PlayReadyHeader" version="4.3.0.0">` +
+		`<DATA><LA_URL>https://old.example.com/</LA_URL><VENDOR id="1"><SETTING>on</SETTING></VENDOR></DATA></WRMHEADER>`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(header.UnknownElements, []string{`<VENDOR id="1"><SETTING>on</SETTING></VENDOR>`}); diff != "" {
+		t.Errorf("wrong unknown elements: %s", diff)
+	}
+
+	header.LAURL = "https://new.example.com/"
+
+	data, err := header.Bytes()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantXML := `<WRMHEADER xmlns="http://schemas.microsoft.com/DRM/2007/03/PlayReadyHeader" version="4.3.0.0">` +
+		`<DATA><LA_URL>https://new.example.com/</LA_URL><VENDOR id="1"><SETTING>on</SETTING></VENDOR></DATA></WRMHEADER>`
+	if diff := cmp.Diff(data, encodeUTF16LE(wantXML)); diff != "" {
+		t.Errorf("wrong header encoding: %s", diff)
+	}
+}
+
+func TestPlayReadyObject_Bytes_Errors(t *testing.T) {
+	for name, object := range map[string]*mpd.PlayReadyObject{
+		"too many records": {Record: make([]mpd.PlayReadyRecord, 65536)},
+		"record too long":  {Record: []mpd.PlayReadyRecord{{Type: mpd.PlayReadyEmbeddedLicenseStoreRecordType, Value: make([]byte, 65536)}}},
+	} {
+		if _, err := object.Base64(); !errors.Is(err, mpd.ErrEncodePlayReadyObject) {
+			t.Errorf("%s: wrong error: %v", name, err)
+		}
+
+		if err := (&mpd.ContentProtection{}).SetPlayReadyObject(object); !errors.Is(err, mpd.ErrEncodePlayReadyObject) {
+			t.Errorf("%s: wrong error: %v", name, err)
+		}
+	}
+}
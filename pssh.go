@@ -0,0 +1,116 @@
+package mpd
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+)
+
+var ErrParsePSSH = errors.New("cannot parse PSSH box")
+
+var (
+	ClearKeySystemID  = mustSystemID(ClearKeySchemeIDURI)
+	FairPlaySystemID  = mustSystemID(FairPlaySchemeIDURI)
+	PlayReadySystemID = mustSystemID(PlayReadySchemeIDURI)
+	WidevineSystemID  = mustSystemID(WidevineSchemeIDURI)
+)
+
+func mustSystemID(schemeIDURI SchemeIDURI) UUID {
+	systemID, err := schemeIDURI.UUID()
+	if err != nil {
+		panic(err)
+	}
+
+	return systemID
+}
+
+// PSSH is a Protection System Specific Header box as per ISO/IEC 23001-7.
+type PSSH struct {
+	Version  uint8
+	SystemID UUID
+
+	// KID is only present in version 1 boxes.
+	KID []UUID
+
+	Data []byte
+}
+
+// NewPSSH creates a PSSH box. The version is 1 if key IDs are given, otherwise 0.
+func NewPSSH(systemID UUID, data []byte, kid ...UUID) *PSSH {
+	pssh := &PSSH{SystemID: systemID, KID: kid, Data: data}
+	if len(kid) > 0 {
+		pssh.Version = 1
+	}
+
+	return pssh
+}
+
+// ParsePSSH parses a binary PSSH box.
+func ParsePSSH(data []byte) (*PSSH, error) {
+	b, err := readBox(data)
+	if err != nil {
+		return nil, errors.Join(ErrParsePSSH, err)
+	}
+
+	if b.Type != "pssh" || b.Size != len(data) {
+		return nil, ErrParsePSSH
+	}
+
+	r := &byteReader{data: b.Payload}
+	version, _ := r.fullBoxHeader()
+	pssh := &PSSH{Version: version}
+	copy(pssh.SystemID[:], r.fixed(16))
+
+	if version > 0 {
+		count := r.uint32()
+		for i := uint32(0); i < count && r.err == nil; i++ {
+			var kid UUID
+			copy(kid[:], r.fixed(16))
+			pssh.KID = append(pssh.KID, kid)
+		}
+	}
+
+	pssh.Data = r.bytes(int(r.uint32()))
+
+	if r.err != nil {
+		return nil, errors.Join(ErrParsePSSH, r.err)
+	}
+
+	if len(r.data) > 0 {
+		return nil, ErrParsePSSH
+	}
+
+	return pssh, nil
+}
+
+// DecodePSSH decodes a base64 encoded PSSH box, as found in ContentProtection.CENCPSSH.
+func DecodePSSH(value string) (*PSSH, error) {
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.Join(ErrParsePSSH, err)
+	}
+
+	return ParsePSSH(data)
+}
+
+// Bytes encodes the PSSH box. Key IDs are omitted in version 0 boxes.
+func (p *PSSH) Bytes() []byte {
+	payload := append([]byte{}, p.SystemID[:]...)
+
+	if p.Version > 0 {
+		payload = binary.BigEndian.AppendUint32(payload, uint32(len(p.KID)))
+		for _, kid := range p.KID {
+			payload = append(payload, kid[:]...)
+		}
+	}
+
+	payload = binary.BigEndian.AppendUint32(payload, uint32(len(p.Data)))
+	payload = append(payload, p.Data...)
+
+	return appendFullBox(nil, "pssh", p.Version, 0, payload)
+}
+
+// Base64 encodes the PSSH box as used by ContentProtection.CENCPSSH.
+func (p *PSSH) Base64() string {
+	return base64.StdEncoding.EncodeToString(p.Bytes())
+}
@@ -0,0 +1,90 @@
+package mpd_test
+
+import (
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"go.eigsys.de/go-mpd"
+	"testing"
+)
+
+func mustParseUUID(value string) mpd.UUID {
+	uuid, err := mpd.ParseUUID(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return uuid
+}
+
+func TestContentProtection_PSSH(t *testing.T) {
+	testMPD, err := mpd.Read(mustOpenFixture("zencoder/live_profile.mpd"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantSystemIDs := []mpd.UUID{mpd.WidevineSystemID, mpd.PlayReadySystemID}
+
+	for i, contentProtection := range testMPD.Period[0].AdaptationSet[0].ContentProtection[1:] {
+		boxes, err := contentProtection.PSSH()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(boxes) != 1 || boxes[0].Version != 0 || boxes[0].SystemID != wantSystemIDs[i] {
+			t.Errorf("wrong PSSH box: %+v", boxes)
+		}
+
+		if encoded := boxes[0].Base64(); encoded != contentProtection.CENCPSSH[0] {
+			t.Errorf("wrong encoding: %s", encoded)
+		}
+	}
+}
+
+func TestPSSH_Version1(t *testing.T) {
+	kids := []mpd.UUID{
+		mustParseUUID("08e36702-8f33-436c-a5dd-60ffe5571e60"),
+		mustParseUUID("5abdd52f-554a-4f2a-b8d0-61f761425155"),
+	}
+
+	pssh := mpd.NewPSSH(mpd.ClearKeySystemID, nil, kids...)
+
+	decoded, err := mpd.DecodePSSH(pssh.Base64())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantPSSH := &mpd.PSSH{Version: 1, SystemID: mpd.ClearKeySystemID, KID: kids, Data: []byte{}}
+	if diff := cmp.Diff(decoded, wantPSSH); diff != "" {
+		t.Errorf("wrong PSSH box: %s", diff)
+	}
+}
+
+func TestDecodePSSH_Errors(t *testing.T) {
+	valid := mpd.NewPSSH(mpd.WidevineSystemID, []byte{1, 2, 3}).Bytes()
+
+	testCases := map[string][]byte{
+		"empty":          {},
+		"other box":      makeBox("free", make([]byte, 32)),
+		"truncated":      valid[:len(valid)-1],
+		"trailing bytes": append(makeFullBox("pssh", 0, 0, valid[12:]), 0),
+		"trailing data":  makeFullBox("pssh", 0, 0, valid[12:], []byte{0}),
+		"missing KIDs":   makeFullBox("pssh", 1, 0, valid[12:28], u32(2)),
+	}
+
+	for name, data := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := mpd.ParsePSSH(data); !errors.Is(err, mpd.ErrParsePSSH) {
+				t.Errorf("wrong error: %v", err)
+			}
+		})
+	}
+
+	if _, err := mpd.DecodePSSH("%"); !errors.Is(err, mpd.ErrParsePSSH) {
+		t.Errorf("wrong error: %v", err)
+	}
+
+	contentProtection := mpd.ContentProtection{CENCPSSH: []string{"AAAA"}}
+	if _, err := contentProtection.PSSH(); !errors.Is(err, mpd.ErrParsePSSH) {
+		t.Errorf("wrong error: %v", err)
+	}
+}
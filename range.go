@@ -0,0 +1,38 @@
+package mpd
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var ErrParseRange = errors.New("cannot parse range")
+
+// NewSingleRFC7233Range creates a range from the first to the last byte position, both inclusive.
+func NewSingleRFC7233Range(first, last uint64) SingleRFC7233Range {
+	return SingleRFC7233Range(strconv.FormatUint(first, 10) + "-" + strconv.FormatUint(last, 10))
+}
+
+// Bounds returns the first and last byte positions of a closed range, both inclusive.
+func (r SingleRFC7233Range) Bounds() (uint64, uint64, error) {
+	firstStr, lastStr, found := strings.Cut(string(r), "-")
+	if !found {
+		return 0, 0, ErrParseRange
+	}
+
+	first, err := strconv.ParseUint(firstStr, 10, 64)
+	if err != nil {
+		return 0, 0, errors.Join(ErrParseRange, err)
+	}
+
+	last, err := strconv.ParseUint(lastStr, 10, 64)
+	if err != nil {
+		return 0, 0, errors.Join(ErrParseRange, err)
+	}
+
+	if last < first {
+		return 0, 0, ErrParseRange
+	}
+
+	return first, last, nil
+}
@@ -0,0 +1,44 @@
+package mpd_test
+
+import (
+	"errors"
+	"go.eigsys.de/go-mpd"
+	"testing"
+)
+
+func TestSingleRFC7233Range_Bounds(t *testing.T) {
+	type TestCase struct {
+		input     mpd.SingleRFC7233Range
+		wantFirst uint64
+		wantLast  uint64
+		wantErr   error
+	}
+
+	testCases := []TestCase{
+		{input: "0-99", wantFirst: 0, wantLast: 99},
+		{input: "100-100", wantFirst: 100, wantLast: 100},
+		{input: "100", wantErr: mpd.ErrParseRange},
+		{input: "100-", wantErr: mpd.ErrParseRange},
+		{input: "-100", wantErr: mpd.ErrParseRange},
+		{input: "100-99", wantErr: mpd.ErrParseRange},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(string(testCase.input), func(t *testing.T) {
+			first, last, err := testCase.input.Bounds()
+			if !errors.Is(err, testCase.wantErr) {
+				t.Errorf("wrong error: %v", err)
+			}
+
+			if first != testCase.wantFirst || last != testCase.wantLast {
+				t.Errorf("wrong bounds: %d-%d", first, last)
+			}
+		})
+	}
+}
+
+func TestNewSingleRFC7233Range(t *testing.T) {
+	if got := mpd.NewSingleRFC7233Range(10, 20); got != "10-20" {
+		t.Errorf("wrong range: %s", got)
+	}
+}
@@ -0,0 +1,747 @@
+package mpd
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrParseSCTE35  = errors.New("cannot parse SCTE-35 splice_info_section")
+	ErrEncodeSCTE35 = errors.New("cannot encode SCTE-35 splice_info_section")
+	ErrSCTE35CRC    = errors.New("SCTE-35 CRC_32 mismatch")
+)
+
+// SCTE-35 splice_command_type values.
+const (
+	SCTE35SpliceNullCommandType           uint8 = 0x00
+	SCTE35SpliceScheduleCommandType       uint8 = 0x04
+	SCTE35SpliceInsertCommandType         uint8 = 0x05
+	SCTE35TimeSignalCommandType           uint8 = 0x06
+	SCTE35BandwidthReservationCommandType uint8 = 0x07
+	SCTE35PrivateCommandType              uint8 = 0xff
+)
+
+// SCTE-35 splice_descriptor_tag values.
+const (
+	SCTE35AvailDescriptorTag        uint8 = 0x00
+	SCTE35DTMFDescriptorTag         uint8 = 0x01
+	SCTE35SegmentationDescriptorTag uint8 = 0x02
+	SCTE35TimeDescriptorTag         uint8 = 0x03
+	SCTE35AudioDescriptorTag        uint8 = 0x04
+)
+
+// SCTE35CUEIIdentifier is the splice descriptor identifier "CUEI".
+const SCTE35CUEIIdentifier uint32 = 0x43554549
+
+const (
+	scte35TableID                  = 0xfc
+	scte35PTSMask                  = 1<<33 - 1
+	scte35UnspecifiedCommandLength = 0xfff
+)
+
+// SCTE35SpliceInfoSection is a decoded SCTE-35 splice_info_section. Encrypted sections are not supported.
+type SCTE35SpliceInfoSection struct {
+	// SAPType is 3 if not specified.
+	SAPType uint8
+
+	ProtocolVersion uint8
+
+	// PTSAdjustment is added to all PTS values of the section, modulo 2^33.
+	PTSAdjustment uint64
+
+	CWIndex uint8
+
+	// Tier is 0xfff if not used.
+	Tier uint16
+
+	SpliceCommandType uint8
+
+	// SpliceInsert is set for SCTE35SpliceInsertCommandType.
+	SpliceInsert *SCTE35SpliceInsert
+
+	// TimeSignal is set for SCTE35TimeSignalCommandType.
+	TimeSignal *SCTE35SpliceTime
+
+	// SpliceCommand holds the raw splice_command of all other command types.
+	SpliceCommand []byte
+
+	SpliceDescriptor []SCTE35SpliceDescriptor
+}
+
+// SCTE35SpliceTime is a splice_time. A nil PTSTime signals an unspecified time.
+type SCTE35SpliceTime struct {
+	PTSTime *uint64
+}
+
+// SCTE35BreakDuration is a break_duration in 90 kHz ticks.
+type SCTE35BreakDuration struct {
+	AutoReturn bool
+	Duration   uint64
+}
+
+// SCTE35SpliceInsert is a splice_insert command.
+type SCTE35SpliceInsert struct {
+	SpliceEventID              uint32
+	SpliceEventCancelIndicator bool
+	OutOfNetworkIndicator      bool
+	SpliceImmediateFlag        bool
+	EventIDComplianceFlag      bool
+	SpliceTime                 *SCTE35SpliceTime
+	Component                  []SCTE35SpliceInsertComponent
+	BreakDuration              *SCTE35BreakDuration
+	UniqueProgramID            uint16
+	AvailNum                   uint8
+	AvailsExpected             uint8
+}
+
+// SCTE35SpliceInsertComponent is a component of a component splice mode splice_insert.
+type SCTE35SpliceInsertComponent struct {
+	ComponentTag uint8
+	SpliceTime   *SCTE35SpliceTime
+}
+
+// SCTE35SpliceDescriptor is a splice_descriptor.
+type SCTE35SpliceDescriptor struct {
+	Tag uint8
+
+	// Identifier is SCTE35CUEIIdentifier for descriptors defined by SCTE-35.
+	Identifier uint32
+
+	// Segmentation is set for segmentation descriptors with the "CUEI" identifier.
+	Segmentation *SCTE35SegmentationDescriptor
+
+	// Data holds the raw payload following the identifier of all other descriptors.
+	Data []byte
+}
+
+// SCTE35SegmentationDescriptor is a segmentation_descriptor.
+type SCTE35SegmentationDescriptor struct {
+	SegmentationEventID                    uint32
+	SegmentationEventCancelIndicator       bool
+	SegmentationEventIDComplianceIndicator bool
+
+	// DeliveryRestrictions is nil if delivery_not_restricted_flag is set.
+	DeliveryRestrictions *SCTE35DeliveryRestrictions
+
+	// Component is empty in program segmentation mode.
+	Component []SCTE35SegmentationComponent
+
+	// SegmentationDuration in 90 kHz ticks, if present.
+	SegmentationDuration *uint64
+
+	UPID []SCTE35UPID
+
+	// MID is set if UPID is carried in a MID (type 0x0d), which is implied for more than one UPID. It keeps a MID
+	// with a single UPID from being encoded as that UPID.
+	MID bool
+
+	SegmentationTypeID uint8
+	SegmentNum         uint8
+	SegmentsExpected   uint8
+
+	// SubSegment is only present for some segmentation types.
+	SubSegment *SCTE35SubSegment
+}
+
+// SCTE35DeliveryRestrictions holds the restriction flags of a segmentation_descriptor.
+type SCTE35DeliveryRestrictions struct {
+	WebDeliveryAllowed bool
+	NoRegionalBlackout bool
+	ArchiveAllowed     bool
+	DeviceRestrictions uint8
+}
+
+// SCTE35SegmentationComponent is a component of a component segmentation mode segmentation_descriptor.
+type SCTE35SegmentationComponent struct {
+	ComponentTag uint8
+	PTSOffset    uint64
+}
+
+// SCTE35SubSegment holds sub_segment_num and sub_segments_expected.
+type SCTE35SubSegment struct {
+	SubSegmentNum       uint8
+	SubSegmentsExpected uint8
+}
+
+// SCTE35UPID is a segmentation_upid. A MID (type 0x0d) is decoded into one SCTE35UPID per contained UPID, with
+// SCTE35SegmentationDescriptor.MID set.
+type SCTE35UPID struct {
+	Type  uint8
+	Value []byte
+}
+
+// SCTE-35 segmentation_upid_type values.
+const (
+	SCTE35NotUsedUPIDType uint8 = 0x00
+	SCTE35AdIDUPIDType    uint8 = 0x03
+	SCTE35TIUPIDType      uint8 = 0x08
+	SCTE35ADIUPIDType     uint8 = 0x09
+	SCTE35EIDRUPIDType    uint8 = 0x0a
+	SCTE35MPUUPIDType     uint8 = 0x0c
+	SCTE35MIDUPIDType     uint8 = 0x0d
+	SCTE35URIUPIDType     uint8 = 0x0f
+	SCTE35UUIDUPIDType    uint8 = 0x10
+)
+
+// NewSCTE35Signal encodes the section for Event.SCTE35Signal in an EventStream with SCTE35XMLBinSchemeIDURI.
+func NewSCTE35Signal(section *SCTE35SpliceInfoSection) (SCTE35Signal, error) {
+	value, err := section.Base64()
+	if err != nil {
+		return SCTE35Signal{}, err
+	}
+
+	return SCTE35Signal{Binary: value}, nil
+}
+
+// SpliceInfoSection decodes SCTE35Signal.Binary.
+func (s *SCTE35Signal) SpliceInfoSection() (*SCTE35SpliceInfoSection, error) {
+	return DecodeSCTE35(strings.TrimSpace(s.Binary))
+}
+
+// DecodeSCTE35 decodes a base64 encoded splice_info_section.
+func DecodeSCTE35(value string) (*SCTE35SpliceInfoSection, error) {
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.Join(ErrParseSCTE35, err)
+	}
+
+	return ParseSCTE35(data)
+}
+
+// ParseSCTE35 parses a binary splice_info_section and verifies its CRC_32.
+func ParseSCTE35(data []byte) (*SCTE35SpliceInfoSection, error) {
+	r := &bitReader{data: data}
+	if r.bits(8) != scte35TableID {
+		return nil, fmt.Errorf("%w: wrong table_id", ErrParseSCTE35)
+	}
+
+	r.bits(2) // section_syntax_indicator, private_indicator
+	section := &SCTE35SpliceInfoSection{SAPType: uint8(r.bits(2))}
+
+	if sectionLength := int(r.bits(12)); r.err != nil || sectionLength+3 != len(data) || sectionLength < 17 {
+		return nil, fmt.Errorf("%w: wrong section_length", ErrParseSCTE35)
+	}
+
+	if crc32MPEG2(data) != 0 {
+		return nil, errors.Join(ErrParseSCTE35, ErrSCTE35CRC)
+	}
+
+	section.ProtocolVersion = uint8(r.bits(8))
+	if r.flag() {
+		return nil, fmt.Errorf("%w: encrypted packets are not supported", ErrParseSCTE35)
+	}
+
+	r.bits(6) // encryption_algorithm
+	section.PTSAdjustment = r.bits(33)
+	section.CWIndex = uint8(r.bits(8))
+	section.Tier = uint16(r.bits(12))
+	commandLength := int(r.bits(12))
+	section.SpliceCommandType = uint8(r.bits(8))
+	commandStart := r.position
+
+	switch section.SpliceCommandType {
+	case SCTE35SpliceInsertCommandType:
+		section.SpliceInsert = readSCTE35SpliceInsert(r)
+	case SCTE35TimeSignalCommandType:
+		section.TimeSignal = readSCTE35SpliceTime(r)
+	case SCTE35SpliceNullCommandType:
+	default:
+		if commandLength == scte35UnspecifiedCommandLength {
+			return nil, fmt.Errorf("%w: unspecified splice_command_length", ErrParseSCTE35)
+		}
+
+		section.SpliceCommand = r.bytes(commandLength)
+	}
+
+	if commandLength != scte35UnspecifiedCommandLength && r.position-commandStart != 8*commandLength {
+		return nil, fmt.Errorf("%w: wrong splice_command_length", ErrParseSCTE35)
+	}
+
+	descriptors := &bitReader{data: r.bytes(int(r.bits(16)))}
+	for r.err == nil && descriptors.err == nil && descriptors.position < 8*len(descriptors.data) {
+		tag := uint8(descriptors.bits(8))
+		descriptor, err := parseSCTE35SpliceDescriptor(tag, descriptors.bytes(int(descriptors.bits(8))))
+		if err != nil {
+			return nil, err
+		}
+
+		section.SpliceDescriptor = append(section.SpliceDescriptor, descriptor)
+	}
+
+	if r.err != nil || descriptors.err != nil {
+		return nil, fmt.Errorf("%w: truncated section", ErrParseSCTE35)
+	}
+
+	if r.position/8+4 > len(data) {
+		return nil, fmt.Errorf("%w: wrong descriptor_loop_length", ErrParseSCTE35)
+	}
+
+	return section, nil
+}
+
+func readSCTE35SpliceTime(r *bitReader) *SCTE35SpliceTime {
+	if !r.flag() {
+		r.bits(7)
+		return &SCTE35SpliceTime{}
+	}
+
+	r.bits(6)
+	ptsTime := r.bits(33)
+
+	return &SCTE35SpliceTime{PTSTime: &ptsTime}
+}
+
+func readSCTE35SpliceInsert(r *bitReader) *SCTE35SpliceInsert {
+	insert := &SCTE35SpliceInsert{SpliceEventID: uint32(r.bits(32)), SpliceEventCancelIndicator: r.flag()}
+	r.bits(7)
+
+	if insert.SpliceEventCancelIndicator {
+		return insert
+	}
+
+	insert.OutOfNetworkIndicator = r.flag()
+	programSpliceFlag := r.flag()
+	durationFlag := r.flag()
+	insert.SpliceImmediateFlag = r.flag()
+	insert.EventIDComplianceFlag = r.flag()
+	r.bits(3)
+
+	if programSpliceFlag && !insert.SpliceImmediateFlag {
+		insert.SpliceTime = readSCTE35SpliceTime(r)
+	}
+
+	if !programSpliceFlag {
+		count := int(r.bits(8))
+		for i := 0; i < count && r.err == nil; i++ {
+			component := SCTE35SpliceInsertComponent{ComponentTag: uint8(r.bits(8))}
+			if !insert.SpliceImmediateFlag {
+				component.SpliceTime = readSCTE35SpliceTime(r)
+			}
+
+			insert.Component = append(insert.Component, component)
+		}
+	}
+
+	if durationFlag {
+		insert.BreakDuration = &SCTE35BreakDuration{AutoReturn: r.flag()}
+		r.bits(6)
+		insert.BreakDuration.Duration = r.bits(33)
+	}
+
+	insert.UniqueProgramID = uint16(r.bits(16))
+	insert.AvailNum = uint8(r.bits(8))
+	insert.AvailsExpected = uint8(r.bits(8))
+
+	return insert
+}
+
+func parseSCTE35SpliceDescriptor(tag uint8, data []byte) (SCTE35SpliceDescriptor, error) {
+	r := &bitReader{data: data}
+	descriptor := SCTE35SpliceDescriptor{Tag: tag, Identifier: uint32(r.bits(32))}
+
+	if tag != SCTE35SegmentationDescriptorTag || descriptor.Identifier != SCTE35CUEIIdentifier {
+		descriptor.Data = r.bytes(len(data) - 4)
+	} else {
+		descriptor.Segmentation = readSCTE35SegmentationDescriptor(r)
+	}
+
+	if r.err != nil || r.position != 8*len(data) {
+		return descriptor, fmt.Errorf("%w: wrong descriptor_length", ErrParseSCTE35)
+	}
+
+	return descriptor, nil
+}
+
+func readSCTE35SegmentationDescriptor(r *bitReader) *SCTE35SegmentationDescriptor {
+	descriptor := &SCTE35SegmentationDescriptor{
+		SegmentationEventID:                    uint32(r.bits(32)),
+		SegmentationEventCancelIndicator:       r.flag(),
+		SegmentationEventIDComplianceIndicator: r.flag(),
+	}
+
+	r.bits(6)
+
+	if descriptor.SegmentationEventCancelIndicator {
+		return descriptor
+	}
+
+	programSegmentationFlag := r.flag()
+	durationFlag := r.flag()
+
+	if r.flag() {
+		r.bits(5)
+	} else {
+		descriptor.DeliveryRestrictions = &SCTE35DeliveryRestrictions{
+			WebDeliveryAllowed: r.flag(),
+			NoRegionalBlackout: r.flag(),
+			ArchiveAllowed:     r.flag(),
+			DeviceRestrictions: uint8(r.bits(2)),
+		}
+	}
+
+	if !programSegmentationFlag {
+		count := int(r.bits(8))
+		for i := 0; i < count && r.err == nil; i++ {
+			component := SCTE35SegmentationComponent{ComponentTag: uint8(r.bits(8))}
+			r.bits(7)
+			component.PTSOffset = r.bits(33)
+			descriptor.Component = append(descriptor.Component, component)
+		}
+	}
+
+	if durationFlag {
+		duration := r.bits(40)
+		descriptor.SegmentationDuration = &duration
+	}
+
+	upidType := uint8(r.bits(8))
+	upid := r.bytes(int(r.bits(8)))
+
+	if upidType == SCTE35MIDUPIDType {
+		descriptor.MID = true
+
+		mid := &bitReader{data: upid}
+		for mid.err == nil && mid.position < 8*len(upid) {
+			midType := uint8(mid.bits(8))
+			descriptor.UPID = append(descriptor.UPID, SCTE35UPID{Type: midType, Value: mid.bytes(int(mid.bits(8)))})
+		}
+
+		r.err = errors.Join(r.err, mid.err)
+	} else if upidType != SCTE35NotUsedUPIDType || len(upid) > 0 {
+		descriptor.UPID = []SCTE35UPID{{Type: upidType, Value: upid}}
+	}
+
+	descriptor.SegmentationTypeID = uint8(r.bits(8))
+	descriptor.SegmentNum = uint8(r.bits(8))
+	descriptor.SegmentsExpected = uint8(r.bits(8))
+
+	if r.err == nil && r.position+16 <= 8*len(r.data) {
+		descriptor.SubSegment = &SCTE35SubSegment{SubSegmentNum: uint8(r.bits(8)), SubSegmentsExpected: uint8(r.bits(8))}
+	}
+
+	return descriptor
+}
+
+// Base64 encodes the section as used by SCTE35Signal.Binary.
+func (s *SCTE35SpliceInfoSection) Base64() (string, error) {
+	data, err := s.Bytes()
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// Bytes encodes the section including its CRC_32.
+func (s *SCTE35SpliceInfoSection) Bytes() ([]byte, error) {
+	command := &bitWriter{}
+
+	switch {
+	case s.SpliceCommandType == SCTE35SpliceInsertCommandType && s.SpliceInsert != nil:
+		s.SpliceInsert.write(command)
+	case s.SpliceCommandType == SCTE35TimeSignalCommandType && s.TimeSignal != nil:
+		s.TimeSignal.write(command)
+	case s.SpliceCommandType != SCTE35SpliceInsertCommandType && s.SpliceCommandType != SCTE35TimeSignalCommandType:
+		command.append(s.SpliceCommand)
+	default:
+		return nil, fmt.Errorf("%w: missing splice command", ErrEncodeSCTE35)
+	}
+
+	descriptors := &bitWriter{}
+	for _, descriptor := range s.SpliceDescriptor {
+		if err := descriptor.write(descriptors); err != nil {
+			return nil, err
+		}
+	}
+
+	sectionLength := 11 + len(command.data) + 2 + len(descriptors.data) + 4
+	if len(command.data) >= scte35UnspecifiedCommandLength || len(descriptors.data) > 0xffff || sectionLength > 0xfff {
+		return nil, fmt.Errorf("%w: section too long", ErrEncodeSCTE35)
+	}
+
+	w := &bitWriter{data: make([]byte, 0, 3+sectionLength)}
+	w.bits(8, scte35TableID)
+	w.bits(2, 0) // section_syntax_indicator, private_indicator
+	w.bits(2, uint64(s.SAPType))
+	w.bits(12, uint64(sectionLength))
+	w.bits(8, uint64(s.ProtocolVersion))
+	w.bits(7, 0) // encrypted_packet, encryption_algorithm
+	w.bits(33, s.PTSAdjustment)
+	w.bits(8, uint64(s.CWIndex))
+	w.bits(12, uint64(s.Tier))
+	w.bits(12, uint64(len(command.data)))
+	w.bits(8, uint64(s.SpliceCommandType))
+	w.append(command.data)
+	w.bits(16, uint64(len(descriptors.data)))
+	w.append(descriptors.data)
+
+	return binary.BigEndian.AppendUint32(w.data, crc32MPEG2(w.data)), nil
+}
+
+func (t *SCTE35SpliceTime) write(w *bitWriter) {
+	if t == nil || t.PTSTime == nil {
+		w.flag(false)
+		w.reserved(7)
+
+		return
+	}
+
+	w.flag(true)
+	w.reserved(6)
+	w.bits(33, *t.PTSTime)
+}
+
+func (i *SCTE35SpliceInsert) write(w *bitWriter) {
+	w.bits(32, uint64(i.SpliceEventID))
+	w.flag(i.SpliceEventCancelIndicator)
+	w.reserved(7)
+
+	if i.SpliceEventCancelIndicator {
+		return
+	}
+
+	programSpliceFlag := len(i.Component) == 0
+	w.flag(i.OutOfNetworkIndicator)
+	w.flag(programSpliceFlag)
+	w.flag(i.BreakDuration != nil)
+	w.flag(i.SpliceImmediateFlag)
+	w.flag(i.EventIDComplianceFlag)
+	w.reserved(3)
+
+	if programSpliceFlag && !i.SpliceImmediateFlag {
+		i.SpliceTime.write(w)
+	}
+
+	if !programSpliceFlag {
+		w.bits(8, uint64(len(i.Component)))
+		for _, component := range i.Component {
+			w.bits(8, uint64(component.ComponentTag))
+			if !i.SpliceImmediateFlag {
+				component.SpliceTime.write(w)
+			}
+		}
+	}
+
+	if i.BreakDuration != nil {
+		w.flag(i.BreakDuration.AutoReturn)
+		w.reserved(6)
+		w.bits(33, i.BreakDuration.Duration)
+	}
+
+	w.bits(16, uint64(i.UniqueProgramID))
+	w.bits(8, uint64(i.AvailNum))
+	w.bits(8, uint64(i.AvailsExpected))
+}
+
+func (d *SCTE35SpliceDescriptor) write(w *bitWriter) error {
+	payload := &bitWriter{}
+	payload.bits(32, uint64(d.Identifier))
+
+	if d.Segmentation != nil {
+		if err := d.Segmentation.write(payload); err != nil {
+			return err
+		}
+	} else {
+		payload.append(d.Data)
+	}
+
+	if len(payload.data) > 0xff {
+		return fmt.Errorf("%w: splice descriptor too long", ErrEncodeSCTE35)
+	}
+
+	w.bits(8, uint64(d.Tag))
+	w.bits(8, uint64(len(payload.data)))
+	w.append(payload.data)
+
+	return nil
+}
+
+func (d *SCTE35SegmentationDescriptor) write(w *bitWriter) error {
+	w.bits(32, uint64(d.SegmentationEventID))
+	w.flag(d.SegmentationEventCancelIndicator)
+	w.flag(d.SegmentationEventIDComplianceIndicator)
+	w.reserved(6)
+
+	if d.SegmentationEventCancelIndicator {
+		return nil
+	}
+
+	w.flag(len(d.Component) == 0)
+	w.flag(d.SegmentationDuration != nil)
+	w.flag(d.DeliveryRestrictions == nil)
+
+	if d.DeliveryRestrictions == nil {
+		w.reserved(5)
+	} else {
+		w.flag(d.DeliveryRestrictions.WebDeliveryAllowed)
+		w.flag(d.DeliveryRestrictions.NoRegionalBlackout)
+		w.flag(d.DeliveryRestrictions.ArchiveAllowed)
+		w.bits(2, uint64(d.DeliveryRestrictions.DeviceRestrictions))
+	}
+
+	if len(d.Component) > 0 {
+		w.bits(8, uint64(len(d.Component)))
+		for _, component := range d.Component {
+			w.bits(8, uint64(component.ComponentTag))
+			w.reserved(7)
+			w.bits(33, component.PTSOffset)
+		}
+	}
+
+	if d.SegmentationDuration != nil {
+		w.bits(40, *d.SegmentationDuration)
+	}
+
+	upidType, upid := SCTE35NotUsedUPIDType, []byte(nil)
+
+	switch {
+	case d.MID || len(d.UPID) > 1:
+		upidType = SCTE35MIDUPIDType
+		for _, value := range d.UPID {
+			if len(value.Value) > 0xff {
+				return fmt.Errorf("%w: segmentation_upid too long", ErrEncodeSCTE35)
+			}
+
+			upid = append(upid, value.Type, uint8(len(value.Value)))
+			upid = append(upid, value.Value...)
+		}
+	case len(d.UPID) == 1:
+		upidType, upid = d.UPID[0].Type, d.UPID[0].Value
+	}
+
+	if len(upid) > 0xff {
+		return fmt.Errorf("%w: segmentation_upid too long", ErrEncodeSCTE35)
+	}
+
+	w.bits(8, uint64(upidType))
+	w.bits(8, uint64(len(upid)))
+	w.append(upid)
+	w.bits(8, uint64(d.SegmentationTypeID))
+	w.bits(8, uint64(d.SegmentNum))
+	w.bits(8, uint64(d.SegmentsExpected))
+
+	if d.SubSegment != nil {
+		w.bits(8, uint64(d.SubSegment.SubSegmentNum))
+		w.bits(8, uint64(d.SubSegment.SubSegmentsExpected))
+	}
+
+	return nil
+}
+
+// SplicePTS returns the PTS of a time_signal or a program splice mode splice_insert with PTSAdjustment applied.
+func (s *SCTE35SpliceInfoSection) SplicePTS() (uint64, bool) {
+	spliceTime := s.TimeSignal
+	if s.SpliceInsert != nil {
+		spliceTime = s.SpliceInsert.SpliceTime
+	}
+
+	if spliceTime == nil || spliceTime.PTSTime == nil {
+		return 0, false
+	}
+
+	return (*spliceTime.PTSTime + s.PTSAdjustment) & scte35PTSMask, true
+}
+
+// crc32MPEG2 calculates the CRC-32/MPEG-2 checksum, which is 0 for data ending with its own checksum.
+func crc32MPEG2(data []byte) uint32 {
+	crc := uint32(0xffffffff)
+
+	for _, b := range data {
+		crc ^= uint32(b) << 24
+		for i := 0; i < 8; i++ {
+			if crc&0x80000000 != 0 {
+				crc = crc<<1 ^ 0x04c11db7
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return crc
+}
+
+// bitReader reads big-endian bit fields. The first error is sticky and reads return 0 afterwards.
+type bitReader struct {
+	data     []byte
+	position int
+	err      error
+}
+
+func (r *bitReader) bits(n int) uint64 {
+	if r.err != nil {
+		return 0
+	}
+
+	if r.position+n > 8*len(r.data) {
+		r.err = ErrParseSCTE35
+		return 0
+	}
+
+	var value uint64
+	for i := 0; i < n; i++ {
+		value = value<<1 | uint64(r.data[r.position/8]>>(7-r.position%8)&1)
+		r.position++
+	}
+
+	return value
+}
+
+func (r *bitReader) flag() bool {
+	return r.bits(1) == 1
+}
+
+// bytes reads n bytes at a byte-aligned position.
+func (r *bitReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+
+	if r.position%8 != 0 || n < 0 || r.position/8+n > len(r.data) {
+		r.err = ErrParseSCTE35
+		return nil
+	}
+
+	value := r.data[r.position/8 : r.position/8+n]
+	r.position += 8 * n
+
+	return value
+}
+
+// bitWriter writes big-endian bit fields.
+type bitWriter struct {
+	data     []byte
+	position int
+}
+
+func (w *bitWriter) bits(n int, value uint64) {
+	for i := n - 1; i >= 0; i-- {
+		if w.position%8 == 0 {
+			w.data = append(w.data, 0)
+		}
+
+		w.data[len(w.data)-1] |= byte(value>>i&1) << (7 - w.position%8)
+		w.position++
+	}
+}
+
+func (w *bitWriter) flag(value bool) {
+	if value {
+		w.bits(1, 1)
+	} else {
+		w.bits(1, 0)
+	}
+}
+
+// reserved writes n reserved bits, which are set to 1.
+func (w *bitWriter) reserved(n int) {
+	w.bits(n, 1<<n-1)
+}
+
+// append writes bytes at a byte-aligned position.
+func (w *bitWriter) append(data []byte) {
+	w.data = append(w.data, data...)
+	w.position += 8 * len(data)
+}
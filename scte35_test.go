@@ -0,0 +1,339 @@
+package mpd_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"go.eigsys.de/go-mpd"
+	"io"
+	"testing"
+)
+
+const (
+	scte35TimeSignal   = "/DA0AAAAAAAA///wBQb+cr0AUAAeAhxDVUVJSAAAjn/PAAGlmbAICAAAAAAsoKGKNAIAmsnRfg=="
+	scte35SpliceInsert = "/DAvAAAAAAAA///wFAVIAACPf+/+c2nALv4AUsz1AAAAAAAKAAhDVUVJAAABNWLbowo="
+)
+
+func TestDecodeSCTE35(t *testing.T) {
+	type TestCase struct {
+		name        string
+		value       string
+		wantSection *mpd.SCTE35SpliceInfoSection
+		wantPTS     uint64
+	}
+
+	testCases := []TestCase{
+		{
+			name:  "time_signal",
+			value: scte35TimeSignal,
+			wantSection: &mpd.SCTE35SpliceInfoSection{
+				SAPType:           3,
+				CWIndex:           0xff,
+				Tier:              0xfff,
+				SpliceCommandType: mpd.SCTE35TimeSignalCommandType,
+				TimeSignal:        &mpd.SCTE35SpliceTime{PTSTime: uint64Ptr(0x072bd0050)},
+				SpliceDescriptor: []mpd.SCTE35SpliceDescriptor{{
+					Tag:        mpd.SCTE35SegmentationDescriptorTag,
+					Identifier: mpd.SCTE35CUEIIdentifier,
+					Segmentation: &mpd.SCTE35SegmentationDescriptor{
+						SegmentationEventID:                    0x4800008e,
+						SegmentationEventIDComplianceIndicator: true,
+						DeliveryRestrictions:                   &mpd.SCTE35DeliveryRestrictions{NoRegionalBlackout: true, ArchiveAllowed: true, DeviceRestrictions: 3},
+						SegmentationDuration:                   uint64Ptr(0x0001a599b0),
+						UPID:                                   []mpd.SCTE35UPID{{Type: mpd.SCTE35TIUPIDType, Value: []byte{0, 0, 0, 0, 0x2c, 0xa0, 0xa1, 0x8a}}},
+						SegmentationTypeID:                     0x34,
+						SegmentNum:                             2,
+					},
+				}},
+			},
+			wantPTS: 0x072bd0050,
+		},
+		{
+			name:  "splice_insert",
+			value: scte35SpliceInsert,
+			wantSection: &mpd.SCTE35SpliceInfoSection{
+				SAPType:           3,
+				CWIndex:           0xff,
+				Tier:              0xfff,
+				SpliceCommandType: mpd.SCTE35SpliceInsertCommandType,
+				SpliceInsert: &mpd.SCTE35SpliceInsert{
+					SpliceEventID:         0x4800008f,
+					OutOfNetworkIndicator: true,
+					EventIDComplianceFlag: true,
+					SpliceTime:            &mpd.SCTE35SpliceTime{PTSTime: uint64Ptr(0x07369c02e)},
+					BreakDuration:         &mpd.SCTE35BreakDuration{AutoReturn: true, Duration: 0x00052ccf5},
+				},
+				SpliceDescriptor: []mpd.SCTE35SpliceDescriptor{{
+					Tag:        mpd.SCTE35AvailDescriptorTag,
+					Identifier: mpd.SCTE35CUEIIdentifier,
+					Data:       []byte{0, 0, 1, 0x35},
+				}},
+			},
+			wantPTS: 0x07369c02e,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			section, err := mpd.DecodeSCTE35(testCase.value)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(section, testCase.wantSection); diff != "" {
+				t.Errorf("wrong section: %s", diff)
+			}
+
+			if pts, ok := section.SplicePTS(); !ok || pts != testCase.wantPTS {
+				t.Errorf("wrong PTS: %d", pts)
+			}
+
+			if encoded, err := section.Base64(); err != nil || encoded != testCase.value {
+				t.Errorf("wrong encoding: %s", encoded)
+			}
+		})
+	}
+}
+
+func TestSCTE35SpliceInfoSection_Bytes(t *testing.T) {
+	testCases := map[string]*mpd.SCTE35SpliceInfoSection{
+		"component splice_insert": {
+			SAPType:           3,
+			PTSAdjustment:     1<<33 - 10,
+			Tier:              0xfff,
+			SpliceCommandType: mpd.SCTE35SpliceInsertCommandType,
+			SpliceInsert: &mpd.SCTE35SpliceInsert{
+				SpliceEventID: 1,
+				Component: []mpd.SCTE35SpliceInsertComponent{
+					{ComponentTag: 1, SpliceTime: &mpd.SCTE35SpliceTime{PTSTime: uint64Ptr(90000)}},
+					{ComponentTag: 2, SpliceTime: &mpd.SCTE35SpliceTime{}},
+				},
+				UniqueProgramID: 7,
+				AvailNum:        1,
+				AvailsExpected:  2,
+			},
+		},
+		"immediate splice_insert": {
+			SpliceCommandType: mpd.SCTE35SpliceInsertCommandType,
+			SpliceInsert: &mpd.SCTE35SpliceInsert{
+				SpliceImmediateFlag: true,
+				Component:           []mpd.SCTE35SpliceInsertComponent{{ComponentTag: 1}},
+			},
+		},
+		"cancelled splice_insert": {
+			SpliceCommandType: mpd.SCTE35SpliceInsertCommandType,
+			SpliceInsert:      &mpd.SCTE35SpliceInsert{SpliceEventID: 2, SpliceEventCancelIndicator: true},
+		},
+		"segmentation descriptors": {
+			SpliceCommandType: mpd.SCTE35TimeSignalCommandType,
+			TimeSignal:        &mpd.SCTE35SpliceTime{},
+			SpliceDescriptor: []mpd.SCTE35SpliceDescriptor{
+				{
+					Tag:        mpd.SCTE35SegmentationDescriptorTag,
+					Identifier: mpd.SCTE35CUEIIdentifier,
+					Segmentation: &mpd.SCTE35SegmentationDescriptor{
+						SegmentationEventID: 3,
+						Component:           []mpd.SCTE35SegmentationComponent{{ComponentTag: 1, PTSOffset: 1 << 32}},
+						UPID: []mpd.SCTE35UPID{
+							{Type: mpd.SCTE35AdIDUPIDType, Value: []byte("ABCD0123456H")},
+							{Type: mpd.SCTE35URIUPIDType, Value: []byte("urn:uuid:08e36702-8f33-436c-a5dd-60ffe5571e60")},
+						},
+						MID:                true,
+						SegmentationTypeID: 0x36,
+						SubSegment:         &mpd.SCTE35SubSegment{SubSegmentNum: 1, SubSegmentsExpected: 4},
+					},
+				},
+				{
+					Tag:          mpd.SCTE35SegmentationDescriptorTag,
+					Identifier:   mpd.SCTE35CUEIIdentifier,
+					Segmentation: &mpd.SCTE35SegmentationDescriptor{SegmentationEventID: 3, SegmentationEventCancelIndicator: true},
+				},
+				{
+					Tag:        mpd.SCTE35SegmentationDescriptorTag,
+					Identifier: mpd.SCTE35CUEIIdentifier,
+					Segmentation: &mpd.SCTE35SegmentationDescriptor{
+						SegmentationEventID: 4,
+						UPID:                []mpd.SCTE35UPID{{Type: mpd.SCTE35AdIDUPIDType, Value: []byte("ABCD0123456H")}},
+						MID:                 true,
+					},
+				},
+				{Tag: mpd.SCTE35SegmentationDescriptorTag, Identifier: 0x41424344, Data: []byte{1, 2}},
+			},
+		},
+		"private_command": {
+			SpliceCommandType: mpd.SCTE35PrivateCommandType,
+			SpliceCommand:     []byte{0x41, 0x42, 0x43, 0x44, 1},
+		},
+	}
+
+	for name, section := range testCases {
+		t.Run(name, func(t *testing.T) {
+			data, err := section.Bytes()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			decoded, err := mpd.ParseSCTE35(data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(decoded, section); diff != "" {
+				t.Errorf("wrong section: %s", diff)
+			}
+		})
+	}
+
+	if pts, ok := testCases["component splice_insert"].SplicePTS(); ok {
+		t.Errorf("unexpected PTS: %d", pts)
+	}
+
+	section := &mpd.SCTE35SpliceInfoSection{
+		PTSAdjustment:     1<<33 - 10,
+		SpliceCommandType: mpd.SCTE35TimeSignalCommandType,
+		TimeSignal:        &mpd.SCTE35SpliceTime{PTSTime: uint64Ptr(90000)},
+	}
+	if pts, ok := section.SplicePTS(); !ok || pts != 89990 {
+		t.Errorf("wrong PTS: %d", pts)
+	}
+}
+
+func TestSCTE35SpliceInfoSection_Bytes_Errors(t *testing.T) {
+	longUPID := []mpd.SCTE35UPID{{Type: mpd.SCTE35URIUPIDType, Value: make([]byte, 256)}}
+
+	testCases := map[string]*mpd.SCTE35SpliceInfoSection{
+		"missing splice_insert": {SpliceCommandType: mpd.SCTE35SpliceInsertCommandType},
+		"missing time_signal":   {SpliceCommandType: mpd.SCTE35TimeSignalCommandType},
+		"long command": {
+			SpliceCommandType: mpd.SCTE35PrivateCommandType,
+			SpliceCommand:     make([]byte, 0xfff),
+		},
+		"long descriptor": {
+			SpliceDescriptor: []mpd.SCTE35SpliceDescriptor{{Data: make([]byte, 0xff)}},
+		},
+		"long UPID": {
+			SpliceDescriptor: []mpd.SCTE35SpliceDescriptor{{Segmentation: &mpd.SCTE35SegmentationDescriptor{UPID: longUPID}}},
+		},
+		"long MID UPID": {
+			SpliceDescriptor: []mpd.SCTE35SpliceDescriptor{{Segmentation: &mpd.SCTE35SegmentationDescriptor{UPID: append(longUPID, longUPID...)}}},
+		},
+		"long MID": {
+			SpliceDescriptor: []mpd.SCTE35SpliceDescriptor{{Segmentation: &mpd.SCTE35SegmentationDescriptor{UPID: []mpd.SCTE35UPID{
+				{Value: make([]byte, 200)}, {Value: make([]byte, 200)},
+			}}}},
+		},
+	}
+
+	for name, section := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := section.Base64(); !errors.Is(err, mpd.ErrEncodeSCTE35) {
+				t.Errorf("wrong error: %v", err)
+			}
+		})
+	}
+}
+
+// makeSCTE35 creates a splice_info_section with protocol_version, pts_adjustment and cw_index 0.
+// The flags byte holds encrypted_packet, encryption_algorithm and the first pts_adjustment bit.
+// The body starts with tier and splice_command_length.
+func makeSCTE35(flags byte, body ...byte) []byte {
+	data := append([]byte{0xfc, 0, 0, 0, flags, 0, 0, 0, 0, 0}, body...)
+	data[1], data[2] = byte((len(data)+1)>>8), byte(len(data)+1)
+
+	crc := uint32(0xffffffff)
+	for _, b := range data {
+		crc ^= uint32(b) << 24
+		for i := 0; i < 8; i++ {
+			if crc&0x80000000 != 0 {
+				crc = crc<<1 ^ 0x04c11db7
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return binary.BigEndian.AppendUint32(data, crc)
+}
+
+func TestParseSCTE35_Errors(t *testing.T) {
+	valid, _ := base64.StdEncoding.DecodeString(scte35TimeSignal)
+
+	testCases := map[string][]byte{
+		"empty":              {},
+		"wrong table_id":     append([]byte{0xfd}, valid[1:]...),
+		"wrong length":       valid[:len(valid)-1],
+		"wrong CRC":          append(valid[:len(valid)-1:len(valid)-1], valid[len(valid)-1]^1),
+		"encrypted":          makeSCTE35(0x80, 0, 0, 0, 0, 0, 0),
+		"wrong command":      makeSCTE35(0, 0, 0, 0x02, 0x06, 0x7f, 0, 0),
+		"unspecified length": makeSCTE35(0, 0, 0x0f, 0xff, 0xff, 0, 0),
+		"truncated command":  makeSCTE35(0, 0, 0x0f, 0xff, 0x06, 0x80),
+		"long loop":          makeSCTE35(0, 0, 0, 0, 0, 0, 0x10),
+		"short descriptor":   makeSCTE35(0, 0, 0, 0, 0, 0, 3, 0, 1, 0),
+		"long segmentation": makeSCTE35(0, 0, 0, 0, 0, 0, 0x12, 2, 0x10,
+			0x43, 0x55, 0x45, 0x49, 0, 0, 0, 1, 0x3f, 0xff, 0, 0, 0, 0, 0, 0),
+		"short MID": makeSCTE35(0, 0, 0, 0, 0, 0, 0x13, 2, 0x11,
+			0x43, 0x55, 0x45, 0x49, 0, 0, 0, 1, 0x3f, 0xbf, 0x0d, 2, 1, 5, 0, 0, 0),
+		"trailing descriptor data": makeSCTE35(0, 0, 0, 0, 0, 0, 0x14, 2, 0x12,
+			0x43, 0x55, 0x45, 0x49, 0, 0, 0, 1, 0x3f, 0xbf, 0, 0, 0, 0, 0, 0, 0, 0),
+	}
+
+	for name, data := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := mpd.ParseSCTE35(data); !errors.Is(err, mpd.ErrParseSCTE35) {
+				t.Errorf("wrong error: %v", err)
+			}
+		})
+	}
+
+	if _, err := mpd.ParseSCTE35(testCases["wrong CRC"]); !errors.Is(err, mpd.ErrSCTE35CRC) {
+		t.Errorf("wrong error: %v", err)
+	}
+
+	if _, err := mpd.DecodeSCTE35("%"); !errors.Is(err, mpd.ErrParseSCTE35) {
+		t.Errorf("wrong error: %v", err)
+	}
+}
+
+func TestNewSCTE35Signal(t *testing.T) {
+	section, err := mpd.DecodeSCTE35(scte35SpliceInsert)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	signal, err := mpd.NewSCTE35Signal(section)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	testMPD := mpd.New()
+	testMPD.Period = []mpd.Period{{EventStream: []mpd.EventStream{{
+		SchemeIdURI: mpd.SCTE35XMLBinSchemeIDURI,
+		Timescale:   90000,
+		Event:       []mpd.Event{{PresentationTime: 0x07369c02e, SCTE35Signal: []mpd.SCTE35Signal{signal}}},
+	}}}}
+
+	data, err := testMPD.Bytes()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	testMPD, err = mpd.Read(io.NopCloser(bytes.NewReader(data)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	decoded, err := testMPD.Period[0].EventStream[0].Event[0].SCTE35Signal[0].SpliceInfoSection()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(decoded, section); diff != "" {
+		t.Errorf("wrong section: %s", diff)
+	}
+
+	if _, err := mpd.NewSCTE35Signal(&mpd.SCTE35SpliceInfoSection{SpliceCommandType: mpd.SCTE35TimeSignalCommandType}); !errors.Is(err, mpd.ErrEncodeSCTE35) {
+		t.Errorf("wrong error: %v", err)
+	}
+}
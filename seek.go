@@ -0,0 +1,234 @@
+package mpd
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+var ErrSeekSegment = errors.New("cannot seek segment")
+
+// SegmentAt returns the segment of the Representation that contains the presentation time at, relative to the start
+// of the media presentation, with its URL resolved against the BaseURLs and its byte range like Segments. Segments
+// of a SegmentTimeline are found by a binary search over its S elements, segments of SegmentTemplate@duration and
+// SegmentList@duration are computed directly. With SegmentBase, the subsegment is looked up in index, the parsed
+// segment index of the Representation, and the whole resource is returned if index is nil. Unlike Segments, an
+// open-ended SegmentTimeline or SegmentTemplate@duration does not need a Period end.
+//
+// SegmentAt resolves the segment information on every call. Use SegmentSeeker for repeated lookups in the same
+// Representation.
+func (m *MPD) SegmentAt(period *Period, adaptationSet *AdaptationSet, representation *Representation, at time.Duration, index *SegmentIndex) (*Segment, error) {
+	seeker, err := m.SegmentSeeker(period, adaptationSet, representation, index)
+	if err != nil {
+		return nil, err
+	}
+
+	return seeker.SegmentAt(at)
+}
+
+// SegmentSeeker looks up the segments of a Representation like MPD.SegmentAt. The segment information and the runs
+// of a SegmentTimeline are resolved once, so that each lookup takes logarithmic time in the number of S elements.
+// The MPD must not be changed while the SegmentSeeker is in use.
+type SegmentSeeker struct {
+	window         segmentWindow
+	baseURL        string
+	representation *Representation
+	base           *MultipleSegmentBase
+	count          int
+	runs           []timelineRun
+
+	// Only one of segmentTemplate and segmentList is set, or none for SegmentBase.
+	segmentTemplate *SegmentTemplate
+	segmentList     *SegmentList
+}
+
+// SegmentSeeker returns a SegmentSeeker for the Representation. index is the parsed segment index of the
+// Representation if it uses SegmentBase, or nil.
+func (m *MPD) SegmentSeeker(period *Period, adaptationSet *AdaptationSet, representation *Representation, index *SegmentIndex) (*SegmentSeeker, error) {
+	i := m.periodIndex(period)
+	if i < 0 {
+		return nil, fmt.Errorf("%w: period not part of the MPD", ErrSeekSegment)
+	}
+
+	periodStart, err := m.PeriodStart(i)
+	if err != nil {
+		return nil, errors.Join(ErrSeekSegment, err)
+	}
+
+	periodDuration, err := m.PeriodDuration(i)
+	if err != nil {
+		return nil, errors.Join(ErrSeekSegment, err)
+	}
+
+	seeker := &SegmentSeeker{
+		window:         segmentWindow{periodStart: periodStart, start: periodStart},
+		baseURL:        m.resolveBaseURL(period, adaptationSet, representation),
+		representation: representation,
+	}
+
+	if periodDuration > 0 {
+		seeker.window.periodEnd = periodStart + periodDuration
+		seeker.window.end = seeker.window.periodEnd
+	}
+
+	segmentBase, segmentList, segmentTemplate := segmentInformation(period, adaptationSet, representation)
+
+	switch {
+	case segmentTemplate != nil:
+		if segmentTemplate.Media == "" {
+			return nil, fmt.Errorf("%w: SegmentTemplate without media", ErrSeekSegment)
+		}
+
+		seeker.segmentTemplate = segmentTemplate
+		seeker.base, seeker.count = &segmentTemplate.MultipleSegmentBase, -1
+	case segmentList == nil && index != nil:
+		segmentList = index.SegmentList(segmentBase)
+
+		fallthrough
+	case segmentList != nil:
+		seeker.segmentList = segmentList
+		seeker.base, seeker.count = &segmentList.MultipleSegmentBase, len(segmentList.SegmentURL)
+	default:
+		if segmentBase == nil {
+			segmentBase = &SegmentBase{}
+		}
+
+		seeker.base, seeker.count = &MultipleSegmentBase{SegmentBase: *segmentBase}, 1
+	}
+
+	if seeker.base.SegmentTimeline != nil {
+		firstNumber, _ := segmentNumbers(seeker.base, seeker.count)
+		if seeker.runs, err = seeker.window.timelineRuns(seeker.base, firstNumber); err != nil {
+			return nil, err
+		}
+	}
+
+	return seeker, nil
+}
+
+// SegmentAt returns the segment that contains the presentation time at, relative to the start of the media
+// presentation.
+func (s *SegmentSeeker) SegmentAt(at time.Duration) (*Segment, error) {
+	if at < s.window.start || s.window.end != 0 && at >= s.window.end {
+		return nil, fmt.Errorf("%w: %s outside of the Period", ErrSeekSegment, at)
+	}
+
+	segment, err := s.window.segmentAt(s.base, s.count, s.runs, at)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case s.segmentTemplate != nil:
+		segment.URL = resolveURL(s.baseURL, expandTemplate(s.segmentTemplate.Media, s.representation, segment.Number, segment.Time))
+	case s.segmentList != nil:
+		firstNumber, _ := segmentNumbers(s.base, s.count)
+		segmentURL := &s.segmentList.SegmentURL[segment.Number-firstNumber]
+		segment.URL = resolveURL(s.baseURL, segmentURL.Media)
+		segment.Range = segmentURL.MediaRange
+	default:
+		segment.URL = s.baseURL
+	}
+
+	return segment, nil
+}
+
+// timelineRun is a run of segments of equal duration in a SegmentTimeline, starting at media time t.
+type timelineRun struct {
+	t, d, number, count uint64
+}
+
+// segmentAt returns the segment of b that contains the presentation time at, limited to count segments unless count
+// is -1. runs are the timelineRuns of the SegmentTimeline of b, if it has one.
+func (w *segmentWindow) segmentAt(b *MultipleSegmentBase, count int, runs []timelineRun, at time.Duration) (*Segment, error) {
+	timescale := segmentTimescale(b)
+	firstNumber, lastNumber := segmentNumbers(b, count)
+	mediaTime := b.PresentationTimeOffset + durationTicks(at-w.periodStart, timescale)
+
+	var number, t, d uint64
+
+	switch {
+	case b.SegmentTimeline != nil:
+		i := sort.Search(len(runs), func(i int) bool { return runs[i].t > mediaTime }) - 1
+		if i < 0 {
+			return nil, fmt.Errorf("%w: no segment at %s", ErrSeekSegment, at)
+		}
+
+		run := runs[i]
+
+		k := (mediaTime - run.t) / run.d
+		if k >= run.count {
+			return nil, fmt.Errorf("%w: no segment at %s", ErrSeekSegment, at)
+		}
+
+		number, t, d = run.number+k, run.t+k*run.d, run.d
+	case b.Duration > 0:
+		d = uint64(b.Duration)
+		k := (mediaTime - b.PresentationTimeOffset) / d
+		number, t = firstNumber+k, b.PresentationTimeOffset+k*d
+	default:
+		if lastNumber > firstNumber || w.periodEnd == 0 {
+			return nil, fmt.Errorf("%w: no SegmentTimeline or duration", ErrSeekSegment)
+		}
+
+		number, t, d = firstNumber, b.PresentationTimeOffset, durationTicks(w.periodEnd-w.periodStart, timescale)
+	}
+
+	if count == 0 || lastNumber > 0 && number > lastNumber {
+		return nil, fmt.Errorf("%w: no segment at %s", ErrSeekSegment, at)
+	}
+
+	segment := w.segment(b, number, t, d)
+
+	return &segment, nil
+}
+
+// timelineRuns returns the runs of the SegmentTimeline of b. An open-ended S element that is neither followed by S@t
+// nor ended by the Period runs until the end of the media timeline, so no later S elements are returned.
+func (w *segmentWindow) timelineRuns(b *MultipleSegmentBase, number uint64) ([]timelineRun, error) {
+	timeline := b.SegmentTimeline.S
+	runs := make([]timelineRun, 0, len(timeline))
+	t := uint64(0)
+
+	for i, s := range timeline {
+		if s.T != nil {
+			t = *s.T
+		}
+
+		if s.D == 0 {
+			return nil, fmt.Errorf("%w: S element without duration", ErrSeekSegment)
+		}
+
+		run := timelineRun{t: t, d: s.D, number: number, count: uint64(s.R) + 1}
+
+		if s.R < 0 {
+			end := uint64(math.MaxUint64)
+
+			switch {
+			case i+1 < len(timeline) && timeline[i+1].T != nil:
+				end = *timeline[i+1].T
+			case w.periodEnd != 0:
+				end = b.PresentationTimeOffset + durationTicks(w.periodEnd-w.periodStart, segmentTimescale(b))
+			}
+
+			if end == math.MaxUint64 {
+				run.count = minUint64((math.MaxUint64-t)/s.D, math.MaxUint64-number)
+
+				return append(runs, run), nil
+			}
+
+			run.count = 0
+			if end > t {
+				run.count = (end - t + s.D - 1) / s.D
+			}
+		}
+
+		runs = append(runs, run)
+		t += run.count * s.D
+		number += run.count
+	}
+
+	return runs, nil
+}
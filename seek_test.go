@@ -0,0 +1,235 @@
+package mpd_test
+
+import (
+	"bytes"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"go.eigsys.de/go-mpd"
+	"math"
+	"testing"
+	"time"
+)
+
+// checkSegmentAt compares SegmentAt at the start and the end of every segment with the segments of Segments. The
+// Period starts at zero.
+func checkSegmentAt(t *testing.T, m *mpd.MPD, representation *mpd.Representation, index *mpd.SegmentIndex, segments []mpd.Segment) {
+	t.Helper()
+
+	period := &m.Period[0]
+	adaptationSet := &period.AdaptationSet[0]
+
+	if len(segments) == 0 {
+		t.Fatal("no segments")
+	}
+
+	periodDuration, err := m.PeriodDuration(0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i := range segments {
+		end := segments[i].PresentationTime + segments[i].PresentationDuration
+		if end > periodDuration {
+			end = periodDuration
+		}
+
+		for _, at := range []time.Duration{segments[i].PresentationTime, end - 1} {
+			segment, err := m.SegmentAt(period, adaptationSet, representation, at, index)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(segment, &segments[i]); diff != "" {
+				t.Errorf("%s: wrong segment: %s", at, diff)
+			}
+		}
+	}
+}
+
+func TestMPD_SegmentAt(t *testing.T) {
+	testMPD := newContentMPD()
+	testMPD.BaseURL = []mpd.BaseURL{{Value: "https://cdn.example.com/"}}
+
+	for _, mutate := range []func(*mpd.AdaptationSet){
+		func(*mpd.AdaptationSet) {},
+		func(adaptationSet *mpd.AdaptationSet) {
+			adaptationSet.SegmentTemplate.SegmentTimeline.S = []mpd.S{{T: uint64Ptr(1000), D: 3003, R: 2}, {T: uint64Ptr(11000), D: 2000, R: -1}}
+		},
+		func(adaptationSet *mpd.AdaptationSet) {
+			adaptationSet.SegmentTemplate.SegmentTimeline = nil
+			adaptationSet.SegmentTemplate.Duration = 3003
+			adaptationSet.SegmentTemplate.PresentationTimeOffset = 1000
+			adaptationSet.SegmentTemplate.StartNumber = 5
+		},
+		func(adaptationSet *mpd.AdaptationSet) {
+			adaptationSet.SegmentTemplate = nil
+			adaptationSet.SegmentList = &mpd.SegmentList{
+				MultipleSegmentBase: mpd.MultipleSegmentBase{SegmentBase: mpd.SegmentBase{Timescale: 1000}, Duration: 10000},
+				SegmentURL: []mpd.SegmentURL{
+					{Media: "video.mp4", MediaRange: mpd.NewSingleRFC7233Range(0, 999)},
+					{Media: "video.mp4", MediaRange: mpd.NewSingleRFC7233Range(1000, 1999)},
+				},
+			}
+		},
+	} {
+		mutate(&testMPD.Period[0].AdaptationSet[0])
+
+		segments := allSegments(t, testMPD)
+		checkSegmentAt(t, testMPD, &testMPD.Period[0].AdaptationSet[0].Representation[0], nil, segments[0])
+	}
+
+	sidx := makeSidx(0, 500, 0,
+		testReference{size: 1000, duration: 2000},
+		testReference{size: 1500, duration: 2000},
+		testReference{size: 800, duration: 1000},
+	)
+	file, indexRange := makeIndexedFile(sidx, 3300)
+
+	index, err := mpd.ReadSegmentIndex(bytes.NewReader(file), indexRange)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	newIndexedMPD := func() *mpd.MPD {
+		testMPD := newContentMPD()
+		testMPD.BaseURL = []mpd.BaseURL{{Value: "https://cdn.example.com/"}}
+		testMPD.Period[0].Duration = "PT5S"
+		testMPD.Period[0].AdaptationSet[0].SegmentTemplate = nil
+		testMPD.Period[0].AdaptationSet[0].Representation[0].BaseURL = []mpd.BaseURL{{Value: "video.mp4"}}
+		testMPD.Period[0].AdaptationSet[0].Representation[0].SegmentBase = &mpd.SegmentBase{
+			Timescale: 1000, PresentationTimeOffset: 500, IndexRange: indexRange,
+		}
+
+		return testMPD
+	}
+
+	convertedMPD := newIndexedMPD()
+	convertedMPD.Period[0].AdaptationSet[0].Representation[0].ConvertSegmentBaseToSegmentList(index)
+
+	segments := allSegments(t, convertedMPD)
+	if len(segments[0]) != 3 || segments[0][1].Range != mpd.NewSingleRFC7233Range(1168, 2667) {
+		t.Errorf("wrong segments: %+v", segments[0])
+	}
+
+	testMPD = newIndexedMPD()
+	adaptationSet := &testMPD.Period[0].AdaptationSet[0]
+
+	checkSegmentAt(t, testMPD, &adaptationSet.Representation[0], index, segments[0])
+
+	// The PresentationTimeOffset of SegmentBase is in its own timescale, not in the one of the sidx.
+	adaptationSet.Representation[0].SegmentBase.Timescale = 90000
+	adaptationSet.Representation[0].SegmentBase.PresentationTimeOffset = 45000
+
+	checkSegmentAt(t, testMPD, &adaptationSet.Representation[0], index, segments[0])
+
+	adaptationSet.Representation[0].SegmentBase = &mpd.SegmentBase{Timescale: 1000, PresentationTimeOffset: 500, IndexRange: indexRange}
+	segments = allSegments(t, testMPD)
+	checkSegmentAt(t, testMPD, &adaptationSet.Representation[0], nil, segments[0])
+}
+
+func TestMPD_SegmentAt_OpenEnded(t *testing.T) {
+	testMPD := newContentMPD()
+	testMPD.Type = mpd.DynamicPresentationType
+	testMPD.MediaPresentationDuration = ""
+	testMPD.Period[0].Duration = ""
+	testMPD.Period[0].AdaptationSet[0].SegmentTemplate.SegmentTimeline.S = []mpd.S{{T: uint64Ptr(0), D: 4000, R: -1}}
+
+	period := &testMPD.Period[0]
+
+	segment, err := testMPD.SegmentAt(period, &period.AdaptationSet[0], &period.AdaptationSet[0].Representation[0], 1000*time.Hour+time.Second, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantSegment := &mpd.Segment{
+		URL: "video/900001.m4s", Number: 900001, Time: 3600000000, Duration: 4000, Timescale: 1000,
+		PresentationTime: 1000 * time.Hour, PresentationDuration: 4 * time.Second,
+	}
+	if diff := cmp.Diff(segment, wantSegment); diff != "" {
+		t.Errorf("wrong segment: %s", diff)
+	}
+
+	// The open-ended S element takes up the rest of the media timeline, even if it is not the last one.
+	period.AdaptationSet[0].SegmentTemplate.SegmentTimeline.S = []mpd.S{{T: uint64Ptr(10000), D: 4000, R: -1}, {D: 4000, R: 2}}
+
+	seeker, err := testMPD.SegmentSeeker(period, &period.AdaptationSet[0], &period.AdaptationSet[0].Representation[0], nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, at := range []time.Duration{1000*time.Hour + time.Second, 1000*time.Hour + 1999*time.Millisecond} {
+		if segment, err := seeker.SegmentAt(at); err != nil || segment.Number != 899998 || segment.Time != 3599998000 {
+			t.Errorf("%s: unexpected result: %+v %v", at, segment, err)
+		}
+	}
+
+	if segment, err := seeker.SegmentAt(time.Duration(math.MaxInt64)); err != nil || segment.Duration != 4000 {
+		t.Errorf("unexpected result: %+v %v", segment, err)
+	}
+
+	period.AdaptationSet[0].SegmentTemplate.SegmentTimeline = nil
+	period.AdaptationSet[0].SegmentTemplate.Duration = 4000
+
+	if segment, err := testMPD.SegmentAt(period, &period.AdaptationSet[0], &period.AdaptationSet[0].Representation[0], 1000*time.Hour+time.Second, nil); err != nil || cmp.Diff(segment, wantSegment) != "" {
+		t.Errorf("unexpected result: %+v %v", segment, err)
+	}
+}
+
+func TestMPD_SegmentAt_Errors(t *testing.T) {
+	testCases := map[string]struct {
+		mutate func(*mpd.MPD)
+		at     time.Duration
+	}{
+		"before Period":    {mutate: func(m *mpd.MPD) { m.Period[0].Start = "PT10S" }, at: 5 * time.Second},
+		"after Period":     {mutate: func(*mpd.MPD) {}, at: 30 * time.Second},
+		"invalid start":    {mutate: func(m *mpd.MPD) { m.Period[0].Start = "invalid" }},
+		"invalid duration": {mutate: func(m *mpd.MPD) { m.Period[0].Duration = "invalid" }},
+		"no media":         {mutate: func(m *mpd.MPD) { m.Period[0].AdaptationSet[0].SegmentTemplate.Media = "" }},
+		"no duration": {mutate: func(m *mpd.MPD) {
+			m.Period[0].AdaptationSet[0].SegmentTemplate.SegmentTimeline.S[0].D = 0
+		}},
+		"gap": {mutate: func(m *mpd.MPD) {
+			m.Period[0].AdaptationSet[0].SegmentTemplate.SegmentTimeline.S = []mpd.S{{T: uint64Ptr(0), D: 4000}, {T: uint64Ptr(8000), D: 4000}}
+		}, at: 5 * time.Second},
+		"before first segment": {mutate: func(m *mpd.MPD) {
+			m.Period[0].AdaptationSet[0].SegmentTemplate.SegmentTimeline.S[0].T = uint64Ptr(1000)
+		}},
+		"after last segment": {mutate: func(m *mpd.MPD) {
+			m.Period[0].AdaptationSet[0].SegmentTemplate.SegmentTimeline.S = []mpd.S{{T: uint64Ptr(0), D: 4000, R: -1}, {T: uint64Ptr(0), D: 4000}}
+		}, at: 5 * time.Second},
+		"after EndNumber": {mutate: func(m *mpd.MPD) {
+			m.Period[0].AdaptationSet[0].SegmentTemplate.SegmentTimeline = nil
+			m.Period[0].AdaptationSet[0].SegmentTemplate.Duration = 4000
+			m.Period[0].AdaptationSet[0].SegmentTemplate.EndNumber = 2
+		}, at: 8 * time.Second},
+		"after SegmentList": {mutate: func(m *mpd.MPD) {
+			m.Period[0].AdaptationSet[0].SegmentTemplate = nil
+			m.Period[0].AdaptationSet[0].SegmentList = &mpd.SegmentList{
+				MultipleSegmentBase: mpd.MultipleSegmentBase{SegmentBase: mpd.SegmentBase{Timescale: 1000}, Duration: 4000},
+				SegmentURL:          []mpd.SegmentURL{{Media: "1.mp4"}},
+			}
+		}, at: 4 * time.Second},
+		"no segment information": {mutate: func(m *mpd.MPD) {
+			m.Period[0].AdaptationSet[0].SegmentTemplate = nil
+			m.Period[0].Duration = ""
+			m.MediaPresentationDuration = ""
+		}},
+	}
+
+	for name, testCase := range testCases {
+		testMPD := newContentMPD()
+		testCase.mutate(testMPD)
+
+		period := &testMPD.Period[0]
+		if _, err := testMPD.SegmentAt(period, &period.AdaptationSet[0], &period.AdaptationSet[0].Representation[0], testCase.at, nil); !errors.Is(err, mpd.ErrSeekSegment) {
+			t.Errorf("%s: wrong error: %v", name, err)
+		}
+	}
+
+	testMPD := newContentMPD()
+	period := testMPD.Period[0]
+
+	if _, err := testMPD.SegmentAt(&period, &period.AdaptationSet[0], &period.AdaptationSet[0].Representation[0], 0, nil); !errors.Is(err, mpd.ErrSeekSegment) {
+		t.Errorf("wrong error: %v", err)
+	}
+}
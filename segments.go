@@ -0,0 +1,431 @@
+package mpd
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrResolveSegments = errors.New("cannot resolve segments")
+
+// Segment is a media or initialization segment of a Representation with its URL and timing resolved.
+type Segment struct {
+	// URL is resolved against the BaseURLs of the MPD, Period, AdaptationSet and Representation.
+	// It stays relative to the MPD URL if none of them is absolute.
+	URL string
+
+	// Range is empty if the segment is the whole resource.
+	Range SingleRFC7233Range
+
+	Number uint64
+
+	// Time and Duration are on the media timeline in Timescale units.
+	Time      uint64
+	Duration  uint64
+	Timescale uint64
+
+	// PresentationTime is the start of the segment relative to the start of the media presentation.
+	PresentationTime     time.Duration
+	PresentationDuration time.Duration
+}
+
+// SegmentFilter selects segments. Empty fields match all segments.
+type SegmentFilter struct {
+	// Start and End select segments that overlap the time window, relative to the start of the media presentation.
+	// An End of 0 leaves the window open, which fails for Periods without a known end that use
+	// SegmentTemplate@duration or open-ended SegmentTimelines.
+	Start time.Duration
+	End   time.Duration
+}
+
+// Segments returns the media segments of the Representation that match the filter, in presentation order.
+// SegmentBase, SegmentList and SegmentTemplate are inherited from the Period and AdaptationSet.
+// With SegmentBase or only a BaseURL, the whole resource is returned as a single segment; use
+// Representation.ConvertSegmentBaseToSegmentList to address its subsegments. A StartNumber of 0 cannot be told
+// apart from an absent one, so numbering starts at 1. A nil filter matches all segments.
+func (m *MPD) Segments(period *Period, adaptationSet *AdaptationSet, representation *Representation, filter *SegmentFilter) ([]Segment, error) {
+	index := m.periodIndex(period)
+	if index < 0 {
+		return nil, fmt.Errorf("%w: period not part of the MPD", ErrResolveSegments)
+	}
+
+	periodStart, err := m.PeriodStart(index)
+	if err != nil {
+		return nil, errors.Join(ErrResolveSegments, err)
+	}
+
+	periodDuration, err := m.PeriodDuration(index)
+	if err != nil {
+		return nil, errors.Join(ErrResolveSegments, err)
+	}
+
+	window := segmentWindow{periodStart: periodStart, start: periodStart}
+	if periodDuration > 0 {
+		window.periodEnd = periodStart + periodDuration
+		window.end = window.periodEnd
+	}
+
+	if filter != nil {
+		if filter.Start > window.start {
+			window.start = filter.Start
+		}
+
+		if filter.End > 0 && (window.end == 0 || filter.End < window.end) {
+			window.end = filter.End
+		}
+	}
+
+	if window.end != 0 && window.end <= window.start {
+		return nil, nil
+	}
+
+	baseURL := m.resolveBaseURL(period, adaptationSet, representation)
+	segmentBase, segmentList, segmentTemplate := segmentInformation(period, adaptationSet, representation)
+
+	switch {
+	case segmentTemplate != nil:
+		if segmentTemplate.Media == "" {
+			return nil, fmt.Errorf("%w: SegmentTemplate without media", ErrResolveSegments)
+		}
+
+		segments, err := window.segments(&segmentTemplate.MultipleSegmentBase, -1)
+		for i := range segments {
+			segments[i].URL = resolveURL(baseURL, expandTemplate(segmentTemplate.Media, representation, segments[i].Number, segments[i].Time))
+		}
+
+		return segments, err
+	case segmentList != nil:
+		startNumber := uint64(1)
+		if segmentList.StartNumber > 0 {
+			startNumber = uint64(segmentList.StartNumber)
+		}
+
+		segments, err := window.segments(&segmentList.MultipleSegmentBase, len(segmentList.SegmentURL))
+		for i := range segments {
+			segmentURL := &segmentList.SegmentURL[segments[i].Number-startNumber]
+			segments[i].URL = resolveURL(baseURL, segmentURL.Media)
+			segments[i].Range = segmentURL.MediaRange
+		}
+
+		return segments, err
+	default:
+		if segmentBase == nil {
+			segmentBase = &SegmentBase{}
+		}
+
+		segments, err := window.segments(&MultipleSegmentBase{SegmentBase: *segmentBase}, 1)
+		for i := range segments {
+			segments[i].URL = baseURL
+		}
+
+		return segments, err
+	}
+}
+
+// InitializationSegment returns the initialization segment of the Representation, or nil if it has none.
+// With SegmentBase@indexRange and without Initialization, the initialization segment is assumed
+// to precede the index in the same resource.
+func (m *MPD) InitializationSegment(period *Period, adaptationSet *AdaptationSet, representation *Representation) (*Segment, error) {
+	baseURL := m.resolveBaseURL(period, adaptationSet, representation)
+	segmentBase, segmentList, segmentTemplate := segmentInformation(period, adaptationSet, representation)
+
+	switch {
+	case segmentTemplate != nil && segmentTemplate.Initialization != "":
+		return &Segment{URL: resolveURL(baseURL, expandTemplate(segmentTemplate.Initialization, representation, 0, 0))}, nil
+	case segmentTemplate != nil:
+		segmentBase = &segmentTemplate.SegmentBase
+	case segmentList != nil:
+		segmentBase = &segmentList.SegmentBase
+	case segmentBase == nil:
+		return nil, nil
+	}
+
+	if segmentBase.Initialization != nil {
+		return &Segment{URL: resolveURL(baseURL, segmentBase.Initialization.SourceURL), Range: segmentBase.Initialization.Range}, nil
+	}
+
+	if segmentBase.IndexRange == "" {
+		return nil, nil
+	}
+
+	first, _, err := segmentBase.IndexRange.Bounds()
+	if err != nil {
+		return nil, errors.Join(ErrResolveSegments, err)
+	}
+
+	if first == 0 {
+		return nil, nil
+	}
+
+	return &Segment{URL: baseURL, Range: NewSingleRFC7233Range(0, first-1)}, nil
+}
+
+// segmentWindow is the time window to generate segments for, relative to the start of the media presentation.
+// An end of 0 is open.
+type segmentWindow struct {
+	periodStart time.Duration
+	periodEnd   time.Duration
+	start       time.Duration
+	end         time.Duration
+}
+
+// segments generates the segment timing of a SegmentTimeline, @duration, or a single segment spanning the Period.
+// A count of -1 is unlimited, otherwise the segments are limited to count numbers from the start number.
+func (w *segmentWindow) segments(b *MultipleSegmentBase, count int) ([]Segment, error) {
+	timescale := segmentTimescale(b)
+	firstNumber, lastNumber := segmentNumbers(b, count)
+
+	if count == 0 || lastNumber > 0 && lastNumber < firstNumber {
+		return nil, nil
+	}
+
+	presentationTime := func(t uint64) time.Duration {
+		return w.periodStart + scaledDuration(int64(t)-int64(b.PresentationTimeOffset), timescale)
+	}
+
+	var segments []Segment
+
+	add := func(number, t, d uint64) {
+		if segment := w.segment(b, number, t, d); segment.PresentationTime+segment.PresentationDuration > w.start {
+			segments = append(segments, segment)
+		}
+	}
+
+	switch {
+	case b.SegmentTimeline != nil:
+		number := firstNumber
+		t := uint64(0)
+
+		for i, s := range b.SegmentTimeline.S {
+			if s.T != nil {
+				t = *s.T
+			}
+
+			if s.D == 0 {
+				return segments, fmt.Errorf("%w: S element without duration", ErrResolveSegments)
+			}
+
+			for r := 0; s.R < 0 || r <= s.R; r++ {
+				if s.R < 0 {
+					if i+1 < len(b.SegmentTimeline.S) && b.SegmentTimeline.S[i+1].T != nil {
+						if t >= *b.SegmentTimeline.S[i+1].T {
+							break
+						}
+					} else if w.end == 0 {
+						return segments, fmt.Errorf("%w: open-ended SegmentTimeline in Period without end", ErrResolveSegments)
+					}
+				}
+
+				if (lastNumber > 0 && number > lastNumber) || (w.end != 0 && presentationTime(t) >= w.end) {
+					return segments, nil
+				}
+
+				add(number, t, s.D)
+
+				t += s.D
+				number++
+			}
+		}
+	case b.Duration > 0:
+		if w.end == 0 && lastNumber == 0 {
+			return nil, fmt.Errorf("%w: SegmentTemplate@duration in Period without end", ErrResolveSegments)
+		}
+
+		d := uint64(b.Duration)
+		first := durationTicks(w.start-w.periodStart, timescale) / d
+		last := uint64(0)
+
+		if w.end != 0 {
+			last = (durationTicks(w.end-w.periodStart, timescale) + d - 1) / d
+		}
+
+		if lastNumber > 0 && (last == 0 || lastNumber-firstNumber+1 < last) {
+			last = lastNumber - firstNumber + 1
+		}
+
+		for i := first; i < last; i++ {
+			add(firstNumber+i, b.PresentationTimeOffset+i*d, d)
+		}
+	default:
+		if lastNumber > firstNumber || w.periodEnd == 0 {
+			return nil, fmt.Errorf("%w: no SegmentTimeline or duration", ErrResolveSegments)
+		}
+
+		add(firstNumber, b.PresentationTimeOffset, durationTicks(w.periodEnd-w.periodStart, timescale))
+	}
+
+	return segments, nil
+}
+
+// segment returns the segment of b with the number, media time t and duration d.
+func (w *segmentWindow) segment(b *MultipleSegmentBase, number, t, d uint64) Segment {
+	timescale := segmentTimescale(b)
+	presentationTime := func(t uint64) time.Duration {
+		return w.periodStart + scaledDuration(int64(t)-int64(b.PresentationTimeOffset), timescale)
+	}
+
+	start := presentationTime(t)
+
+	return Segment{
+		Number: number, Time: t, Duration: d, Timescale: timescale,
+		PresentationTime: start, PresentationDuration: presentationTime(t+d) - start,
+	}
+}
+
+func segmentTimescale(b *MultipleSegmentBase) uint64 {
+	if b.Timescale == 0 {
+		return 1
+	}
+
+	return uint64(b.Timescale)
+}
+
+// segmentNumbers returns the numbers of the first and last segment of b, limited to count segments unless count is
+// -1. The last number is 0 if it is unlimited.
+func segmentNumbers(b *MultipleSegmentBase, count int) (uint64, uint64) {
+	firstNumber := uint64(1)
+	if b.StartNumber > 0 {
+		firstNumber = uint64(b.StartNumber)
+	}
+
+	lastNumber := uint64(b.EndNumber)
+	if count >= 0 && (lastNumber == 0 || lastNumber >= firstNumber+uint64(count)) {
+		lastNumber = firstNumber + uint64(count) - 1
+	}
+
+	return firstNumber, lastNumber
+}
+
+// segmentInformation resolves the inheritance of segment information from the Period and AdaptationSet.
+// The lowest level with segment information selects the addressing scheme; attributes and elements not present
+// on a level are inherited from the same element on the levels above.
+func segmentInformation(period *Period, adaptationSet *AdaptationSet, representation *Representation) (*SegmentBase, *SegmentList, *SegmentTemplate) {
+	segmentBases := []*SegmentBase{period.SegmentBase, adaptationSet.SegmentBase, representation.SegmentBase}
+	segmentLists := []*SegmentList{period.SegmentList, adaptationSet.SegmentList, representation.SegmentList}
+	segmentTemplates := []*SegmentTemplate{period.SegmentTemplate, adaptationSet.SegmentTemplate, representation.SegmentTemplate}
+
+	for i := len(segmentBases) - 1; i >= 0; i-- {
+		switch {
+		case segmentTemplates[i] != nil:
+			return nil, nil, inherit(segmentTemplates[:i+1])
+		case segmentLists[i] != nil:
+			return nil, inherit(segmentLists[:i+1]), nil
+		case segmentBases[i] != nil:
+			return inherit(segmentBases[:i+1]), nil, nil
+		}
+	}
+
+	return nil, nil, nil
+}
+
+// effectiveSegmentBase returns the SegmentBase of the resolved segment information of the Representation, or an
+// empty SegmentBase if it has none.
+func effectiveSegmentBase(period *Period, adaptationSet *AdaptationSet, representation *Representation) *SegmentBase {
+	segmentBase, segmentList, segmentTemplate := segmentInformation(period, adaptationSet, representation)
+
+	switch {
+	case segmentTemplate != nil:
+		return &segmentTemplate.SegmentBase
+	case segmentList != nil:
+		return &segmentList.SegmentBase
+	case segmentBase != nil:
+		return segmentBase
+	default:
+		return &SegmentBase{}
+	}
+}
+
+// inherit merges the levels into a new value, with non-zero fields of lower levels overriding higher levels.
+func inherit[T any](levels []*T) *T {
+	merged := new(T)
+
+	for _, level := range levels {
+		if level != nil {
+			overrideFields(reflect.ValueOf(merged).Elem(), reflect.ValueOf(level).Elem())
+		}
+	}
+
+	return merged
+}
+
+func overrideFields(dst, src reflect.Value) {
+	for i := 0; i < dst.NumField(); i++ {
+		field := dst.Type().Field(i)
+
+		switch {
+		case field.Anonymous && field.Type.Kind() == reflect.Struct:
+			overrideFields(dst.Field(i), src.Field(i))
+		case !src.Field(i).IsZero():
+			dst.Field(i).Set(src.Field(i))
+		}
+	}
+}
+
+// resolveBaseURL resolves the first BaseURL of every level against the level above.
+func (m *MPD) resolveBaseURL(period *Period, adaptationSet *AdaptationSet, representation *Representation) string {
+	var baseURL string
+
+	for _, baseURLs := range [][]BaseURL{m.BaseURL, period.BaseURL, adaptationSet.BaseURL, representation.BaseURL} {
+		if len(baseURLs) > 0 {
+			baseURL = resolveURL(baseURL, strings.TrimSpace(baseURLs[0].Value))
+		}
+	}
+
+	return baseURL
+}
+
+// resolveURL resolves reference against base as per RFC 3986, keeping the result relative if both are.
+func resolveURL(base, reference string) string {
+	if reference == "" {
+		return base
+	}
+
+	referenceURL, err := url.Parse(reference)
+	if err != nil || referenceURL.IsAbs() {
+		return reference
+	}
+
+	if baseURL, err := url.Parse(base); err == nil && baseURL.IsAbs() {
+		return baseURL.ResolveReference(referenceURL).String()
+	}
+
+	if strings.HasPrefix(reference, "/") {
+		return reference
+	}
+
+	return base[:strings.LastIndex(base, "/")+1] + reference
+}
+
+// expandTemplate substitutes the identifiers of a SegmentTemplate media or initialization template.
+func expandTemplate(template string, representation *Representation, number, time uint64) string {
+	return templateIdentifierPattern.ReplaceAllStringFunc(template, func(identifier string) string {
+		match := templateIdentifierPattern.FindStringSubmatch(identifier)
+
+		var value uint64
+
+		switch match[1] {
+		case "":
+			return "$"
+		case "RepresentationID":
+			return representation.ID
+		case "Number":
+			value = number
+		case "Time":
+			value = time
+		case "Bandwidth":
+			value = uint64(representation.Bandwidth)
+		default:
+			return identifier
+		}
+
+		if match[2] != "" {
+			return fmt.Sprintf(match[2], value)
+		}
+
+		return strconv.FormatUint(value, 10)
+	})
+}
@@ -0,0 +1,326 @@
+package mpd_test
+
+import (
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"go.eigsys.de/go-mpd"
+	"testing"
+	"time"
+)
+
+func TestMPD_Segments(t *testing.T) {
+	type TestCase struct {
+		fixture        string
+		period         int
+		adaptationSet  int
+		filter         *mpd.SegmentFilter
+		wantCount      int
+		wantFirst      mpd.Segment
+		wantInitialize mpd.Segment
+	}
+
+	testCases := map[string]TestCase{
+		"SegmentTimeline": {
+			fixture:   "zencoder/segment_timeline.mpd",
+			wantCount: 9,
+			wantFirst: mpd.Segment{
+				URL:                  "http://localhost:8002/public/audio/segment1.m4f",
+				Number:               1,
+				Duration:             231424,
+				Timescale:            48000,
+				PresentationDuration: 4821333333,
+			},
+			wantInitialize: mpd.Segment{URL: "http://localhost:8002/public/audio/init.m4f"},
+		},
+		"SegmentTimeline in second period": {
+			fixture:   "zencoder/segment_timeline_multi_period.mpd",
+			period:    1,
+			filter:    &mpd.SegmentFilter{Start: 35 * time.Second, End: 40 * time.Second},
+			wantCount: 4,
+			wantFirst: mpd.Segment{
+				URL:                  "audio/segment3.m4f",
+				Number:               3,
+				Time:                 190464,
+				Duration:             95232,
+				Timescale:            48000,
+				PresentationTime:     33968 * time.Millisecond,
+				PresentationDuration: 1984 * time.Millisecond,
+			},
+			wantInitialize: mpd.Segment{URL: "audio/init.m4f"},
+		},
+		"SegmentList": {
+			fixture:       "zencoder/segment_list.mpd",
+			adaptationSet: 1,
+			wantCount:     4,
+			wantFirst: mpd.Segment{
+				URL:                  "http://localhost:8002/dash/b4324d65-ad06-4735-9535-5cd4af84ebb6/f2ad47b2-5362-46e6-ad1d-dff7b10f00b8/segment0.m4f",
+				Number:               1,
+				Duration:             225120,
+				Timescale:            30000,
+				PresentationDuration: 7504 * time.Millisecond,
+			},
+			wantInitialize: mpd.Segment{URL: "http://localhost:8002/dash/b4324d65-ad06-4735-9535-5cd4af84ebb6/f2ad47b2-5362-46e6-ad1d-dff7b10f00b8/init.m4f"},
+		},
+		"SegmentBase": {
+			fixture:   "zencoder/ondemand_profile.mpd",
+			wantCount: 1,
+			wantFirst: mpd.Segment{
+				URL:                  "800k/output-audio-und.mp4",
+				Number:               1,
+				Duration:             30,
+				Timescale:            1,
+				PresentationDuration: 30 * time.Second,
+			},
+			wantInitialize: mpd.Segment{URL: "800k/output-audio-und.mp4", Range: "0-628"},
+		},
+		"SegmentTemplate with duration": {
+			fixture:       "zencoder/live_profile_dynamic.mpd",
+			adaptationSet: 1,
+			filter:        &mpd.SegmentFilter{Start: 100 * time.Second, End: 110 * time.Second},
+			wantCount:     6,
+			wantFirst: mpd.Segment{
+				URL:                  "800/video/1/seg-51.m4f",
+				Number:               51,
+				Time:                 98400,
+				Duration:             1968,
+				Timescale:            1000,
+				PresentationTime:     98400 * time.Millisecond,
+				PresentationDuration: 1968 * time.Millisecond,
+			},
+			wantInitialize: mpd.Segment{URL: "800/video/1/init.mp4"},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			testMPD, err := mpd.Read(mustOpenFixture(testCase.fixture))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			period := &testMPD.Period[testCase.period]
+			adaptationSet := &period.AdaptationSet[testCase.adaptationSet]
+			representation := &adaptationSet.Representation[0]
+
+			segments, err := testMPD.Segments(period, adaptationSet, representation, testCase.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(segments) != testCase.wantCount {
+				t.Fatalf("wrong number of segments: %d", len(segments))
+			}
+
+			if diff := cmp.Diff(segments[0], testCase.wantFirst); diff != "" {
+				t.Errorf("wrong first segment: %s", diff)
+			}
+
+			for i := 1; i < len(segments); i++ {
+				if segments[i].Number != segments[i-1].Number+1 || segments[i].Time != segments[i-1].Time+segments[i-1].Duration {
+					t.Errorf("wrong segment %d: %+v", i, segments[i])
+				}
+			}
+
+			initialization, err := testMPD.InitializationSegment(period, adaptationSet, representation)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(*initialization, testCase.wantInitialize); diff != "" {
+				t.Errorf("wrong initialization segment: %s", diff)
+			}
+		})
+	}
+}
+
+func TestMPD_Segments_Inheritance(t *testing.T) {
+	start := uint64(10000)
+	testMPD := &mpd.MPD{
+		BaseURL:                   []mpd.BaseURL{{Value: "https://cdn.example.com/live/"}},
+		MediaPresentationDuration: "PT20S",
+		Period: []mpd.Period{{
+			BaseURL: []mpd.BaseURL{{Value: "p1/"}},
+			SegmentTemplate: &mpd.SegmentTemplate{
+				MultipleSegmentBase: mpd.MultipleSegmentBase{SegmentBase: mpd.SegmentBase{Timescale: 1000}, StartNumber: 5},
+				Media:               "$RepresentationID$/$Number%03d$-$Time$-$Bandwidth$$$.m4s",
+				Initialization:      "$RepresentationID$/init-$Bandwidth$.mp4",
+			},
+			AdaptationSet: []mpd.AdaptationSet{{
+				BaseURL: []mpd.BaseURL{{Value: "../video/"}},
+				SegmentTemplate: &mpd.SegmentTemplate{MultipleSegmentBase: mpd.MultipleSegmentBase{
+					SegmentBase:     mpd.SegmentBase{PresentationTimeOffset: 4000},
+					SegmentTimeline: &mpd.SegmentTimeline{S: []mpd.S{{T: &start, D: 4000, R: -1}}},
+				}},
+				Representation: []mpd.Representation{{ID: "hd", Bandwidth: 5000000}},
+			}},
+		}},
+	}
+
+	period := &testMPD.Period[0]
+	adaptationSet := &period.AdaptationSet[0]
+	representation := &adaptationSet.Representation[0]
+
+	segments, err := testMPD.Segments(period, adaptationSet, representation, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var urls []string
+	for _, segment := range segments {
+		urls = append(urls, segment.URL)
+	}
+
+	wantURLs := []string{
+		"https://cdn.example.com/live/video/hd/005-10000-5000000$.m4s",
+		"https://cdn.example.com/live/video/hd/006-14000-5000000$.m4s",
+		"https://cdn.example.com/live/video/hd/007-18000-5000000$.m4s",
+		"https://cdn.example.com/live/video/hd/008-22000-5000000$.m4s",
+	}
+	if diff := cmp.Diff(urls, wantURLs); diff != "" {
+		t.Errorf("wrong URLs: %s", diff)
+	}
+
+	if segments[0].PresentationTime != 6*time.Second {
+		t.Errorf("wrong presentation time: %s", segments[0].PresentationTime)
+	}
+
+	initialization, err := testMPD.InitializationSegment(period, adaptationSet, representation)
+	if err != nil || initialization.URL != "https://cdn.example.com/live/video/hd/init-5000000.mp4" {
+		t.Errorf("wrong initialization segment: %+v, %v", initialization, err)
+	}
+
+	representation.SegmentTemplate = &mpd.SegmentTemplate{MultipleSegmentBase: mpd.MultipleSegmentBase{EndNumber: 6}}
+	if segments, err := testMPD.Segments(period, adaptationSet, representation, nil); err != nil || len(segments) != 2 {
+		t.Errorf("wrong segments with end number: %+v, %v", segments, err)
+	}
+
+	adaptationSet.SegmentTemplate = nil
+	representation.SegmentTemplate.StartNumber = 7
+	if segments, err := testMPD.Segments(period, adaptationSet, representation, nil); err != nil || len(segments) != 0 {
+		t.Errorf("wrong segments with end number before start number: %+v, %v", segments, err)
+	}
+}
+
+func TestMPD_Segments_Timeline(t *testing.T) {
+	t5 := uint64(5)
+	t20 := uint64(20)
+	testMPD := &mpd.MPD{
+		Type: mpd.DynamicPresentationType,
+		Period: []mpd.Period{{
+			AdaptationSet: []mpd.AdaptationSet{{
+				SegmentTemplate: &mpd.SegmentTemplate{
+					Media: "$Time$.m4s",
+					MultipleSegmentBase: mpd.MultipleSegmentBase{
+						SegmentTimeline: &mpd.SegmentTimeline{S: []mpd.S{
+							{T: &t5, D: 4, R: -1},
+							{T: &t20, D: 2, R: -1},
+						}},
+					},
+				},
+				Representation: []mpd.Representation{{}},
+			}},
+		}},
+	}
+
+	period := &testMPD.Period[0]
+	adaptationSet := &period.AdaptationSet[0]
+	representation := &adaptationSet.Representation[0]
+
+	segments, err := testMPD.Segments(period, adaptationSet, representation, &mpd.SegmentFilter{Start: 10 * time.Second, End: 25 * time.Second})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var urls []string
+	for _, segment := range segments {
+		urls = append(urls, segment.URL)
+	}
+
+	if diff := cmp.Diff(urls, []string{"9.m4s", "13.m4s", "17.m4s", "20.m4s", "22.m4s", "24.m4s"}); diff != "" {
+		t.Errorf("wrong URLs: %s", diff)
+	}
+
+	if _, err := testMPD.Segments(period, adaptationSet, representation, nil); !errors.Is(err, mpd.ErrResolveSegments) {
+		t.Errorf("wrong error: %v", err)
+	}
+
+	if segments, err := testMPD.Segments(period, adaptationSet, representation, &mpd.SegmentFilter{Start: 10 * time.Second, End: 10 * time.Second}); err != nil || len(segments) != 0 {
+		t.Errorf("wrong segments in empty window: %+v, %v", segments, err)
+	}
+}
+
+func TestMPD_Segments_Errors(t *testing.T) {
+	testCases := map[string]mpd.Representation{
+		"no segment information":        {},
+		"SegmentTemplate without media": {SegmentTemplate: &mpd.SegmentTemplate{}},
+		"S element without duration": {SegmentTemplate: &mpd.SegmentTemplate{
+			Media:               "$Number$.m4s",
+			MultipleSegmentBase: mpd.MultipleSegmentBase{SegmentTimeline: &mpd.SegmentTimeline{S: []mpd.S{{}}}},
+		}},
+		"duration without period end": {SegmentTemplate: &mpd.SegmentTemplate{
+			Media:               "$Number$.m4s",
+			MultipleSegmentBase: mpd.MultipleSegmentBase{Duration: 2},
+		}},
+		"SegmentList without duration":   {SegmentList: &mpd.SegmentList{SegmentURL: []mpd.SegmentURL{{}, {}}}},
+		"SegmentBase without period end": {SegmentBase: &mpd.SegmentBase{}},
+		"BaseURL without period end":     {BaseURL: []mpd.BaseURL{{Value: "video.mp4"}}},
+	}
+
+	for name, representation := range testCases {
+		t.Run(name, func(t *testing.T) {
+			testMPD := &mpd.MPD{Period: []mpd.Period{{AdaptationSet: []mpd.AdaptationSet{{Representation: []mpd.Representation{representation}}}}}}
+			period := &testMPD.Period[0]
+			adaptationSet := &period.AdaptationSet[0]
+
+			if _, err := testMPD.Segments(period, adaptationSet, &adaptationSet.Representation[0], nil); !errors.Is(err, mpd.ErrResolveSegments) {
+				t.Errorf("wrong error: %v", err)
+			}
+		})
+	}
+
+	testMPD := &mpd.MPD{Period: []mpd.Period{{Start: "invalid"}, {Duration: "invalid"}}}
+	for i := range testMPD.Period {
+		if _, err := testMPD.Segments(&testMPD.Period[i], &mpd.AdaptationSet{}, &mpd.Representation{}, nil); !errors.Is(err, mpd.ErrResolveSegments) {
+			t.Errorf("wrong error: %v", err)
+		}
+	}
+
+	if _, err := testMPD.Segments(&mpd.Period{}, &mpd.AdaptationSet{}, &mpd.Representation{}, nil); !errors.Is(err, mpd.ErrResolveSegments) {
+		t.Errorf("wrong error: %v", err)
+	}
+}
+
+func TestMPD_InitializationSegment(t *testing.T) {
+	testMPD := &mpd.MPD{BaseURL: []mpd.BaseURL{{Value: "/media/"}}}
+
+	testCases := map[string]struct {
+		representation mpd.Representation
+		want           *mpd.Segment
+	}{
+		"none":             {representation: mpd.Representation{}},
+		"SegmentBase":      {representation: mpd.Representation{SegmentBase: &mpd.SegmentBase{}}},
+		"index at start":   {representation: mpd.Representation{SegmentBase: &mpd.SegmentBase{IndexRange: "0-99"}}},
+		"index after init": {representation: mpd.Representation{SegmentBase: &mpd.SegmentBase{IndexRange: "800-999"}}, want: &mpd.Segment{URL: "/media/", Range: "0-799"}},
+		"SegmentList":      {representation: mpd.Representation{SegmentList: &mpd.SegmentList{MultipleSegmentBase: mpd.MultipleSegmentBase{SegmentBase: mpd.SegmentBase{Initialization: &mpd.URL{SourceURL: "init.mp4"}}}}}, want: &mpd.Segment{URL: "/media/init.mp4"}},
+		"SegmentTemplate":  {representation: mpd.Representation{SegmentTemplate: &mpd.SegmentTemplate{MultipleSegmentBase: mpd.MultipleSegmentBase{SegmentBase: mpd.SegmentBase{Initialization: &mpd.URL{SourceURL: "https://example.com/init.mp4", Range: "0-99"}}}}}, want: &mpd.Segment{URL: "https://example.com/init.mp4", Range: "0-99"}},
+		"relative BaseURL": {representation: mpd.Representation{BaseURL: []mpd.BaseURL{{Value: "/other/file.mp4"}}, SegmentBase: &mpd.SegmentBase{Initialization: &mpd.URL{}}}, want: &mpd.Segment{URL: "/other/file.mp4"}},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			initialization, err := testMPD.InitializationSegment(&mpd.Period{}, &mpd.AdaptationSet{}, &testCase.representation)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(initialization, testCase.want); diff != "" {
+				t.Errorf("wrong initialization segment: %s", diff)
+			}
+		})
+	}
+
+	representation := &mpd.Representation{SegmentBase: &mpd.SegmentBase{IndexRange: "invalid"}}
+	if _, err := testMPD.InitializationSegment(&mpd.Period{}, &mpd.AdaptationSet{}, representation); !errors.Is(err, mpd.ErrResolveSegments) {
+		t.Errorf("wrong error: %v", err)
+	}
+}
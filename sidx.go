@@ -0,0 +1,257 @@
+package mpd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+var (
+	ErrReadSegmentIndex  = errors.New("cannot read segment index")
+	ErrParseSegmentIndex = errors.New("cannot parse segment index")
+)
+
+// SegmentIndex is a Segment Index box (sidx) as per ISO/IEC 14496-12.
+type SegmentIndex struct {
+	Version                  uint8
+	ReferenceID              uint32
+	Timescale                uint32
+	EarliestPresentationTime uint64
+	FirstOffset              uint64
+	Reference                []SegmentIndexReference
+
+	// Anchor is the file offset of the first byte following the box.
+	Anchor uint64
+}
+
+type SegmentIndexReference struct {
+	// ReferenceType is `true` if the reference points to another SegmentIndex instead of media.
+	ReferenceType bool
+
+	ReferencedSize     uint32
+	SubsegmentDuration uint32
+	StartsWithSAP      bool
+	SAPType            SAPType
+	SAPDeltaTime       uint32
+
+	// SegmentIndex is the referenced SegmentIndex, if ReferenceType is `true` and it has been resolved.
+	SegmentIndex *SegmentIndex
+}
+
+// Subsegment is a media subsegment referenced by a SegmentIndex.
+type Subsegment struct {
+	// Time is the earliest presentation time in SegmentIndex timescale units.
+	Time     uint64
+	Duration uint64
+
+	// FirstByte and LastByte are file offsets, both inclusive.
+	FirstByte uint64
+	LastByte  uint64
+
+	StartsWithSAP bool
+	SAPType       SAPType
+	SAPDeltaTime  uint32
+}
+
+// Range returns the byte range of the Subsegment.
+func (s Subsegment) Range() SingleRFC7233Range {
+	return NewSingleRFC7233Range(s.FirstByte, s.LastByte)
+}
+
+// ParseSegmentIndex parses the first sidx box found in data.
+// The offset is the file offset of the first byte of data, which is needed to compute the anchor.
+// References to other SegmentIndex boxes are not resolved.
+func ParseSegmentIndex(data []byte, offset uint64) (*SegmentIndex, error) {
+	for len(data) > 0 {
+		b, err := readBox(data)
+		if err != nil {
+			return nil, errors.Join(ErrParseSegmentIndex, err)
+		}
+
+		offset += uint64(b.Size)
+		data = data[b.Size:]
+
+		if b.Type == "sidx" {
+			return parseSegmentIndexPayload(b.Payload, offset)
+		}
+	}
+
+	return nil, ErrParseSegmentIndex
+}
+
+func parseSegmentIndexPayload(payload []byte, anchor uint64) (*SegmentIndex, error) {
+	r := &byteReader{data: payload}
+	version, _ := r.fullBoxHeader()
+	index := &SegmentIndex{
+		Version:     version,
+		ReferenceID: r.uint32(),
+		Timescale:   r.uint32(),
+		Anchor:      anchor,
+	}
+
+	if version == 0 {
+		index.EarliestPresentationTime = uint64(r.uint32())
+		index.FirstOffset = uint64(r.uint32())
+	} else {
+		index.EarliestPresentationTime = r.uint64()
+		index.FirstOffset = r.uint64()
+	}
+
+	_ = r.uint16()
+	referenceCount := r.uint16()
+
+	for i := uint16(0); i < referenceCount && r.err == nil; i++ {
+		typeAndSize := r.uint32()
+		duration := r.uint32()
+		sap := r.uint32()
+
+		if typeAndSize&0x7fffffff == 0 && r.err == nil {
+			return nil, fmt.Errorf("%w: reference %d without size", ErrParseSegmentIndex, i)
+		}
+
+		index.Reference = append(index.Reference, SegmentIndexReference{
+			ReferenceType:      typeAndSize>>31 == 1,
+			ReferencedSize:     typeAndSize & 0x7fffffff,
+			SubsegmentDuration: duration,
+			StartsWithSAP:      sap>>31 == 1,
+			SAPType:            SAPType(sap >> 28 & 0x7),
+			SAPDeltaTime:       sap & 0x0fffffff,
+		})
+	}
+
+	if r.err != nil {
+		return nil, errors.Join(ErrParseSegmentIndex, r.err)
+	}
+
+	return index, nil
+}
+
+// ReadSegmentIndex reads the SegmentIndex located at the given index range, e.g. SegmentBase.IndexRange.
+// References to other SegmentIndex boxes are resolved recursively.
+func ReadSegmentIndex(reader io.ReaderAt, indexRange SingleRFC7233Range) (*SegmentIndex, error) {
+	first, last, err := indexRange.Bounds()
+	if err != nil {
+		return nil, errors.Join(ErrReadSegmentIndex, err)
+	}
+
+	return readSegmentIndexAt(reader, first, last-first+1)
+}
+
+// ReadSegmentIndexFile works like ReadSegmentIndex, but reads from a local file.
+func ReadSegmentIndexFile(name string, indexRange SingleRFC7233Range) (*SegmentIndex, error) {
+	handle, err := os.Open(name)
+	if err != nil {
+		return nil, errors.Join(ErrReadSegmentIndex, err)
+	}
+
+	defer func() { _ = handle.Close() }()
+
+	return ReadSegmentIndex(handle, indexRange)
+}
+
+// readSegmentIndexAt reads size bytes at offset and parses the SegmentIndex in them. The buffer only grows with the
+// data actually read, so that an untrusted size cannot exhaust memory.
+func readSegmentIndexAt(reader io.ReaderAt, offset, size uint64) (*SegmentIndex, error) {
+	if offset > math.MaxInt64 || size > math.MaxInt64-offset {
+		return nil, fmt.Errorf("%w: range of %d bytes at %d out of bounds", ErrReadSegmentIndex, size, offset)
+	}
+
+	data, err := io.ReadAll(io.NewSectionReader(reader, int64(offset), int64(size)))
+	if err != nil {
+		return nil, errors.Join(ErrReadSegmentIndex, err)
+	}
+
+	if uint64(len(data)) < size {
+		return nil, errors.Join(ErrReadSegmentIndex, io.ErrUnexpectedEOF)
+	}
+
+	index, err := ParseSegmentIndex(data, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	position := index.Anchor + index.FirstOffset
+	for i := range index.Reference {
+		reference := &index.Reference[i]
+		if reference.ReferenceType {
+			if reference.SegmentIndex, err = readSegmentIndexAt(reader, position, uint64(reference.ReferencedSize)); err != nil {
+				return nil, err
+			}
+		}
+
+		position += uint64(reference.ReferencedSize)
+	}
+
+	return index, nil
+}
+
+// Subsegments returns the media subsegments in presentation order.
+// Unresolved references to other SegmentIndex boxes are skipped.
+func (s *SegmentIndex) Subsegments() []Subsegment {
+	var subsegments []Subsegment
+
+	position := s.Anchor + s.FirstOffset
+	presentationTime := s.EarliestPresentationTime
+
+	for _, reference := range s.Reference {
+		switch {
+		case reference.ReferenceType && reference.SegmentIndex != nil:
+			subsegments = append(subsegments, reference.SegmentIndex.Subsegments()...)
+		case !reference.ReferenceType:
+			subsegments = append(subsegments, Subsegment{
+				Time:          presentationTime,
+				Duration:      uint64(reference.SubsegmentDuration),
+				FirstByte:     position,
+				LastByte:      position + uint64(reference.ReferencedSize) - 1,
+				StartsWithSAP: reference.StartsWithSAP,
+				SAPType:       reference.SAPType,
+				SAPDeltaTime:  reference.SAPDeltaTime,
+			})
+		}
+
+		position += uint64(reference.ReferencedSize)
+		presentationTime += uint64(reference.SubsegmentDuration)
+	}
+
+	return subsegments
+}
+
+// SegmentTimeline returns a SegmentTimeline describing the subsegments.
+func (s *SegmentIndex) SegmentTimeline() *SegmentTimeline {
+	timeline := &SegmentTimeline{}
+	builder := newTimelineBuilder(timeline)
+
+	for _, subsegment := range s.Subsegments() {
+		builder.append(subsegment.Time, subsegment.Duration)
+	}
+
+	return timeline
+}
+
+// SegmentList returns a SegmentList addressing every subsegment by its byte range.
+// Initialization and PresentationTimeOffset are taken from segmentBase, which may be nil. The PresentationTimeOffset
+// is rescaled from the timescale of segmentBase to the timescale of the SegmentIndex.
+func (s *SegmentIndex) SegmentList(segmentBase *SegmentBase) *SegmentList {
+	segmentList := &SegmentList{}
+	segmentList.Timescale = uint(s.Timescale)
+	segmentList.SegmentTimeline = s.SegmentTimeline()
+
+	if segmentBase != nil {
+		segmentList.Initialization = segmentBase.Initialization
+		segmentList.PresentationTimeOffset = rescale(segmentBase.PresentationTimeOffset, uint64(maxUint(segmentBase.Timescale, 1)), uint64(s.Timescale))
+	}
+
+	for _, subsegment := range s.Subsegments() {
+		segmentList.SegmentURL = append(segmentList.SegmentURL, SegmentURL{MediaRange: subsegment.Range()})
+	}
+
+	return segmentList
+}
+
+// ConvertSegmentBaseToSegmentList replaces the SegmentBase of the Representation by an equivalent SegmentList.
+func (r *Representation) ConvertSegmentBaseToSegmentList(index *SegmentIndex) {
+	r.SegmentList = index.SegmentList(r.SegmentBase)
+	r.SegmentBase = nil
+}
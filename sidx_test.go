@@ -0,0 +1,316 @@
+package mpd_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"go.eigsys.de/go-mpd"
+	"os"
+	"path"
+	"testing"
+)
+
+func makeBox(boxType string, payload ...[]byte) []byte {
+	size := 8
+	for _, p := range payload {
+		size += len(p)
+	}
+
+	data := binary.BigEndian.AppendUint32(nil, uint32(size))
+	data = append(data, boxType...)
+
+	for _, p := range payload {
+		data = append(data, p...)
+	}
+
+	return data
+}
+
+type testReference struct {
+	referenceType bool
+	size          uint32
+	duration      uint32
+	sap           uint32
+}
+
+func makeSidx(version uint8, earliestPresentationTime, firstOffset uint64, references ...testReference) []byte {
+	payload := []byte{version, 0, 0, 0}
+	payload = binary.BigEndian.AppendUint32(payload, 1)
+	payload = binary.BigEndian.AppendUint32(payload, 1000)
+
+	if version == 0 {
+		payload = binary.BigEndian.AppendUint32(payload, uint32(earliestPresentationTime))
+		payload = binary.BigEndian.AppendUint32(payload, uint32(firstOffset))
+	} else {
+		payload = binary.BigEndian.AppendUint64(payload, earliestPresentationTime)
+		payload = binary.BigEndian.AppendUint64(payload, firstOffset)
+	}
+
+	payload = binary.BigEndian.AppendUint16(payload, 0)
+	payload = binary.BigEndian.AppendUint16(payload, uint16(len(references)))
+
+	for _, reference := range references {
+		typeAndSize := reference.size
+		if reference.referenceType {
+			typeAndSize |= 1 << 31
+		}
+
+		payload = binary.BigEndian.AppendUint32(payload, typeAndSize)
+		payload = binary.BigEndian.AppendUint32(payload, reference.duration)
+		payload = binary.BigEndian.AppendUint32(payload, reference.sap)
+	}
+
+	return makeBox("sidx", payload)
+}
+
+// makeIndexedFile returns a file consisting of a 100-byte init segment, a sidx box and the referenced media.
+func makeIndexedFile(sidx []byte, mediaSize int) ([]byte, mpd.SingleRFC7233Range) {
+	file := makeBox("moov", make([]byte, 92))
+	indexRange := mpd.NewSingleRFC7233Range(uint64(len(file)), uint64(len(file)+len(sidx)-1))
+	file = append(file, sidx...)
+	file = append(file, make([]byte, mediaSize)...)
+
+	return file, indexRange
+}
+
+func TestReadSegmentIndex(t *testing.T) {
+	sidx := makeSidx(0, 500, 0,
+		testReference{size: 1000, duration: 2000, sap: 1<<31 | 1<<28},
+		testReference{size: 1500, duration: 2000, sap: 1<<31 | 2<<28 | 10},
+		testReference{size: 800, duration: 1000},
+	)
+	file, indexRange := makeIndexedFile(sidx, 3300)
+
+	index, err := mpd.ReadSegmentIndex(bytes.NewReader(file), indexRange)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantSubsegments := []mpd.Subsegment{
+		{Time: 500, Duration: 2000, FirstByte: 168, LastByte: 1167, StartsWithSAP: true, SAPType: 1},
+		{Time: 2500, Duration: 2000, FirstByte: 1168, LastByte: 2667, StartsWithSAP: true, SAPType: 2, SAPDeltaTime: 10},
+		{Time: 4500, Duration: 1000, FirstByte: 2668, LastByte: 3467},
+	}
+
+	if diff := cmp.Diff(index.Subsegments(), wantSubsegments); diff != "" {
+		t.Errorf("wrong subsegments: %s", diff)
+	}
+
+	if index.Timescale != 1000 || index.ReferenceID != 1 {
+		t.Error("wrong header")
+	}
+}
+
+func TestReadSegmentIndex_Version1(t *testing.T) {
+	sidx := makeSidx(1, 1<<33, 24, testReference{size: 100, duration: 3000})
+	file, indexRange := makeIndexedFile(sidx, 124)
+
+	index, err := mpd.ReadSegmentIndex(bytes.NewReader(file), indexRange)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantSubsegments := []mpd.Subsegment{{Time: 1 << 33, Duration: 3000, FirstByte: 176, LastByte: 275}}
+	if diff := cmp.Diff(index.Subsegments(), wantSubsegments); diff != "" {
+		t.Errorf("wrong subsegments: %s", diff)
+	}
+}
+
+func TestReadSegmentIndex_Hierarchical(t *testing.T) {
+	childA := makeSidx(0, 0, 0, testReference{size: 10, duration: 100}, testReference{size: 20, duration: 100})
+	childB := makeSidx(0, 200, 0, testReference{size: 30, duration: 50})
+	root := makeSidx(0, 0, 0,
+		testReference{referenceType: true, size: uint32(len(childA) + 30), duration: 200},
+		testReference{referenceType: true, size: uint32(len(childB) + 30), duration: 50},
+	)
+
+	file, indexRange := makeIndexedFile(root, 0)
+	file = append(file, childA...)
+	file = append(file, make([]byte, 30)...)
+	file = append(file, childB...)
+	file = append(file, make([]byte, 30)...)
+
+	index, err := mpd.ReadSegmentIndex(bytes.NewReader(file), indexRange)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	childAEnd := uint64(100 + len(root) + len(childA))
+	childBEnd := childAEnd + 30 + uint64(len(childB))
+	wantSubsegments := []mpd.Subsegment{
+		{Time: 0, Duration: 100, FirstByte: childAEnd, LastByte: childAEnd + 9},
+		{Time: 100, Duration: 100, FirstByte: childAEnd + 10, LastByte: childAEnd + 29},
+		{Time: 200, Duration: 50, FirstByte: childBEnd, LastByte: childBEnd + 29},
+	}
+
+	if diff := cmp.Diff(index.Subsegments(), wantSubsegments); diff != "" {
+		t.Errorf("wrong subsegments: %s", diff)
+	}
+
+	unresolved, err := mpd.ParseSegmentIndex(root, 100)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(unresolved.Subsegments()) != 0 {
+		t.Error("unresolved references must be skipped")
+	}
+}
+
+func TestParseSegmentIndex_BoxSizes(t *testing.T) {
+	sidx := makeSidx(0, 0, 0, testReference{size: 10, duration: 100})
+	payload := sidx[8:]
+
+	largeSize := binary.BigEndian.AppendUint32(nil, 1)
+	largeSize = append(largeSize, "sidx"...)
+	largeSize = binary.BigEndian.AppendUint64(largeSize, uint64(16+len(payload)))
+	largeSize = append(largeSize, payload...)
+
+	untilEOF := append([]byte{0, 0, 0, 0}, sidx[4:]...)
+
+	uuid := makeBox("uuid", make([]byte, 16))
+
+	type TestCase struct {
+		name       string
+		data       []byte
+		wantAnchor uint64
+	}
+
+	testCases := []TestCase{
+		{name: "large size", data: largeSize, wantAnchor: uint64(len(largeSize))},
+		{name: "size until EOF", data: untilEOF, wantAnchor: uint64(len(untilEOF))},
+		{name: "preceding uuid box", data: append(uuid, sidx...), wantAnchor: uint64(len(uuid) + len(sidx))},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			index, err := mpd.ParseSegmentIndex(testCase.data, 0)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if index.Anchor != testCase.wantAnchor || len(index.Reference) != 1 {
+				t.Errorf("wrong SegmentIndex: %+v", index)
+			}
+		})
+	}
+
+	if _, err := mpd.ParseSegmentIndex(largeSize[:12], 0); !errors.Is(err, mpd.ErrParseBox) {
+		t.Errorf("wrong error: %v", err)
+	}
+}
+
+func TestReadSegmentIndex_Errors(t *testing.T) {
+	sidx := makeSidx(0, 0, 0, testReference{size: 10, duration: 100})
+	file, _ := makeIndexedFile(sidx, 10)
+	emptyReferenceFile, emptyReferenceRange := makeIndexedFile(makeSidx(0, 0, 0, testReference{duration: 100}), 10)
+
+	type TestCase struct {
+		name       string
+		file       []byte
+		indexRange mpd.SingleRFC7233Range
+		wantErr    error
+	}
+
+	testCases := []TestCase{
+		{name: "invalid range", file: file, indexRange: "abc", wantErr: mpd.ErrReadSegmentIndex},
+		{name: "range beyond EOF", file: file, indexRange: "100-10000", wantErr: mpd.ErrReadSegmentIndex},
+		{name: "huge range", file: file, indexRange: "0-9223372036854775806", wantErr: mpd.ErrReadSegmentIndex},
+		{name: "range beyond int64", file: file, indexRange: "9223372036854775807-9223372036854775808", wantErr: mpd.ErrReadSegmentIndex},
+		{name: "reference without size", file: emptyReferenceFile, indexRange: emptyReferenceRange, wantErr: mpd.ErrParseSegmentIndex},
+		{name: "no sidx box", file: file, indexRange: "0-99", wantErr: mpd.ErrParseSegmentIndex},
+		{name: "truncated box", file: file, indexRange: "100-120", wantErr: mpd.ErrParseSegmentIndex},
+		{
+			name:       "truncated payload",
+			file:       append(makeBox("moov", make([]byte, 92)), makeBox("sidx", make([]byte, 10))...),
+			indexRange: "100-117",
+			wantErr:    mpd.ErrParseSegmentIndex,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if _, err := mpd.ReadSegmentIndex(bytes.NewReader(testCase.file), testCase.indexRange); !errors.Is(err, testCase.wantErr) {
+				t.Errorf("wrong error: %v", err)
+			}
+		})
+	}
+}
+
+func TestReadSegmentIndexFile(t *testing.T) {
+	sidx := makeSidx(0, 0, 0, testReference{size: 10, duration: 100})
+	file, indexRange := makeIndexedFile(sidx, 10)
+	name := path.Join(t.TempDir(), "media.mp4")
+
+	if err := os.WriteFile(name, file, 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := mpd.ReadSegmentIndexFile(name, indexRange); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if _, err := mpd.ReadSegmentIndexFile(path.Join(t.TempDir(), "missing.mp4"), indexRange); !errors.Is(err, mpd.ErrReadSegmentIndex) {
+		t.Errorf("wrong error: %v", err)
+	}
+}
+
+func TestRepresentation_ConvertSegmentBaseToSegmentList(t *testing.T) {
+	sidx := makeSidx(0, 0, 0,
+		testReference{size: 1000, duration: 2000},
+		testReference{size: 1000, duration: 2000},
+		testReference{size: 500, duration: 1000},
+	)
+	file, indexRange := makeIndexedFile(sidx, 2500)
+
+	index, err := mpd.ReadSegmentIndex(bytes.NewReader(file), indexRange)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	representation := mpd.Representation{
+		BaseURL: []mpd.BaseURL{{Value: "media.mp4"}},
+		SegmentBase: &mpd.SegmentBase{
+			Initialization:         &mpd.URL{Range: "0-99"},
+			Timescale:              1000,
+			IndexRange:             indexRange,
+			PresentationTimeOffset: 10,
+		},
+	}
+	representation.ConvertSegmentBaseToSegmentList(index)
+
+	wantSegmentList := &mpd.SegmentList{
+		MultipleSegmentBase: mpd.MultipleSegmentBase{
+			SegmentBase: mpd.SegmentBase{
+				Initialization:         &mpd.URL{Range: "0-99"},
+				Timescale:              1000,
+				PresentationTimeOffset: 10,
+			},
+			SegmentTimeline: &mpd.SegmentTimeline{S: []mpd.S{{T: uint64Ptr(0), D: 2000, R: 1}, {D: 1000}}},
+		},
+		SegmentURL: []mpd.SegmentURL{
+			{MediaRange: "168-1167"},
+			{MediaRange: "1168-2167"},
+			{MediaRange: "2168-2667"},
+		},
+	}
+
+	if representation.SegmentBase != nil {
+		t.Error("SegmentBase must be removed")
+	}
+
+	if diff := cmp.Diff(representation.SegmentList, wantSegmentList); diff != "" {
+		t.Errorf("wrong SegmentList: %s", diff)
+	}
+
+	// The PresentationTimeOffset of 0.5 s is rescaled to the timescale of the sidx.
+	segmentList := index.SegmentList(&mpd.SegmentBase{Timescale: 90000, PresentationTimeOffset: 45000})
+	if segmentList.Timescale != 1000 || segmentList.PresentationTimeOffset != 500 {
+		t.Errorf("wrong timing: %d %d", segmentList.Timescale, segmentList.PresentationTimeOffset)
+	}
+
+	if segmentList := index.SegmentList(&mpd.SegmentBase{PresentationTimeOffset: 2}); segmentList.PresentationTimeOffset != 2000 {
+		t.Errorf("wrong PresentationTimeOffset: %d", segmentList.PresentationTimeOffset)
+	}
+}
@@ -0,0 +1,429 @@
+package mpd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go.eigsys.de/go-mpd/third_party/encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+var (
+	ErrParseSmoothStreaming       = errors.New("cannot parse Smooth Streaming manifest")
+	ErrConvertFromSmoothStreaming = errors.New("cannot convert Smooth Streaming manifest to MPD")
+)
+
+// SmoothStreamingTimeScale is the default TimeScale of Smooth Streaming manifests, in 100 ns units.
+const SmoothStreamingTimeScale = 10000000
+
+// SmoothStreamingMedia is a Smooth Streaming client manifest as per MS-SSTR.
+// Elements and attributes other than the ones below are not preserved.
+type SmoothStreamingMedia struct {
+	XMLName      xml.Name `xml:"SmoothStreamingMedia"`
+	MajorVersion uint     `xml:"MajorVersion,attr"`
+	MinorVersion uint     `xml:"MinorVersion,attr"`
+
+	// TimeScale defaults to SmoothStreamingTimeScale.
+	TimeScale uint64 `xml:"TimeScale,attr,omitempty"`
+
+	Duration        uint64                     `xml:"Duration,attr"`
+	IsLive          bool                       `xml:"IsLive,attr,omitempty"`
+	DVRWindowLength uint64                     `xml:"DVRWindowLength,attr,omitempty"`
+	Protection      *SmoothStreamingProtection `xml:"Protection,omitempty"`
+	StreamIndex     []SmoothStreamingStreamIndex
+}
+
+type SmoothStreamingProtection struct {
+	ProtectionHeader []SmoothStreamingProtectionHeader
+}
+
+// SmoothStreamingProtectionHeader holds base64 encoded DRM system data, which is a PlayReady Object for PlayReady.
+type SmoothStreamingProtectionHeader struct {
+	SystemID string `xml:"SystemID,attr"`
+	Value    string `xml:",chardata"`
+}
+
+// SmoothStreamingStreamIndex is a track of a Smooth Streaming presentation. The URL is a template containing
+// the {bitrate} and {start time} placeholders.
+type SmoothStreamingStreamIndex struct {
+	Type    ContentType `xml:"Type,attr"`
+	Name    string      `xml:"Name,attr,omitempty"`
+	Subtype string      `xml:"Subtype,attr,omitempty"`
+	URL     string      `xml:"Url,attr"`
+
+	// TimeScale defaults to the TimeScale of the SmoothStreamingMedia.
+	TimeScale uint64 `xml:"TimeScale,attr,omitempty"`
+
+	Language     string                        `xml:"Language,attr,omitempty"`
+	MaxWidth     uint                          `xml:"MaxWidth,attr,omitempty"`
+	MaxHeight    uint                          `xml:"MaxHeight,attr,omitempty"`
+	QualityLevel []SmoothStreamingQualityLevel `xml:"QualityLevel"`
+	C            []SmoothStreamingChunk        `xml:"c"`
+}
+
+// SmoothStreamingQualityLevel is a bitrate of a track. CodecPrivateData is hex encoded, e.g. the Annex B
+// parameter sets of video or the AudioSpecificConfig of AAC.
+type SmoothStreamingQualityLevel struct {
+	Index            uint   `xml:"Index,attr,omitempty"`
+	Bitrate          uint   `xml:"Bitrate,attr"`
+	FourCC           string `xml:"FourCC,attr,omitempty"`
+	MaxWidth         uint   `xml:"MaxWidth,attr,omitempty"`
+	MaxHeight        uint   `xml:"MaxHeight,attr,omitempty"`
+	CodecPrivateData string `xml:"CodecPrivateData,attr,omitempty"`
+	SamplingRate     uint   `xml:"SamplingRate,attr,omitempty"`
+	Channels         uint   `xml:"Channels,attr,omitempty"`
+}
+
+// SmoothStreamingChunk is a c element. T defaults to the end of the previous chunk and D to the start of the
+// next one. R is the total number of chunks with the same duration and defaults to 1.
+type SmoothStreamingChunk struct {
+	T *uint64 `xml:"t,attr,omitempty"`
+	D uint64  `xml:"d,attr,omitempty"`
+	R uint64  `xml:"r,attr,omitempty"`
+}
+
+// ParseSmoothStreamingMedia parses a client manifest encoded as UTF-8 or, with a byte order mark, as UTF-16.
+func ParseSmoothStreamingMedia(data []byte) (*SmoothStreamingMedia, error) {
+	if bytes.HasPrefix(data, []byte{0xff, 0xfe}) || bytes.HasPrefix(data, []byte{0xfe, 0xff}) {
+		units := make([]uint16, (len(data)-2)/2)
+		for i := range units {
+			if data[0] == 0xff {
+				units[i] = uint16(data[2+2*i]) | uint16(data[3+2*i])<<8
+			} else {
+				units[i] = uint16(data[2+2*i])<<8 | uint16(data[3+2*i])
+			}
+		}
+
+		data = []byte(string(utf16.Decode(units)))
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		if !strings.EqualFold(charset, "utf-16") {
+			return nil, fmt.Errorf("unsupported charset %q", charset)
+		}
+
+		return input, nil
+	}
+
+	manifest := &SmoothStreamingMedia{}
+	if err := decoder.Decode(manifest); err != nil {
+		return nil, errors.Join(ErrParseSmoothStreaming, err)
+	}
+
+	return manifest, nil
+}
+
+// ConvertFromSmoothStreaming converts a client manifest to an MPD with a single Period. Every StreamIndex becomes an
+// AdaptationSet with a SegmentTemplate whose SegmentTimeline is built from the c elements, and every QualityLevel a
+// Representation with the codecs derived from its FourCC and CodecPrivateData. A PlayReady ProtectionHeader becomes a
+// PlayReady ContentProtection of the audio and video AdaptationSets with mspr:pro and the key IDs of its header,
+// preceded by the mp4protection ContentProtection. Smooth Streaming has no initialization segments, so the
+// SegmentTemplates have no initialization attribute. Live manifests result in a dynamic MPD whose AvailabilityStartTime
+// is the Unix epoch, which requires chunk times to be wall-clock based.
+func ConvertFromSmoothStreaming(manifest *SmoothStreamingMedia) (*MPD, error) {
+	timeScale := manifest.TimeScale
+	if timeScale == 0 {
+		timeScale = SmoothStreamingTimeScale
+	}
+
+	contentProtection, err := manifest.contentProtection()
+	if err != nil {
+		return nil, errors.Join(ErrConvertFromSmoothStreaming, err)
+	}
+
+	period := Period{ID: "0", Start: FormatDuration(0)}
+
+	var maxSegmentDuration time.Duration
+
+	for i, streamIndex := range manifest.StreamIndex {
+		adaptationSet, segmentDuration, err := streamIndex.adaptationSet(timeScale)
+		if err != nil {
+			return nil, fmt.Errorf("%w: StreamIndex %d: %v", ErrConvertFromSmoothStreaming, i, err)
+		}
+
+		if segmentDuration > maxSegmentDuration {
+			maxSegmentDuration = segmentDuration
+		}
+
+		adaptationSet.ID = uint(i + 1)
+		if streamIndex.Type == VideoContentType || streamIndex.Type == AudioContentType {
+			adaptationSet.ContentProtection = contentProtection
+		}
+
+		period.AdaptationSet = append(period.AdaptationSet, *adaptationSet)
+	}
+
+	mpd := New()
+	mpd.Profiles = Live2011Profile
+	mpd.Type = StaticPresentationType
+	mpd.MinBufferTime = FormatDuration(maxSegmentDuration)
+	mpd.MaxSegmentDuration = FormatDuration(maxSegmentDuration)
+	mpd.Period = []Period{period}
+
+	if manifest.IsLive {
+		mpd.Type = DynamicPresentationType
+		mpd.AvailabilityStartTime = FormatDateTime(time.Unix(0, 0))
+		mpd.MinimumUpdatePeriod = FormatDuration(maxSegmentDuration)
+
+		if manifest.DVRWindowLength > 0 {
+			mpd.TimeShiftBufferDepth = FormatDuration(scaledDuration(int64(manifest.DVRWindowLength), timeScale))
+		}
+	} else {
+		mpd.MediaPresentationDuration = FormatDuration(scaledDuration(int64(manifest.Duration), timeScale))
+	}
+
+	return mpd, nil
+}
+
+// contentProtection converts the PlayReady ProtectionHeader. Other DRM systems are not converted
+// because their data is system specific.
+func (m *SmoothStreamingMedia) contentProtection() ([]ContentProtection, error) {
+	if m.Protection == nil {
+		return nil, nil
+	}
+
+	for _, protectionHeader := range m.Protection.ProtectionHeader {
+		systemID, err := ParseUUID(strings.Trim(protectionHeader.SystemID, "{}"))
+		if err != nil {
+			return nil, err
+		}
+
+		if systemID != PlayReadySystemID {
+			continue
+		}
+
+		data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(protectionHeader.Value))
+		if err != nil {
+			return nil, err
+		}
+
+		object, err := ParsePlayReadyObject(data)
+		if err != nil {
+			return nil, err
+		}
+
+		header, err := object.Header()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(header.KID) == 0 {
+			return nil, errors.New("PlayReady header without KID")
+		}
+
+		kids := make([]UUID, 0, len(header.KID))
+		for _, kid := range header.KID {
+			kids = append(kids, kid.ID)
+		}
+
+		scheme := "cenc"
+		if header.KID[0].AlgID == "AESCBC" {
+			scheme = "cbcs"
+		}
+
+		return []ContentProtection{NewMP4ProtectionContentProtection(scheme, kids[0]), NewPlayReadyContentProtection(data, kids...)}, nil
+	}
+
+	return nil, nil
+}
+
+// adaptationSet converts the StreamIndex and returns its longest chunk duration.
+func (s *SmoothStreamingStreamIndex) adaptationSet(timeScale uint64) (*AdaptationSet, time.Duration, error) {
+	if s.TimeScale != 0 {
+		timeScale = s.TimeScale
+	}
+
+	timeline, maxDuration, err := s.timeline()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	template := &SegmentTemplate{Media: smoothStreamingMediaTemplate(s.URL)}
+	template.Timescale = uint(timeScale)
+	template.SegmentTimeline = timeline
+
+	adaptationSet := &AdaptationSet{
+		ContentType:      s.Type,
+		Lang:             s.Language,
+		MaxWidth:         s.MaxWidth,
+		MaxHeight:        s.MaxHeight,
+		SegmentAlignment: true,
+		SegmentTemplate:  template,
+	}
+
+	adaptationSet.StartWithSAP = 1
+
+	switch s.Type {
+	case VideoContentType:
+		adaptationSet.MIMEType = VideoMP4MIMEType
+	case AudioContentType:
+		adaptationSet.MIMEType = AudioMP4MIMEType
+	default:
+		adaptationSet.MIMEType = ApplicationMP4MIMEType
+
+		switch s.Subtype {
+		case "CAPT":
+			adaptationSet.Role = []Descriptor{{SchemeIDURI: Role2011SchemeIDURI, Value: "caption"}}
+		case "SUBT":
+			adaptationSet.Role = []Descriptor{{SchemeIDURI: Role2011SchemeIDURI, Value: "subtitle"}}
+		}
+	}
+
+	name := s.Name
+	if name == "" {
+		name = string(s.Type)
+	}
+
+	for i := range s.QualityLevel {
+		level := &s.QualityLevel[i]
+
+		representation := Representation{ID: name + "_" + strconv.Itoa(i), Bandwidth: level.Bitrate}
+		representation.Codecs = level.codecs()
+		representation.Width, representation.Height = level.MaxWidth, level.MaxHeight
+
+		if level.SamplingRate > 0 {
+			representation.AudioSamplingRate = &AudioSamplingRate{level.SamplingRate}
+		}
+
+		if level.Channels > 0 {
+			representation.AudioChannelConfiguration = []*Descriptor{{
+				SchemeIDURI: AudioChannelConfiguration2011SchemeIDURI,
+				Value:       strconv.FormatUint(uint64(level.Channels), 10),
+			}}
+		}
+
+		adaptationSet.Representation = append(adaptationSet.Representation, representation)
+	}
+
+	return adaptationSet, scaledDuration(int64(maxDuration), timeScale), nil
+}
+
+// timeline converts the c elements and returns the longest duration.
+func (s *SmoothStreamingStreamIndex) timeline() (*SegmentTimeline, uint64, error) {
+	if len(s.C) == 0 {
+		return nil, 0, errors.New("no chunks")
+	}
+
+	timeline := &SegmentTimeline{}
+	builder := newTimelineBuilder(timeline)
+
+	var start, maxDuration uint64
+
+	for i, chunk := range s.C {
+		if chunk.T != nil {
+			start = *chunk.T
+		}
+
+		duration := chunk.D
+		if duration == 0 {
+			if i+1 == len(s.C) || s.C[i+1].T == nil || *s.C[i+1].T <= start {
+				return nil, 0, fmt.Errorf("chunk %d without duration", i)
+			}
+
+			duration = *s.C[i+1].T - start
+		}
+
+		if duration > maxDuration {
+			maxDuration = duration
+		}
+
+		for repeat := uint64(0); repeat < chunk.R || repeat == 0; repeat++ {
+			builder.append(start, duration)
+			start += duration
+		}
+	}
+
+	return timeline, maxDuration, nil
+}
+
+// smoothStreamingMediaTemplate converts the {bitrate} and {start time} placeholders of a StreamIndex URL.
+func smoothStreamingMediaTemplate(url string) string {
+	return strings.NewReplacer(
+		"$", "$$",
+		"{bitrate}", "$Bandwidth$",
+		"{Bitrate}", "$Bandwidth$",
+		"{start time}", "$Time$",
+		"{start_time}", "$Time$",
+	).Replace(url)
+}
+
+// codecs derives the codecs parameter from the FourCC and CodecPrivateData, falling back to the FourCC.
+func (l *SmoothStreamingQualityLevel) codecs() Codecs {
+	codecPrivateData, _ := hex.DecodeString(l.CodecPrivateData)
+
+	switch fourCC := strings.ToUpper(l.FourCC); fourCC {
+	case "H264", "AVC1", "DAVC":
+		for _, nalUnit := range annexBNALUnits(codecPrivateData) {
+			if len(nalUnit) >= 4 && nalUnit[0]&0x1f == 7 {
+				return codecsFromConfiguration("avc1", map[string][]byte{"avcC": {1, nalUnit[1], nalUnit[2], nalUnit[3]}})
+			}
+		}
+
+		return "avc1"
+	case "HVC1", "HEV1":
+		format := strings.ToLower(fourCC)
+
+		for _, nalUnit := range annexBNALUnits(codecPrivateData) {
+			// The general profile_tier_level of a sequence parameter set follows the two byte NAL unit header and
+			// one byte of sub-layer information, and has the layout of hvcC bytes 1 to 12.
+			if rbsp := removeEmulationPrevention(nalUnit); len(rbsp) >= 15 && rbsp[0]>>1&0x3f == 33 {
+				return codecsFromConfiguration(format, map[string][]byte{"hvcC": append([]byte{1}, rbsp[3:15]...)})
+			}
+		}
+
+		return Codecs(format)
+	case "AACL", "AACH":
+		if len(codecPrivateData) > 0 {
+			return Codecs(audioSpecificConfigCodecs(codecPrivateData))
+		}
+
+		if fourCC == "AACH" {
+			return "mp4a.40.5"
+		}
+
+		return "mp4a.40.2"
+	case "EC-3", "DDPL":
+		return "ec-3"
+	case "AC-3":
+		return "ac-3"
+	case "TTML":
+		return "stpp"
+	}
+
+	return Codecs(strings.ToLower(l.FourCC))
+}
+
+// annexBNALUnits splits a byte stream at its start codes.
+func annexBNALUnits(data []byte) [][]byte {
+	var nalUnits [][]byte
+
+	for _, nalUnit := range bytes.Split(data, []byte{0, 0, 1}) {
+		if nalUnit = bytes.TrimRight(nalUnit, "\x00"); len(nalUnit) > 0 {
+			nalUnits = append(nalUnits, nalUnit)
+		}
+	}
+
+	return nalUnits
+}
+
+// removeEmulationPrevention converts a NAL unit to its raw byte sequence payload.
+func removeEmulationPrevention(nalUnit []byte) []byte {
+	rbsp := make([]byte, 0, len(nalUnit))
+
+	for i := 0; i < len(nalUnit); i++ {
+		if i >= 2 && nalUnit[i] == 3 && nalUnit[i-1] == 0 && nalUnit[i-2] == 0 {
+			continue
+		}
+
+		rbsp = append(rbsp, nalUnit[i])
+	}
+
+	return rbsp
+}
@@ -0,0 +1,226 @@
+package mpd_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"go.eigsys.de/go-mpd"
+	"testing"
+)
+
+const smoothStreamingManifest = `<?xml version="1.0" encoding="%s"?>
+<SmoothStreamingMedia MajorVersion="2" MinorVersion="2" Duration="60000000">
+  <Protection>
+    <ProtectionHeader SystemID="{9A04F079-9840-4286-AB92-E65BE0885F95}">%s</ProtectionHeader>
+  </Protection>
+  <StreamIndex Type="video" Name="video" Chunks="3" QualityLevels="2" MaxWidth="1280" MaxHeight="720" Url="QualityLevels({bitrate})/Fragments(video={start time})">
+    <QualityLevel Index="0" Bitrate="2962000" FourCC="H264" MaxWidth="1280" MaxHeight="720" CodecPrivateData="000000016764001FAC2CA5014016EC04400000FA40003A9803C60C6580000000168E9093525"></QualityLevel>
+    <QualityLevel Index="1" Bitrate="1427000" FourCC="HVC1" MaxWidth="640" MaxHeight="360" CodecPrivateData="0000000140010C01FFFF016000000300B0000003000003005D95980900000001420101016000000300B0000003000003005DA00280802D165959A4932BC05A02000003000200000300321000000001440101C172B46240"></QualityLevel>
+    <c t="0" d="20000000" r="2"></c>
+    <c d="20000000"></c>
+  </StreamIndex>
+  <StreamIndex Type="audio" Name="audio_de" Language="de" Url="QualityLevels({bitrate})/Fragments(audio_de={start_time})" TimeScale="48000">
+    <QualityLevel Bitrate="128000" FourCC="AACL" SamplingRate="48000" Channels="2" CodecPrivateData="1190"></QualityLevel>
+    <QualityLevel Bitrate="64000" FourCC="AACH" SamplingRate="48000" Channels="2"></QualityLevel>
+    <c t="0"></c>
+    <c t="96256" d="96256"></c>
+    <c d="95744"></c>
+  </StreamIndex>
+  <StreamIndex Type="text" Subtype="SUBT" Language="en" Url="QualityLevels({bitrate})/Fragments(text={start time})">
+    <QualityLevel Bitrate="1000" FourCC="TTML"></QualityLevel>
+    <c t="0" d="60000000"></c>
+  </StreamIndex>
+</SmoothStreamingMedia>
+`
+
+func mustSmoothStreamingPlayReadyObject() *mpd.PlayReadyObject {
+	object, err := mpd.NewPlayReadyObject(&mpd.PlayReadyHeader{
+		Version: mpd.PlayReadyHeaderVersion43,
+		KID:     []mpd.PlayReadyKID{{ID: mustParseUUID("08e36702-8f33-436c-a5dd-60ffe5571e60"), AlgID: "AESCTR"}},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return object
+}
+
+func TestConvertFromSmoothStreaming(t *testing.T) {
+	object := mustSmoothStreamingPlayReadyObject()
+	utf8 := fmt.Sprintf(smoothStreamingManifest, "utf-8", mustEncodePlayReadyObjectBase64(object))
+	utf16 := append([]byte{0xff, 0xfe}, encodeUTF16LE(fmt.Sprintf(smoothStreamingManifest, "utf-16", mustEncodePlayReadyObjectBase64(object)))...)
+
+	for name, data := range map[string][]byte{"UTF-8": []byte(utf8), "UTF-16": utf16} {
+		t.Run(name, func(t *testing.T) {
+			manifest, err := mpd.ParseSmoothStreamingMedia(data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			converted, err := mpd.ConvertFromSmoothStreaming(manifest)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			kid := mustParseUUID("08e36702-8f33-436c-a5dd-60ffe5571e60")
+			wantContentProtection := []mpd.ContentProtection{
+				mpd.NewMP4ProtectionContentProtection("cenc", kid),
+				mpd.NewPlayReadyContentProtection(mustEncodePlayReadyObject(object), kid),
+			}
+
+			for i := range converted.Period[0].AdaptationSet {
+				adaptationSet := &converted.Period[0].AdaptationSet[i]
+				if adaptationSet.ContentType == mpd.SubtitlesContentType {
+					wantContentProtection = nil
+				}
+
+				if diff := cmp.Diff(adaptationSet.ContentProtection, wantContentProtection); diff != "" {
+					t.Errorf("wrong ContentProtection of AdaptationSet %d: %s", adaptationSet.ID, diff)
+				}
+
+				adaptationSet.ContentProtection = nil
+			}
+
+			got, err := converted.Bytes()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			want := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-live:2011" type="static" mediaPresentationDuration="PT6S" minBufferTime="PT6S" maxSegmentDuration="PT6S">
+  <Period id="0" start="PT0S">
+    <AdaptationSet mimeType="video/mp4" startWithSAP="1" id="1" contentType="video" maxWidth="1280" maxHeight="720" segmentAlignment="true">
+      <SegmentTemplate timescale="10000000" media="QualityLevels($Bandwidth$)/Fragments(video=$Time$)">
+        <SegmentTimeline>
+          <S t="0" d="20000000" r="2"></S>
+        </SegmentTimeline>
+      </SegmentTemplate>
+      <Representation width="1280" height="720" codecs="avc1.64001F" bandwidth="2962000" id="video_0"></Representation>
+      <Representation width="640" height="360" codecs="hvc1.1.6.L93.B0" bandwidth="1427000" id="video_1"></Representation>
+    </AdaptationSet>
+    <AdaptationSet mimeType="audio/mp4" startWithSAP="1" id="2" lang="de" contentType="audio" segmentAlignment="true">
+      <SegmentTemplate timescale="48000" media="QualityLevels($Bandwidth$)/Fragments(audio_de=$Time$)">
+        <SegmentTimeline>
+          <S t="0" d="96256" r="1"></S>
+          <S d="95744"></S>
+        </SegmentTimeline>
+      </SegmentTemplate>
+      <Representation audioSamplingRate="48000" codecs="mp4a.40.2" bandwidth="128000" id="audio_de_0">
+        <AudioChannelConfiguration schemeIdUri="urn:mpeg:dash:23003:3:audio_channel_configuration:2011" value="2"></AudioChannelConfiguration>
+      </Representation>
+      <Representation audioSamplingRate="48000" codecs="mp4a.40.5" bandwidth="64000" id="audio_de_1">
+        <AudioChannelConfiguration schemeIdUri="urn:mpeg:dash:23003:3:audio_channel_configuration:2011" value="2"></AudioChannelConfiguration>
+      </Representation>
+    </AdaptationSet>
+    <AdaptationSet mimeType="application/mp4" startWithSAP="1" id="3" lang="en" contentType="text" segmentAlignment="true">
+      <Role schemeIdUri="urn:mpeg:dash:role:2011" value="subtitle"></Role>
+      <SegmentTemplate timescale="10000000" media="QualityLevels($Bandwidth$)/Fragments(text=$Time$)">
+        <SegmentTimeline>
+          <S t="0" d="60000000"></S>
+        </SegmentTimeline>
+      </SegmentTemplate>
+      <Representation codecs="stpp" bandwidth="1000" id="text_0"></Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>`
+
+			if diff := cmp.Diff(string(got), want); diff != "" {
+				t.Errorf("wrong MPD: %s", diff)
+			}
+		})
+	}
+}
+
+func TestConvertFromSmoothStreaming_Live(t *testing.T) {
+	object, err := mpd.NewPlayReadyObject(&mpd.PlayReadyHeader{
+		Version: mpd.PlayReadyHeaderVersion43,
+		KID:     []mpd.PlayReadyKID{{ID: mustParseUUID("08e36702-8f33-436c-a5dd-60ffe5571e60"), AlgID: "AESCBC"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	manifest, err := mpd.ParseSmoothStreamingMedia([]byte(`<SmoothStreamingMedia MajorVersion="2" MinorVersion="0" Duration="0" IsLive="TRUE" DVRWindowLength="120000" TimeScale="1000">
+  <Protection>
+    <ProtectionHeader SystemID="edef8ba9-79d6-4ace-a3c8-27dcd51d21ed">AAAA</ProtectionHeader>
+    <ProtectionHeader SystemID="9a04f079-9840-4286-ab92-e65be0885f95">` + mustEncodePlayReadyObjectBase64(object) + `</ProtectionHeader>
+  </Protection>
+  <StreamIndex Type="video" Url="QualityLevels({Bitrate})/Fragments(video={start time})">
+    <QualityLevel Bitrate="1000000" FourCC="AVC1"></QualityLevel>
+    <c t="1700000000000" d="2000" r="3"></c>
+  </StreamIndex>
+</SmoothStreamingMedia>`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	converted, err := mpd.ConvertFromSmoothStreaming(manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if converted.Type != mpd.DynamicPresentationType || converted.AvailabilityStartTime != "1970-01-01T00:00:00Z" ||
+		converted.TimeShiftBufferDepth != "PT2M" || converted.MinimumUpdatePeriod != "PT2S" || converted.MediaPresentationDuration != "" {
+		t.Errorf("wrong live MPD: %+v", converted)
+	}
+
+	adaptationSet := &converted.Period[0].AdaptationSet[0]
+	if len(adaptationSet.ContentProtection) != 2 || adaptationSet.ContentProtection[0].Value != "cbcs" {
+		t.Errorf("wrong ContentProtection: %+v", adaptationSet.ContentProtection)
+	}
+
+	wantTimeline := &mpd.SegmentTimeline{S: []mpd.S{{T: uint64Ptr(1700000000000), D: 2000, R: 2}}}
+	if diff := cmp.Diff(adaptationSet.SegmentTemplate.SegmentTimeline, wantTimeline); diff != "" {
+		t.Errorf("wrong SegmentTimeline: %s", diff)
+	}
+
+	if representation := adaptationSet.Representation[0]; representation.ID != "video_0" || representation.Codecs != "avc1" {
+		t.Errorf("wrong Representation: %+v", representation)
+	}
+}
+
+func TestParseSmoothStreamingMedia_Errors(t *testing.T) {
+	testCases := map[string]string{
+		"invalid XML":         `<SmoothStreamingMedia`,
+		"unsupported charset": `<?xml version="1.0" encoding="iso-8859-1"?><SmoothStreamingMedia></SmoothStreamingMedia>`,
+		"wrong root":          `<MPD></MPD>`,
+	}
+
+	for name, data := range testCases {
+		if _, err := mpd.ParseSmoothStreamingMedia([]byte(data)); !errors.Is(err, mpd.ErrParseSmoothStreaming) {
+			t.Errorf("%s: wrong error: %v", name, err)
+		}
+	}
+}
+
+func TestConvertFromSmoothStreaming_Errors(t *testing.T) {
+	playReady := func(value string) *mpd.SmoothStreamingProtection {
+		return &mpd.SmoothStreamingProtection{ProtectionHeader: []mpd.SmoothStreamingProtectionHeader{
+			{SystemID: "9A04F079-9840-4286-AB92-E65BE0885F95", Value: value},
+		}}
+	}
+
+	withoutKID, err := mpd.NewPlayReadyObject(&mpd.PlayReadyHeader{Version: mpd.PlayReadyHeaderVersion43})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	chunk := []mpd.SmoothStreamingChunk{{D: 1}}
+
+	testCases := map[string]*mpd.SmoothStreamingMedia{
+		"invalid system ID": {Protection: &mpd.SmoothStreamingProtection{ProtectionHeader: []mpd.SmoothStreamingProtectionHeader{{SystemID: "PlayReady"}}}},
+		"invalid base64":    {Protection: playReady("!")},
+		"invalid object":    {Protection: playReady("AAAA")},
+		"invalid header":    {Protection: playReady(mustEncodePlayReadyObjectBase64(&mpd.PlayReadyObject{Record: []mpd.PlayReadyRecord{{Type: mpd.PlayReadyRightsManagementHeaderRecordType, Value: []byte{1}}}}))},
+		"missing KID":       {Protection: playReady(mustEncodePlayReadyObjectBase64(withoutKID))},
+		"no chunks":         {StreamIndex: []mpd.SmoothStreamingStreamIndex{{Type: mpd.VideoContentType}}},
+		"no duration":       {StreamIndex: []mpd.SmoothStreamingStreamIndex{{Type: mpd.VideoContentType, C: []mpd.SmoothStreamingChunk{{}}}}},
+		"overlapping":       {StreamIndex: []mpd.SmoothStreamingStreamIndex{{Type: mpd.VideoContentType, C: append([]mpd.SmoothStreamingChunk{{T: uint64Ptr(5)}, {T: uint64Ptr(5)}}, chunk...)}}},
+	}
+
+	for name, manifest := range testCases {
+		if _, err := mpd.ConvertFromSmoothStreaming(manifest); !errors.Is(err, mpd.ErrConvertFromSmoothStreaming) {
+			t.Errorf("%s: wrong error: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package mpd
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrSplicePeriod = errors.New("cannot splice period")
+
+// SplitPeriod splits the Period at the index into two Periods at a time relative to the start of the media
+// presentation, e.g. to insert an ad break. The segment information of both Periods is trimmed like with
+// ConvertToStatic, and segments that span the split are kept in both Periods. The second Period starts at the time
+// and gets a new ID derived from the ID of the first Period, which gets an ID if it has none.
+//
+// The AdaptationSets with an ID in the second Period signal that they continue the AdaptationSets of the first
+// Period: with period continuity if the second Period starts on a segment boundary of all Representations, and with
+// period connectivity otherwise.
+func (m *MPD) SplitPeriod(index int, at time.Duration) error {
+	start, err := m.PeriodStart(index)
+	if err != nil {
+		return errors.Join(ErrSplicePeriod, err)
+	}
+
+	duration, err := m.PeriodDuration(index)
+	if err != nil {
+		return errors.Join(ErrSplicePeriod, err)
+	}
+
+	if at <= start || duration > 0 && at >= start+duration {
+		return fmt.Errorf("%w: %s is not inside period %d", ErrSplicePeriod, at, index)
+	}
+
+	first := &m.Period[index]
+	second := deepCopy(first)
+
+	head := periodTrim{index: index, start: start, duration: at - start}
+	tail := periodTrim{index: index, start: at, cut: at - start}
+
+	if duration > 0 {
+		tail.duration = duration - tail.cut
+	}
+
+	apply, err := head.trimPeriod(first)
+	if err != nil {
+		return errors.Join(ErrSplicePeriod, err)
+	}
+
+	tailApply, err := tail.trimPeriod(second)
+	if err != nil {
+		return errors.Join(ErrSplicePeriod, err)
+	}
+
+	for _, f := range append(apply, tailApply...) {
+		f()
+	}
+
+	if first.ID == "" {
+		first.ID = m.newPeriodID("")
+	}
+
+	second.ID = m.newPeriodID(first.ID)
+	second.Start = FormatDuration(at)
+
+	if first.Duration != "" {
+		first.Duration = FormatDuration(head.duration)
+		second.Duration = FormatDuration(tail.duration)
+	}
+
+	m.Period = append(m.Period[:index+1], append([]Period{*second}, m.Period[index+1:]...)...)
+	m.signalContinuity(index, index+1, at)
+
+	return nil
+}
+
+// InsertPeriod inserts a copy of the Period at sourceIndex of another MPD, e.g. an ad, at a time relative to the
+// start of the media presentation, and returns its index. A Period that spans the time is split with SplitPeriod,
+// so that its content resumes after the inserted Period. The duration of the inserted Period must be known; the
+// following Periods with Period@start and MediaPresentationDuration are shifted by it.
+//
+// The BaseURL and ContentProtection references of the source MPD are resolved into the copy, so that it does not
+// depend on the source MPD. The copy gets a new ID if it has none or if its ID is in use.
+func (m *MPD) InsertPeriod(at time.Duration, source *MPD, sourceIndex int) (int, error) {
+	if sourceIndex < 0 || sourceIndex >= len(source.Period) {
+		return -1, fmt.Errorf("%w: no source period %d", ErrSplicePeriod, sourceIndex)
+	}
+
+	duration, err := source.PeriodDuration(sourceIndex)
+	if err != nil {
+		return -1, errors.Join(ErrSplicePeriod, err)
+	}
+
+	if duration == 0 {
+		return -1, fmt.Errorf("%w: source period %d has no known duration", ErrSplicePeriod, sourceIndex)
+	}
+
+	inserted, err := source.exportPeriod(sourceIndex)
+	if err != nil {
+		return -1, errors.Join(ErrSplicePeriod, err)
+	}
+
+	var mediaPresentationDuration time.Duration
+	if m.MediaPresentationDuration != "" {
+		if mediaPresentationDuration, err = ParseDuration(m.MediaPresentationDuration); err != nil {
+			return -1, errors.Join(ErrSplicePeriod, err)
+		}
+	}
+
+	index, split, err := m.insertionIndex(at)
+	if err != nil {
+		return -1, err
+	}
+
+	if split {
+		if err := m.SplitPeriod(index-1, at); err != nil {
+			return -1, err
+		}
+	}
+
+	for i := index; i < len(m.Period); i++ {
+		if period := &m.Period[i]; period.Start != "" {
+			// Period@start of all Periods was validated by insertionIndex.
+			start, _ := ParseDuration(period.Start)
+			period.Start = FormatDuration(start + duration)
+		}
+	}
+
+	if inserted.ID == "" || m.periodByID(inserted.ID) != nil {
+		inserted.ID = m.newPeriodID(inserted.ID)
+	}
+
+	inserted.Start = FormatDuration(at)
+	inserted.Duration = FormatDuration(duration)
+
+	m.Period = append(m.Period[:index], append([]Period{*inserted}, m.Period[index:]...)...)
+
+	if m.MediaPresentationDuration != "" {
+		m.MediaPresentationDuration = FormatDuration(mediaPresentationDuration + duration)
+	}
+
+	return index, nil
+}
+
+// insertionIndex returns the index to insert a Period at the time at, and whether the Period before it has to be
+// split.
+func (m *MPD) insertionIndex(at time.Duration) (int, bool, error) {
+	starts := make([]time.Duration, len(m.Period))
+
+	for i := range m.Period {
+		var err error
+		if starts[i], err = m.PeriodStart(i); err != nil {
+			return 0, false, errors.Join(ErrSplicePeriod, err)
+		}
+	}
+
+	for i, start := range starts {
+		if at <= start {
+			return i, false, nil
+		}
+
+		duration, err := m.PeriodDuration(i)
+		if err != nil {
+			return 0, false, errors.Join(ErrSplicePeriod, err)
+		}
+
+		switch {
+		case duration == 0 || at < start+duration:
+			return i + 1, true, nil
+		case at == start+duration:
+			if i+1 == len(m.Period) {
+				return i + 1, false, nil
+			}
+		case i+1 == len(m.Period):
+			return 0, false, fmt.Errorf("%w: %s is after the end of the media presentation", ErrSplicePeriod, at)
+		}
+	}
+
+	return 0, false, fmt.Errorf("%w: no period", ErrSplicePeriod)
+}
+
+// exportPeriod returns a copy of the Period at the index with the BaseURL of the MPD and the ContentProtection
+// references resolved.
+func (m *MPD) exportPeriod(index int) (*Period, error) {
+	period := deepCopy(&m.Period[index])
+
+	if len(m.BaseURL) > 0 {
+		base := strings.TrimSpace(m.BaseURL[0].Value)
+
+		if len(period.BaseURL) == 0 {
+			period.BaseURL = []BaseURL{{Value: base}}
+		}
+
+		for i := range period.BaseURL {
+			period.BaseURL[i].Value = resolveURL(base, strings.TrimSpace(period.BaseURL[i].Value))
+		}
+	}
+
+	resolve := func(contentProtection *[]ContentProtection) error {
+		if len(*contentProtection) == 0 {
+			return nil
+		}
+
+		resolved, err := m.ResolveContentProtection(&m.Period[index], *contentProtection)
+		if err != nil {
+			return err
+		}
+
+		*contentProtection = resolved
+
+		return nil
+	}
+
+	for i := range period.AdaptationSet {
+		adaptationSet := &period.AdaptationSet[i]
+		if err := resolve(&adaptationSet.ContentProtection); err != nil {
+			return nil, err
+		}
+
+		for j := range adaptationSet.Representation {
+			if err := resolve(&adaptationSet.Representation[j].ContentProtection); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return period, nil
+}
+
+// signalContinuity adds period continuity or connectivity descriptors to the AdaptationSets of the Period at the
+// index later, which starts at start, that continue the AdaptationSets with the same ID of the Period at the index
+// earlier. Existing descriptors are replaced.
+func (m *MPD) signalContinuity(earlier, later int, start time.Duration) {
+	period := &m.Period[later]
+
+	for i := range period.AdaptationSet {
+		adaptationSet := &period.AdaptationSet[i]
+		if adaptationSet.ID == 0 || m.Period[earlier].adaptationSetByID(adaptationSet.ID) == nil {
+			continue
+		}
+
+		adaptationSet.signalContinuedPeriod(m.Period[earlier].ID, m.startsOnSegmentBoundary(period, adaptationSet, start))
+	}
+}
+
+// startsOnSegmentBoundary reports whether a segment of every Representation of the AdaptationSet starts at the start
+// of the Period.
+func (m *MPD) startsOnSegmentBoundary(period *Period, adaptationSet *AdaptationSet, start time.Duration) bool {
+	for i := range adaptationSet.Representation {
+		segments, err := m.Segments(period, adaptationSet, &adaptationSet.Representation[i], &SegmentFilter{Start: start, End: start + time.Nanosecond})
+		if err != nil || len(segments) == 0 || segments[0].PresentationTime != start {
+			return false
+		}
+	}
+
+	return true
+}
+
+// newPeriodID returns an ID that no Period uses, made of base and a number.
+func (m *MPD) newPeriodID(base string) string {
+	for n := 1; ; n++ {
+		id := strconv.Itoa(n)
+		if base != "" {
+			id = base + "-" + id
+		}
+
+		if m.periodByID(id) == nil {
+			return id
+		}
+	}
+}
+
+func (m *MPD) periodByID(id string) *Period {
+	for i := range m.Period {
+		if m.Period[i].ID == id {
+			return &m.Period[i]
+		}
+	}
+
+	return nil
+}
+
+func (p *Period) adaptationSetByID(id uint) *AdaptationSet {
+	for i := range p.AdaptationSet {
+		if p.AdaptationSet[i].ID == id {
+			return &p.AdaptationSet[i]
+		}
+	}
+
+	return nil
+}
+
+// deepCopy returns a copy of the value that shares no pointers or slices with it.
+func deepCopy[T any](value *T) *T {
+	copied := new(T)
+	copyValue(reflect.ValueOf(copied).Elem(), reflect.ValueOf(value).Elem())
+
+	return copied
+}
+
+func copyValue(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Pointer:
+		if !src.IsNil() {
+			dst.Set(reflect.New(src.Type().Elem()))
+			copyValue(dst.Elem(), src.Elem())
+		}
+	case reflect.Slice:
+		if !src.IsNil() {
+			dst.Set(reflect.MakeSlice(src.Type(), src.Len(), src.Len()))
+
+			for i := 0; i < src.Len(); i++ {
+				copyValue(dst.Index(i), src.Index(i))
+			}
+		}
+	case reflect.Struct:
+		dst.Set(src)
+
+		for i := 0; i < src.NumField(); i++ {
+			if src.Type().Field(i).IsExported() {
+				copyValue(dst.Field(i), src.Field(i))
+			}
+		}
+	default:
+		dst.Set(src)
+	}
+}
@@ -0,0 +1,244 @@
+package mpd_test
+
+import (
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"go.eigsys.de/go-mpd"
+	"testing"
+	"time"
+)
+
+func newContentMPD() *mpd.MPD {
+	return &mpd.MPD{
+		Profiles:                  mpd.Profile("urn:mpeg:dash:profile:isoff-live:2011"),
+		MinBufferTime:             "PT2S",
+		MediaPresentationDuration: "PT30S",
+		Period: []mpd.Period{{
+			ID:       "main",
+			Duration: "PT30S",
+			AdaptationSet: []mpd.AdaptationSet{
+				{
+					ID:                 1,
+					RepresentationBase: mpd.RepresentationBase{MIMEType: mpd.VideoMP4MIMEType},
+					SegmentTemplate: &mpd.SegmentTemplate{
+						Media: "video/$Number$.m4s",
+						MultipleSegmentBase: mpd.MultipleSegmentBase{
+							SegmentBase:     mpd.SegmentBase{Timescale: 1000},
+							SegmentTimeline: &mpd.SegmentTimeline{S: []mpd.S{{T: uint64Ptr(0), D: 4000, R: 6}, {D: 2000}}},
+						},
+					},
+					Representation: []mpd.Representation{{ID: "v1", Bandwidth: 1000000}},
+				},
+				{
+					ID:                 2,
+					RepresentationBase: mpd.RepresentationBase{MIMEType: mpd.AudioMP4MIMEType},
+					SegmentTemplate: &mpd.SegmentTemplate{
+						Media: "audio/$Number$.m4s",
+						MultipleSegmentBase: mpd.MultipleSegmentBase{
+							SegmentBase:     mpd.SegmentBase{Timescale: 48000},
+							SegmentTimeline: &mpd.SegmentTimeline{S: []mpd.S{{T: uint64Ptr(0), D: 96000, R: 14}}},
+						},
+					},
+					Representation: []mpd.Representation{{ID: "a1", Bandwidth: 128000}},
+				},
+			},
+		}},
+	}
+}
+
+func newAdMPD() *mpd.MPD {
+	return &mpd.MPD{
+		BaseURL:           []mpd.BaseURL{{Value: "https://ads.example.com/ad1/"}},
+		ContentProtection: []mpd.ContentProtection{{Descriptor: mpd.Descriptor{SchemeIDURI: mpd.WidevineSchemeIDURI}, RefID: "drm"}},
+		Period: []mpd.Period{{
+			ID:       "main",
+			Duration: "PT15S",
+			AdaptationSet: []mpd.AdaptationSet{{
+				ID: 1,
+				RepresentationBase: mpd.RepresentationBase{
+					MIMEType:          mpd.VideoMP4MIMEType,
+					ContentProtection: []mpd.ContentProtection{{Ref: "drm"}},
+				},
+				SegmentTemplate: &mpd.SegmentTemplate{
+					Media:               "$Number$.m4s",
+					MultipleSegmentBase: mpd.MultipleSegmentBase{SegmentBase: mpd.SegmentBase{Timescale: 1}, Duration: 5},
+				},
+				Representation: []mpd.Representation{{ID: "ad", Bandwidth: 1000000}},
+			}},
+		}},
+	}
+}
+
+func TestMPD_SplitPeriod(t *testing.T) {
+	testMPD := newContentMPD()
+	if err := testMPD.SplitPeriod(0, 10*time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	head := newContentMPD().Period[0]
+	head.Duration = "PT10S"
+	head.AdaptationSet[0].SegmentTemplate.SegmentTimeline.S = []mpd.S{{T: uint64Ptr(0), D: 4000, R: 2}}
+	head.AdaptationSet[1].SegmentTemplate.SegmentTimeline.S = []mpd.S{{T: uint64Ptr(0), D: 96000, R: 4}}
+
+	tail := newContentMPD().Period[0]
+	tail.ID = "main-1"
+	tail.Start = "PT10S"
+	tail.Duration = "PT20S"
+	video := &tail.AdaptationSet[0]
+	video.SupplementalProperty = []mpd.Descriptor{{SchemeIDURI: mpd.PeriodConnectivitySchemeIDURI, Value: "main"}}
+	video.SegmentTemplate.PresentationTimeOffset = 10000
+	video.SegmentTemplate.StartNumber = 3
+	video.SegmentTemplate.SegmentTimeline.S = []mpd.S{{T: uint64Ptr(8000), D: 4000, R: 4}, {D: 2000}}
+	audio := &tail.AdaptationSet[1]
+	audio.SupplementalProperty = []mpd.Descriptor{{SchemeIDURI: mpd.PeriodContinuitySchemeIDURI, Value: "main"}}
+	audio.SegmentTemplate.PresentationTimeOffset = 480000
+	audio.SegmentTemplate.StartNumber = 6
+	audio.SegmentTemplate.SegmentTimeline.S = []mpd.S{{T: uint64Ptr(480000), D: 96000, R: 9}}
+
+	if diff := cmp.Diff(testMPD.Period, []mpd.Period{head, tail}); diff != "" {
+		t.Errorf("wrong Periods: %s", diff)
+	}
+
+	if err := testMPD.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestMPD_SplitPeriod_Live(t *testing.T) {
+	testMPD := newLiveMPD()
+	if err := testMPD.SplitPeriod(1, 24*time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	segmentList := testMPD.Period[2].AdaptationSet[0].SegmentList
+	if len(segmentList.SegmentURL) != 4 || segmentList.SegmentURL[0].Media != "b.m4s" || segmentList.StartNumber != 2 {
+		t.Errorf("wrong SegmentList: %+v", segmentList)
+	}
+
+	if testMPD.Period[2].Start != "PT24S" || testMPD.Period[2].ID != "1-1" {
+		t.Errorf("wrong Period: %s %s", testMPD.Period[2].ID, testMPD.Period[2].Start)
+	}
+
+	testMPD = newLiveMPD()
+	testMPD.Period = testMPD.Period[:1]
+	testMPD.Period[0].ID = ""
+	testMPD.Period[0].Duration = ""
+
+	if err := testMPD.SplitPeriod(0, 5*time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []mpd.S{{T: uint64Ptr(192000), D: 96000, R: -1}}
+	if diff := cmp.Diff(testMPD.Period[1].SegmentTemplate.SegmentTimeline.S, want); diff != "" {
+		t.Errorf("wrong SegmentTimeline: %s", diff)
+	}
+
+	if testMPD.Period[0].ID != "1" || testMPD.Period[1].ID != "1-1" {
+		t.Errorf("wrong IDs: %s %s", testMPD.Period[0].ID, testMPD.Period[1].ID)
+	}
+}
+
+func TestMPD_InsertPeriod(t *testing.T) {
+	testMPD := newContentMPD()
+
+	index, err := testMPD.InsertPeriod(10*time.Second, newAdMPD(), 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if index != 1 || len(testMPD.Period) != 3 {
+		t.Fatalf("wrong index: %d of %d", index, len(testMPD.Period))
+	}
+
+	wantAd := newAdMPD().Period[0]
+	wantAd.ID = "main-2"
+	wantAd.Start = "PT10S"
+	wantAd.BaseURL = []mpd.BaseURL{{Value: "https://ads.example.com/ad1/"}}
+	wantAd.AdaptationSet[0].ContentProtection = []mpd.ContentProtection{{Descriptor: mpd.Descriptor{SchemeIDURI: mpd.WidevineSchemeIDURI}}}
+
+	if diff := cmp.Diff(testMPD.Period[1], wantAd); diff != "" {
+		t.Errorf("wrong Period: %s", diff)
+	}
+
+	if resumed := testMPD.Period[2]; resumed.ID != "main-1" || resumed.Start != "PT25S" || resumed.AdaptationSet[1].SupplementalProperty[0].Value != "main" {
+		t.Errorf("wrong Period: %+v", resumed)
+	}
+
+	if testMPD.MediaPresentationDuration != "PT45S" {
+		t.Errorf("wrong duration: %s", testMPD.MediaPresentationDuration)
+	}
+
+	if err := testMPD.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	segments, err := testMPD.Segments(&testMPD.Period[1], &testMPD.Period[1].AdaptationSet[0], &testMPD.Period[1].AdaptationSet[0].Representation[0], nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(segments) != 3 || segments[0].URL != "https://ads.example.com/ad1/1.m4s" || segments[0].PresentationTime != 10*time.Second {
+		t.Errorf("wrong segments: %+v", segments)
+	}
+
+	for _, insertion := range []struct {
+		at        time.Duration
+		wantIndex int
+	}{{at: 45 * time.Second, wantIndex: 3}, {at: 0, wantIndex: 0}, {at: 25 * time.Second, wantIndex: 2}} {
+		index, err := testMPD.InsertPeriod(insertion.at, newAdMPD(), 0)
+		if err != nil || index != insertion.wantIndex {
+			t.Errorf("%s: unexpected result: %d %v", insertion.at, index, err)
+		}
+	}
+}
+
+func TestMPD_SplicePeriod_Errors(t *testing.T) {
+	openAd := newAdMPD()
+	openAd.Type = mpd.DynamicPresentationType
+	openAd.Period[0].Duration = ""
+
+	invalidAd := newAdMPD()
+	invalidAd.Period[0].Duration = "invalid"
+
+	danglingAd := newAdMPD()
+	danglingAd.ContentProtection = nil
+
+	insert := func(at time.Duration, source *mpd.MPD, sourceIndex int) func(*mpd.MPD) error {
+		return func(m *mpd.MPD) error {
+			_, err := m.InsertPeriod(at, source, sourceIndex)
+			return err
+		}
+	}
+
+	testCases := map[string]struct {
+		mutate func(*mpd.MPD)
+		splice func(*mpd.MPD) error
+	}{
+		"split at start":               {splice: func(m *mpd.MPD) error { return m.SplitPeriod(0, 0) }},
+		"split at end":                 {splice: func(m *mpd.MPD) error { return m.SplitPeriod(0, 30*time.Second) }},
+		"split missing period":         {splice: func(m *mpd.MPD) error { return m.SplitPeriod(1, 10*time.Second) }},
+		"split with invalid duration":  {mutate: func(m *mpd.MPD) { m.Period[0].Duration = "invalid" }, splice: func(m *mpd.MPD) error { return m.SplitPeriod(0, 10*time.Second) }},
+		"split off segment boundary":   {mutate: func(m *mpd.MPD) { *m = *newAdMPD() }, splice: func(m *mpd.MPD) error { return m.SplitPeriod(0, 12*time.Second) }},
+		"insert missing source period": {splice: insert(0, newAdMPD(), 1)},
+		"insert open source period":    {splice: insert(0, openAd, 0)},
+		"insert invalid source period": {splice: insert(0, invalidAd, 0)},
+		"insert dangling source ref":   {splice: insert(0, danglingAd, 0)},
+		"insert with invalid duration": {mutate: func(m *mpd.MPD) { m.MediaPresentationDuration = "invalid" }, splice: insert(0, newAdMPD(), 0)},
+		"insert with invalid start":    {mutate: func(m *mpd.MPD) { m.Period[0].Start = "invalid" }, splice: insert(0, newAdMPD(), 0)},
+		"insert with invalid end":      {mutate: func(m *mpd.MPD) { m.Period[0].Duration = "invalid" }, splice: insert(10*time.Second, newAdMPD(), 0)},
+		"insert after end":             {splice: insert(40*time.Second, newAdMPD(), 0)},
+		"insert without periods":       {mutate: func(m *mpd.MPD) { m.Period = nil }, splice: insert(0, newAdMPD(), 0)},
+		"insert off segment boundary":  {mutate: func(m *mpd.MPD) { *m = *newAdMPD() }, splice: insert(12*time.Second, newAdMPD(), 0)},
+	}
+
+	for name, testCase := range testCases {
+		testMPD := newContentMPD()
+		if testCase.mutate != nil {
+			testCase.mutate(testMPD)
+		}
+
+		if err := testCase.splice(testMPD); !errors.Is(err, mpd.ErrSplicePeriod) {
+			t.Errorf("%s: wrong error: %v", name, err)
+		}
+	}
+}
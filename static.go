@@ -0,0 +1,438 @@
+package mpd
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var ErrConvertToStatic = errors.New("cannot convert MPD to static")
+
+// ConvertToStatic turns a dynamic MPD into a static MPD with the media between start and end, relative to the start
+// of the media presentation, e.g. to publish the time shift buffer of a live event on demand. An end of 0 ends the
+// media presentation with the last segment.
+//
+// Periods outside the range are removed. SegmentTimelines and SegmentLists are trimmed to the segments that overlap
+// the range, and Period@start, StartNumber and PresentationTimeOffset are rebased so that the media presentation
+// starts at 0 without changing the segments' media times. Segments at the edges of the range are kept whole.
+// SegmentTemplate@duration requires start to fall on a segment boundary. Events outside the range are removed.
+// The MPD is not modified if an error is returned.
+func (m *MPD) ConvertToStatic(start, end time.Duration) error {
+	if m.Type != DynamicPresentationType {
+		return fmt.Errorf("%w: MPD is not dynamic", ErrConvertToStatic)
+	}
+
+	if end == 0 {
+		var err error
+		if end, err = m.segmentsEnd(); err != nil {
+			return errors.Join(ErrConvertToStatic, err)
+		}
+	}
+
+	if start < 0 || end <= start {
+		return fmt.Errorf("%w: empty range from %s to %s", ErrConvertToStatic, start, end)
+	}
+
+	var (
+		apply   []func()
+		periods []int
+	)
+
+	for i := range m.Period {
+		periodStart, err := m.PeriodStart(i)
+		if err != nil {
+			return errors.Join(ErrConvertToStatic, err)
+		}
+
+		periodDuration, err := m.PeriodDuration(i)
+		if err != nil {
+			return errors.Join(ErrConvertToStatic, err)
+		}
+
+		periodEnd := end
+		if periodDuration > 0 && periodStart+periodDuration < end {
+			periodEnd = periodStart + periodDuration
+		}
+
+		trim := periodTrim{index: i, start: periodStart}
+		if start > periodStart {
+			trim.start = start
+		}
+
+		if periodEnd <= trim.start {
+			continue
+		}
+
+		trim.cut = trim.start - periodStart
+		trim.duration = periodEnd - trim.start
+
+		periodApply, err := trim.trimPeriod(&m.Period[i])
+		if err != nil {
+			return err
+		}
+
+		apply = append(apply, periodApply...)
+		apply = append(apply, func() {
+			period := &m.Period[trim.index]
+			period.Start = FormatDuration(trim.start - start)
+
+			if period.Duration != "" {
+				period.Duration = FormatDuration(trim.duration)
+			}
+		})
+		periods = append(periods, i)
+	}
+
+	for _, f := range apply {
+		f()
+	}
+
+	kept := make([]Period, 0, len(periods))
+	for _, i := range periods {
+		kept = append(kept, m.Period[i])
+	}
+
+	m.Period = kept
+	m.Type = StaticPresentationType
+	m.MediaPresentationDuration = FormatDuration(end - start)
+	m.AvailabilityStartTime = ""
+	m.AvailabilityEndTime = ""
+	m.MinimumUpdatePeriod = ""
+	m.TimeShiftBufferDepth = ""
+	m.SuggestedPresentationDelay = ""
+	m.PatchLocation = nil
+	m.UTCTiming = nil
+
+	return nil
+}
+
+// segmentsEnd returns the end of the last segment of all Representations.
+func (m *MPD) segmentsEnd() (time.Duration, error) {
+	var end time.Duration
+
+	for i := range m.Period {
+		period := &m.Period[i]
+
+		for j := range period.AdaptationSet {
+			adaptationSet := &period.AdaptationSet[j]
+
+			for k := range adaptationSet.Representation {
+				segments, err := m.Segments(period, adaptationSet, &adaptationSet.Representation[k], nil)
+				if err != nil {
+					return 0, err
+				}
+
+				if n := len(segments); n > 0 && segments[n-1].PresentationTime+segments[n-1].PresentationDuration > end {
+					end = segments[n-1].PresentationTime + segments[n-1].PresentationDuration
+				}
+			}
+		}
+	}
+
+	return end, nil
+}
+
+// periodTrim trims a Period to the part that starts cut into the Period and lasts duration, or is open-ended if
+// duration is 0. Start is the new start of the Period relative to the start of the media presentation before
+// rebasing.
+type periodTrim struct {
+	index    int
+	start    time.Duration
+	cut      time.Duration
+	duration time.Duration
+}
+
+// trimmedSegments is the segment information of an element after trimming.
+type trimmedSegments struct {
+	presentationTimeOffset uint64
+	startNumber            uint
+
+	// removed segments precede the range, kept segments overlap it. Kept is -1 if all segments from the
+	// start of the range are kept.
+	removed uint64
+	kept    int64
+}
+
+// segmentChains are the segment information elements of the levels down to the current one, together with the
+// trimmed segment information of the lowest level that has each element.
+type segmentChains struct {
+	templates []*SegmentTemplate
+	lists     []*SegmentList
+	bases     []*SegmentBase
+
+	template, list, base *trimmedSegments
+}
+
+// trimPeriod returns the functions that apply the trimming to the Period, so that nothing is modified before all
+// Periods are trimmed without errors.
+func (t *periodTrim) trimPeriod(period *Period) ([]func(), error) {
+	var apply []func()
+
+	periodChains, err := t.trimLevel(&segmentChains{}, period.SegmentTemplate, period.SegmentList, period.SegmentBase, &apply)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range period.AdaptationSet {
+		adaptationSet := &period.AdaptationSet[i]
+
+		adaptationSetChains, err := t.trimLevel(periodChains, adaptationSet.SegmentTemplate, adaptationSet.SegmentList, adaptationSet.SegmentBase, &apply)
+		if err != nil {
+			return nil, err
+		}
+
+		for j := range adaptationSet.Representation {
+			representation := &adaptationSet.Representation[j]
+			if _, err := t.trimLevel(adaptationSetChains, representation.SegmentTemplate, representation.SegmentList, representation.SegmentBase, &apply); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for i := range period.EventStream {
+		apply = append(apply, t.trimEventStream(&period.EventStream[i]))
+	}
+
+	return apply, nil
+}
+
+// trimLevel trims the segment information elements of a level, resolving inheritance from the parent levels.
+// An element only receives StartNumber and PresentationTimeOffset if it cannot inherit them from its parent.
+func (t *periodTrim) trimLevel(parent *segmentChains, template *SegmentTemplate, list *SegmentList, base *SegmentBase, apply *[]func()) (*segmentChains, error) {
+	chains := &segmentChains{
+		templates: append(parent.templates[:len(parent.templates):len(parent.templates)], template),
+		lists:     append(parent.lists[:len(parent.lists):len(parent.lists)], list),
+		bases:     append(parent.bases[:len(parent.bases):len(parent.bases)], base),
+		template:  parent.template,
+		list:      parent.list,
+		base:      parent.base,
+	}
+
+	var err error
+
+	if template != nil {
+		effective := inherit(chains.templates)
+		if chains.template, err = t.trimElement(&template.SegmentBase, &template.MultipleSegmentBase, &effective.MultipleSegmentBase, parent.template, apply); err != nil {
+			return nil, err
+		}
+	}
+
+	if list != nil {
+		effective := inherit(chains.lists)
+		if chains.list, err = t.trimElement(&list.SegmentBase, &list.MultipleSegmentBase, &effective.MultipleSegmentBase, parent.list, apply); err != nil {
+			return nil, err
+		}
+
+		if trimmed := chains.list; len(list.SegmentURL) > 0 {
+			from, to := minUint64(trimmed.removed, uint64(len(list.SegmentURL))), uint64(len(list.SegmentURL))
+			if trimmed.kept >= 0 {
+				to = minUint64(trimmed.removed+uint64(trimmed.kept), to)
+			}
+
+			*apply = append(*apply, func() { list.SegmentURL = list.SegmentURL[from:to] })
+		}
+	}
+
+	if base != nil {
+		effective := &MultipleSegmentBase{SegmentBase: *inherit(chains.bases)}
+		if chains.base, err = t.trimElement(base, nil, effective, parent.base, apply); err != nil {
+			return nil, err
+		}
+	}
+
+	return chains, nil
+}
+
+// trimElement trims an element with the effective segment information resolved from the levels above. Multiple is
+// nil for SegmentBase elements. Only a SegmentTimeline of the element itself is replaced.
+func (t *periodTrim) trimElement(element *SegmentBase, multiple, effective *MultipleSegmentBase, parent *trimmedSegments, apply *[]func()) (*trimmedSegments, error) {
+	timescale := uint64(effective.Timescale)
+	if timescale == 0 {
+		timescale = 1
+	}
+
+	cut := durationTicks(t.cut, timescale)
+	from := effective.PresentationTimeOffset + cut
+	to := t.end(from, timescale)
+
+	trimmed := &trimmedSegments{presentationTimeOffset: from, startNumber: effective.StartNumber, kept: -1}
+
+	switch {
+	case effective.SegmentTimeline != nil:
+		timeline, removed, kept := effective.SegmentTimeline.trim(from, to)
+		trimmed.removed, trimmed.kept = removed, kept
+
+		if multiple != nil && multiple.SegmentTimeline != nil {
+			*apply = append(*apply, func() { multiple.SegmentTimeline = timeline })
+		}
+	case effective.Duration > 0:
+		duration := uint64(effective.Duration)
+		if cut%duration != 0 {
+			return nil, fmt.Errorf("%w: period %d: start of range not on a segment boundary", ErrConvertToStatic, t.index)
+		}
+
+		trimmed.removed = cut / duration
+		if t.duration > 0 {
+			trimmed.kept = int64((to - from + duration - 1) / duration)
+		}
+	}
+
+	if trimmed.removed > 0 {
+		if trimmed.startNumber == 0 {
+			trimmed.startNumber = 1
+		}
+
+		trimmed.startNumber += uint(trimmed.removed)
+	}
+
+	parentOffset, parentStartNumber := presentationTimeOffset(parent), uint(0)
+	if parent != nil {
+		parentStartNumber = parent.startNumber
+	}
+
+	if element.PresentationTimeOffset != 0 || trimmed.presentationTimeOffset != parentOffset {
+		*apply = append(*apply, func() { element.PresentationTimeOffset = trimmed.presentationTimeOffset })
+	}
+
+	if multiple != nil && (multiple.StartNumber != 0 || trimmed.startNumber != parentStartNumber) {
+		*apply = append(*apply, func() { multiple.StartNumber = trimmed.startNumber })
+	}
+
+	return trimmed, nil
+}
+
+// trimEventStream removes the events that do not overlap the range and rebases the PresentationTimeOffset.
+func (t *periodTrim) trimEventStream(eventStream *EventStream) func() {
+	timescale := uint64(eventStream.Timescale)
+	if timescale == 0 {
+		timescale = 1
+	}
+
+	from := uint64(eventStream.PresentationTimeOffset) + durationTicks(t.cut, timescale)
+	to := t.end(from, timescale)
+
+	var events []Event
+
+	for _, event := range eventStream.Event {
+		if event.PresentationTime < to && (event.PresentationTime+event.Duration > from || event.PresentationTime >= from) {
+			events = append(events, event)
+		}
+	}
+
+	return func() {
+		eventStream.Event = events
+		eventStream.PresentationTimeOffset = uint(from)
+	}
+}
+
+// end returns the end of the range in timescale units for a range that starts at from, or math.MaxUint64 if the
+// range is open-ended.
+func (t *periodTrim) end(from, timescale uint64) uint64 {
+	if t.duration == 0 {
+		return math.MaxUint64
+	}
+
+	return from + durationTicks(t.duration, timescale)
+}
+
+// trim returns the part of the SegmentTimeline with the segments that overlap the media time range from start to
+// end, together with the number of segments before the range and in it. An open-ended repeat ends at the next S@t
+// or at end. If end is math.MaxUint64, a trailing open-ended repeat stays open-ended and the number of segments in
+// the range is -1.
+func (t *SegmentTimeline) trim(start, end uint64) (*SegmentTimeline, uint64, int64) {
+	trimmed := &SegmentTimeline{Items: t.Items}
+
+	var (
+		removed uint64
+		kept    int64
+		current uint64
+	)
+
+	for i, s := range t.S {
+		if s.T != nil {
+			current = *s.T
+		}
+
+		if s.D == 0 {
+			continue
+		}
+
+		count, open := uint64(s.R)+1, false
+		if s.R < 0 {
+			next := end
+			if i+1 < len(t.S) && t.S[i+1].T != nil {
+				next = *t.S[i+1].T
+			}
+
+			count, open = 0, next == math.MaxUint64
+			switch {
+			case open:
+				count = math.MaxUint64
+			case next > current:
+				count = (next - current + s.D - 1) / s.D
+			}
+		}
+
+		var first, last uint64
+		if start > current {
+			first = minUint64((start-current)/s.D, count)
+		}
+
+		switch {
+		case end == math.MaxUint64:
+			last = count
+		case end > current:
+			last = minUint64((end-current+s.D-1)/s.D, count)
+		}
+
+		removed += first
+
+		if first < last {
+			trimmedS := S{D: s.D, R: int(last - first - 1), K: s.K}
+			if open {
+				trimmedS.R = -1
+			}
+
+			if len(trimmed.S) == 0 || s.T != nil || first > 0 {
+				segmentTime := current + first*s.D
+				trimmedS.T = &segmentTime
+			}
+
+			if s.N != 0 {
+				trimmedS.N = s.N + first
+			}
+
+			trimmed.S = append(trimmed.S, trimmedS)
+			kept += int64(last - first)
+		}
+
+		if open {
+			return trimmed, removed, -1
+		}
+
+		if last < count {
+			break
+		}
+
+		current += count * s.D
+	}
+
+	return trimmed, removed, kept
+}
+
+func presentationTimeOffset(trimmed *trimmedSegments) uint64 {
+	if trimmed == nil {
+		return 0
+	}
+
+	return trimmed.presentationTimeOffset
+}
+
+func minUint64(a, b uint64) uint64 {
+	if b < a {
+		return b
+	}
+
+	return a
+}
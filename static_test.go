@@ -0,0 +1,196 @@
+package mpd_test
+
+import (
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"go.eigsys.de/go-mpd"
+	"testing"
+	"time"
+)
+
+func newLiveMPD() *mpd.MPD {
+	return &mpd.MPD{
+		Profiles:              mpd.Profile("urn:mpeg:dash:profile:isoff-live:2011"),
+		Type:                  mpd.DynamicPresentationType,
+		AvailabilityStartTime: "2024-01-01T00:00:00Z",
+		PublishTime:           "2024-01-01T00:00:30Z",
+		MinimumUpdatePeriod:   "PT2S",
+		TimeShiftBufferDepth:  "PT30S",
+		MinBufferTime:         "PT2S",
+		UTCTiming:             []mpd.Descriptor{{SchemeIDURI: "urn:mpeg:dash:utc:http-iso:2014", Value: "https://time.example.com"}},
+		Period: []mpd.Period{
+			{
+				ID:       "0",
+				Start:    "PT0S",
+				Duration: "PT20S",
+				SegmentTemplate: &mpd.SegmentTemplate{
+					Media: "$RepresentationID$_$Number$.m4s",
+					MultipleSegmentBase: mpd.MultipleSegmentBase{
+						SegmentBase:     mpd.SegmentBase{Timescale: 48000},
+						StartNumber:     100,
+						SegmentTimeline: &mpd.SegmentTimeline{S: []mpd.S{{T: uint64Ptr(0), D: 96000, R: -1}}},
+					},
+				},
+				EventStream: []mpd.EventStream{{
+					SchemeIdURI: "urn:example:events",
+					Timescale:   1000,
+					Event:       []mpd.Event{{ID: "1", PresentationTime: 3000, Duration: 1000}, {ID: "2", PresentationTime: 15000}},
+				}},
+				AdaptationSet: []mpd.AdaptationSet{
+					{
+						ID:                 1,
+						RepresentationBase: mpd.RepresentationBase{MIMEType: mpd.VideoMP4MIMEType},
+						SegmentTemplate: &mpd.SegmentTemplate{
+							Media: "$RepresentationID$_$Time$.m4s",
+							MultipleSegmentBase: mpd.MultipleSegmentBase{
+								SegmentBase:     mpd.SegmentBase{Timescale: 90000, PresentationTimeOffset: 900000},
+								SegmentTimeline: &mpd.SegmentTimeline{S: []mpd.S{{T: uint64Ptr(900000), D: 180000, R: 9}}},
+							},
+						},
+						Representation: []mpd.Representation{
+							{ID: "v1", Bandwidth: 1000000},
+							{ID: "v2", Bandwidth: 2000000, SegmentTemplate: &mpd.SegmentTemplate{Media: "v2/$Time$.m4s"}},
+						},
+					},
+					{ID: 2, RepresentationBase: mpd.RepresentationBase{MIMEType: mpd.AudioMP4MIMEType}, Representation: []mpd.Representation{{ID: "a1", Bandwidth: 128000}}},
+				},
+			},
+			{
+				ID:    "1",
+				Start: "PT20S",
+				AdaptationSet: []mpd.AdaptationSet{{
+					ID:                 1,
+					RepresentationBase: mpd.RepresentationBase{MIMEType: mpd.VideoMP4MIMEType},
+					SegmentList: &mpd.SegmentList{
+						MultipleSegmentBase: mpd.MultipleSegmentBase{SegmentBase: mpd.SegmentBase{Timescale: 1}, Duration: 4},
+						SegmentURL:          []mpd.SegmentURL{{Media: "a.m4s"}, {Media: "b.m4s"}, {Media: "c.m4s"}, {Media: "d.m4s"}, {Media: "e.m4s"}},
+					},
+					Representation: []mpd.Representation{{ID: "v3", Bandwidth: 1000000}},
+				}},
+			},
+		},
+	}
+}
+
+func TestMPD_ConvertToStatic(t *testing.T) {
+	liveMPD := newLiveMPD()
+	testMPD := newLiveMPD()
+
+	if err := testMPD.ConvertToStatic(6*time.Second, 30*time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantMPD := newLiveMPD()
+	wantMPD.Type = mpd.StaticPresentationType
+	wantMPD.MediaPresentationDuration = "PT24S"
+	wantMPD.AvailabilityStartTime = ""
+	wantMPD.MinimumUpdatePeriod = ""
+	wantMPD.TimeShiftBufferDepth = ""
+	wantMPD.UTCTiming = nil
+
+	period := &wantMPD.Period[0]
+	period.Duration = "PT14S"
+	period.SegmentTemplate.PresentationTimeOffset = 288000
+	period.SegmentTemplate.StartNumber = 103
+	period.SegmentTemplate.SegmentTimeline.S = []mpd.S{{T: uint64Ptr(288000), D: 96000, R: 6}}
+	period.EventStream[0].PresentationTimeOffset = 6000
+	period.EventStream[0].Event = period.EventStream[0].Event[1:]
+	video := period.AdaptationSet[0].SegmentTemplate
+	video.PresentationTimeOffset = 1440000
+	video.SegmentTimeline.S = []mpd.S{{T: uint64Ptr(1440000), D: 180000, R: 6}}
+
+	period = &wantMPD.Period[1]
+	period.Start = "PT14S"
+	period.AdaptationSet[0].SegmentList.SegmentURL = period.AdaptationSet[0].SegmentList.SegmentURL[:3]
+
+	if diff := cmp.Diff(testMPD, wantMPD); diff != "" {
+		t.Errorf("wrong MPD: %s", diff)
+	}
+
+	if err := testMPD.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	for i := range liveMPD.Period {
+		for j := range liveMPD.Period[i].AdaptationSet {
+			for k := range liveMPD.Period[i].AdaptationSet[j].Representation {
+				liveAdaptationSet := &liveMPD.Period[i].AdaptationSet[j]
+				liveSegments, err := liveMPD.Segments(&liveMPD.Period[i], liveAdaptationSet, &liveAdaptationSet.Representation[k], &mpd.SegmentFilter{Start: 6 * time.Second, End: 30 * time.Second})
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				for l := range liveSegments {
+					liveSegments[l].PresentationTime -= 6 * time.Second
+				}
+
+				adaptationSet := &testMPD.Period[i].AdaptationSet[j]
+				segments, err := testMPD.Segments(&testMPD.Period[i], adaptationSet, &adaptationSet.Representation[k], nil)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				if diff := cmp.Diff(segments, liveSegments); diff != "" {
+					t.Errorf("wrong segments of %s: %s", adaptationSet.Representation[k].ID, diff)
+				}
+			}
+		}
+	}
+}
+
+func TestMPD_ConvertToStatic_LastSegment(t *testing.T) {
+	testMPD := newLiveMPD()
+	testMPD.Period = testMPD.Period[:1]
+	testMPD.Period[0].Duration = ""
+	testMPD.Period[0].SegmentTemplate.SegmentTimeline.S[0].R = 4
+
+	if err := testMPD.ConvertToStatic(0, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if testMPD.MediaPresentationDuration != "PT20S" {
+		t.Errorf("wrong duration: %s", testMPD.MediaPresentationDuration)
+	}
+
+	period := &testMPD.Period[0]
+	if period.SegmentTemplate.PresentationTimeOffset != 0 || period.SegmentTemplate.StartNumber != 100 {
+		t.Errorf("wrong SegmentTemplate: %+v", period.SegmentTemplate)
+	}
+
+	if diff := cmp.Diff(period.SegmentTemplate.SegmentTimeline.S, []mpd.S{{T: uint64Ptr(0), D: 96000, R: 4}}); diff != "" {
+		t.Errorf("wrong SegmentTimeline: %s", diff)
+	}
+}
+
+func TestMPD_ConvertToStatic_Errors(t *testing.T) {
+	testCases := map[string]struct {
+		mutate     func(*mpd.MPD)
+		start, end time.Duration
+	}{
+		"static MPD":       {mutate: func(m *mpd.MPD) { m.Type = mpd.StaticPresentationType }, end: 10 * time.Second},
+		"empty range":      {start: 10 * time.Second, end: 10 * time.Second},
+		"open timeline":    {mutate: func(m *mpd.MPD) { m.Period = m.Period[:1]; m.Period[0].Duration = "" }},
+		"period timing":    {mutate: func(m *mpd.MPD) { m.Period[1].Start = "invalid" }, end: 10 * time.Second},
+		"segment boundary": {start: 21 * time.Second, end: 30 * time.Second},
+	}
+
+	for name, testCase := range testCases {
+		testMPD := newLiveMPD()
+		if testCase.mutate != nil {
+			testCase.mutate(testMPD)
+		}
+
+		wantMPD := newLiveMPD()
+		if testCase.mutate != nil {
+			testCase.mutate(wantMPD)
+		}
+
+		if err := testMPD.ConvertToStatic(testCase.start, testCase.end); !errors.Is(err, mpd.ErrConvertToStatic) {
+			t.Errorf("%s: wrong error: %v", name, err)
+		}
+
+		if diff := cmp.Diff(testMPD, wantMPD); diff != "" {
+			t.Errorf("%s: MPD modified: %s", name, diff)
+		}
+	}
+}
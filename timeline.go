@@ -0,0 +1,148 @@
+package mpd
+
+// Append adds a segment to the end of the SegmentTimeline.
+// A segment which directly follows the last S element with the same duration increments its repeat count.
+// An explicit start time is only written for the first segment and after gaps or overlaps.
+// Append determines the end of the SegmentTimeline from all S elements.
+func (t *SegmentTimeline) Append(start, duration uint64) {
+	t.append(t.end(), start, duration)
+}
+
+// append adds a segment to the SegmentTimeline ending at end like Append.
+func (t *SegmentTimeline) append(end, start, duration uint64) {
+	if n := len(t.S); n > 0 && end == start {
+		if last := &t.S[n-1]; last.D == duration && last.R >= 0 {
+			last.R++
+			return
+		}
+
+		t.S = append(t.S, S{D: duration})
+
+		return
+	}
+
+	t.S = append(t.S, S{T: &start, D: duration})
+}
+
+// timelineBuilder appends segments to a SegmentTimeline and keeps track of its end, so that building a
+// SegmentTimeline takes linear time.
+type timelineBuilder struct {
+	timeline *SegmentTimeline
+	end      uint64
+}
+
+func newTimelineBuilder(timeline *SegmentTimeline) *timelineBuilder {
+	return &timelineBuilder{timeline: timeline, end: timeline.end()}
+}
+
+func (b *timelineBuilder) append(start, duration uint64) {
+	b.timeline.append(b.end, start, duration)
+	b.end = start + duration
+}
+
+// end returns the end time of the last S element.
+// Open-ended repeats are counted as a single segment.
+func (t *SegmentTimeline) end() uint64 {
+	var end uint64
+
+	for _, s := range t.S {
+		if s.T != nil {
+			end = *s.T
+		}
+
+		repeat := uint64(0)
+		if s.R > 0 {
+			repeat = uint64(s.R)
+		}
+
+		end += s.D * (repeat + 1)
+	}
+
+	return end
+}
+
+// Normalize compacts the SegmentTimeline without changing the segments it describes. Consecutive segments with the
+// same duration are merged into the repeat count of one S element, and start times that follow from the previous
+// segment are dropped, so that an explicit start time is only kept for the first segment and after gaps, overlaps or
+// open-ended repeats. S elements with a segment number or an open-ended repeat count are not merged into the
+// previous S element.
+func (t *SegmentTimeline) Normalize() {
+	var (
+		normalized []S
+		end        uint64
+		known      = true
+	)
+
+	for _, s := range t.S {
+		start := end
+		if s.T != nil {
+			start = *s.T
+			known = true
+		}
+
+		if n := len(normalized); n > 0 && known && start == end {
+			if last := &normalized[n-1]; last.D == s.D && last.K == s.K && last.R >= 0 && s.R >= 0 && s.N == 0 && s.D > 0 {
+				last.R += s.R + 1
+				end += s.D * uint64(s.R+1)
+
+				continue
+			}
+
+			s.T = nil
+		} else if s.T != nil {
+			s.T = &start
+		}
+
+		normalized = append(normalized, s)
+
+		if s.R < 0 {
+			known = false
+		} else {
+			end = start + s.D*uint64(s.R+1)
+		}
+	}
+
+	t.S = normalized
+}
+
+// NormalizeSegmentTimelines normalizes all SegmentTimelines of the MPD with SegmentTimeline.Normalize.
+func (m *MPD) NormalizeSegmentTimelines() {
+	normalize := func(template *SegmentTemplate, list *SegmentList) {
+		for _, base := range []*MultipleSegmentBase{templateBase(template), listBase(list)} {
+			if base != nil && base.SegmentTimeline != nil {
+				base.SegmentTimeline.Normalize()
+			}
+		}
+	}
+
+	for i := range m.Period {
+		period := &m.Period[i]
+		normalize(period.SegmentTemplate, period.SegmentList)
+
+		for j := range period.AdaptationSet {
+			adaptationSet := &period.AdaptationSet[j]
+			normalize(adaptationSet.SegmentTemplate, adaptationSet.SegmentList)
+
+			for k := range adaptationSet.Representation {
+				representation := &adaptationSet.Representation[k]
+				normalize(representation.SegmentTemplate, representation.SegmentList)
+			}
+		}
+	}
+}
+
+func templateBase(template *SegmentTemplate) *MultipleSegmentBase {
+	if template == nil {
+		return nil
+	}
+
+	return &template.MultipleSegmentBase
+}
+
+func listBase(list *SegmentList) *MultipleSegmentBase {
+	if list == nil {
+		return nil
+	}
+
+	return &list.MultipleSegmentBase
+}
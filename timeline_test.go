@@ -0,0 +1,155 @@
+package mpd_test
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"go.eigsys.de/go-mpd"
+	"testing"
+)
+
+func uint64Ptr(value uint64) *uint64 {
+	return &value
+}
+
+func TestSegmentTimeline_Append(t *testing.T) {
+	timeline := &mpd.SegmentTimeline{}
+	timeline.Append(100, 10)
+	timeline.Append(110, 10)
+	timeline.Append(120, 10)
+	timeline.Append(130, 5)
+	timeline.Append(140, 10)
+	timeline.Append(150, 10)
+	timeline.Append(155, 10)
+
+	wantTimeline := &mpd.SegmentTimeline{S: []mpd.S{
+		{T: uint64Ptr(100), D: 10, R: 2},
+		{D: 5},
+		{T: uint64Ptr(140), D: 10, R: 1},
+		{T: uint64Ptr(155), D: 10},
+	}}
+
+	if diff := cmp.Diff(timeline, wantTimeline); diff != "" {
+		t.Errorf("wrong SegmentTimeline: %s", diff)
+	}
+}
+
+func TestSegmentTimeline_Normalize(t *testing.T) {
+	timeline := &mpd.SegmentTimeline{S: []mpd.S{
+		{T: uint64Ptr(100), D: 10},
+		{T: uint64Ptr(110), D: 10},
+		{D: 10, R: 1},
+		{T: uint64Ptr(140), D: 5},
+		{D: 5},
+		{T: uint64Ptr(160), D: 10},
+		{T: uint64Ptr(165), D: 10},
+		{D: 10, N: 9},
+		{D: 10, K: 2},
+		{D: 10, K: 2},
+		{D: 10, R: -1},
+		{T: uint64Ptr(300), D: 10},
+		{D: 10},
+	}}
+	timeline.Normalize()
+
+	wantTimeline := &mpd.SegmentTimeline{S: []mpd.S{
+		{T: uint64Ptr(100), D: 10, R: 3},
+		{D: 5, R: 1},
+		{T: uint64Ptr(160), D: 10},
+		{T: uint64Ptr(165), D: 10},
+		{D: 10, N: 9},
+		{D: 10, K: 2, R: 1},
+		{D: 10, R: -1},
+		{T: uint64Ptr(300), D: 10, R: 1},
+	}}
+
+	if diff := cmp.Diff(timeline, wantTimeline); diff != "" {
+		t.Errorf("wrong SegmentTimeline: %s", diff)
+	}
+
+	// Without S@t, the first S element starts at 0.
+	timeline = &mpd.SegmentTimeline{S: []mpd.S{{D: 2}, {D: 2}, {D: 2}, {D: 3}}}
+	wantSegments := expandTimeline(timeline.S)
+	timeline.Normalize()
+
+	if diff := cmp.Diff(timeline, &mpd.SegmentTimeline{S: []mpd.S{{D: 2, R: 2}, {D: 3}}}); diff != "" {
+		t.Errorf("wrong SegmentTimeline: %s", diff)
+	}
+
+	if diff := cmp.Diff(expandTimeline(timeline.S), wantSegments); diff != "" {
+		t.Errorf("wrong segments: %s", diff)
+	}
+}
+
+// expandTimeline returns the start and duration of every segment of S elements without open-ended repeats.
+func expandTimeline(timeline []mpd.S) [][2]uint64 {
+	var (
+		segments [][2]uint64
+		t        uint64
+	)
+
+	for _, s := range timeline {
+		if s.T != nil {
+			t = *s.T
+		}
+
+		for i := 0; i <= s.R; i++ {
+			segments = append(segments, [2]uint64{t, s.D})
+			t += s.D
+		}
+	}
+
+	return segments
+}
+
+func TestMPD_NormalizeSegmentTimelines(t *testing.T) {
+	testMPD, err := mpd.Read(mustOpenFixture("zencoder/segment_timeline.mpd"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var wantSegments [][]mpd.Segment
+
+	// Expand all SegmentTimelines to one S element with an explicit start time per segment.
+	for i := range testMPD.Period[0].AdaptationSet {
+		adaptationSet := &testMPD.Period[0].AdaptationSet[i]
+
+		for j := range adaptationSet.Representation {
+			representation := &adaptationSet.Representation[j]
+
+			segments, err := testMPD.Segments(&testMPD.Period[0], adaptationSet, representation, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			wantSegments = append(wantSegments, segments)
+
+			timeline := representation.SegmentTemplate.SegmentTimeline
+			timeline.S = nil
+
+			for _, segment := range segments {
+				timeline.S = append(timeline.S, mpd.S{T: uint64Ptr(segment.Time), D: segment.Duration})
+			}
+		}
+	}
+
+	testMPD.NormalizeSegmentTimelines()
+
+	wantMPD, _ := mpd.Read(mustOpenFixture("zencoder/segment_timeline.mpd"))
+	if diff := cmp.Diff(testMPD, wantMPD); diff != "" {
+		t.Errorf("wrong MPD: %s", diff)
+	}
+
+	var segments [][]mpd.Segment
+
+	for i := range testMPD.Period[0].AdaptationSet {
+		adaptationSet := &testMPD.Period[0].AdaptationSet[i]
+
+		for j := range adaptationSet.Representation {
+			representationSegments, _ := testMPD.Segments(&testMPD.Period[0], adaptationSet, &adaptationSet.Representation[j], nil)
+			segments = append(segments, representationSegments)
+		}
+	}
+
+	if diff := cmp.Diff(segments, wantSegments); diff != "" {
+		t.Errorf("wrong segments: %s", diff)
+	}
+}
@@ -0,0 +1,142 @@
+package mpd
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+var ErrTimeMapping = errors.New("cannot map time")
+
+// TimeMapping converts between the media timeline of a Representation, the presentation timeline of the MPD and
+// wall-clock time with exact rational arithmetic. Media times are in Timescale units, presentation times are in
+// seconds relative to the start of the media presentation. Media times and presentation times are not limited to
+// the Period.
+type TimeMapping struct {
+	Timescale              uint64
+	PresentationTimeOffset uint64
+	EPTDelta               int64
+
+	// PeriodStart is in seconds relative to the start of the media presentation.
+	PeriodStart *big.Rat
+
+	// AvailabilityStartTime is zero if the MPD has none, e.g. if it is static.
+	AvailabilityStartTime time.Time
+
+	// LeapSecondOffset is the change of the leap second offset of LeapSecondInformation in seconds, which applies
+	// from NextLeapChangeTime on. Both are zero without a signalled change.
+	LeapSecondOffset   int
+	NextLeapChangeTime time.Time
+}
+
+// TimeMapping resolves the timing of the Representation. Timescale, PresentationTimeOffset and EPTDelta are
+// inherited from the Period and AdaptationSet.
+func (m *MPD) TimeMapping(period *Period, adaptationSet *AdaptationSet, representation *Representation) (*TimeMapping, error) {
+	index := m.periodIndex(period)
+	if index < 0 {
+		return nil, fmt.Errorf("%w: period not part of the MPD", ErrTimeMapping)
+	}
+
+	periodStart, err := m.periodStartSeconds(index)
+	if err != nil {
+		return nil, errors.Join(ErrTimeMapping, err)
+	}
+
+	segmentBase := effectiveSegmentBase(period, adaptationSet, representation)
+	mapping := &TimeMapping{
+		Timescale:              uint64(maxUint(segmentBase.Timescale, 1)),
+		PresentationTimeOffset: segmentBase.PresentationTimeOffset,
+		EPTDelta:               int64(segmentBase.EPTDelta),
+		PeriodStart:            periodStart,
+	}
+
+	if m.AvailabilityStartTime != "" {
+		if mapping.AvailabilityStartTime, err = ParseDateTime(m.AvailabilityStartTime); err != nil {
+			return nil, errors.Join(ErrTimeMapping, err)
+		}
+	}
+
+	if leap := m.LeapSecondInformation; leap != nil && leap.NextLeapChangeTime != "" {
+		if mapping.NextLeapChangeTime, err = ParseDateTime(leap.NextLeapChangeTime); err != nil {
+			return nil, errors.Join(ErrTimeMapping, err)
+		}
+
+		mapping.LeapSecondOffset = leap.NextAvailabilityStartLeapOffset - leap.AvailabilityStartLeapOffset
+	}
+
+	return mapping, nil
+}
+
+// MediaToPresentation converts a media time to a presentation time.
+func (t *TimeMapping) MediaToPresentation(mediaTime uint64) *big.Rat {
+	offset := new(big.Int).Sub(new(big.Int).SetUint64(mediaTime), new(big.Int).SetUint64(t.PresentationTimeOffset))
+	presentationTime := new(big.Rat).SetFrac(offset, new(big.Int).SetUint64(t.Timescale))
+
+	return presentationTime.Add(presentationTime, t.PeriodStart)
+}
+
+// PresentationToMedia converts a presentation time to a media time, which is fractional if the presentation time
+// does not fall on a tick.
+func (t *TimeMapping) PresentationToMedia(presentationTime *big.Rat) *big.Rat {
+	mediaTime := new(big.Rat).Sub(presentationTime, t.PeriodStart)
+	mediaTime.Mul(mediaTime, new(big.Rat).SetInt(new(big.Int).SetUint64(t.Timescale)))
+
+	return mediaTime.Add(mediaTime, new(big.Rat).SetInt(new(big.Int).SetUint64(t.PresentationTimeOffset)))
+}
+
+// EarliestPresentationTime returns the presentation time of the earliest media sample of the Representation in the
+// Period, which is the PresentationTimeOffset corrected by EPTDelta.
+func (t *TimeMapping) EarliestPresentationTime() *big.Rat {
+	presentationTime := big.NewRat(t.EPTDelta, int64(t.Timescale))
+
+	return presentationTime.Add(presentationTime, t.PeriodStart)
+}
+
+// PresentationToWallClock converts a presentation time to wall-clock time in UTC, rounded to nanoseconds. From
+// NextLeapChangeTime on, the wall-clock time is corrected by the leap seconds inserted since the
+// AvailabilityStartTime, so that the second of an inserted leap second repeats.
+func (t *TimeMapping) PresentationToWallClock(presentationTime *big.Rat) (time.Time, error) {
+	if t.AvailabilityStartTime.IsZero() {
+		return time.Time{}, fmt.Errorf("%w: no availabilityStartTime", ErrTimeMapping)
+	}
+
+	wallClock := t.AvailabilityStartTime.Add(roundDuration(presentationTime))
+
+	if corrected := wallClock.Add(-time.Duration(t.LeapSecondOffset) * time.Second); !t.NextLeapChangeTime.IsZero() && !corrected.Before(t.NextLeapChangeTime) {
+		wallClock = corrected
+	}
+
+	return wallClock.UTC(), nil
+}
+
+// WallClockToPresentation converts a wall-clock time to a presentation time. During a repeated leap second, the
+// later presentation time is returned.
+func (t *TimeMapping) WallClockToPresentation(wallClock time.Time) (*big.Rat, error) {
+	if t.AvailabilityStartTime.IsZero() {
+		return nil, fmt.Errorf("%w: no availabilityStartTime", ErrTimeMapping)
+	}
+
+	presentationTime := wallClock.Sub(t.AvailabilityStartTime)
+
+	if !t.NextLeapChangeTime.IsZero() && !wallClock.Before(t.NextLeapChangeTime) {
+		presentationTime += time.Duration(t.LeapSecondOffset) * time.Second
+	}
+
+	return big.NewRat(int64(presentationTime), int64(time.Second)), nil
+}
+
+// MediaToWallClock converts a media time to wall-clock time like PresentationToWallClock.
+func (t *TimeMapping) MediaToWallClock(mediaTime uint64) (time.Time, error) {
+	return t.PresentationToWallClock(t.MediaToPresentation(mediaTime))
+}
+
+// WallClockToMedia converts a wall-clock time to a media time like WallClockToPresentation.
+func (t *TimeMapping) WallClockToMedia(wallClock time.Time) (*big.Rat, error) {
+	presentationTime, err := t.WallClockToPresentation(wallClock)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.PresentationToMedia(presentationTime), nil
+}
@@ -0,0 +1,139 @@
+package mpd_test
+
+import (
+	"errors"
+	"go.eigsys.de/go-mpd"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func newTimeMappingMPD() *mpd.MPD {
+	return &mpd.MPD{
+		Type:                  mpd.DynamicPresentationType,
+		AvailabilityStartTime: "2024-01-01T00:00:00Z",
+		LeapSecondInformation: &mpd.LeapSecondInformation{
+			AvailabilityStartLeapOffset:     37,
+			NextAvailabilityStartLeapOffset: 38,
+			NextLeapChangeTime:              "2024-01-01T00:00:20Z",
+		},
+		Period: []mpd.Period{{
+			Start: "PT10S",
+			SegmentTemplate: &mpd.SegmentTemplate{
+				MultipleSegmentBase: mpd.MultipleSegmentBase{SegmentBase: mpd.SegmentBase{Timescale: 30000, PresentationTimeOffset: 300000}},
+			},
+			AdaptationSet: []mpd.AdaptationSet{{
+				SegmentTemplate: &mpd.SegmentTemplate{
+					MultipleSegmentBase: mpd.MultipleSegmentBase{SegmentBase: mpd.SegmentBase{EPTDelta: 3003}},
+				},
+				Representation: []mpd.Representation{{ID: "v1"}},
+			}},
+		}},
+	}
+}
+
+func TestMPD_TimeMapping(t *testing.T) {
+	testMPD := newTimeMappingMPD()
+	period := &testMPD.Period[0]
+
+	mapping, err := testMPD.TimeMapping(period, &period.AdaptationSet[0], &period.AdaptationSet[0].Representation[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 1001 ticks at 30000/s do not fit into nanoseconds.
+	presentationTime := mapping.MediaToPresentation(301001)
+	if want := big.NewRat(301001, 30000); presentationTime.Cmp(want) != 0 {
+		t.Errorf("wrong presentation time: %s", presentationTime)
+	}
+
+	if mediaTime := mapping.PresentationToMedia(presentationTime); mediaTime.Cmp(big.NewRat(301001, 1)) != 0 {
+		t.Errorf("wrong media time: %s", mediaTime)
+	}
+
+	if presentationTime := mapping.MediaToPresentation(0); presentationTime.Cmp(big.NewRat(0, 1)) != 0 {
+		t.Errorf("wrong presentation time: %s", presentationTime)
+	}
+
+	if presentationTime := mapping.EarliestPresentationTime(); presentationTime.Cmp(big.NewRat(101001, 10000)) != 0 {
+		t.Errorf("wrong earliest presentation time: %s", presentationTime)
+	}
+
+	// Period@start is not rounded to nanoseconds.
+	testMPD.Period = append([]mpd.Period{{Start: "PT0.0000000001S", Duration: "PT10.0000000004S"}}, testMPD.Period[0])
+	testMPD.Period[1].Start = ""
+
+	if mapping, err := testMPD.TimeMapping(&testMPD.Period[1], &testMPD.Period[1].AdaptationSet[0], &testMPD.Period[1].AdaptationSet[0].Representation[0]); err != nil || mapping.PeriodStart.Cmp(big.NewRat(20000000001, 2000000000)) != 0 {
+		t.Errorf("unexpected result: %v %v", mapping, err)
+	}
+
+	testMPD.Period = testMPD.Period[1:]
+	testMPD.Period[0].Start = "PT10S"
+	period = &testMPD.Period[0]
+
+	for _, wallClock := range []struct {
+		mediaTime uint64
+		want      string
+		presented *big.Rat
+	}{
+		{mediaTime: 301001, want: "2024-01-01T00:00:10.033366667Z", presented: big.NewRat(10033366667, 1000000000)},
+		// The leap second repeats the wall-clock times from 00:00:20, which map back to the later presentation time.
+		{mediaTime: 600000, want: "2024-01-01T00:00:20Z", presented: big.NewRat(21, 1)},
+		{mediaTime: 615000, want: "2024-01-01T00:00:20.5Z", presented: big.NewRat(43, 2)},
+		{mediaTime: 630000, want: "2024-01-01T00:00:20Z", presented: big.NewRat(21, 1)},
+		{mediaTime: 900000, want: "2024-01-01T00:00:29Z", presented: big.NewRat(30, 1)},
+	} {
+		got, err := mapping.MediaToWallClock(wallClock.mediaTime)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if mpd.FormatDateTime(got) != wallClock.want {
+			t.Errorf("%d: wrong wall-clock time: %s", wallClock.mediaTime, mpd.FormatDateTime(got))
+		}
+
+		if mediaTime, err := mapping.WallClockToMedia(got); err != nil || mediaTime.Cmp(mapping.PresentationToMedia(wallClock.presented)) != 0 {
+			t.Errorf("%d: wrong media time: %s %v", wallClock.mediaTime, mediaTime, err)
+		}
+	}
+}
+
+func TestMPD_TimeMapping_Errors(t *testing.T) {
+	testCases := map[string]func(*mpd.MPD){
+		"invalid period start":          func(m *mpd.MPD) { m.Period[0].Start = "invalid" },
+		"invalid availabilityStartTime": func(m *mpd.MPD) { m.AvailabilityStartTime = "invalid" },
+		"invalid nextLeapChangeTime":    func(m *mpd.MPD) { m.LeapSecondInformation.NextLeapChangeTime = "invalid" },
+	}
+
+	for name, mutate := range testCases {
+		testMPD := newTimeMappingMPD()
+		mutate(testMPD)
+
+		period := &testMPD.Period[0]
+		if _, err := testMPD.TimeMapping(period, &period.AdaptationSet[0], &period.AdaptationSet[0].Representation[0]); !errors.Is(err, mpd.ErrTimeMapping) {
+			t.Errorf("%s: wrong error: %v", name, err)
+		}
+	}
+
+	testMPD := newTimeMappingMPD()
+	period := testMPD.Period[0]
+
+	if _, err := testMPD.TimeMapping(&period, &period.AdaptationSet[0], &period.AdaptationSet[0].Representation[0]); !errors.Is(err, mpd.ErrTimeMapping) {
+		t.Errorf("wrong error: %v", err)
+	}
+
+	testMPD.AvailabilityStartTime = ""
+
+	mapping, err := testMPD.TimeMapping(&testMPD.Period[0], &period.AdaptationSet[0], &period.AdaptationSet[0].Representation[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := mapping.MediaToWallClock(0); !errors.Is(err, mpd.ErrTimeMapping) {
+		t.Errorf("wrong error: %v", err)
+	}
+
+	if _, err := mapping.WallClockToMedia(time.Now()); !errors.Is(err, mpd.ErrTimeMapping) {
+		t.Errorf("wrong error: %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package mpd
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+var ErrParseUUID = errors.New("cannot parse UUID")
+
+// UUID as per RFC 4122, e.g. a DRM system ID or a key ID (KID).
+type UUID [16]byte
+
+// ParseUUID parses a UUID in the canonical form, e.g. "08e36702-8f33-436c-a5dd-60ffe5571e60".
+// The hyphens may be omitted.
+func ParseUUID(value string) (UUID, error) {
+	var uuid UUID
+
+	if len(value) == 36 {
+		for _, position := range []int{8, 13, 18, 23} {
+			if value[position] != '-' {
+				return uuid, ErrParseUUID
+			}
+		}
+
+		value = strings.ReplaceAll(value, "-", "")
+	}
+
+	if len(value) != 32 {
+		return uuid, ErrParseUUID
+	}
+
+	if _, err := hex.Decode(uuid[:], []byte(value)); err != nil {
+		return uuid, errors.Join(ErrParseUUID, err)
+	}
+
+	return uuid, nil
+}
+
+// String returns the canonical form of the UUID.
+func (u UUID) String() string {
+	h := hex.EncodeToString(u[:])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (u *UUID) UnmarshalText(text []byte) error {
+	uuid, err := ParseUUID(string(text))
+	if err != nil {
+		return err
+	}
+
+	*u = uuid
+
+	return nil
+}
+
+// NewUUIDSchemeIDURI creates a "urn:uuid:" scheme identifier, e.g. for a DRM system ID.
+func NewUUIDSchemeIDURI(uuid UUID) SchemeIDURI {
+	return SchemeIDURI("urn:uuid:" + uuid.String())
+}
+
+// UUID returns the UUID of a "urn:uuid:" scheme identifier.
+func (s SchemeIDURI) UUID() (UUID, error) {
+	value := string(s)
+	if len(value) < 9 || !strings.EqualFold(value[:9], "urn:uuid:") {
+		return UUID{}, ErrParseUUID
+	}
+
+	return ParseUUID(value[9:])
+}
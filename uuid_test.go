@@ -0,0 +1,75 @@
+package mpd_test
+
+import (
+	"errors"
+	"go.eigsys.de/go-mpd"
+	"testing"
+)
+
+func TestParseUUID(t *testing.T) {
+	type TestCase struct {
+		input      string
+		wantString string
+		wantErr    error
+	}
+
+	testCases := []TestCase{
+		{input: "08e36702-8f33-436c-a5dd-60ffe5571e60", wantString: "08e36702-8f33-436c-a5dd-60ffe5571e60"},
+		{input: "08E367028F33436CA5DD60FFE5571E60", wantString: "08e36702-8f33-436c-a5dd-60ffe5571e60"},
+		{input: "08e36702+8f33-436c-a5dd-60ffe5571e60", wantErr: mpd.ErrParseUUID},
+		{input: "08e36702-8f33-436c-a5dd-60ffe5571e6", wantErr: mpd.ErrParseUUID},
+		{input: "08e36702-8f33-436c-a5dd-60ffe5571e6x", wantErr: mpd.ErrParseUUID},
+		{input: "", wantErr: mpd.ErrParseUUID},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.input, func(t *testing.T) {
+			uuid, err := mpd.ParseUUID(testCase.input)
+			if !errors.Is(err, testCase.wantErr) {
+				t.Errorf("wrong error: %v", err)
+			}
+
+			if err == nil && uuid.String() != testCase.wantString {
+				t.Errorf("wrong UUID: %s", uuid)
+			}
+		})
+	}
+}
+
+func TestUUID_Text(t *testing.T) {
+	var uuid mpd.UUID
+	if err := uuid.UnmarshalText([]byte("edef8ba9-79d6-4ace-a3c8-27dcd51d21ed")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if uuid != mpd.WidevineSystemID {
+		t.Errorf("wrong UUID: %s", uuid)
+	}
+
+	text, err := uuid.MarshalText()
+	if err != nil || string(text) != "edef8ba9-79d6-4ace-a3c8-27dcd51d21ed" {
+		t.Errorf("wrong text: %s", text)
+	}
+
+	if err := uuid.UnmarshalText([]byte("invalid")); !errors.Is(err, mpd.ErrParseUUID) {
+		t.Errorf("wrong error: %v", err)
+	}
+}
+
+func TestSchemeIDURI_UUID(t *testing.T) {
+	if uuid, err := mpd.PlayReadySchemeIDURI.UUID(); err != nil || uuid != mpd.PlayReadySystemID {
+		t.Errorf("wrong UUID: %s", uuid)
+	}
+
+	if uuid, err := mpd.SchemeIDURI("URN:UUID:9A04F079-9840-4286-AB92-E65BE0885F95").UUID(); err != nil || uuid != mpd.PlayReadySystemID {
+		t.Errorf("wrong UUID: %s", uuid)
+	}
+
+	if _, err := mpd.MP4Protection2011SchemeIDURI.UUID(); !errors.Is(err, mpd.ErrParseUUID) {
+		t.Errorf("wrong error: %v", err)
+	}
+
+	if schemeIDURI := mpd.NewUUIDSchemeIDURI(mpd.WidevineSystemID); schemeIDURI != mpd.WidevineSchemeIDURI {
+		t.Errorf("wrong scheme: %s", schemeIDURI)
+	}
+}
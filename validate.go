@@ -0,0 +1,252 @@
+package mpd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidMPD = errors.New("invalid MPD")
+
+// Validate checks the MPD against conformance rules of ISO/IEC 23009-1 and returns all violations joined, each
+// wrapping ErrInvalidMPD. It covers the presence and syntax of mandatory attributes, the uniqueness of IDs, timing
+// and segment addressing, but does not fetch any resources.
+func (m *MPD) Validate() error {
+	v := &validator{mpd: m}
+	v.validateMPD()
+
+	for i := range m.Period {
+		v.validatePeriod(i)
+	}
+
+	for _, ref := range m.DanglingContentProtectionRefs() {
+		v.fail("MPD", "ContentProtection@ref %q does not resolve", ref)
+	}
+
+	return errors.Join(v.errs...)
+}
+
+type validator struct {
+	mpd  *MPD
+	errs []error
+}
+
+func (v *validator) fail(location, format string, args ...any) {
+	v.errs = append(v.errs, fmt.Errorf("%w: %s: %s", ErrInvalidMPD, location, fmt.Sprintf(format, args...)))
+}
+
+func (v *validator) duration(location, name, value string) {
+	if value == "" {
+		return
+	}
+
+	if _, err := ParseDuration(value); err != nil {
+		v.fail(location, "invalid @%s %q", name, value)
+	}
+}
+
+func (v *validator) dateTime(location, name, value string) {
+	if value == "" {
+		return
+	}
+
+	if _, err := ParseDateTime(value); err != nil {
+		v.fail(location, "invalid @%s %q", name, value)
+	}
+}
+
+func (v *validator) validateMPD() {
+	m := v.mpd
+
+	if m.Profiles == "" {
+		v.fail("MPD", "missing @profiles")
+	}
+
+	if m.MinBufferTime == "" {
+		v.fail("MPD", "missing @minBufferTime")
+	}
+
+	v.duration("MPD", "mediaPresentationDuration", m.MediaPresentationDuration)
+	v.duration("MPD", "minimumUpdatePeriod", m.MinimumUpdatePeriod)
+	v.duration("MPD", "minBufferTime", m.MinBufferTime)
+	v.duration("MPD", "timeShiftBufferDepth", m.TimeShiftBufferDepth)
+	v.duration("MPD", "suggestedPresentationDelay", m.SuggestedPresentationDelay)
+	v.duration("MPD", "maxSegmentDuration", m.MaxSegmentDuration)
+	v.duration("MPD", "maxSubsegmentDuration", m.MaxSubsegmentDuration)
+	v.dateTime("MPD", "availabilityStartTime", m.AvailabilityStartTime)
+	v.dateTime("MPD", "availabilityEndTime", m.AvailabilityEndTime)
+	v.dateTime("MPD", "publishTime", m.PublishTime)
+
+	switch m.Type {
+	case DynamicPresentationType:
+		if m.AvailabilityStartTime == "" {
+			v.fail("MPD", "dynamic MPD without @availabilityStartTime")
+		}
+	case "", StaticPresentationType:
+		if m.MinimumUpdatePeriod != "" {
+			v.fail("MPD", "static MPD with @minimumUpdatePeriod")
+		}
+
+		if m.MediaPresentationDuration == "" && len(m.Period) > 0 && m.Period[len(m.Period)-1].Duration == "" {
+			v.fail("MPD", "static MPD without @mediaPresentationDuration and duration of the last Period")
+		}
+	default:
+		v.fail("MPD", "invalid @type %q", m.Type)
+	}
+
+	if len(m.Period) == 0 && len(m.Location) == 0 && len(m.PatchLocation) == 0 {
+		v.fail("MPD", "no Period")
+	}
+
+	v.validateLowLatency()
+}
+
+// validateLowLatency checks that a low-latency MPD signals the clock synchronisation and the latency target players
+// need to play at the live edge.
+func (v *validator) validateLowLatency() {
+	m := v.mpd
+	if !m.IsLowLatency() {
+		return
+	}
+
+	if len(m.UTCTiming) == 0 {
+		v.fail("MPD", "low-latency MPD without UTCTiming")
+	}
+
+	for i := range m.Period {
+		if serviceLatency := m.ServiceLatency(&m.Period[i]); serviceLatency == nil || serviceLatency.Target == 0 {
+			v.fail(fmt.Sprintf("Period %d", i), "low-latency Period without ServiceDescription with Latency@target")
+		}
+	}
+}
+
+func (v *validator) validatePeriod(index int) {
+	period := &v.mpd.Period[index]
+	location := fmt.Sprintf("Period %d", index)
+
+	if period.ID == "" && v.mpd.Type == DynamicPresentationType {
+		v.fail(location, "missing @id in dynamic MPD")
+	}
+
+	for i := range v.mpd.Period[:index] {
+		if period.ID != "" && v.mpd.Period[i].ID == period.ID {
+			v.fail(location, "duplicate @id %q", period.ID)
+		}
+	}
+
+	v.duration(location, "start", period.Start)
+	v.duration(location, "duration", period.Duration)
+
+	if start, err := v.mpd.PeriodStart(index); err != nil {
+		v.fail(location, "%v", err)
+	} else if index > 0 {
+		if previous, err := v.mpd.PeriodStart(index - 1); err == nil && start < previous {
+			v.fail(location, "starts before the previous Period")
+		}
+	}
+
+	adaptationSetIDs := map[uint]bool{}
+	representationIDs := map[string]bool{}
+
+	for i := range period.AdaptationSet {
+		adaptationSet := &period.AdaptationSet[i]
+		adaptationSetLocation := fmt.Sprintf("%s: AdaptationSet %d", location, i)
+
+		if adaptationSet.ID != 0 {
+			if adaptationSetIDs[adaptationSet.ID] {
+				v.fail(adaptationSetLocation, "duplicate @id %d", adaptationSet.ID)
+			}
+
+			adaptationSetIDs[adaptationSet.ID] = true
+		}
+
+		if len(adaptationSet.Representation) == 0 {
+			v.fail(adaptationSetLocation, "no Representation")
+		}
+
+		for j := range adaptationSet.Representation {
+			representation := &adaptationSet.Representation[j]
+			representationLocation := fmt.Sprintf("%s: Representation %d", adaptationSetLocation, j)
+
+			switch {
+			case representation.ID == "":
+				v.fail(representationLocation, "missing @id")
+			case strings.ContainsAny(representation.ID, " \t\r\n"):
+				v.fail(representationLocation, "@id %q contains whitespace", representation.ID)
+			case representationIDs[representation.ID]:
+				v.fail(representationLocation, "duplicate @id %q", representation.ID)
+			}
+
+			representationIDs[representation.ID] = true
+
+			if representation.Bandwidth == 0 {
+				v.fail(representationLocation, "missing @bandwidth")
+			}
+
+			if representation.MIMEType == "" && adaptationSet.MIMEType == "" {
+				v.fail(representationLocation, "missing @mimeType")
+			}
+
+			v.validateSegmentInformation(representationLocation, period, adaptationSet, representation)
+		}
+	}
+}
+
+func (v *validator) validateSegmentInformation(location string, period *Period, adaptationSet *AdaptationSet, representation *Representation) {
+	segmentBase, segmentList, segmentTemplate := segmentInformation(period, adaptationSet, representation)
+
+	var multipleSegmentBase *MultipleSegmentBase
+
+	switch {
+	case segmentTemplate != nil:
+		multipleSegmentBase = &segmentTemplate.MultipleSegmentBase
+
+		if segmentTemplate.Media == "" {
+			v.fail(location, "SegmentTemplate without @media")
+		}
+
+		if strings.Contains(segmentTemplate.Media, "$Number") && strings.Contains(segmentTemplate.Media, "$Time") {
+			v.fail(location, "SegmentTemplate@media with both $Number$ and $Time$")
+		}
+
+		if segmentTemplate.SegmentTimeline == nil && segmentTemplate.Duration == 0 && segmentTemplate.Media != "" {
+			v.fail(location, "SegmentTemplate without SegmentTimeline or @duration")
+		}
+
+		for _, template := range []string{segmentTemplate.Media, segmentTemplate.Initialization, segmentTemplate.Index} {
+			if unknown := strings.Count(template, "$") - 2*len(templateIdentifierPattern.FindAllString(template, -1)); unknown != 0 {
+				v.fail(location, "invalid template %q", template)
+			}
+		}
+	case segmentList != nil:
+		multipleSegmentBase = &segmentList.MultipleSegmentBase
+
+		if len(segmentList.SegmentURL) > 1 && segmentList.SegmentTimeline == nil && segmentList.Duration == 0 {
+			v.fail(location, "SegmentList without SegmentTimeline or @duration")
+		}
+	case segmentBase != nil:
+		if _, _, err := segmentBase.IndexRange.Bounds(); segmentBase.IndexRange != "" && err != nil {
+			v.fail(location, "invalid SegmentBase@indexRange %q", segmentBase.IndexRange)
+		}
+	}
+
+	if multipleSegmentBase == nil || multipleSegmentBase.SegmentTimeline == nil {
+		return
+	}
+
+	timeline := multipleSegmentBase.SegmentTimeline.S
+	for i, s := range timeline {
+		switch {
+		case s.D == 0:
+			v.fail(location, "S %d without @d", i)
+		case s.R < -1:
+			v.fail(location, "S %d with invalid @r %d", i, s.R)
+		case s.R == -1 && i+1 < len(timeline) && timeline[i+1].T == nil:
+			v.fail(location, "S %d with @r -1 not followed by @t", i)
+		}
+
+		if i > 0 && s.T != nil && timeline[i-1].T != nil && *s.T < *timeline[i-1].T {
+			v.fail(location, "S %d starts before S %d", i, i-1)
+		}
+	}
+}
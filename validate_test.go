@@ -0,0 +1,251 @@
+package mpd_test
+
+import (
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"go.eigsys.de/go-mpd"
+	"testing"
+)
+
+func TestMPD_Validate(t *testing.T) {
+	for _, fixture := range []string{"zencoder/segment_timeline.mpd", "zencoder/segment_list.mpd", "zencoder/location.mpd"} {
+		testMPD, err := mpd.Read(mustOpenFixture(fixture))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := testMPD.Validate(); err != nil {
+			t.Errorf("%s: unexpected error: %v", fixture, err)
+		}
+	}
+}
+
+func TestMPD_Validate_Errors(t *testing.T) {
+	validMPD := func() *mpd.MPD {
+		return &mpd.MPD{
+			Profiles:                  mpd.Live2011Profile,
+			MinBufferTime:             "PT2S",
+			MediaPresentationDuration: "PT10S",
+			Period: []mpd.Period{{
+				ID: "0",
+				AdaptationSet: []mpd.AdaptationSet{{
+					ID:                 1,
+					RepresentationBase: mpd.RepresentationBase{MIMEType: mpd.VideoMP4MIMEType},
+					SegmentTemplate: &mpd.SegmentTemplate{
+						Media:               "$RepresentationID$/$Number%05d$.m4s",
+						Initialization:      "$RepresentationID$/init.mp4",
+						MultipleSegmentBase: mpd.MultipleSegmentBase{Duration: 2},
+					},
+					Representation: []mpd.Representation{{ID: "v1", Bandwidth: 1000}},
+				}},
+			}},
+		}
+	}
+
+	if err := validMPD().Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	testCases := map[string]struct {
+		mutate  func(m *mpd.MPD)
+		wantErr string
+	}{
+		"missing profiles": {
+			mutate:  func(m *mpd.MPD) { m.Profiles = "" },
+			wantErr: "invalid MPD: MPD: missing @profiles",
+		},
+		"missing minBufferTime": {
+			mutate:  func(m *mpd.MPD) { m.MinBufferTime = "" },
+			wantErr: "invalid MPD: MPD: missing @minBufferTime",
+		},
+		"invalid duration": {
+			mutate:  func(m *mpd.MPD) { m.TimeShiftBufferDepth = "2s" },
+			wantErr: `invalid MPD: MPD: invalid @timeShiftBufferDepth "2s"`,
+		},
+		"invalid date time": {
+			mutate:  func(m *mpd.MPD) { m.PublishTime = "yesterday" },
+			wantErr: `invalid MPD: MPD: invalid @publishTime "yesterday"`,
+		},
+		"dynamic without availabilityStartTime": {
+			mutate:  func(m *mpd.MPD) { m.Type = mpd.DynamicPresentationType },
+			wantErr: "invalid MPD: MPD: dynamic MPD without @availabilityStartTime",
+		},
+		"static with minimumUpdatePeriod": {
+			mutate:  func(m *mpd.MPD) { m.MinimumUpdatePeriod = "PT2S" },
+			wantErr: "invalid MPD: MPD: static MPD with @minimumUpdatePeriod",
+		},
+		"static without duration": {
+			mutate:  func(m *mpd.MPD) { m.MediaPresentationDuration = "" },
+			wantErr: "invalid MPD: MPD: static MPD without @mediaPresentationDuration and duration of the last Period",
+		},
+		"invalid type": {
+			mutate:  func(m *mpd.MPD) { m.Type = "live" },
+			wantErr: `invalid MPD: MPD: invalid @type "live"`,
+		},
+		"no period": {
+			mutate:  func(m *mpd.MPD) { m.Period = nil },
+			wantErr: "invalid MPD: MPD: no Period",
+		},
+		"dangling ContentProtection reference": {
+			mutate: func(m *mpd.MPD) {
+				m.Period[0].AdaptationSet[0].ContentProtection = []mpd.ContentProtection{{Ref: "drm"}}
+			},
+			wantErr: `invalid MPD: MPD: ContentProtection@ref "drm" does not resolve`,
+		},
+		"dynamic period without ID": {
+			mutate: func(m *mpd.MPD) {
+				m.Type = mpd.DynamicPresentationType
+				m.AvailabilityStartTime = "1970-01-01T00:00:00Z"
+				m.Period[0].ID = ""
+			},
+			wantErr: "invalid MPD: Period 0: missing @id in dynamic MPD",
+		},
+		"duplicate period ID": {
+			mutate: func(m *mpd.MPD) {
+				m.Period = append(m.Period, mpd.Period{ID: "0", Start: "PT5S"})
+			},
+			wantErr: `invalid MPD: Period 1: duplicate @id "0"`,
+		},
+		"period out of order": {
+			mutate: func(m *mpd.MPD) {
+				m.Period[0].Start = "PT5S"
+				m.Period = append(m.Period, mpd.Period{ID: "1", Start: "PT1S"})
+			},
+			wantErr: "invalid MPD: Period 1: starts before the previous Period",
+		},
+		"unknown period start": {
+			mutate: func(m *mpd.MPD) {
+				m.Period = append(m.Period, mpd.Period{ID: "1", Duration: "PT1S"})
+			},
+			wantErr: "invalid MPD: Period 1: cannot determine period timing: period 1 lacks start and previous period lacks duration",
+		},
+		"invalid period duration": {
+			mutate:  func(m *mpd.MPD) { m.Period[0].Duration = "10" },
+			wantErr: `invalid MPD: Period 0: invalid @duration "10"`,
+		},
+		"duplicate AdaptationSet ID": {
+			mutate: func(m *mpd.MPD) {
+				adaptationSet := m.Period[0].AdaptationSet[0]
+				adaptationSet.Representation = []mpd.Representation{{ID: "v2", Bandwidth: 2000}}
+				m.Period[0].AdaptationSet = append(m.Period[0].AdaptationSet, adaptationSet)
+			},
+			wantErr: "invalid MPD: Period 0: AdaptationSet 1: duplicate @id 1",
+		},
+		"no Representation": {
+			mutate:  func(m *mpd.MPD) { m.Period[0].AdaptationSet[0].Representation = nil },
+			wantErr: "invalid MPD: Period 0: AdaptationSet 0: no Representation",
+		},
+		"missing Representation ID": {
+			mutate:  func(m *mpd.MPD) { m.Period[0].AdaptationSet[0].Representation[0].ID = "" },
+			wantErr: "invalid MPD: Period 0: AdaptationSet 0: Representation 0: missing @id",
+		},
+		"Representation ID with whitespace": {
+			mutate:  func(m *mpd.MPD) { m.Period[0].AdaptationSet[0].Representation[0].ID = "v 1" },
+			wantErr: `invalid MPD: Period 0: AdaptationSet 0: Representation 0: @id "v 1" contains whitespace`,
+		},
+		"duplicate Representation ID": {
+			mutate: func(m *mpd.MPD) {
+				adaptationSet := &m.Period[0].AdaptationSet[0]
+				adaptationSet.Representation = append(adaptationSet.Representation, adaptationSet.Representation[0])
+			},
+			wantErr: `invalid MPD: Period 0: AdaptationSet 0: Representation 1: duplicate @id "v1"`,
+		},
+		"missing bandwidth": {
+			mutate:  func(m *mpd.MPD) { m.Period[0].AdaptationSet[0].Representation[0].Bandwidth = 0 },
+			wantErr: "invalid MPD: Period 0: AdaptationSet 0: Representation 0: missing @bandwidth",
+		},
+		"missing mimeType": {
+			mutate:  func(m *mpd.MPD) { m.Period[0].AdaptationSet[0].MIMEType = "" },
+			wantErr: "invalid MPD: Period 0: AdaptationSet 0: Representation 0: missing @mimeType",
+		},
+		"SegmentTemplate without media": {
+			mutate:  func(m *mpd.MPD) { m.Period[0].AdaptationSet[0].SegmentTemplate.Media = "" },
+			wantErr: "invalid MPD: Period 0: AdaptationSet 0: Representation 0: SegmentTemplate without @media",
+		},
+		"SegmentTemplate with Number and Time": {
+			mutate:  func(m *mpd.MPD) { m.Period[0].AdaptationSet[0].SegmentTemplate.Media = "$Number$-$Time$.m4s" },
+			wantErr: "invalid MPD: Period 0: AdaptationSet 0: Representation 0: SegmentTemplate@media with both $Number$ and $Time$",
+		},
+		"SegmentTemplate without timing": {
+			mutate:  func(m *mpd.MPD) { m.Period[0].AdaptationSet[0].SegmentTemplate.Duration = 0 },
+			wantErr: "invalid MPD: Period 0: AdaptationSet 0: Representation 0: SegmentTemplate without SegmentTimeline or @duration",
+		},
+		"invalid template": {
+			mutate:  func(m *mpd.MPD) { m.Period[0].AdaptationSet[0].SegmentTemplate.Initialization = "$Init$.mp4" },
+			wantErr: `invalid MPD: Period 0: AdaptationSet 0: Representation 0: invalid template "$Init$.mp4"`,
+		},
+		"SegmentList without timing": {
+			mutate: func(m *mpd.MPD) {
+				m.Period[0].AdaptationSet[0].SegmentTemplate = nil
+				m.Period[0].AdaptationSet[0].SegmentList = &mpd.SegmentList{SegmentURL: []mpd.SegmentURL{{Media: "1.m4s"}, {Media: "2.m4s"}}}
+			},
+			wantErr: "invalid MPD: Period 0: AdaptationSet 0: Representation 0: SegmentList without SegmentTimeline or @duration",
+		},
+		"invalid indexRange": {
+			mutate: func(m *mpd.MPD) {
+				m.Period[0].AdaptationSet[0].SegmentTemplate = nil
+				m.Period[0].AdaptationSet[0].SegmentBase = &mpd.SegmentBase{IndexRange: "100"}
+			},
+			wantErr: `invalid MPD: Period 0: AdaptationSet 0: Representation 0: invalid SegmentBase@indexRange "100"`,
+		},
+		"S without duration": {
+			mutate: func(m *mpd.MPD) {
+				m.Period[0].AdaptationSet[0].SegmentTemplate.SegmentTimeline = &mpd.SegmentTimeline{S: []mpd.S{{D: 2}, {}}}
+			},
+			wantErr: "invalid MPD: Period 0: AdaptationSet 0: Representation 0: S 1 without @d",
+		},
+		"S with invalid repeat": {
+			mutate: func(m *mpd.MPD) {
+				m.Period[0].AdaptationSet[0].SegmentTemplate.SegmentTimeline = &mpd.SegmentTimeline{S: []mpd.S{{D: 2, R: -2}}}
+			},
+			wantErr: "invalid MPD: Period 0: AdaptationSet 0: Representation 0: S 0 with invalid @r -2",
+		},
+		"open S not followed by t": {
+			mutate: func(m *mpd.MPD) {
+				m.Period[0].AdaptationSet[0].SegmentTemplate.SegmentTimeline = &mpd.SegmentTimeline{S: []mpd.S{{D: 2, R: -1}, {D: 2}}}
+			},
+			wantErr: "invalid MPD: Period 0: AdaptationSet 0: Representation 0: S 0 with @r -1 not followed by @t",
+		},
+		"S out of order": {
+			mutate: func(m *mpd.MPD) {
+				m.Period[0].AdaptationSet[0].SegmentTemplate.SegmentTimeline = &mpd.SegmentTimeline{S: []mpd.S{{T: uint64Ptr(10), D: 2}, {T: uint64Ptr(4), D: 2}}}
+			},
+			wantErr: "invalid MPD: Period 0: AdaptationSet 0: Representation 0: S 1 starts before S 0",
+		},
+		"low-latency without UTCTiming": {
+			mutate: func(m *mpd.MPD) {
+				m.Type = mpd.DynamicPresentationType
+				m.AvailabilityStartTime = "1970-01-01T00:00:00Z"
+				m.ServiceDescription = []mpd.ServiceDescription{{Latency: []mpd.Latency{{Target: 3000}}}}
+				m.Period[0].AdaptationSet[0].SegmentTemplate.AvailabilityTimeOffset = 1.5
+			},
+			wantErr: "invalid MPD: MPD: low-latency MPD without UTCTiming",
+		},
+		"low-latency without ServiceDescription": {
+			mutate: func(m *mpd.MPD) {
+				m.Type = mpd.DynamicPresentationType
+				m.AvailabilityStartTime = "1970-01-01T00:00:00Z"
+				m.UTCTiming = []mpd.Descriptor{{SchemeIDURI: "urn:mpeg:dash:utc:http-iso:2014", Value: "https://time.example.com/"}}
+				m.Period[0].ServiceDescription = []mpd.ServiceDescription{{PlaybackRate: []mpd.PlaybackRate{{Min: 0.96, Max: 1.04}}}}
+				m.Period[0].AdaptationSet[0].SegmentTemplate.AvailabilityTimeOffset = 1.5
+			},
+			wantErr: "invalid MPD: Period 0: low-latency Period without ServiceDescription with Latency@target",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			testMPD := validMPD()
+			testCase.mutate(testMPD)
+
+			err := testMPD.Validate()
+			if !errors.Is(err, mpd.ErrInvalidMPD) {
+				t.Fatalf("wrong error: %v", err)
+			}
+
+			if diff := cmp.Diff(err.Error(), testCase.wantErr); diff != "" {
+				t.Errorf("wrong error: %s", diff)
+			}
+		})
+	}
+}